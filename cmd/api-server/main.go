@@ -3,10 +3,13 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -22,32 +25,75 @@ import (
 	pkgcache "cinekami-server/pkg/cache"
 	pkgcrypto "cinekami-server/pkg/crypto"
 	pkgdb "cinekami-server/pkg/db"
+	pkgmetrics "cinekami-server/pkg/metrics"
 	pkgtmdb "cinekami-server/pkg/tmdb"
+	pkgtracing "cinekami-server/pkg/tracing"
+	pkgwebhook "cinekami-server/pkg/webhook"
 )
 
 func main() {
 	_ = godotenv.Load() // best-effort
-	cfg := config.FromEnv()
+	configPath := flag.String("config", "", "YAML config file (default $CONFIG_FILE); env vars override it")
+	printConfig := flag.Bool("print-config", false, "print the effective config with secrets redacted and exit")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if *printConfig {
+		b, yerr := cfg.Redacted().YAML()
+		if yerr != nil {
+			log.Fatal().Err(yerr).Msg("render config failed")
+		}
+		os.Stdout.Write(b)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid config")
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	pool, err := pkgdb.Connect(ctx, cfg.DatabaseURL)
+	shutdownTracing, err := pkgtracing.Setup(ctx, cfg.TracesExporter, "cinekami-server")
+	if err != nil {
+		log.Fatal().Err(err).Msg("tracing setup failed")
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Error().Err(err).Msg("tracing shutdown failed")
+		}
+	}()
+
+	pool, err := pkgdb.ConnectWith(ctx, cfg.DatabaseURL, pkgdb.PoolOptions(cfg.DB))
 	if err != nil {
 		log.Fatal().Err(err).Msg("db connect failed")
 	}
-	defer pool.Close()
+	if err := pkgmetrics.RegisterPool(pool); err != nil {
+		log.Error().Err(err).Msg("register pool metrics failed")
+	}
 
-	if err := migrate.Up(cfg.DatabaseURL); err != nil {
-		log.Fatal().Err(err).Msg("migrations failed")
+	if cfg.AutoMigrate {
+		if err := migrate.Up(cfg.DatabaseURL); err != nil {
+			log.Fatal().Err(err).Msg("migrations failed")
+		}
+	} else if s, err := migrate.Verify(cfg.DatabaseURL); err != nil {
+		// /readyz keeps failing until `cinekami migrate up` has run
+		log.Warn().Err(err).Uints("pending", s.Pending).Msg("auto-migrate disabled and schema not current")
 	}
 
 	var c pkgcache.Cache
+	cacheFallback := false
 	if addr := cfg.ValkeyAddr; addr != "" {
 		vc, err := pkgcache.NewValkey(addr, cfg.ValkeyPassword)
 		if err != nil {
 			log.Error().Err(err).Msg("valkey connect failed, using in-memory cache")
 			c = pkgcache.NewInMemory()
+			cacheFallback = true
 		} else {
 			c = vc
 		}
@@ -55,43 +101,151 @@ func main() {
 		c = pkgcache.NewInMemory()
 	}
 
+	c = pkgcache.NewInstrumented(c)
+
 	repository := repos.New(pool)
-	signer := pkgcrypto.NewHMAC(cfg.CursorSecret)
+	repository.Votes.Milestones = cfg.Webhooks.VoteMilestones
+	// The default region always has a board, even if the migration seed lacks it.
+	if err := repository.Regions.EnsureRegion(ctx, cfg.TMDBRegion, ""); err != nil {
+		log.Fatal().Err(err).Str("region", cfg.TMDBRegion).Msg("failed to ensure default region")
+	}
+	var previousSecrets [][]byte
+	for _, p := range cfg.CursorPreviousSecrets {
+		previousSecrets = append(previousSecrets, []byte(p))
+	}
+	signer := pkgcrypto.NewHMAC([]byte(cfg.CursorSecret), previousSecrets...)
+	signer.TTL = cfg.CursorTTL
 	api := server.New(repository, c, signer, cfg.CORSAllowedOrigins)
-
-	// Trigger a one-off test snapshot at startup (temporary for testing).
-	// Remove or comment this line after verification.
+	api.MaxBodyBytes = cfg.HTTP.MaxBodyBytes
+	api.MaxImportBytes = cfg.HTTP.MaxImportBytes
+	api.DefaultPageSize = cfg.Paging.DefaultLimit
+	api.MaxPageSize = cfg.Paging.MaxLimit
+	api.GraphQLMaxDepth = cfg.GraphQL.MaxDepth
+	api.GraphQLMaxComplexity = cfg.GraphQL.MaxComplexity
+	api.TallyWatchInterval = cfg.GRPC.WatchInterval
+	api.ActiveMoviesTTL = cfg.Cache.ActiveMoviesTTL
+	api.SnapshotsTTL = cfg.Cache.SnapshotsTTL
+	api.CacheFallback = cacheFallback
+
+	// Jobs get their own context so they keep running while HTTP drains,
+	// and are stopped (and awaited) only afterwards.
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	var jobsWG sync.WaitGroup
 
 	// Start background jobs
 	var tmdbClient *pkgtmdb.Client
 	if cfg.TMDBAPIKey != "" {
 		tmdbClient = pkgtmdb.New(cfg.TMDBAPIKey)
 	}
+	api.TMDB = tmdbClient
+	api.CheckTMDB = cfg.ReadyzCheckTMDB
+	api.AdminKeys = make(map[string]string, len(cfg.AdminAPIKeys))
+	for name, key := range cfg.AdminAPIKeys {
+		api.AdminKeys[key] = name
+	}
+	api.Jobs = jobs.NewGroup(jobsCtx, &jobsWG)
+	api.DefaultRegion = cfg.TMDBRegion
+	api.Languages = cfg.Languages()
+	api.PublicURL = cfg.PublicURL
+	api.EmbedOrigins = cfg.EmbedAllowedOrigins
+	api.Fingerprints = pkgcrypto.NewFingerprinter([]byte(cfg.FingerprintSecret))
+
+	// Voters stored before fingerprints were digested are rewritten once.
+	if n, err := repository.Voters.HashLegacyFingerprints(ctx, api.Fingerprints.Digest); err != nil {
+		log.Error().Err(err).Msg("failed to hash legacy voter fingerprints")
+	} else if n > 0 {
+		log.Info().Int64("voters", n).Msg("hashed legacy voter fingerprints")
+	}
 
 	if cfg.TMDBTestMode {
 		log.Info().Msg("TMDB test mode enabled; starting fast sync and one-off snapshot")
-		jobs.StartTMDBSyncTest(ctx, repository, tmdbClient, cfg.TMDBRegion, cfg.TMDBLanguage)
-		jobs.StartTestSnapshot(ctx, repository)
+		jobs.StartTMDBSyncTest(jobsCtx, &jobsWG, repository, tmdbClient, cfg.Languages(), cfg.Jobs.TestSyncInterval)
+		jobs.StartTestSnapshot(jobsCtx, &jobsWG, repository)
 	} else {
-		jobs.StartTMDBSync(ctx, repository, tmdbClient, cfg.TMDBRegion, cfg.TMDBLanguage)
+		jobs.StartTMDBSync(jobsCtx, &jobsWG, repository, tmdbClient, cfg.Languages(), cfg.Jobs.SyncWeekday(), cfg.Jobs.TMDBSyncHour)
 	}
 
 	// Seed movies once if table is empty (useful for testing/dev)
-	if err := jobs.SeedTMDBIfEmpty(ctx, repository, tmdbClient, cfg.TMDBRegion, cfg.TMDBLanguage); err != nil {
+	if err := jobs.SeedTMDBIfEmpty(ctx, repository, tmdbClient, cfg.Languages()); err != nil {
 		log.Error().Err(err).Msg("seed from TMDb failed")
 	}
 
-	jobs.StartMonthlySnapshot(ctx, repository)
-
-	addr := ":" + cfg.Port
+	jobs.StartMonthlySnapshot(jobsCtx, &jobsWG, repository)
+	jobs.StartVoterRetention(jobsCtx, &jobsWG, repository, cfg.Jobs.VoterRetention)
+	webhookSender := &pkgwebhook.Sender{Client: &http.Client{Timeout: cfg.Webhooks.Timeout}, UserAgent: "cinekami-webhooks/1"}
+	jobs.StartWebhookDelivery(jobsCtx, &jobsWG, repository, webhookSender, cfg.Webhooks.PollInterval, cfg.Webhooks.MaxAttempts)
+
+	srv := server.NewHTTPServer(":"+cfg.Port, api.Router(), cfg.HTTP)
+	adminSrv := server.NewHTTPServer(":"+cfg.AdminPort, api.AdminRouter(), cfg.HTTP)
+	serveErr := make(chan error, 3)
+	for name, s := range map[string]*http.Server{"api": srv, "admin": adminSrv} {
+		go func() {
+			log.Info().Str("server", name).Str("addr", s.Addr).Msg("listening")
+			if err := s.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- fmt.Errorf("%s server: %w", name, err)
+			}
+		}()
+	}
+	grpcSrv := api.GRPCServer()
+	grpcLis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatal().Err(err).Msg("grpc listen failed")
+	}
 	go func() {
-		log.Info().Str("addr", addr).Msg("listening")
-		if err := server.StartHTTP(ctx, addr, api.Router()); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatal().Err(err).Msg("server error")
+		log.Info().Str("server", "grpc").Str("addr", grpcLis.Addr().String()).Msg("listening")
+		if err := grpcSrv.Serve(grpcLis); err != nil {
+			serveErr <- fmt.Errorf("grpc server: %w", err)
 		}
 	}()
 
-	<-ctx.Done()
-	_, _ = fmt.Fprintln(os.Stderr, "shutting down...")
-	time.Sleep(200 * time.Millisecond)
+	select {
+	case <-ctx.Done():
+		log.Info().Msg("shutdown signal received")
+	case err := <-serveErr:
+		log.Error().Err(err).Msg("server error, shutting down")
+	}
+
+	// Shutdown order: fail readiness -> drain HTTP and gRPC -> stop and await jobs -> close cache -> close pool.
+	api.Draining.Store(true)
+	if cfg.HTTP.DrainDelay > 0 {
+		log.Info().Dur("delay", cfg.HTTP.DrainDelay).Msg("readiness failing; waiting before drain")
+		time.Sleep(cfg.HTTP.DrainDelay)
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("api server shutdown error")
+	}
+	if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("admin server shutdown error")
+	}
+	// WatchTallies streams end with Unavailable once draining; cut off what is left at the timeout
+	grpcDone := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(grpcDone)
+	}()
+	select {
+	case <-grpcDone:
+	case <-shutdownCtx.Done():
+		log.Warn().Msg("grpc calls did not finish before shutdown timeout")
+		grpcSrv.Stop()
+	}
+	stopJobs()
+	jobsDone := make(chan struct{})
+	go func() {
+		jobsWG.Wait()
+		close(jobsDone)
+	}()
+	select {
+	case <-jobsDone:
+	case <-shutdownCtx.Done():
+		log.Warn().Msg("background jobs did not stop before shutdown timeout")
+	}
+	if err := c.Close(); err != nil {
+		log.Error().Err(err).Msg("cache close error")
+	}
+	pool.Close()
+	log.Info().Msg("shutdown complete")
 }
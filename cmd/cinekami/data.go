@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"cinekami-server/internal/jobs"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkgexport "cinekami-server/pkg/export"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+type exportResult struct {
+	Region    string `json:"region"`
+	Month     string `json:"month"`
+	Snapshots int    `json:"snapshots"`
+	Out       string `json:"out"`
+}
+
+type bulkExportResult struct {
+	Kind   string `json:"kind"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Region string `json:"region,omitempty"`
+	Format string `json:"format"`
+	Rows   int    `json:"rows"`
+	Out    string `json:"out"`
+}
+
+// runExport handles "export --month YYYY-MM [--region XX] [--out file]": one
+// snapshot per line, the format import reads. "export snapshots|tallies|votes"
+// is the bulk export.
+func runExport(ctx context.Context, a *app, args []string) error {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return runBulkExport(ctx, a, args)
+	}
+	fs := a.flags("export", nil)
+	month := fs.String("month", "", "month to export (YYYY-MM)")
+	region := fs.String("region", a.cfg.TMDBRegion, "region to export")
+	out := fs.String("out", "-", "output file, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := time.Parse("2006-01", *month); err != nil {
+		return fmt.Errorf("%w: --month must be YYYY-MM", errUsage)
+	}
+	*region = strings.ToUpper(*region)
+	if !repos.ValidRegionCode(*region) {
+		return fmt.Errorf("%w: invalid --region %q", errUsage, *region)
+	}
+	if *out == "-" && a.json {
+		return fmt.Errorf("%w: --json needs --out, stdout carries the export", errUsage)
+	}
+	r, err := a.repository(ctx)
+	if err != nil {
+		return err
+	}
+	snaps, err := r.Snapshots.GetSnapshotsByMonth(ctx, *region, *month)
+	if err != nil {
+		return err
+	}
+
+	w := a.out
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, s := range snaps {
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if *out == "-" {
+		return nil
+	}
+	return a.emit(exportResult{Region: *region, Month: *month, Snapshots: len(snaps), Out: *out}, "exported %d snapshot(s) for %s in %s to %s", len(snaps), *month, *region, *out)
+}
+
+// runBulkExport handles "export snapshots|tallies|votes --from YYYY-MM [--to
+// YYYY-MM] [--region XX] [--format csv|ndjson|parquet] [--out file]", the CLI
+// side of GET /admin/export/*. Without --region every region is exported.
+func runBulkExport(ctx context.Context, a *app, args []string) error {
+	kind, rest, err := subcommand(args, "snapshots", "tallies", "votes")
+	if err != nil {
+		return err
+	}
+	fs := a.flags("export "+kind, nil)
+	from := fs.String("from", "", "first month (YYYY-MM)")
+	to := fs.String("to", "", "last month (YYYY-MM), defaults to --from")
+	region := fs.String("region", "", "region to export, all when empty")
+	formatName := fs.String("format", string(pkgexport.NDJSON), "csv, ndjson or parquet")
+	out := fs.String("out", "-", "output file, - for stdout")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	if *to == "" {
+		*to = *from
+	}
+	f := repos.ExportFilter{From: *from, To: *to, Region: strings.ToUpper(*region)}
+	if err := f.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if f.Region != "" && !repos.ValidRegionCode(f.Region) {
+		return fmt.Errorf("%w: invalid --region %q", errUsage, *region)
+	}
+	format, ok := pkgexport.ParseFormat(*formatName)
+	if !ok {
+		return fmt.Errorf("%w: --format must be one of %v", errUsage, pkgexport.Formats)
+	}
+	if *out == "-" && a.json {
+		return fmt.Errorf("%w: --json needs --out, stdout carries the export", errUsage)
+	}
+	r, err := a.repository(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := a.out
+	if *out != "-" {
+		fh, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		w = fh
+	}
+	var rows int
+	switch kind {
+	case "snapshots":
+		rows, err = writeExport(ctx, w, format, f, r.Exports.ExportSnapshots)
+	case "tallies":
+		rows, err = writeExport(ctx, w, format, f, r.Exports.ExportTallies)
+	default:
+		rows, err = writeExport(ctx, w, format, f, r.Exports.ExportVotes)
+	}
+	if err != nil {
+		return err
+	}
+	if *out == "-" {
+		return nil
+	}
+	return a.emit(bulkExportResult{Kind: kind, From: f.From, To: f.To, Region: f.Region, Format: string(format), Rows: rows, Out: *out},
+		"exported %d %s row(s) for %s..%s to %s", rows, kind, f.From, f.To, *out)
+}
+
+func writeExport[T pkgexport.Record](ctx context.Context, w io.Writer, format pkgexport.Format, f repos.ExportFilter,
+	stream func(context.Context, repos.ExportFilter, func(T) error) error) (int, error) {
+	out := pkgexport.NewWriter[T](w, format)
+	n := 0
+	err := stream(ctx, f, func(row T) error {
+		n++
+		return out.Write(row)
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, out.Close()
+}
+
+// runImport handles "import [snapshots|movies] [--in file] [--format csv|ndjson]
+// [--region XX]": imports an archive, snapshots when no kind is given. Rows
+// without a region go to --region. Invalid rows are logged and counted; the
+// rest are still imported, each month of snapshots in one transaction.
+// Snapshot movies missing from the database are fetched from TMDb when
+// TMDB_API_KEY is set.
+func runImport(ctx context.Context, a *app, args []string) error {
+	kind := "snapshots"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		var err error
+		if kind, args, err = subcommand(args, "snapshots", "movies"); err != nil {
+			return err
+		}
+	}
+	var dryRun bool
+	fs := a.flags("import", &dryRun)
+	in := fs.String("in", "-", "input file, - for stdin")
+	formatName := fs.String("format", "", "csv or ndjson, by default from the --in extension")
+	region := fs.String("region", a.cfg.TMDBRegion, "region for rows that carry none")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	*region = strings.ToUpper(*region)
+	if !repos.ValidRegionCode(*region) {
+		return fmt.Errorf("%w: invalid --region %q", errUsage, *region)
+	}
+	format := pkgexport.NDJSON
+	if *formatName == "" && strings.EqualFold(filepath.Ext(*in), ".csv") {
+		format = pkgexport.CSV
+	} else if *formatName != "" {
+		f, ok := pkgexport.ParseFormat(*formatName)
+		if !ok || f == pkgexport.Parquet {
+			return fmt.Errorf("%w: --format must be csv or ndjson", errUsage)
+		}
+		format = f
+	}
+	var rd io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		rd = f
+	}
+	r, err := a.repository(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts := jobs.ImportOptions{Format: format, Region: *region, DryRun: dryRun}
+	if a.cfg.TMDBAPIKey != "" {
+		opts.TMDB = pkgtmdb.New(a.cfg.TMDBAPIKey)
+	}
+	var sum model.ImportSummary
+	if kind == "movies" {
+		sum, err = jobs.ImportMovies(ctx, r, rd, opts)
+	} else {
+		sum, err = jobs.ImportSnapshots(ctx, r, rd, opts)
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range sum.Errors {
+		log.Warn().Int("line", e.Line).Int64("movie_id", e.MovieID).Str("error", e.Error).Msg("rejected " + kind + " row")
+	}
+	verb := "imported"
+	if dryRun {
+		verb = "would import"
+	}
+	return a.emit(sum, "%s %s: %d created, %d updated, %d unchanged, %d rejected, %d movie(s) fetched from TMDb",
+		verb, kind, sum.Created, sum.Updated, sum.Unchanged, sum.Rejected, sum.Resolved)
+}
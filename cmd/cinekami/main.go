@@ -0,0 +1,169 @@
+// Command cinekami runs operational tasks against the CineKami database:
+// migrations, TMDb syncs, snapshots, tally reconciliation, data export/import
+// and voter cleanup. It reads the same configuration as api-server
+// (CONFIG_FILE plus environment).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"cinekami-server/internal/config"
+	"cinekami-server/internal/repos"
+
+	pkgdb "cinekami-server/pkg/db"
+)
+
+// command is a subcommand; args are what follows its name on the command line.
+type command struct {
+	usage string
+	run   func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"migrate":  {"migrate up|down|status|force   manage schema migrations", runMigrate},
+	"tmdb":     {"tmdb sync --from --to          discover and upsert TMDb releases", runTMDB},
+	"snapshot": {"snapshot run --month           archive a month's tallies", runSnapshot},
+	"tallies":  {"tallies reconcile              rebuild tallies that drifted from votes", runTallies},
+	"export":   {"export --month [--out]         write a month's snapshots as NDJSON (for import)\n  export snapshots|tallies|votes --from [--to] [--format]\n                                 bulk export as CSV, NDJSON or Parquet", runExport},
+	"import":   {"import snapshots|movies --in   upsert snapshots or movies from NDJSON or CSV", runImport},
+	"voters":   {"voters purge|anonymize|rehash  delete, anonymize or rehash voters", runVoters},
+}
+
+// errUsage marks bad invocations; main exits with status 2 for them.
+var errUsage = errors.New("usage")
+
+func main() {
+	_ = godotenv.Load() // best-effort
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage(os.Stdout)
+		return
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		os.Exit(1)
+	}
+	a := &app{cfg: cfg, out: os.Stdout}
+	defer a.close()
+	if err := cmd.run(ctx, a, os.Args[2:]); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			if !errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(2)
+		}
+		log.Error().Err(err).Str("command", name).Msg("command failed")
+		a.close()
+		os.Exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: cinekami <command> [flags]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for n := range commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintln(w, "  "+commands[n].usage)
+	}
+	fmt.Fprintln(w, "\nEvery command accepts --json for machine-readable output; commands that write accept --dry-run.")
+}
+
+// app holds state shared by subcommands; the pool is opened on first use.
+type app struct {
+	cfg  config.Config
+	out  io.Writer
+	json bool
+
+	pool *pgxpool.Pool
+	repo *repos.Repository
+}
+
+func (a *app) repository(ctx context.Context) (*repos.Repository, error) {
+	if a.repo != nil {
+		return a.repo, nil
+	}
+	pool, err := pkgdb.ConnectWith(ctx, a.cfg.DatabaseURL, pkgdb.PoolOptions(a.cfg.DB))
+	if err != nil {
+		return nil, fmt.Errorf("db connect: %w", err)
+	}
+	a.pool = pool
+	a.repo = repos.New(pool)
+	return a.repo, nil
+}
+
+func (a *app) close() {
+	if a.pool != nil {
+		a.pool.Close()
+		a.pool = nil
+	}
+}
+
+// emit prints v as JSON with --json, else the formatted human summary.
+func (a *app) emit(v any, format string, args ...any) error {
+	if a.json {
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	_, err := fmt.Fprintf(a.out, format+"\n", args...)
+	return err
+}
+
+// flags returns a FlagSet with the shared --json flag (and --dry-run when
+// dryRun is non-nil) bound to a.
+func (a *app) flags(name string, dryRun *bool) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&a.json, "json", false, "print JSON")
+	if dryRun != nil {
+		fs.BoolVar(dryRun, "dry-run", false, "report what would change without writing")
+	}
+	return fs
+}
+
+// subcommand splits "name rest..." and checks name against the allowed verbs.
+func subcommand(args []string, verbs ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%w: expected one of %v", errUsage, verbs)
+	}
+	for _, v := range verbs {
+		if args[0] == v {
+			return v, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("%w: unknown subcommand %q, expected one of %v", errUsage, args[0], verbs)
+}
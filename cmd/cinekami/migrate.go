@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"cinekami-server/internal/migrate"
+)
+
+// runMigrate handles "migrate up|down|status|force".
+func runMigrate(_ context.Context, a *app, args []string) error {
+	verb, rest, err := subcommand(args, "up", "down", "status", "force")
+	if err != nil {
+		return err
+	}
+	var dryRun bool
+	fs := a.flags("migrate "+verb, &dryRun)
+	steps := 1
+	all := false
+	if verb == "down" {
+		fs.IntVar(&steps, "steps", 1, "number of migrations to roll back")
+		fs.BoolVar(&all, "all", false, "roll back every migration (drops all data)")
+	}
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+
+	status, err := migrate.Status(a.cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	switch verb {
+	case "status":
+		return a.emit(status, "version %d (dirty=%t), latest %d, pending %v", status.Version, status.Dirty, status.Latest, status.Pending)
+	case "up":
+		if dryRun || len(status.Pending) == 0 {
+			return a.emit(status, "would apply %d migration(s): %v", len(status.Pending), status.Pending)
+		}
+		if err := migrate.Up(a.cfg.DatabaseURL); err != nil {
+			return err
+		}
+	case "down":
+		if steps <= 0 {
+			return fmt.Errorf("%w: --steps must be positive", errUsage)
+		}
+		if all {
+			steps = int(status.Version)
+		}
+		if dryRun {
+			return a.emit(map[string]any{"version": status.Version, "steps": steps}, "would roll back %d migration(s) from version %d", steps, status.Version)
+		}
+		if all {
+			err = migrate.Down(a.cfg.DatabaseURL)
+		} else {
+			err = migrate.Steps(a.cfg.DatabaseURL, -steps)
+		}
+		if err != nil {
+			return err
+		}
+	case "force":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("%w: migrate force <version>", errUsage)
+		}
+		v, err := strconv.Atoi(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("%w: invalid version %q", errUsage, fs.Arg(0))
+		}
+		if dryRun {
+			return a.emit(map[string]any{"version": status.Version, "force": v}, "would force version %d (currently %d, dirty=%t)", v, status.Version, status.Dirty)
+		}
+		if err := migrate.Force(a.cfg.DatabaseURL, v); err != nil {
+			return err
+		}
+	}
+	if status, err = migrate.Status(a.cfg.DatabaseURL); err != nil {
+		return err
+	}
+	return a.emit(status, "now at version %d (dirty=%t), latest %d", status.Version, status.Dirty, status.Latest)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/repos"
+)
+
+type snapshotResult struct {
+	Region string `json:"region"`
+	Month  string `json:"month"`
+	Movies int    `json:"movies"`
+	DryRun bool   `json:"dry_run"`
+}
+
+// runSnapshot handles "snapshot run --month YYYY-MM --region XX" (default:
+// previous month, default region).
+func runSnapshot(ctx context.Context, a *app, args []string) error {
+	_, rest, err := subcommand(args, "run")
+	if err != nil {
+		return err
+	}
+	var dryRun bool
+	fs := a.flags("snapshot run", &dryRun)
+	now := time.Now().UTC()
+	prev := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	month := fs.String("month", prev.Format("2006-01"), "month to archive (YYYY-MM)")
+	region := fs.String("region", a.cfg.TMDBRegion, "region whose board to archive")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	m, err := time.Parse("2006-01", *month)
+	if err != nil {
+		return fmt.Errorf("%w: invalid --month %q", errUsage, *month)
+	}
+	*region = strings.ToUpper(*region)
+	if !repos.ValidRegionCode(*region) {
+		return fmt.Errorf("%w: invalid --region %q", errUsage, *region)
+	}
+	r, err := a.repository(ctx)
+	if err != nil {
+		return err
+	}
+	ids, err := r.Snapshots.MovieIDsForMonth(ctx, *region, m.Year(), m.Month())
+	if err != nil {
+		return err
+	}
+	res := snapshotResult{Region: *region, Month: *month, Movies: len(ids), DryRun: dryRun}
+	if dryRun {
+		return a.emit(res, "would snapshot %d movie(s) for %s in %s", res.Movies, res.Month, res.Region)
+	}
+	if err := r.SnapshotMonth(ctx, *region, m.Year(), m.Month()); err != nil {
+		return err
+	}
+	return a.emit(res, "snapshotted %d movie(s) for %s in %s", res.Movies, res.Month, res.Region)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+
+	"cinekami-server/internal/repos"
+)
+
+type reconcileResult struct {
+	Drift  []repos.TallyDrift `json:"drift"`
+	Fixed  bool               `json:"fixed"`
+	DryRun bool               `json:"dry_run"`
+}
+
+// runTallies handles "tallies reconcile": vote_tallies is rebuilt from votes for
+// every movie whose stored counts disagree.
+func runTallies(ctx context.Context, a *app, args []string) error {
+	_, rest, err := subcommand(args, "reconcile")
+	if err != nil {
+		return err
+	}
+	var dryRun bool
+	fs := a.flags("tallies reconcile", &dryRun)
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	r, err := a.repository(ctx)
+	if err != nil {
+		return err
+	}
+	drift, err := r.Tallies.ReconcileTallies(ctx, !dryRun)
+	if err != nil {
+		return err
+	}
+	res := reconcileResult{Drift: drift, Fixed: !dryRun && len(drift) > 0, DryRun: dryRun}
+	if !a.json {
+		for _, d := range drift {
+			_ = a.emit(nil, "movie %d %-12s stored %d actual %d", d.MovieID, d.Category, d.Stored, d.Actual)
+		}
+	}
+	if dryRun {
+		return a.emit(res, "%d drifted tally row(s); nothing changed", len(drift))
+	}
+	return a.emit(res, "%d drifted tally row(s) rebuilt", len(drift))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/jobs"
+	"cinekami-server/internal/repos"
+
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+type tmdbSyncResult struct {
+	Region     string `json:"region"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Discovered int    `json:"discovered"`
+	Upserted   int    `json:"upserted"`
+	DryRun     bool   `json:"dry_run"`
+}
+
+// runTMDB handles "tmdb sync --from YYYY-MM-DD --to YYYY-MM-DD --region XX"; the
+// dates default to the current month and the region to the default region.
+func runTMDB(ctx context.Context, a *app, args []string) error {
+	_, rest, err := subcommand(args, "sync")
+	if err != nil {
+		return err
+	}
+	var dryRun bool
+	fs := a.flags("tmdb sync", &dryRun)
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	from := fs.String("from", monthStart.Format(time.DateOnly), "first release date (YYYY-MM-DD)")
+	to := fs.String("to", monthStart.AddDate(0, 1, -1).Format(time.DateOnly), "last release date (YYYY-MM-DD)")
+	region := fs.String("region", a.cfg.TMDBRegion, "region to sync release dates for")
+	language := fs.String("language", a.cfg.TMDBLanguage, "TMDb language movies are stored in; TMDB_LANGUAGES are synced as translations")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	start, err1 := time.Parse(time.DateOnly, *from)
+	end, err2 := time.Parse(time.DateOnly, *to)
+	if err := errors.Join(err1, err2); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	*region = strings.ToUpper(*region)
+	if !repos.ValidRegionCode(*region) {
+		return fmt.Errorf("%w: invalid --region %q", errUsage, *region)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("%w: --to is before --from", errUsage)
+	}
+	if a.cfg.TMDBAPIKey == "" {
+		return errors.New("TMDB_API_KEY is not set")
+	}
+	c := pkgtmdb.New(a.cfg.TMDBAPIKey)
+
+	res := tmdbSyncResult{Region: *region, From: *from, To: *to, DryRun: dryRun}
+	if dryRun {
+		movies, err := c.DiscoverByReleaseWindow(ctx, start, end, *region, *language, 0)
+		if err != nil {
+			return err
+		}
+		res.Discovered = len(movies)
+		return a.emit(res, "would upsert %d movie(s) released %s..%s in %s", res.Discovered, res.From, res.To, res.Region)
+	}
+	r, err := a.repository(ctx)
+	if err != nil {
+		return err
+	}
+	cfg := a.cfg
+	cfg.TMDBLanguage = *language
+	n, err := jobs.SyncTMDBWindow(ctx, r, c, start, end, *region, cfg.Languages())
+	res.Discovered, res.Upserted = n, n
+	if err != nil {
+		return err
+	}
+	return a.emit(res, "upserted %d movie(s) released %s..%s in %s", n, res.From, res.To, res.Region)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pkgcrypto "cinekami-server/pkg/crypto"
+)
+
+type purgeResult struct {
+	Cutoff time.Time `json:"cutoff"`
+	Voters int64     `json:"voters"`
+	DryRun bool      `json:"dry_run"`
+}
+
+type rehashResult struct {
+	Voters int64 `json:"voters"`
+}
+
+// runVoters handles "voters purge|anonymize|rehash".
+func runVoters(ctx context.Context, a *app, args []string) error {
+	verb, rest, err := subcommand(args, "purge", "anonymize", "rehash")
+	if err != nil {
+		return err
+	}
+	switch verb {
+	case "anonymize":
+		return runVotersAnonymize(ctx, a, rest)
+	case "rehash":
+		return runVotersRehash(ctx, a, rest)
+	}
+	return runVotersPurge(ctx, a, rest)
+}
+
+// runVotersPurge handles "voters purge --older-than 720h". Only anonymous voters
+// without any remaining votes are removed, so tallies are unaffected.
+func runVotersPurge(ctx context.Context, a *app, args []string) error {
+	var dryRun bool
+	fs := a.flags("voters purge", &dryRun)
+	olderThan := fs.Duration("older-than", 30*24*time.Hour, "only voters created longer ago than this")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *olderThan < 0 {
+		return fmt.Errorf("%w: --older-than must not be negative", errUsage)
+	}
+	r, err := a.repository(ctx)
+	if err != nil {
+		return err
+	}
+	res := purgeResult{Cutoff: time.Now().UTC().Add(-*olderThan), DryRun: dryRun}
+	if res.Voters, err = r.Votes.PurgeOrphanVoters(ctx, res.Cutoff, dryRun); err != nil {
+		return err
+	}
+	if dryRun {
+		return a.emit(res, "would purge %d voter(s) created before %s", res.Voters, res.Cutoff.Format(time.RFC3339))
+	}
+	return a.emit(res, "purged %d voter(s) created before %s", res.Voters, res.Cutoff.Format(time.RFC3339))
+}
+
+// runVotersAnonymize handles "voters anonymize --inactive-for 8760h", the
+// retention job run on demand. Votes are kept, so tallies are unaffected.
+func runVotersAnonymize(ctx context.Context, a *app, args []string) error {
+	var dryRun bool
+	fs := a.flags("voters anonymize", &dryRun)
+	inactiveFor := fs.Duration("inactive-for", a.cfg.Jobs.VoterRetention, "anonymize voters without a vote for this long")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *inactiveFor <= 0 {
+		return fmt.Errorf("%w: --inactive-for must be positive", errUsage)
+	}
+	r, err := a.repository(ctx)
+	if err != nil {
+		return err
+	}
+	res := purgeResult{Cutoff: time.Now().UTC().Add(-*inactiveFor), DryRun: dryRun}
+	if res.Voters, err = r.Voters.AnonymizeInactiveVoters(ctx, res.Cutoff, dryRun); err != nil {
+		return err
+	}
+	if dryRun {
+		return a.emit(res, "would anonymize %d voter(s) inactive since %s", res.Voters, res.Cutoff.Format(time.RFC3339))
+	}
+	return a.emit(res, "anonymized %d voter(s) inactive since %s", res.Voters, res.Cutoff.Format(time.RFC3339))
+}
+
+// runVotersRehash handles "voters rehash": raw fingerprints stored before
+// digests were introduced are replaced with their digest under the configured
+// fingerprint secret. The API server does the same at startup.
+func runVotersRehash(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("voters rehash", nil)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r, err := a.repository(ctx)
+	if err != nil {
+		return err
+	}
+	f := pkgcrypto.NewFingerprinter([]byte(a.cfg.FingerprintSecret))
+	var res rehashResult
+	if res.Voters, err = r.Voters.HashLegacyFingerprints(ctx, f.Digest); err != nil {
+		return err
+	}
+	return a.emit(res, "hashed %d legacy voter fingerprint(s)", res.Voters)
+}
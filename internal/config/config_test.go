@@ -0,0 +1,83 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cinekami-server/internal/config"
+)
+
+func writeFile(t *testing.T, name, body string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadFileEnvAndSecretFiles(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+port: "8081"
+tmdb_region: US
+cache:
+  active_movies_ttl: 30s
+db:
+  max_conns: 4
+`)
+	t.Setenv("TMDB_REGION", "DE") // env wins over the file
+	t.Setenv("TMDB_API_KEY_FILE", writeFile(t, "tmdb", "file-key\n"))
+	t.Setenv("ADMIN_API_KEYS", "ops:0123456789abcdef")
+
+	c, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if c.Port != "8081" || c.TMDBRegion != "DE" || c.TMDBAPIKey != "file-key" {
+		t.Fatalf("unexpected config: port=%s region=%s key=%q", c.Port, c.TMDBRegion, c.TMDBAPIKey)
+	}
+	if c.Cache.ActiveMoviesTTL != 30*time.Second || c.DB.MaxConns != 4 || c.Paging.MaxLimit != 100 {
+		t.Fatalf("file values or defaults not applied: %+v %+v %+v", c.Cache, c.DB, c.Paging)
+	}
+	if !c.CursorSecretEphemeral {
+		t.Fatal("expected an ephemeral cursor secret in development")
+	}
+
+	out, err := c.Redacted().YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"file-key", "0123456789abcdef", c.CursorSecret} {
+		if strings.Contains(string(out), secret) {
+			t.Fatalf("redacted config leaks %q:\n%s", secret, out)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	t.Setenv("ENV", "production")
+	t.Setenv("HTTP_READ_TIMEOUT", "soon")
+	t.Setenv("PAGE_DEFAULT_LIMIT", "500")
+	t.Setenv("VOTER_RETENTION", "1h")
+	t.Setenv("PUBLIC_URL", "api.example.com")
+	t.Setenv("EMBED_ALLOWED_ORIGINS", "https://blog.example.com/posts")
+	t.Setenv("GRAPHQL_MAX_DEPTH", "0")
+	t.Setenv("GRPC_PORT", "8080")
+
+	_, err := config.Load(writeFile(t, "config.yaml", "tmdb_region: ro\ntmdb_languages: [romanian]\n"))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"HTTP_READ_TIMEOUT", "paging.default_limit", "tmdb_region", "tmdb_languages", "cursor_secret", "fingerprint_secret", "jobs.voter_retention", "public_url", "embed_allowed_origins", "graphql", "grpc_port"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %s:\n%v", want, err)
+		}
+	}
+
+	if _, err := config.Load(writeFile(t, "typo.yaml", "prot: 1\n")); err == nil {
+		t.Fatal("expected unknown keys to be rejected")
+	}
+}
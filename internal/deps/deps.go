@@ -1,12 +1,16 @@
 package deps
 
 import (
+	"sync/atomic"
 	"time"
 
+	"cinekami-server/internal/jobs"
 	"cinekami-server/internal/repos"
 
 	pkgcache "cinekami-server/pkg/cache"
 	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgratelimit "cinekami-server/pkg/ratelimit"
+	pkgtmdb "cinekami-server/pkg/tmdb"
 )
 
 // ServerDeps holds the dependencies required by handlers and server.
@@ -14,7 +18,49 @@ type ServerDeps struct {
 	Repo           *repos.Repository
 	Cache          pkgcache.Cache
 	Codec          pkgcrypto.Codec
+	Fingerprints   *pkgcrypto.Fingerprinter // digests X-Fingerprint before it reaches the database
 	Name           string
 	StartedAt      time.Time
 	AllowedOrigins []string
+	EmbedOrigins   []string // may frame the embeddable widgets; "*" for any
+	MaxBodyBytes   int64
+	MaxImportBytes int64 // replaces MaxBodyBytes for archive imports
+
+	// List paging and response cache lifetimes
+	DefaultPageSize int
+	MaxPageSize     int
+	ActiveMoviesTTL time.Duration
+	SnapshotsTTL    time.Duration
+
+	// GraphQL query limits; 0 disables one
+	GraphQLMaxDepth      int
+	GraphQLMaxComplexity int
+
+	// TallyWatchInterval is how often gRPC WatchTallies streams poll for changes.
+	TallyWatchInterval time.Duration
+
+	// Readiness inputs
+	TMDB          *pkgtmdb.Client // optional; key validity is checked when CheckTMDB is set
+	CheckTMDB     bool
+	CacheFallback bool         // Valkey is configured but the in-memory cache is serving instead
+	Draining      *atomic.Bool // set on shutdown so /readyz fails while requests drain
+
+	// CredentialAttempts throttles account credentials on /v1/me per client and
+	// per email; nil disables it.
+	CredentialAttempts *pkgratelimit.Limiter
+
+	// Admin API
+	AdminKeys map[string]string // API key -> key name recorded as the audit actor
+	Jobs      *jobs.Group       // runs admin-triggered syncs; nil disables them
+
+	// Languages are the served languages; movies are stored in the first one
+	// and the others come from movie_translations.
+	Languages []string
+
+	// DefaultRegion is the board served when a request has no region parameter.
+	DefaultRegion string
+
+	// PublicURL is the base of absolute links in feeds; empty derives it from
+	// the request's Host.
+	PublicURL string
 }
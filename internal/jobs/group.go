@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	pkgmetrics "cinekami-server/pkg/metrics"
+)
+
+// Group runs one-off jobs, such as those triggered from the admin API, under the
+// same lifecycle as the scheduled ones: they are cancelled with the jobs context
+// and shutdown waits for them through the shared WaitGroup.
+type Group struct {
+	ctx context.Context
+	wg  *sync.WaitGroup
+}
+
+func NewGroup(ctx context.Context, wg *sync.WaitGroup) *Group {
+	return &Group{ctx: ctx, wg: wg}
+}
+
+// Go runs fn in the background and records it under the given job name.
+func (g *Group) Go(name string, fn func(ctx context.Context) error) {
+	g.wg.Go(func() {
+		started := time.Now()
+		err := fn(g.ctx)
+		pkgmetrics.ObserveJob(name, started, err)
+		if err != nil {
+			log.Error().Err(err).Str("job", name).Msg("job failed")
+			return
+		}
+		log.Info().Str("job", name).Dur("duration", time.Since(started)).Msg("job completed")
+	})
+}
@@ -0,0 +1,436 @@
+package jobs
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkgexport "cinekami-server/pkg/export"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+// Archive imports read the files written by the exports (and snapshots by the
+// legacy "export --month") back in. Re-running an import changes nothing: rows
+// equal to what is stored count as unchanged.
+
+// ErrInvalidArchive is returned for files that cannot be read at all; bad rows
+// are rejected one by one instead.
+var ErrInvalidArchive = errors.New("invalid archive")
+
+// ImportOptions configures ImportSnapshots and ImportMovies.
+type ImportOptions struct {
+	Format pkgexport.Format // CSV or NDJSON
+	Region string           // region of rows that carry none
+	DryRun bool
+	// TMDB, when set, fetches snapshot movies missing from the database.
+	TMDB *pkgtmdb.Client
+}
+
+// snapshot CSV columns that are not categories
+var snapshotMeta = map[string]bool{"region": true, "month": true, "movie_id": true, "title": true, "closed_at": true, "total_votes": true}
+
+// ImportSnapshots imports a snapshot archive. Each region and month is applied
+// in its own transaction, after every row of it was validated; categories must
+// be in model.AllowedCategories.
+func ImportSnapshots(ctx context.Context, r *repos.Repository, rd io.Reader, opts ImportOptions) (model.ImportSummary, error) {
+	sum := model.ImportSummary{Kind: "snapshots", DryRun: opts.DryRun}
+	type pending struct {
+		line int
+		snap model.Snapshot
+	}
+	months := map[string][]pending{}
+	err := readArchive(rd, opts.Format, func(line int, rec archiveRecord) error {
+		s, err := parseSnapshot(rec)
+		if err != nil {
+			sum.Reject(line, s.MovieID, err)
+			return nil
+		}
+		if s.Region == "" {
+			s.Region = opts.Region
+		}
+		s.Region = strings.ToUpper(s.Region)
+		key := s.Region + " " + s.Month
+		months[key] = append(months[key], pending{line, s})
+		return nil
+	})
+	if err != nil {
+		return sum, err
+	}
+
+	keys := make([]string, 0, len(months))
+	for k := range months {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	res := &resolver{client: opts.TMDB, cache: map[int64]resolved{}}
+	for _, key := range keys {
+		rows := months[key]
+		mon := model.ImportMonth{Region: rows[0].snap.Region, Month: rows[0].snap.Month}
+		var (
+			movies []repos.ArchiveMovie
+			valid  []model.Snapshot
+			seen   = map[int64]bool{}
+		)
+		for _, p := range rows {
+			err := r.Snapshots.ValidateSnapshot(ctx, p.snap)
+			if errors.Is(err, repos.ErrMovieNotFound) && res.client != nil {
+				var m repos.ArchiveMovie
+				if m, err = res.resolve(ctx, p.snap.MovieID, p.snap.Region); err == nil && !seen[p.snap.MovieID] {
+					seen[p.snap.MovieID] = true
+					movies = append(movies, m)
+				}
+			}
+			if err != nil {
+				if !isRowError(err) {
+					return sum, err
+				}
+				sum.Reject(p.line, p.snap.MovieID, err)
+				mon.Rejected++
+				continue
+			}
+			valid = append(valid, p.snap)
+		}
+		if len(valid) > 0 {
+			outcomes, err := r.Snapshots.ImportMonth(ctx, movies, valid, !opts.DryRun)
+			if err != nil {
+				return sum, fmt.Errorf("import %s %s: %w", mon.Region, mon.Month, err)
+			}
+			for _, o := range outcomes {
+				sum.Count(o)
+				switch o {
+				case model.ImportCreated:
+					mon.Created++
+				case model.ImportUpdated:
+					mon.Updated++
+				default:
+					mon.Unchanged++
+				}
+			}
+		}
+		sum.Months = append(sum.Months, mon)
+	}
+	sum.Resolved = res.count
+	return sum, nil
+}
+
+// ImportMovies imports a movie archive in one transaction: each row is a movie
+// with its release date in the row's region.
+func ImportMovies(ctx context.Context, r *repos.Repository, rd io.Reader, opts ImportOptions) (model.ImportSummary, error) {
+	sum := model.ImportSummary{Kind: "movies", DryRun: opts.DryRun}
+	regions, err := r.Regions.ListRegions(ctx)
+	if err != nil {
+		return sum, err
+	}
+	known := make(map[string]bool, len(regions))
+	for _, rg := range regions {
+		known[rg.Code] = true
+	}
+	var movies []repos.ArchiveMovie
+	err = readArchive(rd, opts.Format, func(line int, rec archiveRecord) error {
+		m, err := parseMovie(rec)
+		if m.Region == "" {
+			m.Region = opts.Region
+		}
+		m.Region = strings.ToUpper(m.Region)
+		if err == nil && !known[m.Region] {
+			err = fmt.Errorf("unknown region %q", m.Region)
+		}
+		if err != nil {
+			sum.Reject(line, int64(m.Movie.TMDBID), err)
+			return nil
+		}
+		movies = append(movies, m)
+		return nil
+	})
+	if err != nil || len(movies) == 0 {
+		return sum, err
+	}
+	outcomes, err := r.Movies.ImportMovies(ctx, movies, !opts.DryRun)
+	if err != nil {
+		return sum, err
+	}
+	for _, o := range outcomes {
+		sum.Count(o)
+	}
+	return sum, nil
+}
+
+// isRowError tells validation failures of a row from database errors.
+func isRowError(err error) bool {
+	return errors.Is(err, repos.ErrInvalidSnapshot) || errors.Is(err, repos.ErrMovieNotFound) || errors.Is(err, pkgtmdb.ErrNotFound)
+}
+
+type resolved struct {
+	movie pkgtmdb.Movie
+	dates map[string]time.Time
+	err   error
+}
+
+// resolver fetches movies from TMDb once per import.
+type resolver struct {
+	client *pkgtmdb.Client
+	cache  map[int64]resolved
+	count  int
+}
+
+// resolve returns movie id with its release date in region: the regional one,
+// else the primary one.
+func (r *resolver) resolve(ctx context.Context, id int64, region string) (repos.ArchiveMovie, error) {
+	res, ok := r.cache[id]
+	if !ok {
+		res.movie, res.err = r.client.GetMovie(ctx, int32(id))
+		if res.err == nil {
+			res.dates, res.err = r.client.GetRegionalReleaseDates(ctx, int32(id))
+		}
+		if res.err != nil && !errors.Is(res.err, pkgtmdb.ErrNotFound) {
+			return repos.ArchiveMovie{}, res.err // not cached: TMDb may recover
+		}
+		if res.err == nil {
+			r.count++
+		}
+		r.cache[id] = res
+	}
+	if res.err != nil {
+		return repos.ArchiveMovie{}, fmt.Errorf("%w: %w", repos.ErrMovieNotFound, res.err)
+	}
+	m := res.movie
+	if d, ok := res.dates[region]; ok {
+		m.ReleaseDate = d
+	}
+	if m.ReleaseDate.IsZero() {
+		return repos.ArchiveMovie{}, fmt.Errorf("%w: TMDb has no release date", repos.ErrMovieNotFound)
+	}
+	return repos.ArchiveMovie{Region: region, Movie: m}, nil
+}
+
+// archiveRecord is one row: the raw line of NDJSON or the columns of CSV by
+// header name.
+type archiveRecord struct {
+	json []byte
+	csv  map[string]string
+}
+
+// readArchive calls fn with each row of rd and its line number.
+func readArchive(rd io.Reader, format pkgexport.Format, fn func(line int, rec archiveRecord) error) error {
+	switch format {
+	case pkgexport.CSV:
+		cr := csv.NewReader(rd)
+		header, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		for i := range header {
+			header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+		}
+		for {
+			rec, err := cr.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+			}
+			line, _ := cr.FieldPos(0)
+			cols := make(map[string]string, len(header))
+			for i, h := range header {
+				cols[h] = strings.TrimSpace(rec[i])
+			}
+			if err := fn(line, archiveRecord{csv: cols}); err != nil {
+				return err
+			}
+		}
+	case pkgexport.NDJSON:
+		sc := bufio.NewScanner(rd)
+		sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for line := 1; sc.Scan(); line++ {
+			b := sc.Bytes()
+			if len(strings.TrimSpace(string(b))) == 0 {
+				continue
+			}
+			if err := fn(line, archiveRecord{json: append([]byte(nil), b...)}); err != nil {
+				return err
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: format %q cannot be imported", ErrInvalidArchive, format)
+	}
+}
+
+// snapshotLine is a snapshot in NDJSON: model.Snapshot with its tallies map or
+// an export row with one count per category.
+type snapshotLine struct {
+	Region      string           `json:"region"`
+	Month       string           `json:"month"`
+	MovieID     int64            `json:"movie_id"`
+	Tallies     map[string]int64 `json:"tallies"`
+	ClosedAt    time.Time        `json:"closed_at"`
+	SoloFriends *int64           `json:"solo_friends"`
+	Couple      *int64           `json:"couple"`
+	Streaming   *int64           `json:"streaming"`
+	Arr         *int64           `json:"arr"`
+	TotalVotes  *int64           `json:"total_votes"`
+}
+
+func parseSnapshot(rec archiveRecord) (model.Snapshot, error) {
+	var (
+		s     model.Snapshot
+		total *int64
+	)
+	if rec.json != nil {
+		var l snapshotLine
+		if err := json.Unmarshal(rec.json, &l); err != nil {
+			return s, fmt.Errorf("malformed line: %w", err)
+		}
+		s = model.Snapshot{Region: l.Region, Month: l.Month, MovieID: l.MovieID, Tallies: l.Tallies, Closed: l.ClosedAt}
+		if s.Tallies == nil {
+			s.Tallies = map[string]int64{}
+			for cat, n := range map[string]*int64{model.CategorySoloFriends: l.SoloFriends, model.CategoryCouple: l.Couple, model.CategoryStreaming: l.Streaming, model.CategoryArr: l.Arr} {
+				if n != nil {
+					s.Tallies[cat] = *n
+				}
+			}
+		}
+		total = l.TotalVotes
+	} else {
+		s.Region, s.Month = rec.csv["region"], rec.csv["month"]
+		id, err := strconv.ParseInt(rec.csv["movie_id"], 10, 64)
+		if err != nil {
+			return s, fmt.Errorf("invalid movie_id %q", rec.csv["movie_id"])
+		}
+		s.MovieID = id
+		if v := rec.csv["closed_at"]; v != "" {
+			if s.Closed, err = time.Parse(time.RFC3339, v); err != nil {
+				return s, fmt.Errorf("invalid closed_at %q", v)
+			}
+		}
+		s.Tallies = map[string]int64{}
+		for col, v := range rec.csv {
+			if snapshotMeta[col] {
+				continue
+			}
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return s, fmt.Errorf("invalid count %q for %s", v, col)
+			}
+			s.Tallies[col] = n
+		}
+		if v := rec.csv["total_votes"]; v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return s, fmt.Errorf("invalid total_votes %q", v)
+			}
+			total = &n
+		}
+	}
+	if s.MovieID <= 0 {
+		return s, errors.New("missing movie_id")
+	}
+	if total != nil {
+		var sum int64
+		for _, n := range s.Tallies {
+			sum += n
+		}
+		if sum != *total {
+			return s, fmt.Errorf("total_votes %d does not match the categories (%d)", *total, sum)
+		}
+	}
+	return s, nil
+}
+
+// movieLine is a movie in NDJSON, as model.Movie or a flat archive row.
+type movieLine struct {
+	ID               int64   `json:"id"`
+	MovieID          int64   `json:"movie_id"`
+	Region           string  `json:"region"`
+	Title            string  `json:"title"`
+	ReleaseDate      string  `json:"release_date"`
+	Overview         string  `json:"overview"`
+	PosterPath       string  `json:"poster_path"`
+	BackdropPath     string  `json:"backdrop_path"`
+	Popularity       float64 `json:"popularity"`
+	GenreIDs         []int32 `json:"genre_ids"`
+	OriginalTitle    string  `json:"original_title"`
+	OriginalLanguage string  `json:"original_language"`
+}
+
+func parseMovie(rec archiveRecord) (repos.ArchiveMovie, error) {
+	var l movieLine
+	if rec.json != nil {
+		if err := json.Unmarshal(rec.json, &l); err != nil {
+			return repos.ArchiveMovie{}, fmt.Errorf("malformed line: %w", err)
+		}
+	} else {
+		c := rec.csv
+		l = movieLine{Region: c["region"], Title: c["title"], ReleaseDate: c["release_date"], Overview: c["overview"],
+			PosterPath: c["poster_path"], BackdropPath: c["backdrop_path"], OriginalTitle: c["original_title"], OriginalLanguage: c["original_language"]}
+		id := c["id"]
+		if id == "" {
+			id = c["movie_id"]
+		}
+		var err error
+		if l.ID, err = strconv.ParseInt(id, 10, 64); err != nil {
+			return repos.ArchiveMovie{}, fmt.Errorf("invalid id %q", id)
+		}
+		if v := c["popularity"]; v != "" {
+			if l.Popularity, err = strconv.ParseFloat(v, 64); err != nil {
+				return repos.ArchiveMovie{}, fmt.Errorf("invalid popularity %q", v)
+			}
+		}
+		for _, g := range strings.FieldsFunc(c["genre_ids"], func(r rune) bool { return r == '|' || r == ',' || r == ' ' }) {
+			n, err := strconv.ParseInt(g, 10, 32)
+			if err != nil {
+				return repos.ArchiveMovie{}, fmt.Errorf("invalid genre id %q", g)
+			}
+			l.GenreIDs = append(l.GenreIDs, int32(n))
+		}
+	}
+	if l.ID == 0 {
+		l.ID = l.MovieID
+	}
+	m := repos.ArchiveMovie{Region: l.Region, Movie: pkgtmdb.Movie{
+		TMDBID: int32(l.ID), Title: strings.TrimSpace(l.Title), Overview: l.Overview, PosterPath: l.PosterPath, BackdropPath: l.BackdropPath,
+		Popularity: l.Popularity, GenreIDs: l.GenreIDs, OriginalTitle: l.OriginalTitle, OriginalLanguage: l.OriginalLanguage,
+	}}
+	switch {
+	case l.ID <= 0 || l.ID > 1<<31-1:
+		return m, fmt.Errorf("invalid id %d", l.ID)
+	case m.Movie.Title == "":
+		return m, errors.New("missing title")
+	}
+	d, err := parseDate(l.ReleaseDate)
+	if err != nil {
+		return m, fmt.Errorf("invalid release_date %q", l.ReleaseDate)
+	}
+	m.Movie.ReleaseDate = d
+	return m, nil
+}
+
+// parseDate accepts YYYY-MM-DD or an RFC 3339 time, as model.Movie is encoded.
+func parseDate(s string) (time.Time, error) {
+	if d, err := time.Parse(time.DateOnly, s); err == nil {
+		return d, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
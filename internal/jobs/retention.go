@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"cinekami-server/internal/repos"
+
+	pkgmetrics "cinekami-server/pkg/metrics"
+)
+
+// StartVoterRetention anonymizes voters inactive for longer than retention once a
+// day (03:15 UTC). Their votes stay, so tallies are unaffected. A zero retention
+// disables the job.
+func StartVoterRetention(ctx context.Context, wg *sync.WaitGroup, r *repos.Repository, retention time.Duration) {
+	if retention <= 0 {
+		log.Info().Msg("voter retention disabled")
+		return
+	}
+	wg.Go(func() {
+		for {
+			now := time.Now().UTC()
+			next := time.Date(now.Year(), now.Month(), now.Day(), 3, 15, 0, 0, time.UTC)
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+			t := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+				started := time.Now()
+				cutoff := started.UTC().Add(-retention)
+				n, err := r.Voters.AnonymizeInactiveVoters(ctx, cutoff, false)
+				pkgmetrics.ObserveJob("voter_retention", started, err)
+				if err != nil {
+					log.Error().Err(err).Msg("voter retention job failed")
+				} else {
+					log.Info().Int64("voters", n).Time("cutoff", cutoff).Msg("voter retention job completed")
+				}
+			}
+		}
+	})
+}
@@ -8,12 +8,13 @@ import (
 
 	"cinekami-server/internal/repos"
 
+	pkgmetrics "cinekami-server/pkg/metrics"
 	pkgtmdb "cinekami-server/pkg/tmdb"
 )
 
-// SeedTMDBIfEmpty populates movies with current-month TMDb releases if the table is empty.
-// Intended for testing/dev convenience; no-op if client is nil or movies already exist.
-func SeedTMDBIfEmpty(ctx context.Context, r *repos.Repository, c *pkgtmdb.Client, region, language string) error {
+// SeedTMDBIfEmpty populates movies with current-month TMDb releases of every enabled region if
+// the table is empty. Intended for testing/dev convenience; no-op if client is nil or movies already exist.
+func SeedTMDBIfEmpty(ctx context.Context, r *repos.Repository, c *pkgtmdb.Client, languages []string) (err error) {
 	if c == nil {
 		return nil
 	}
@@ -25,15 +26,10 @@ func SeedTMDBIfEmpty(ctx context.Context, r *repos.Repository, c *pkgtmdb.Client
 		return nil
 	}
 	// Compute current month window
-	now := time.Now().UTC()
-	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
-	end := start.AddDate(0, 1, -1)
-	// Fetch all pages for the month
-	movies, err := c.DiscoverByReleaseWindow(start, end, region, language, 0)
-	if err != nil {
-		return err
-	}
-	n, err := r.UpsertMoviesFromTMDB(ctx, movies, c)
+	started := time.Now()
+	defer func() { pkgmetrics.ObserveJob("tmdb_seed", started, err) }()
+	now := started.UTC()
+	n, err := SyncTMDBMonthAllRegions(ctx, r, c, now.Year(), now.Month(), languages)
 	if err != nil {
 		return err
 	}
@@ -2,16 +2,22 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
 	"cinekami-server/internal/repos"
+
+	pkgmetrics "cinekami-server/pkg/metrics"
 )
 
-// StartMonthlySnapshot runs a snapshot at the end of each month (00:05 UTC on the 1st).
-func StartMonthlySnapshot(ctx context.Context, r *repos.Repository) {
-	go func() {
+// StartMonthlySnapshot runs a snapshot of every enabled region at the end of each month (00:05 UTC on the 1st).
+// The goroutine is tracked by wg so shutdown can wait for an in-flight run.
+func StartMonthlySnapshot(ctx context.Context, wg *sync.WaitGroup, r *repos.Repository) {
+	wg.Go(func() {
 		for {
 			now := time.Now().UTC()
 			// next run at next month 00:05 UTC
@@ -25,25 +31,47 @@ func StartMonthlySnapshot(ctx context.Context, r *repos.Repository) {
 			case <-t.C:
 				// Snapshot previous month
 				prev := next.AddDate(0, 0, -1)
-				if err := r.SnapshotMonth(ctx, prev.Year(), prev.Month()); err != nil {
+				started := time.Now()
+				err := SnapshotAllRegions(ctx, r, prev.Year(), prev.Month())
+				pkgmetrics.ObserveJob("monthly_snapshot", started, err)
+				if err != nil {
 					log.Error().Err(err).Msg("snapshot job failed")
 				} else {
 					log.Info().Int("year", prev.Year()).Int("month", int(prev.Month())).Msg("snapshot job completed")
 				}
 			}
 		}
-	}()
+	})
 }
 
 // StartTestSnapshot runs a single snapshot immediately in a goroutine for manual testing.
 // This is intentionally not wired into any HTTP endpoint — call it from tests or main when needed.
-func StartTestSnapshot(ctx context.Context, r *repos.Repository) {
-	go func() {
-		now := time.Now().UTC()
-		if err := r.SnapshotMonth(ctx, now.Year(), now.Month()); err != nil {
+func StartTestSnapshot(ctx context.Context, wg *sync.WaitGroup, r *repos.Repository) {
+	wg.Go(func() {
+		started := time.Now()
+		now := started.UTC()
+		err := SnapshotAllRegions(ctx, r, now.Year(), now.Month())
+		pkgmetrics.ObserveJob("test_snapshot", started, err)
+		if err != nil {
 			log.Error().Err(err).Msg("test snapshot failed")
 			return
 		}
 		log.Info().Int("year", now.Year()).Int("month", int(now.Month())).Msg("test snapshot completed")
-	}()
+	})
+}
+
+// SnapshotAllRegions archives the given month in every enabled region. A failing
+// region does not stop the others; the errors are joined.
+func SnapshotAllRegions(ctx context.Context, r *repos.Repository, year int, month time.Month) error {
+	regions, err := r.EnabledRegions(ctx)
+	if err != nil {
+		return fmt.Errorf("list regions: %w", err)
+	}
+	var errs []error
+	for _, region := range regions {
+		if err := r.SnapshotMonth(ctx, region, year, month); err != nil {
+			errs = append(errs, fmt.Errorf("region %s: %w", region, err))
+		}
+	}
+	return errors.Join(errs...)
 }
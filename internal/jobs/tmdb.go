@@ -2,96 +2,131 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
 	"cinekami-server/internal/repos"
 
+	pkgmetrics "cinekami-server/pkg/metrics"
 	pkgtmdb "cinekami-server/pkg/tmdb"
 )
 
-// StartTMDBSync starts a weekly ticker that triggers the TMDb sync for current month releases.
-func StartTMDBSync(ctx context.Context, r *repos.Repository, c *pkgtmdb.Client, region, language string) {
+// StartTMDBSync runs the TMDb sync for current month releases in every enabled
+// region every week on weekday at hour:00 UTC. The goroutine is tracked by wg so
+// shutdown can wait for an in-flight run. languages are as for SyncTMDBWindow.
+func StartTMDBSync(ctx context.Context, wg *sync.WaitGroup, r *repos.Repository, c *pkgtmdb.Client, languages []string, weekday time.Weekday, hour int) {
 	if c == nil {
 		log.Warn().Msg("TMDb client not configured; skipping weekly sync")
 		return
 	}
-	go func() {
-		// Align to next Monday 03:00 UTC
-		now := time.Now().UTC()
-		// find days until next Monday (Weekday 1)
-		daysUntilMonday := (int(time.Monday) - int(now.Weekday()) + 7) % 7
-		if daysUntilMonday == 0 {
-			// if already Monday past 03:00, schedule next week; else today 03:00
-			next := time.Date(now.Year(), now.Month(), now.Day(), 3, 0, 0, 0, time.UTC)
-			if !next.After(now) {
-				now = now.AddDate(0, 0, 7)
-				daysUntilMonday = (int(time.Monday) - int(now.Weekday()) + 7) % 7
-			}
-		}
-		next := time.Date(now.Year(), now.Month(), now.Day(), 3, 0, 0, 0, time.UTC).AddDate(0, 0, daysUntilMonday)
-		if !next.After(now) {
-			next = next.AddDate(0, 0, 7)
-		}
-		t := time.NewTimer(time.Until(next))
+	wg.Go(func() {
+		t := time.NewTimer(time.Until(nextWeekly(time.Now().UTC(), weekday, hour)))
 		defer t.Stop()
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-t.C:
-				// Compute current month window in UTC
-				cur := time.Now().UTC()
-				start := time.Date(cur.Year(), cur.Month(), 1, 0, 0, 0, 0, time.UTC)
-				end := start.AddDate(0, 1, -1)
-				movies, err := c.DiscoverByReleaseWindow(start, end, region, language, 0) // all pages
+				started := time.Now()
+				cur := started.UTC()
+				n, err := SyncTMDBMonthAllRegions(ctx, r, c, cur.Year(), cur.Month(), languages)
 				if err != nil {
-					log.Error().Err(err).Msg("tmdb discover failed")
+					log.Error().Err(err).Msg("tmdb weekly sync failed")
 				} else {
-					if n, e := r.UpsertMoviesFromTMDB(ctx, movies, c); e != nil {
-						log.Error().Err(e).Msg("upsert movies failed")
-					} else {
-						log.Info().Int("count", n).Msg("tmdb weekly sync upserted movies")
-					}
+					log.Info().Int("count", n).Msg("tmdb weekly sync upserted movies")
 				}
+				pkgmetrics.ObserveJob("tmdb_sync", started, err)
 				// Schedule next week
-				t.Reset(7 * 24 * time.Hour)
+				t.Reset(time.Until(nextWeekly(time.Now().UTC(), weekday, hour)))
 			}
 		}
-	}()
+	})
 }
 
-// StartTMDBSyncTest starts a fast sync every 30 seconds for testing purposes.
-// It performs the same movie discovery and upsert as the weekly sync but with a 30s ticker.
-func StartTMDBSyncTest(ctx context.Context, r *repos.Repository, c *pkgtmdb.Client, region, language string) {
+// nextWeekly returns the first weekday at hour:00 UTC strictly after now.
+func nextWeekly(now time.Time, weekday time.Weekday, hour int) time.Time {
+	days := (int(weekday) - int(now.Weekday()) + 7) % 7
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// StartTMDBSyncTest runs the same discovery and upsert as the weekly sync every
+// interval, for testing purposes.
+func StartTMDBSyncTest(ctx context.Context, wg *sync.WaitGroup, r *repos.Repository, c *pkgtmdb.Client, languages []string, interval time.Duration) {
 	if c == nil {
 		log.Warn().Msg("TMDb client not configured; skipping test sync")
 		return
 	}
-	go func() {
-		ticker := time.NewTicker(30 * time.Second)
+	wg.Go(func() {
+		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				// Compute current month window in UTC
-				cur := time.Now().UTC()
-				start := time.Date(cur.Year(), cur.Month(), 1, 0, 0, 0, 0, time.UTC)
-				end := start.AddDate(0, 1, -1)
-				movies, err := c.DiscoverByReleaseWindow(start, end, region, language, 0)
+				started := time.Now()
+				cur := started.UTC()
+				n, err := SyncTMDBMonthAllRegions(ctx, r, c, cur.Year(), cur.Month(), languages)
 				if err != nil {
-					log.Error().Err(err).Msg("tmdb test discover failed")
+					log.Error().Err(err).Msg("tmdb test sync failed")
 				} else {
-					if n, e := r.UpsertMoviesFromTMDB(ctx, movies, c); e != nil {
-						log.Error().Err(e).Msg("upsert movies failed (test)")
-					} else {
-						log.Info().Int("count", n).Msg("tmdb test sync upserted movies")
-					}
+					log.Info().Int("count", n).Msg("tmdb test sync upserted movies")
 				}
+				pkgmetrics.ObserveJob("tmdb_sync_test", started, err)
 			}
 		}
-	}()
+	})
+}
+
+// SyncTMDBMonthAllRegions runs SyncTMDBMonth for every enabled region. A failing
+// region does not stop the others; the errors are joined.
+func SyncTMDBMonthAllRegions(ctx context.Context, r *repos.Repository, c *pkgtmdb.Client, year int, month time.Month, languages []string) (int, error) {
+	regions, err := r.EnabledRegions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list regions: %w", err)
+	}
+	total := 0
+	var errs []error
+	for _, region := range regions {
+		n, err := SyncTMDBMonth(ctx, r, c, year, month, region, languages)
+		total += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("region %s: %w", region, err))
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// SyncTMDBMonth discovers every release of the given month (UTC) in region and
+// upserts it. Movies locked by an admin keep their edited values.
+func SyncTMDBMonth(ctx context.Context, r *repos.Repository, c *pkgtmdb.Client, year int, month time.Month, region string, languages []string) (int, error) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return SyncTMDBWindow(ctx, r, c, start, start.AddDate(0, 1, -1), region, languages)
+}
+
+// SyncTMDBWindow discovers releases in region between start and end (inclusive dates) and upserts them.
+// Movies are stored in languages[0]; titles and overviews in the other languages are synced as translations.
+func SyncTMDBWindow(ctx context.Context, r *repos.Repository, c *pkgtmdb.Client, start, end time.Time, region string, languages []string) (int, error) {
+	var language string
+	var translations []string
+	if len(languages) > 0 {
+		language, translations = languages[0], languages[1:]
+	}
+	movies, err := c.DiscoverByReleaseWindow(ctx, start, end, region, language, 0) // all pages
+	if err != nil {
+		return 0, fmt.Errorf("tmdb discover: %w", err)
+	}
+	n, err := r.UpsertMoviesFromTMDB(ctx, region, movies, c, translations)
+	if err != nil {
+		return n, fmt.Errorf("upsert movies: %w", err)
+	}
+	return n, nil
 }
@@ -0,0 +1,121 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"cinekami-server/internal/repos"
+
+	pkgmetrics "cinekami-server/pkg/metrics"
+	pkgwebhook "cinekami-server/pkg/webhook"
+)
+
+// webhookBatch bounds the deliveries claimed per pass.
+const webhookBatch = 50
+
+// A claimed batch is leased for webhookLease plus the client timeout and its
+// sends are cut off webhookLeaseSlack before the lease ends, leaving time to
+// record the outcomes; tests shorten both.
+var (
+	webhookLease      = time.Minute
+	webhookLeaseSlack = 15 * time.Second
+)
+
+// StartWebhookDelivery sends due webhook deliveries every interval until ctx is
+// done. Failed attempts are retried with pkgwebhook.Backoff; after maxAttempts
+// the delivery is dead until an admin retries it.
+func StartWebhookDelivery(ctx context.Context, wg *sync.WaitGroup, r *repos.Repository, s *pkgwebhook.Sender, interval time.Duration, maxAttempts int) {
+	wg.Go(func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				started := time.Now()
+				n, err := DeliverWebhooks(ctx, r, s, maxAttempts)
+				if n > 0 || err != nil {
+					pkgmetrics.ObserveJob("webhook_delivery", started, err)
+				}
+				if err != nil {
+					log.Error().Err(err).Msg("webhook delivery failed")
+				}
+			}
+		}
+	})
+}
+
+// DeliverWebhooks makes one pass over the due deliveries, batch by batch, and
+// returns the number of attempts made. A batch is sent concurrently and never
+// outlives its lease: sends still running near its end are abandoned, so the
+// deliveries are retried only once no one is sending them any more.
+func DeliverWebhooks(ctx context.Context, r *repos.Repository, s *pkgwebhook.Sender, maxAttempts int) (int, error) {
+	lease := webhookLease
+	if s.Client != nil && s.Client.Timeout > 0 {
+		lease += s.Client.Timeout
+	}
+	total := 0
+	for ctx.Err() == nil {
+		sendCtx, cancel := context.WithDeadline(ctx, time.Now().Add(lease-webhookLeaseSlack))
+		batch, err := r.Webhooks.ClaimDeliveries(ctx, webhookBatch, lease)
+		if err != nil || len(batch) == 0 {
+			cancel()
+			return total, err
+		}
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			errs []error
+		)
+		for _, d := range batch {
+			wg.Go(func() {
+				attempted, err := deliver(ctx, sendCtx, r, s, d, maxAttempts)
+				mu.Lock()
+				defer mu.Unlock()
+				if attempted {
+					total++
+				}
+				if err != nil {
+					errs = append(errs, err)
+				}
+			})
+		}
+		wg.Wait()
+		cancel()
+		if err := errors.Join(errs...); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// deliver sends d within sendCtx and records the outcome with ctx. It reports
+// whether an attempt was recorded.
+func deliver(ctx, sendCtx context.Context, r *repos.Repository, s *pkgwebhook.Sender, d repos.PendingDelivery, maxAttempts int) (bool, error) {
+	env := pkgwebhook.Envelope{ID: d.ID, Event: d.Event, CreatedAt: d.CreatedAt.UTC(), Data: d.Payload}
+	status, sendErr := s.Send(sendCtx, d.URL, d.Secret, env)
+	if sendErr == nil {
+		pkgmetrics.WebhookDeliveries.WithLabelValues(d.Event, "delivered").Inc()
+		return true, r.Webhooks.MarkDelivered(ctx, d.ID, status)
+	}
+	if sendCtx.Err() != nil {
+		// shutting down or out of lease; the lease expires and the attempt is
+		// made again
+		return false, nil
+	}
+	attempt := int(d.Attempts) + 1
+	var next *time.Time
+	outcome := "dead"
+	if attempt < maxAttempts {
+		at := time.Now().Add(pkgwebhook.Backoff(attempt))
+		next, outcome = &at, "retry"
+	}
+	pkgmetrics.WebhookDeliveries.WithLabelValues(d.Event, outcome).Inc()
+	log.Warn().Err(sendErr).Int64("delivery", d.ID).Str("event", d.Event).Int("attempt", attempt).Str("outcome", outcome).Msg("webhook delivery attempt failed")
+	return true, r.Webhooks.MarkFailed(ctx, d.ID, status, sendErr.Error(), next)
+}
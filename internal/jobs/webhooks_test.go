@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"cinekami-server/internal/migrate"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkgdb "cinekami-server/pkg/db"
+	pkgwebhook "cinekami-server/pkg/webhook"
+)
+
+// TestDeliveryOutlivingLease has two workers deliver to a receiver that holds
+// the first attempt longer than the batch's lease: that attempt must be given
+// up before another worker may claim the delivery again, and the retry must be
+// the only delivery. Set TEST_DATABASE_URL to run it.
+func TestDeliveryOutlivingLease(t *testing.T) {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	if err := migrate.Up(dbURL); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	pool, err := pkgdb.Connect(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	repo := repos.New(pool)
+	ctx := context.Background()
+
+	lease, slack := webhookLease, webhookLeaseSlack
+	webhookLease, webhookLeaseSlack = 2*time.Second, time.Second
+	t.Cleanup(func() { webhookLease, webhookLeaseSlack = lease, slack })
+
+	var (
+		mu        sync.Mutex
+		inFlight  int
+		attempts  int
+		delivered int
+		overlap   bool
+	)
+	stop := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		overlap = overlap || inFlight > 0
+		inFlight++
+		attempts++
+		first := attempts == 1
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		if first {
+			// slower than the lease
+			select {
+			case <-r.Context().Done():
+			case <-stop:
+			}
+			return
+		}
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+	t.Cleanup(func() { close(stop) })
+
+	sub, err := repo.Webhooks.CreateSubscription(ctx, receiver.URL, []string{model.EventSnapshotClosed}, "whsec-lease")
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := repo.Webhooks.DeleteSubscription(context.Background(), sub.ID); err != nil {
+			t.Errorf("cleanup subscription: %v", err)
+		}
+	})
+	if _, err := pool.Exec(ctx, "INSERT INTO webhook_deliveries (subscription_id, event, payload) VALUES ($1, $2, '{}')", sub.ID, model.EventSnapshotClosed); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Go(func() {
+			for time.Now().Before(deadline) {
+				if _, err := DeliverWebhooks(ctx, repo, &pkgwebhook.Sender{Client: receiver.Client()}, 3); err != nil {
+					t.Errorf("deliver: %v", err)
+					return
+				}
+				mu.Lock()
+				done := delivered > 0
+				mu.Unlock()
+				if done {
+					return
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+		})
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if overlap {
+		t.Fatalf("delivery claimed again while still being sent")
+	}
+	if attempts != 2 || delivered != 1 {
+		t.Fatalf("expected the held attempt and one retry, got %d attempts, %d delivered", attempts, delivered)
+	}
+}
@@ -0,0 +1,303 @@
+// Package listquery parses and validates the sort and filter parameters shared by
+// the movie and snapshot listings, so both endpoints accept the same vocabulary
+// and reject unknown values with the allowed ones spelled out.
+package listquery
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/model"
+)
+
+// Field is a sort key.
+type Field string
+
+const (
+	FieldPopularity  Field = "popularity"
+	FieldReleaseDate Field = "release_date"
+	FieldTitle       Field = "title"
+	FieldTotalVotes  Field = "total_votes"
+
+	// Vote counts per category
+	FieldSoloFriends Field = model.CategorySoloFriends
+	FieldCouple      Field = model.CategoryCouple
+	FieldStreaming   Field = model.CategoryStreaming
+	FieldArr         Field = model.CategoryArr
+
+	// Category share of total votes, 0-100; 0 for movies without votes
+	FieldSoloFriendsPct Field = model.CategorySoloFriends + "_pct"
+	FieldCouplePct      Field = model.CategoryCouple + "_pct"
+	FieldStreamingPct   Field = model.CategoryStreaming + "_pct"
+	FieldArrPct         Field = model.CategoryArr + "_pct"
+
+	// Lower bound of the category share's 95% Wilson interval, 0-1; ranks a
+	// 30/40 split above a 3/4 one
+	FieldSoloFriendsLower Field = model.CategorySoloFriends + "_lower_bound"
+	FieldCoupleLower      Field = model.CategoryCouple + "_lower_bound"
+	FieldStreamingLower   Field = model.CategoryStreaming + "_lower_bound"
+	FieldArrLower         Field = model.CategoryArr + "_lower_bound"
+)
+
+// Fields lists every sort field, in the order error messages show them.
+var Fields = []Field{
+	FieldPopularity, FieldReleaseDate, FieldTitle, FieldTotalVotes,
+	FieldSoloFriends, FieldCouple, FieldStreaming, FieldArr,
+	FieldSoloFriendsPct, FieldCouplePct, FieldStreamingPct, FieldArrPct,
+	FieldSoloFriendsLower, FieldCoupleLower, FieldStreamingLower, FieldArrLower,
+}
+
+// Dir is a sort direction.
+type Dir string
+
+const (
+	Asc  Dir = "asc"
+	Desc Dir = "desc"
+)
+
+// Reverse returns the opposite direction.
+func (d Dir) Reverse() Dir {
+	if d == Asc {
+		return Desc
+	}
+	return Asc
+}
+
+// Sort is one key of a multi-key sort.
+type Sort struct {
+	Field Field
+	Dir   Dir
+}
+
+// MaxSortKeys bounds sort_by; every key is carried in the pagination cursor.
+const MaxSortKeys = 3
+
+// DefaultSort applies when sort_by is absent.
+var DefaultSort = []Sort{{Field: FieldPopularity, Dir: Desc}}
+
+// Query is a validated listing request. Nil filters are unset.
+type Query struct {
+	Sort          []Sort
+	MinPopularity *float64
+	MaxPopularity *float64
+	ReleasedFrom  *time.Time // inclusive
+	ReleasedTo    *time.Time // inclusive
+	Genres        []int32    // TMDb genre ids; a movie matches if it has any of them
+	MinVotes      *int64
+	Voted         *bool // voted (or not) by the caller's fingerprint
+}
+
+// Error is a rejected parameter. Allowed is set when the parameter takes one of
+// a fixed set of values.
+type Error struct {
+	Param   string
+	Value   string
+	Reason  string
+	Allowed []string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("invalid %s %q", e.Param, e.Value)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if len(e.Allowed) > 0 {
+		msg += "; allowed values: " + strings.Join(e.Allowed, ", ")
+	}
+	return msg
+}
+
+func allowedFields() []string {
+	out := make([]string, len(Fields))
+	for i, f := range Fields {
+		out[i] = string(f)
+	}
+	return out
+}
+
+// Parse reads sort_by, sort_dir and the filter parameters from v.
+//
+// sort_by is a comma-separated list of up to MaxSortKeys fields. sort_dir is
+// either a single direction applied to every key or one direction per key; it
+// defaults to desc.
+func Parse(v url.Values) (Query, error) {
+	var q Query
+	var err error
+	if q.Sort, err = parseSort(v.Get("sort_by"), v.Get("sort_dir")); err != nil {
+		return Query{}, err
+	}
+	if q.MinPopularity, err = parseFloat(v, "min_popularity"); err != nil {
+		return Query{}, err
+	}
+	if q.MaxPopularity, err = parseFloat(v, "max_popularity"); err != nil {
+		return Query{}, err
+	}
+	if q.MinPopularity != nil && q.MaxPopularity != nil && *q.MinPopularity > *q.MaxPopularity {
+		return Query{}, &Error{Param: "min_popularity", Value: v.Get("min_popularity"), Reason: "greater than max_popularity"}
+	}
+	if q.ReleasedFrom, err = parseDate(v, "release_from"); err != nil {
+		return Query{}, err
+	}
+	if q.ReleasedTo, err = parseDate(v, "release_to"); err != nil {
+		return Query{}, err
+	}
+	if q.ReleasedFrom != nil && q.ReleasedTo != nil && q.ReleasedFrom.After(*q.ReleasedTo) {
+		return Query{}, &Error{Param: "release_from", Value: v.Get("release_from"), Reason: "after release_to"}
+	}
+	if q.Genres, err = ParseGenres(v.Get("genre")); err != nil {
+		return Query{}, err
+	}
+	if s := v.Get("min_votes"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 {
+			return Query{}, &Error{Param: "min_votes", Value: s, Reason: "expected a non-negative integer"}
+		}
+		q.MinVotes = &n
+	}
+	if s := v.Get("voted"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return Query{}, &Error{Param: "voted", Value: s, Allowed: []string{"true", "false"}}
+		}
+		q.Voted = &b
+	}
+	return q, nil
+}
+
+// ParseGenres parses the genre parameter: comma-separated TMDb genre ids,
+// returned sorted and without duplicates; empty gives none.
+func ParseGenres(s string) ([]int32, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var genres []int32
+	for _, part := range strings.Split(s, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 32)
+		if err != nil || id <= 0 {
+			return nil, &Error{Param: "genre", Value: s, Reason: "expected comma-separated TMDb genre ids"}
+		}
+		if !slices.Contains(genres, int32(id)) {
+			genres = append(genres, int32(id))
+		}
+	}
+	slices.Sort(genres)
+	return genres, nil
+}
+
+func parseSort(by, dir string) ([]Sort, error) {
+	if by == "" {
+		by = string(DefaultSort[0].Field)
+	}
+	fields := strings.Split(strings.ToLower(by), ",")
+	if len(fields) > MaxSortKeys {
+		return nil, &Error{Param: "sort_by", Value: by, Reason: fmt.Sprintf("at most %d keys", MaxSortKeys)}
+	}
+	var dirs []string
+	if dir != "" {
+		dirs = strings.Split(strings.ToLower(dir), ",")
+	}
+	if len(dirs) > 1 && len(dirs) != len(fields) {
+		return nil, &Error{Param: "sort_dir", Value: dir, Reason: "give one direction or one per sort_by key"}
+	}
+	out := make([]Sort, 0, len(fields))
+	for i, name := range fields {
+		f := Field(strings.TrimSpace(name))
+		if !slices.Contains(Fields, f) {
+			return nil, &Error{Param: "sort_by", Value: name, Allowed: allowedFields()}
+		}
+		if slices.ContainsFunc(out, func(s Sort) bool { return s.Field == f }) {
+			return nil, &Error{Param: "sort_by", Value: by, Reason: "repeats " + string(f)}
+		}
+		d := Desc
+		switch {
+		case len(dirs) == 1:
+			d = Dir(strings.TrimSpace(dirs[0]))
+		case len(dirs) > 1:
+			d = Dir(strings.TrimSpace(dirs[i]))
+		}
+		if d != Asc && d != Desc {
+			return nil, &Error{Param: "sort_dir", Value: string(d), Allowed: []string{string(Asc), string(Desc)}}
+		}
+		out = append(out, Sort{Field: f, Dir: d})
+	}
+	return out, nil
+}
+
+func parseFloat(v url.Values, name string) (*float64, error) {
+	s := v.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, &Error{Param: name, Value: s, Reason: "expected a number"}
+	}
+	return &f, nil
+}
+
+func parseDate(v url.Values, name string) (*time.Time, error) {
+	s := v.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return nil, &Error{Param: name, Value: s, Reason: "expected YYYY-MM-DD"}
+	}
+	return &t, nil
+}
+
+// Canonical renders q as name=value strings in a fixed order, for cursor scopes
+// and cache keys. Equivalent queries render identically.
+func (q Query) Canonical() []string {
+	sorts := make([]string, len(q.Sort))
+	for i, s := range q.Sort {
+		sorts[i] = string(s.Field) + ":" + string(s.Dir)
+	}
+	genres := make([]string, len(q.Genres))
+	for i, g := range q.Genres {
+		genres[i] = strconv.Itoa(int(g))
+	}
+	return []string{
+		"sort=" + strings.Join(sorts, ","),
+		"min_pop=" + formatFloat(q.MinPopularity),
+		"max_pop=" + formatFloat(q.MaxPopularity),
+		"from=" + formatDate(q.ReleasedFrom),
+		"to=" + formatDate(q.ReleasedTo),
+		"genre=" + strings.Join(genres, ","),
+		"min_votes=" + formatInt(q.MinVotes),
+		"voted=" + formatBool(q.Voted),
+	}
+}
+
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'g', -1, 64)
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+func formatInt(n *int64) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatInt(*n, 10)
+}
+
+func formatBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
@@ -0,0 +1,68 @@
+package listquery_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"cinekami-server/internal/listquery"
+)
+
+func TestParseSortAndFilters(t *testing.T) {
+	v, _ := url.ParseQuery("sort_by=total_votes,TITLE&sort_dir=desc,asc&genre=28,12,28&min_votes=5&voted=true&release_from=2025-10-01&release_to=2025-10-31")
+	q, err := listquery.Parse(v)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	wantSort := []listquery.Sort{{Field: listquery.FieldTotalVotes, Dir: listquery.Desc}, {Field: listquery.FieldTitle, Dir: listquery.Asc}}
+	if !reflect.DeepEqual(q.Sort, wantSort) {
+		t.Fatalf("sort = %+v, want %+v", q.Sort, wantSort)
+	}
+	if !reflect.DeepEqual(q.Genres, []int32{12, 28}) || *q.MinVotes != 5 || !*q.Voted || q.ReleasedTo.Day() != 31 {
+		t.Fatalf("filters not parsed: %+v", q)
+	}
+
+	// One direction applies to every key; equivalent queries share a canonical form.
+	a, _ := listquery.Parse(url.Values{"sort_by": {"couple_pct,popularity"}, "sort_dir": {"asc"}, "genre": {"28,12"}})
+	b, _ := listquery.Parse(url.Values{"sort_by": {"couple_pct,popularity"}, "sort_dir": {"asc,asc"}, "genre": {"12,28"}})
+	if !reflect.DeepEqual(a.Canonical(), b.Canonical()) {
+		t.Fatalf("canonical forms differ:\n%v\n%v", a.Canonical(), b.Canonical())
+	}
+
+	d, _ := listquery.Parse(url.Values{})
+	if !reflect.DeepEqual(d.Sort, listquery.DefaultSort) {
+		t.Fatalf("default sort = %+v", d.Sort)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	cases := []struct {
+		query, param string
+		allowed      bool
+	}{
+		{"sort_by=rating", "sort_by", true},
+		{"sort_by=title,title", "sort_by", false},
+		{"sort_by=title,couple,arr,popularity", "sort_by", false},
+		{"sort_by=title,couple&sort_dir=asc,desc,asc", "sort_dir", false},
+		{"sort_dir=up", "sort_dir", true},
+		{"min_popularity=x", "min_popularity", false},
+		{"min_popularity=5&max_popularity=1", "min_popularity", false},
+		{"release_from=10/01/2025", "release_from", false},
+		{"release_from=2025-11-01&release_to=2025-10-01", "release_from", false},
+		{"genre=action", "genre", false},
+		{"min_votes=-1", "min_votes", false},
+		{"voted=maybe", "voted", true},
+	}
+	for _, c := range cases {
+		v, _ := url.ParseQuery(c.query)
+		_, err := listquery.Parse(v)
+		var qe *listquery.Error
+		if !errors.As(err, &qe) {
+			t.Fatalf("%s: expected *listquery.Error, got %v", c.query, err)
+		}
+		if qe.Param != c.param || (len(qe.Allowed) > 0) != c.allowed {
+			t.Fatalf("%s: got param %q allowed %v", c.query, qe.Param, qe.Allowed)
+		}
+	}
+}
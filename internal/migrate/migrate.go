@@ -3,7 +3,13 @@ package migrate
 import (
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -14,33 +20,179 @@ import (
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
+// lockTimeout bounds how long an instance waits for another one to finish
+// migrating. The postgres driver serialises migrations with pg_advisory_lock,
+// so only one instance applies them and the others then find nothing to do.
+const lockTimeout = 5 * time.Minute
+
+var (
+	ErrSchemaDirty  = errors.New("schema is dirty")
+	ErrSchemaBehind = errors.New("schema is behind the embedded migrations")
+)
+
+// SchemaStatus describes a database's schema version relative to the embedded migrations.
+type SchemaStatus struct {
+	Version uint   `json:"version"` // 0 when nothing has been applied
+	Dirty   bool   `json:"dirty"`
+	Latest  uint   `json:"latest"`
+	Pending []uint `json:"pending"`
+}
+
 // Up applies all up migrations embedded in the binary.
 func Up(databaseURL string) error {
+	m, closeFn, err := open(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// Down rolls back every applied migration, dropping all application data.
+func Down(databaseURL string) error {
+	m, closeFn, err := open(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	if err := m.Down(); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// Steps applies n up migrations, or -n down migrations when n is negative.
+func Steps(databaseURL string, n int) error {
+	m, closeFn, err := open(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	if err := m.Steps(n); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// Force records version as applied and clears the dirty flag without running any
+// migration; use it after fixing a migration that failed halfway.
+func Force(databaseURL string, version int) error {
+	m, closeFn, err := open(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+	return m.Force(version)
+}
+
+// Status reports the applied version and which embedded migrations are pending.
+func Status(databaseURL string) (SchemaStatus, error) {
+	all, err := versions()
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+	m, closeFn, err := open(databaseURL)
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+	defer closeFn()
+
+	s := SchemaStatus{Pending: []uint{}}
+	if len(all) > 0 {
+		s.Latest = all[len(all)-1]
+	}
+	s.Version, s.Dirty, err = m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return SchemaStatus{}, err
+	}
+	for _, v := range all {
+		if v > s.Version {
+			s.Pending = append(s.Pending, v)
+		}
+	}
+	return s, nil
+}
+
+// Verify returns the status and an error wrapping ErrSchemaDirty or ErrSchemaBehind
+// when this binary should not serve against the database. A schema ahead of the
+// binary is accepted so an older release can run after a rollback of the code.
+func Verify(databaseURL string) (SchemaStatus, error) {
+	s, err := Status(databaseURL)
+	if err != nil {
+		return s, err
+	}
+	switch {
+	case s.Dirty:
+		return s, fmt.Errorf("%w: version %d", ErrSchemaDirty, s.Version)
+	case len(s.Pending) > 0:
+		return s, fmt.Errorf("%w: version %d, latest %d", ErrSchemaBehind, s.Version, s.Latest)
+	}
+	return s, nil
+}
+
+func open(databaseURL string) (*migrate.Migrate, func(), error) {
 	// Open database/sql connection using pgx stdlib
 	db, err := sql.Open("pgx", databaseURL)
 	if err != nil {
-		return fmt.Errorf("open db: %w", err)
+		return nil, nil, fmt.Errorf("open db: %w", err)
 	}
-	defer db.Close()
 
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
-		return fmt.Errorf("postgres driver: %w", err)
+		db.Close()
+		return nil, nil, fmt.Errorf("postgres driver: %w", err)
 	}
 
 	src, err := iofs.New(migrationsFS, "migrations")
 	if err != nil {
-		return fmt.Errorf("iofs: %w", err)
+		db.Close()
+		return nil, nil, fmt.Errorf("iofs: %w", err)
 	}
 
 	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
 	if err != nil {
-		return fmt.Errorf("migrate instance: %w", err)
+		db.Close()
+		return nil, nil, fmt.Errorf("migrate instance: %w", err)
 	}
-	defer m.Close()
+	m.LockTimeout = lockTimeout
+	return m, func() { m.Close(); db.Close() }, nil
+}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return err
+// LatestVersion returns the highest migration version embedded in the binary.
+func LatestVersion() (uint, error) {
+	all, err := versions()
+	if err != nil || len(all) == 0 {
+		return 0, err
 	}
-	return nil
+	return all[len(all)-1], nil
+}
+
+// versions lists the embedded migration versions in ascending order.
+func versions() ([]uint, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	var out []uint
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		if !slices.Contains(out, uint(v)) {
+			out = append(out, uint(v))
+		}
+	}
+	slices.Sort(out)
+	return out, nil
 }
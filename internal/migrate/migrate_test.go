@@ -0,0 +1,156 @@
+package migrate_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"cinekami-server/internal/migrate"
+)
+
+func TestEveryMigrationIsReversible(t *testing.T) {
+	ups, _ := filepath.Glob("migrations/*.up.sql")
+	if len(ups) == 0 {
+		t.Fatal("no migrations found")
+	}
+	for _, up := range ups {
+		down := strings.TrimSuffix(up, ".up.sql") + ".down.sql"
+		if _, err := os.Stat(down); err != nil {
+			t.Errorf("%s has no down migration", filepath.Base(up))
+		}
+	}
+	latest, err := migrate.LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(latest) != len(ups) {
+		t.Fatalf("latest version %d but %d up migrations; versions must be contiguous", latest, len(ups))
+	}
+}
+
+// TestUpDownUp applies every migration, rolls all of them back one step at a
+// time, applies them again and checks the schema is identical both times. It runs
+// against a scratch database so it cannot disturb other packages' tests.
+func TestUpDownUp(t *testing.T) {
+	dbURL := scratchDatabase(t)
+
+	if err := migrate.Up(dbURL); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	s, err := migrate.Verify(dbURL)
+	if err != nil {
+		t.Fatalf("verify after up: %v", err)
+	}
+	first := dumpSchema(t, dbURL)
+
+	for v := s.Latest; v > 0; v-- {
+		if err := migrate.Steps(dbURL, -1); err != nil {
+			t.Fatalf("down from %d: %v", v, err)
+		}
+	}
+	s, err = migrate.Status(dbURL)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if s.Version != 0 || len(s.Pending) != int(s.Latest) {
+		t.Fatalf("expected nothing applied after rolling back, got %+v", s)
+	}
+	if empty := dumpSchema(t, dbURL); empty != "" {
+		t.Fatalf("down migrations left objects behind:\n%s", empty)
+	}
+	if _, err := migrate.Verify(dbURL); err == nil {
+		t.Fatal("verify should fail on an empty schema")
+	}
+
+	if err := migrate.Up(dbURL); err != nil {
+		t.Fatalf("second up: %v", err)
+	}
+	if second := dumpSchema(t, dbURL); second != first {
+		t.Fatalf("schema differs after up/down/up\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+
+	if err := migrate.Down(dbURL); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if s, err := migrate.Status(dbURL); err != nil || s.Version != 0 {
+		t.Fatalf("expected version 0 after Down, got %+v (%v)", s, err)
+	}
+}
+
+// scratchDatabase creates an empty database next to TEST_DATABASE_URL and drops it
+// when the test ends.
+func scratchDatabase(t *testing.T) string {
+	t.Helper()
+	base := os.Getenv("TEST_DATABASE_URL")
+	if base == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	admin, err := pgx.Connect(ctx, base)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = admin.Close(ctx) })
+
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	name := "cinekami_migrate_" + hex.EncodeToString(b)
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		t.Skipf("cannot create scratch database: %v", err)
+	}
+	t.Cleanup(func() { _, _ = admin.Exec(ctx, "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)") })
+
+	u, err := url.Parse(base)
+	if err != nil {
+		t.Fatalf("parse TEST_DATABASE_URL: %v", err)
+	}
+	u.Path = "/" + name
+	return u.String()
+}
+
+// dumpSchema renders the public schema's tables, columns, constraints, indexes and
+// types (excluding golang-migrate's bookkeeping table) in a stable order.
+func dumpSchema(t *testing.T, dbURL string) string {
+	t.Helper()
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	const q = `
+SELECT 'column ' || table_name || '.' || column_name || ' ' || data_type || ' ' || is_nullable || ' ' || coalesce(column_default, '')
+FROM information_schema.columns
+WHERE table_schema = 'public' AND table_name <> 'schema_migrations'
+UNION ALL
+SELECT 'constraint ' || conrelid::regclass || ' ' || conname || ' ' || pg_get_constraintdef(oid)
+FROM pg_constraint
+WHERE connamespace = 'public'::regnamespace AND conrelid::regclass::text <> 'schema_migrations'
+UNION ALL
+SELECT 'index ' || tablename || ' ' || indexdef
+FROM pg_indexes
+WHERE schemaname = 'public' AND tablename <> 'schema_migrations'
+UNION ALL
+SELECT 'type ' || t.typname || ' ' || string_agg(e.enumlabel, ',' ORDER BY e.enumsortorder)
+FROM pg_type t JOIN pg_enum e ON e.enumtypid = t.oid
+WHERE t.typnamespace = 'public'::regnamespace
+GROUP BY t.typname
+ORDER BY 1`
+	rows, err := conn.Query(ctx, q)
+	if err != nil {
+		t.Fatalf("dump schema: %v", err)
+	}
+	lines, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatalf("dump schema: %v", err)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,8 @@
+-- +migrate Down
+DROP TABLE IF EXISTS snapshots;
+DROP TABLE IF EXISTS vote_tallies;
+DROP TABLE IF EXISTS votes;
+DROP TABLE IF EXISTS voters;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS movies;
+DROP TYPE IF EXISTS vote_category;
@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_snapshots_month_movie;
+DROP INDEX IF EXISTS idx_vote_tallies_movie_category;
+DROP INDEX IF EXISTS idx_votes_movie_category;
+DROP INDEX IF EXISTS idx_movies_release_date;
@@ -0,0 +1,4 @@
+-- +migrate Down
+-- Back to one vote per movie, voter and category
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS unique_voter_vote;
+ALTER TABLE votes ADD CONSTRAINT unique_voter_vote UNIQUE (movie_id, voter_id, category);
@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE movies
+  DROP COLUMN IF EXISTS cinemagia_url,
+  DROP COLUMN IF EXISTS imdb_url;
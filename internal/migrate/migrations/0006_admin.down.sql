@@ -0,0 +1,13 @@
+-- +migrate Down
+-- Moderation state (hidden movies, merges, audit history) is lost.
+DROP TABLE IF EXISTS admin_audit_log;
+
+DROP INDEX IF EXISTS idx_votes_created_at;
+DROP INDEX IF EXISTS idx_votes_voter;
+
+ALTER TABLE movies
+  DROP COLUMN IF EXISTS merged_into,
+  DROP COLUMN IF EXISTS admin_locked,
+  DROP COLUMN IF EXISTS hidden;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,28 @@
+-- +migrate Up
+
+-- Admin users are regular users with role = 'admin'
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'));
+
+-- Moderation flags. Hidden movies drop out of public listings and voting;
+-- admin_locked stops the TMDb sync from overwriting manual edits;
+-- merged_into points a duplicate at the movie that absorbed its votes.
+ALTER TABLE movies
+  ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS admin_locked BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS merged_into BIGINT REFERENCES movies(id) ON DELETE SET NULL;
+
+-- Vote lookups by voter and time range for moderation
+CREATE INDEX IF NOT EXISTS idx_votes_voter ON votes (voter_id);
+CREATE INDEX IF NOT EXISTS idx_votes_created_at ON votes (created_at);
+
+-- Who did what through the admin API
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id         BIGSERIAL PRIMARY KEY,
+    actor      TEXT NOT NULL,
+    action     TEXT NOT NULL,
+    target     TEXT NOT NULL DEFAULT '',
+    details    JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created_at ON admin_audit_log (created_at DESC, id DESC);
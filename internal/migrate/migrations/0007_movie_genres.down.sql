@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_movies_genre_ids;
+
+ALTER TABLE movies DROP COLUMN IF EXISTS genre_ids;
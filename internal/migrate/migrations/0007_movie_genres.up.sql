@@ -0,0 +1,7 @@
+-- +migrate Up
+
+-- TMDb genre ids, filterable with the listings' genre parameter
+ALTER TABLE movies
+  ADD COLUMN IF NOT EXISTS genre_ids INTEGER[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_movies_genre_ids ON movies USING GIN (genre_ids);
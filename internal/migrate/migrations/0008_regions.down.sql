@@ -0,0 +1,21 @@
+-- +migrate Down
+-- Back to a single board: votes, tallies and snapshots outside RO are lost.
+DELETE FROM snapshots WHERE region <> 'RO';
+ALTER TABLE snapshots DROP CONSTRAINT IF EXISTS snapshots_region_month_movie_id_key;
+ALTER TABLE snapshots DROP COLUMN IF EXISTS region;
+ALTER TABLE snapshots ADD CONSTRAINT snapshots_month_movie_id_key UNIQUE (month, movie_id);
+CREATE INDEX IF NOT EXISTS idx_snapshots_month_movie ON snapshots (month, movie_id);
+
+DELETE FROM vote_tallies WHERE region <> 'RO';
+ALTER TABLE vote_tallies DROP CONSTRAINT IF EXISTS vote_tallies_pkey;
+ALTER TABLE vote_tallies DROP COLUMN IF EXISTS region;
+ALTER TABLE vote_tallies ADD PRIMARY KEY (movie_id, category);
+CREATE INDEX IF NOT EXISTS idx_vote_tallies_movie_category ON vote_tallies (movie_id, category);
+
+DELETE FROM votes WHERE region <> 'RO';
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS unique_voter_vote;
+ALTER TABLE votes DROP COLUMN IF EXISTS region;
+ALTER TABLE votes ADD CONSTRAINT unique_voter_vote UNIQUE (movie_id, voter_id);
+
+DROP TABLE IF EXISTS movie_releases;
+DROP TABLE IF EXISTS regions;
@@ -0,0 +1,46 @@
+-- +migrate Up
+
+-- Regions with their own board: release dates, votes, tallies and snapshots
+-- are kept per region. Disabled regions are neither synced nor listed.
+CREATE TABLE IF NOT EXISTS regions (
+    code       TEXT PRIMARY KEY CHECK (code ~ '^[A-Z]{2}$'), -- ISO 3166-1, as TMDb uses it
+    name       TEXT NOT NULL,
+    enabled    BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO regions (code, name) VALUES ('RO', 'Romania'), ('US', 'United States')
+ON CONFLICT (code) DO NOTHING;
+
+-- Theatrical release date of a movie in a region (TMDb release_dates);
+-- movies.release_date stays the primary release date.
+CREATE TABLE IF NOT EXISTS movie_releases (
+    movie_id     BIGINT NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    region       TEXT NOT NULL REFERENCES regions(code),
+    release_date DATE NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (movie_id, region)
+);
+CREATE INDEX IF NOT EXISTS idx_movie_releases_region_date ON movie_releases (region, release_date);
+
+-- Everything recorded so far belongs to RO, the only region synced until now
+INSERT INTO movie_releases (movie_id, region, release_date)
+SELECT id, 'RO', release_date FROM movies
+ON CONFLICT (movie_id, region) DO NOTHING;
+
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT 'RO' REFERENCES regions(code);
+ALTER TABLE votes ALTER COLUMN region DROP DEFAULT;
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS unique_voter_vote;
+ALTER TABLE votes ADD CONSTRAINT unique_voter_vote UNIQUE (movie_id, region, voter_id);
+
+ALTER TABLE vote_tallies ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT 'RO' REFERENCES regions(code);
+ALTER TABLE vote_tallies ALTER COLUMN region DROP DEFAULT;
+ALTER TABLE vote_tallies DROP CONSTRAINT IF EXISTS vote_tallies_pkey;
+ALTER TABLE vote_tallies ADD PRIMARY KEY (movie_id, region, category);
+DROP INDEX IF EXISTS idx_vote_tallies_movie_category;
+
+ALTER TABLE snapshots ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT 'RO' REFERENCES regions(code);
+ALTER TABLE snapshots ALTER COLUMN region DROP DEFAULT;
+ALTER TABLE snapshots DROP CONSTRAINT IF EXISTS snapshots_month_movie_id_key;
+ALTER TABLE snapshots ADD CONSTRAINT snapshots_region_month_movie_id_key UNIQUE (region, month, movie_id);
+DROP INDEX IF EXISTS idx_snapshots_month_movie;
@@ -0,0 +1,6 @@
+-- +migrate Down
+DROP TABLE IF EXISTS movie_translations;
+
+ALTER TABLE movies
+  DROP COLUMN IF EXISTS original_language,
+  DROP COLUMN IF EXISTS original_title;
@@ -0,0 +1,19 @@
+-- +migrate Up
+
+-- Original title and language from TMDb; untranslated titles fall back to them.
+ALTER TABLE movies
+  ADD COLUMN IF NOT EXISTS original_title    TEXT,
+  ADD COLUMN IF NOT EXISTS original_language TEXT;
+
+-- Title and overview per served language (BCP 47 tag such as ro-RO), synced
+-- from TMDb translations. movies.title and movies.overview stay in the
+-- language movies are synced in (TMDB_LANGUAGE). Empty text means TMDb has no
+-- translation for that field.
+CREATE TABLE IF NOT EXISTS movie_translations (
+    movie_id   BIGINT NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    language   TEXT NOT NULL,
+    title      TEXT NOT NULL DEFAULT '',
+    overview   TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (movie_id, language)
+);
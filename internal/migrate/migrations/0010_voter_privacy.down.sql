@@ -0,0 +1,14 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_votes_voter_created;
+
+-- Digests cannot be turned back into fingerprints; anonymized voters get a
+-- placeholder so the column can be NOT NULL again.
+UPDATE voters SET fingerprint = 'anonymized:' || id WHERE fingerprint IS NULL;
+
+ALTER TABLE voters
+  DROP COLUMN IF EXISTS anonymized_at,
+  ALTER COLUMN fingerprint SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_voters_unhashed;
+
+ALTER TABLE voters DROP COLUMN IF EXISTS fingerprint_hashed;
@@ -0,0 +1,18 @@
+-- +migrate Up
+
+-- Fingerprints are stored as keyed HMAC digests. The key is not known to SQL,
+-- so existing raw fingerprints are only flagged here and hashed by the server
+-- at startup (or by `cinekami voters rehash`).
+ALTER TABLE voters
+  ADD COLUMN IF NOT EXISTS fingerprint_hashed BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_voters_unhashed ON voters (id) WHERE NOT fingerprint_hashed;
+
+-- Erased and long inactive voters keep their votes, so tallies stay intact,
+-- but lose their fingerprint and account link.
+ALTER TABLE voters
+  ALTER COLUMN fingerprint DROP NOT NULL,
+  ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ;
+
+-- The retention job looks for voters without recent votes.
+CREATE INDEX IF NOT EXISTS idx_votes_voter_created ON votes (voter_id, created_at);
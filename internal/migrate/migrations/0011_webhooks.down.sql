@@ -0,0 +1,6 @@
+-- +migrate Down
+-- Subscriptions, the delivery log and announced milestones are lost.
+
+DROP TABLE IF EXISTS vote_milestones;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,4 @@
+-- +migrate Down
+
+DROP INDEX IF EXISTS idx_movie_releases_region_created;
+ALTER TABLE movie_releases DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,10 @@
+-- +migrate Up
+
+-- When a movie was put on a region's board, for the feed of newly votable
+-- movies. Existing rows only know their last update.
+ALTER TABLE movie_releases
+  ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+UPDATE movie_releases SET created_at = updated_at;
+
+CREATE INDEX IF NOT EXISTS idx_movie_releases_region_created ON movie_releases (region, created_at DESC);
@@ -0,0 +1,3 @@
+-- +migrate Down
+
+ALTER TABLE movie_releases DROP COLUMN IF EXISTS revision;
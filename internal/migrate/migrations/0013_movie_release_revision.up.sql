@@ -0,0 +1,6 @@
+-- +migrate Up
+
+-- Counts changes of a regional release date; calendar events use it as their
+-- SEQUENCE so subscribed clients move an event instead of adding another.
+ALTER TABLE movie_releases
+  ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 0;
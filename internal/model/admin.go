@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AdminMovie is a movie as seen by the admin API, including moderation flags.
+type AdminMovie struct {
+	Movie
+	Hidden      bool      `json:"hidden"`
+	AdminLocked bool      `json:"admin_locked"` // TMDb sync leaves locked movies untouched
+	MergedInto  *int64    `json:"merged_into,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// Releases maps region codes to the movie's release date there.
+	Releases map[string]time.Time `json:"releases"`
+}
+
+// AdminVote is a single vote with its voter, for moderation.
+type AdminVote struct {
+	ID          string    `json:"id"`
+	MovieID     int64     `json:"movie_id"`
+	Region      string    `json:"region"`
+	VoterID     string    `json:"voter_id"`
+	Fingerprint string    `json:"fingerprint"`
+	Category    string    `json:"category"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AuditEntry is a row of the admin audit log.
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target"`
+	Details   json.RawMessage `json:"details"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// Rows of the bulk exports. Each is written as CSV (CSVHeader/CSVRecord),
+// NDJSON (json tags) or Parquet (parquet tags); the column names match.
+
+// SnapshotExportRow is one movie's archived tallies for a month.
+type SnapshotExportRow struct {
+	Region      string    `json:"region" parquet:"region,dict"`
+	Month       string    `json:"month" parquet:"month,dict"`
+	MovieID     int64     `json:"movie_id" parquet:"movie_id"`
+	Title       string    `json:"title" parquet:"title"`
+	ClosedAt    time.Time `json:"closed_at" parquet:"closed_at,timestamp"`
+	SoloFriends int64     `json:"solo_friends" parquet:"solo_friends"`
+	Couple      int64     `json:"couple" parquet:"couple"`
+	Streaming   int64     `json:"streaming" parquet:"streaming"`
+	Arr         int64     `json:"arr" parquet:"arr"`
+	TotalVotes  int64     `json:"total_votes" parquet:"total_votes"`
+}
+
+func (SnapshotExportRow) CSVHeader() []string {
+	return []string{"region", "month", "movie_id", "title", "closed_at", "solo_friends", "couple", "streaming", "arr", "total_votes"}
+}
+
+func (r SnapshotExportRow) CSVRecord() []string {
+	return []string{r.Region, r.Month, itoa(r.MovieID), r.Title, r.ClosedAt.UTC().Format(time.RFC3339),
+		itoa(r.SoloFriends), itoa(r.Couple), itoa(r.Streaming), itoa(r.Arr), itoa(r.TotalVotes)}
+}
+
+// TallyExportRow is a movie's current tallies in a region.
+type TallyExportRow struct {
+	Region      string `json:"region" parquet:"region,dict"`
+	MovieID     int64  `json:"movie_id" parquet:"movie_id"`
+	Title       string `json:"title" parquet:"title"`
+	ReleaseDate string `json:"release_date" parquet:"release_date"` // YYYY-MM-DD
+	SoloFriends int64  `json:"solo_friends" parquet:"solo_friends"`
+	Couple      int64  `json:"couple" parquet:"couple"`
+	Streaming   int64  `json:"streaming" parquet:"streaming"`
+	Arr         int64  `json:"arr" parquet:"arr"`
+	TotalVotes  int64  `json:"total_votes" parquet:"total_votes"`
+}
+
+func (TallyExportRow) CSVHeader() []string {
+	return []string{"region", "movie_id", "title", "release_date", "solo_friends", "couple", "streaming", "arr", "total_votes"}
+}
+
+func (r TallyExportRow) CSVRecord() []string {
+	return []string{r.Region, itoa(r.MovieID), r.Title, r.ReleaseDate,
+		itoa(r.SoloFriends), itoa(r.Couple), itoa(r.Streaming), itoa(r.Arr), itoa(r.TotalVotes)}
+}
+
+// VoteExportRow is an anonymized vote: no voter, and the time only to the hour.
+type VoteExportRow struct {
+	MovieID  int64     `json:"movie_id" parquet:"movie_id"`
+	Region   string    `json:"region" parquet:"region,dict"`
+	Category string    `json:"category" parquet:"category,dict"`
+	VotedAt  time.Time `json:"voted_at" parquet:"voted_at,timestamp"`
+}
+
+func (VoteExportRow) CSVHeader() []string {
+	return []string{"movie_id", "region", "category", "voted_at"}
+}
+
+func (r VoteExportRow) CSVRecord() []string {
+	return []string{itoa(r.MovieID), r.Region, r.Category, r.VotedAt.UTC().Format(time.RFC3339)}
+}
+
+func itoa(n int64) string { return strconv.FormatInt(n, 10) }
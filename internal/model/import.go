@@ -0,0 +1,68 @@
+package model
+
+// ImportOutcome is what importing one archive row did.
+type ImportOutcome string
+
+const (
+	ImportCreated   ImportOutcome = "created"
+	ImportUpdated   ImportOutcome = "updated"
+	ImportUnchanged ImportOutcome = "unchanged"
+)
+
+// MaxImportErrors caps the row errors an ImportSummary lists; Rejected still
+// counts them all.
+const MaxImportErrors = 100
+
+// ImportSummary reports an archive import. With DryRun nothing was written and
+// the counts are what the import would have done.
+type ImportSummary struct {
+	Kind      string           `json:"kind"` // snapshots or movies
+	DryRun    bool             `json:"dry_run"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Rejected  int              `json:"rejected"`
+	Resolved  int              `json:"resolved_movies"` // missing movies fetched from TMDb
+	Months    []ImportMonth    `json:"months,omitempty"`
+	Errors    []ImportRowError `json:"errors,omitempty"`
+}
+
+// ImportMonth is the part of a snapshot import for one region and month, which
+// is applied in one transaction.
+type ImportMonth struct {
+	Region    string `json:"region"`
+	Month     string `json:"month"`
+	Created   int    `json:"created"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"`
+	Rejected  int    `json:"rejected"`
+}
+
+// ImportRowError is a rejected archive row; Line counts from 1 and includes a
+// CSV header.
+type ImportRowError struct {
+	Line    int    `json:"line"`
+	MovieID int64  `json:"movie_id,omitempty"`
+	Error   string `json:"error"`
+}
+
+// Count adds an outcome to the totals.
+func (s *ImportSummary) Count(o ImportOutcome) {
+	switch o {
+	case ImportCreated:
+		s.Created++
+	case ImportUpdated:
+		s.Updated++
+	default:
+		s.Unchanged++
+	}
+}
+
+// Reject counts a rejected row, keeping its error while fewer than
+// MaxImportErrors are listed.
+func (s *ImportSummary) Reject(line int, movieID int64, err error) {
+	s.Rejected++
+	if len(s.Errors) < MaxImportErrors {
+		s.Errors = append(s.Errors, ImportRowError{Line: line, MovieID: movieID, Error: err.Error()})
+	}
+}
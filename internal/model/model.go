@@ -29,6 +29,16 @@ type Movie struct {
 	VotedCategory *string          `json:"voted_category,omitempty"`
 	ImdbURL       *string          `json:"imdb_url,omitempty"`
 	CinemagiaURL  *string          `json:"cinemagia_url,omitempty"`
+	GenreIDs      []int32          `json:"genre_ids,omitempty"` // TMDb genre ids
+	Stats         *TallyStats      `json:"stats,omitempty"`
+}
+
+// Region is a country with its own board: release dates, votes and snapshots
+// are kept per region.
+type Region struct {
+	Code    string `json:"code"` // ISO 3166-1, e.g. RO
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
 }
 
 type Tally struct {
@@ -38,17 +48,89 @@ type Tally struct {
 }
 
 type Snapshot struct {
-	Month   string           `json:"month"` // YYYY-MM
+	Region  string           `json:"region"` // ISO 3166-1 code of the board
+	Month   string           `json:"month"`  // YYYY-MM
 	MovieID int64            `json:"movie_id"`
 	Tallies map[string]int64 `json:"tallies"`
 	Closed  time.Time        `json:"closed_at"`
 	// movie metadata joined for convenience
-	Title        string    `json:"title,omitempty"`
-	ReleaseDate  time.Time `json:"release_date,omitempty"`
+	Title        string      `json:"title,omitempty"`
+	ReleaseDate  time.Time   `json:"release_date,omitempty"`
+	Overview     *string     `json:"overview,omitempty"`
+	PosterPath   *string     `json:"poster_path,omitempty"`
+	BackdropPath *string     `json:"backdrop_path,omitempty"`
+	Popularity   float64     `json:"popularity,omitempty"`
+	ImdbURL      *string     `json:"imdb_url,omitempty"`
+	CinemagiaURL *string     `json:"cinemagia_url,omitempty"`
+	GenreIDs     []int32     `json:"genre_ids,omitempty"`
+	Stats        *TallyStats `json:"stats,omitempty"`
+}
+
+// VoterExport is everything stored about a voter: its voter ids and votes.
+type VoterExport struct {
+	VoterIDs   []string    `json:"voter_ids"`
+	Votes      []VoterVote `json:"votes"`
+	ExportedAt time.Time   `json:"exported_at"`
+}
+
+// VoterVote is a single vote in a voter export.
+type VoterVote struct {
+	MovieID   int64     `json:"movie_id"`
+	Title     string    `json:"title"`
+	Region    string    `json:"region"`
+	Category  string    `json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MonthResult is a closed month of a region's board with the winner of each
+// category that received votes.
+type MonthResult struct {
+	Region   string           `json:"region"`
+	Month    string           `json:"month"` // YYYY-MM
+	ClosedAt time.Time        `json:"closed_at"`
+	Movies   int64            `json:"movies"` // snapshots taken that month
+	Winners  []CategoryWinner `json:"winners"`
+	Totals   map[string]int64 `json:"totals,omitempty"` // votes per category over the month's movies
+}
+
+// MovieCard is a movie as shown on its share card and widget, released in
+// Region on ReleaseDate.
+type MovieCard struct {
+	ID          int64     `json:"id"`
+	Region      string    `json:"region"`
+	Title       string    `json:"title"`
+	Overview    *string   `json:"overview,omitempty"`
+	PosterPath  *string   `json:"poster_path,omitempty"`
+	ReleaseDate time.Time `json:"release_date"`
+}
+
+// CategoryWinner is the movie with the most votes in a category of a month.
+type CategoryWinner struct {
+	Category string `json:"category"`
+	MovieID  int64  `json:"movie_id"`
+	Title    string `json:"title"`
+	Votes    int64  `json:"votes"`
+}
+
+// VotableMovie is a movie put on a region's board, with the time voting on it
+// opened: the start of its release month or, for movies added later, the time
+// they were added.
+type VotableMovie struct {
+	ID           int64     `json:"id"`
+	Title        string    `json:"title"`
 	Overview     *string   `json:"overview,omitempty"`
 	PosterPath   *string   `json:"poster_path,omitempty"`
-	BackdropPath *string   `json:"backdrop_path,omitempty"`
-	Popularity   float64   `json:"popularity,omitempty"`
-	ImdbURL      *string   `json:"imdb_url,omitempty"`
-	CinemagiaURL *string   `json:"cinemagia_url,omitempty"`
+	ReleaseDate  time.Time `json:"release_date"`
+	VotableSince time.Time `json:"votable_since"`
+}
+
+// CalendarRelease is a movie's release in a region as shown in the calendar.
+// Revision counts changes of the date.
+type CalendarRelease struct {
+	MovieID     int64
+	Title       string
+	Region      string
+	ReleaseDate time.Time
+	Revision    int
+	UpdatedAt   time.Time
 }
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"sort"
+
+	pkgstats "cinekami-server/pkg/stats"
+)
+
+// Share is a category's fraction of all votes with its 95% Wilson score interval.
+type Share struct {
+	Share float64 `json:"share"`
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+}
+
+// Leading is the category with the most votes. Confident is set when its
+// interval lies entirely above the runner-up's, i.e. the lead is unlikely to be noise.
+type Leading struct {
+	Category  string `json:"category"`
+	Confident bool   `json:"confident"`
+}
+
+// TallyStats summarises tallies so a 3-vote movie does not look as decisive as
+// a 3000-vote one.
+type TallyStats struct {
+	TotalVotes int64            `json:"total_votes"`
+	Shares     map[string]Share `json:"shares"`
+	Leading    *Leading         `json:"leading,omitempty"` // absent without votes
+}
+
+// NewTallyStats computes shares and intervals for every allowed category.
+func NewTallyStats(tallies map[string]int64) *TallyStats {
+	s := &TallyStats{Shares: make(map[string]Share, len(AllowedCategories))}
+	for cat := range AllowedCategories {
+		s.TotalVotes += tallies[cat]
+	}
+	cats := make([]string, 0, len(AllowedCategories))
+	for cat := range AllowedCategories {
+		n := tallies[cat]
+		sh := Share{}
+		if s.TotalVotes > 0 {
+			sh.Share = float64(n) / float64(s.TotalVotes)
+			sh.Low, sh.High = pkgstats.Wilson(n, s.TotalVotes, pkgstats.Z95)
+		}
+		s.Shares[cat] = sh
+		cats = append(cats, cat)
+	}
+	if s.TotalVotes == 0 {
+		return s
+	}
+	// Most votes first, category name as the tie-breaker
+	sort.Slice(cats, func(i, j int) bool {
+		if tallies[cats[i]] != tallies[cats[j]] {
+			return tallies[cats[i]] > tallies[cats[j]]
+		}
+		return cats[i] < cats[j]
+	})
+	lead, runnerUp := s.Shares[cats[0]], s.Shares[cats[1]]
+	s.Leading = &Leading{Category: cats[0], Confident: lead.Low > runnerUp.High}
+	return s
+}
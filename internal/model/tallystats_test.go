@@ -0,0 +1,28 @@
+package model_test
+
+import (
+	"testing"
+
+	"cinekami-server/internal/model"
+)
+
+func TestTallyStatsLeadingConfidence(t *testing.T) {
+	few := model.NewTallyStats(map[string]int64{model.CategoryCouple: 3})
+	many := model.NewTallyStats(map[string]int64{model.CategoryCouple: 2400, model.CategoryArr: 600})
+
+	if few.TotalVotes != 3 || few.Shares[model.CategoryCouple].Share != 1 {
+		t.Fatalf("unexpected stats for 3 votes: %+v", few)
+	}
+	if few.Leading == nil || few.Leading.Category != model.CategoryCouple || few.Leading.Confident {
+		t.Fatalf("3 unanimous votes should lead without confidence: %+v", few.Leading)
+	}
+	if many.Leading == nil || many.Leading.Category != model.CategoryCouple || !many.Leading.Confident {
+		t.Fatalf("an 80%% share of 3000 votes should lead with confidence: %+v", many.Leading)
+	}
+	if s := many.Shares[model.CategoryCouple]; s.Low >= s.Share || s.High <= s.Share {
+		t.Fatalf("interval should contain the share: %+v", s)
+	}
+	if empty := model.NewTallyStats(nil); empty.Leading != nil || len(empty.Shares) != len(model.AllowedCategories) {
+		t.Fatalf("no votes: %+v", empty)
+	}
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook events.
+const (
+	EventSnapshotClosed = "snapshot.closed" // a month's snapshot of a region was archived for the first time
+	EventMoviesAdded    = "movies.added"    // a sync put new movies on a region's board
+	EventVoteMilestone  = "vote.milestone"  // a movie's votes in a region reached a milestone
+)
+
+// WebhookEvents lists every event a subscription may ask for.
+var WebhookEvents = []string{EventSnapshotClosed, EventMoviesAdded, EventVoteMilestone}
+
+// Delivery states: pending deliveries are retried with backoff until they
+// succeed (delivered) or run out of attempts (dead).
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookSubscription is a partner endpoint and the events it receives. The
+// secret is only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is one event sent (or to be sent) to one subscription.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"` // pending only
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus *int32          `json:"response_status,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// SnapshotClosedEvent is the payload of snapshot.closed.
+type SnapshotClosedEvent struct {
+	Region string `json:"region"`
+	Month  string `json:"month"` // YYYY-MM
+	Movies int    `json:"movies"`
+}
+
+// MoviesAddedEvent is the payload of movies.added.
+type MoviesAddedEvent struct {
+	Region string       `json:"region"`
+	Movies []AddedMovie `json:"movies"`
+}
+
+// AddedMovie is a movie new to a region's board.
+type AddedMovie struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date"` // YYYY-MM-DD, regional
+}
+
+// VoteMilestoneEvent is the payload of vote.milestone.
+type VoteMilestoneEvent struct {
+	MovieID   int64  `json:"movie_id"`
+	Region    string `json:"region"`
+	Milestone int64  `json:"milestone"`
+}
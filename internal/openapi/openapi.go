@@ -0,0 +1,13 @@
+// Package openapi embeds the OpenAPI 3.1 document describing the public /v1 API.
+package openapi
+
+import (
+	_ "embed"
+)
+
+// Spec is the raw OpenAPI document served at /v1/openapi.json.
+// It is maintained by hand next to the handlers in internal/routes; the
+// server tests validate real handler output against it.
+//
+//go:embed openapi.json
+var Spec []byte
@@ -0,0 +1,1488 @@
+// gRPC API for internal services: the same boards, tallies and snapshots as
+// the REST API under /v1, served on its own port. Regenerate the Go code in
+// internal/pb/cinekamiv1 with `make proto` after editing.
+//
+// Callers identify the voter they act for with the x-fingerprint metadata key,
+// as X-Fingerprint on the REST API, and may pass x-correlation-id, which is
+// echoed in the response headers and logged with the call. Failures carry the
+// REST error code as the reason of a google.rpc.ErrorInfo detail.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: cinekami/v1/cinekami.proto
+
+package cinekamiv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Category int32
+
+const (
+	Category_CATEGORY_UNSPECIFIED  Category = 0
+	Category_CATEGORY_SOLO_FRIENDS Category = 1
+	Category_CATEGORY_COUPLE       Category = 2
+	Category_CATEGORY_STREAMING    Category = 3
+	Category_CATEGORY_ARR          Category = 4
+)
+
+// Enum value maps for Category.
+var (
+	Category_name = map[int32]string{
+		0: "CATEGORY_UNSPECIFIED",
+		1: "CATEGORY_SOLO_FRIENDS",
+		2: "CATEGORY_COUPLE",
+		3: "CATEGORY_STREAMING",
+		4: "CATEGORY_ARR",
+	}
+	Category_value = map[string]int32{
+		"CATEGORY_UNSPECIFIED":  0,
+		"CATEGORY_SOLO_FRIENDS": 1,
+		"CATEGORY_COUPLE":       2,
+		"CATEGORY_STREAMING":    3,
+		"CATEGORY_ARR":          4,
+	}
+)
+
+func (x Category) Enum() *Category {
+	p := new(Category)
+	*p = x
+	return p
+}
+
+func (x Category) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Category) Descriptor() protoreflect.EnumDescriptor {
+	return file_cinekami_v1_cinekami_proto_enumTypes[0].Descriptor()
+}
+
+func (Category) Type() protoreflect.EnumType {
+	return &file_cinekami_v1_cinekami_proto_enumTypes[0]
+}
+
+func (x Category) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Category.Descriptor instead.
+func (Category) EnumDescriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{0}
+}
+
+// ListOptions are the paging, sort and filter parameters of the REST
+// listings; cursors are interchangeable with theirs.
+type ListOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ISO 3166-1 code of the board; the default region when empty.
+	Region string `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	// Served language, as ?lang=; negotiated from accept-language when empty.
+	Language string `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	// Page size; the REST default and maximum apply.
+	Limit int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// next_cursor or prev_cursor of the previous response.
+	Cursor       string `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	IncludeTotal bool   `protobuf:"varint,5,opt,name=include_total,json=includeTotal,proto3" json:"include_total,omitempty"`
+	// As sort_by and sort_dir: up to three comma-separated fields.
+	SortBy        string   `protobuf:"bytes,6,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	SortDir       string   `protobuf:"bytes,7,opt,name=sort_dir,json=sortDir,proto3" json:"sort_dir,omitempty"`
+	MinPopularity *float64 `protobuf:"fixed64,8,opt,name=min_popularity,json=minPopularity,proto3,oneof" json:"min_popularity,omitempty"`
+	MaxPopularity *float64 `protobuf:"fixed64,9,opt,name=max_popularity,json=maxPopularity,proto3,oneof" json:"max_popularity,omitempty"`
+	// YYYY-MM-DD, inclusive.
+	ReleaseFrom string `protobuf:"bytes,10,opt,name=release_from,json=releaseFrom,proto3" json:"release_from,omitempty"`
+	ReleaseTo   string `protobuf:"bytes,11,opt,name=release_to,json=releaseTo,proto3" json:"release_to,omitempty"`
+	// TMDb genre ids; movies with any of them.
+	GenreIds []int32 `protobuf:"varint,12,rep,packed,name=genre_ids,json=genreIds,proto3" json:"genre_ids,omitempty"`
+	MinVotes *int64  `protobuf:"varint,13,opt,name=min_votes,json=minVotes,proto3,oneof" json:"min_votes,omitempty"`
+	// Voted (or not) by the caller's x-fingerprint.
+	Voted         *bool `protobuf:"varint,14,opt,name=voted,proto3,oneof" json:"voted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOptions) Reset() {
+	*x = ListOptions{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOptions) ProtoMessage() {}
+
+func (x *ListOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOptions.ProtoReflect.Descriptor instead.
+func (*ListOptions) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListOptions) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ListOptions) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ListOptions) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListOptions) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListOptions) GetIncludeTotal() bool {
+	if x != nil {
+		return x.IncludeTotal
+	}
+	return false
+}
+
+func (x *ListOptions) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListOptions) GetSortDir() string {
+	if x != nil {
+		return x.SortDir
+	}
+	return ""
+}
+
+func (x *ListOptions) GetMinPopularity() float64 {
+	if x != nil && x.MinPopularity != nil {
+		return *x.MinPopularity
+	}
+	return 0
+}
+
+func (x *ListOptions) GetMaxPopularity() float64 {
+	if x != nil && x.MaxPopularity != nil {
+		return *x.MaxPopularity
+	}
+	return 0
+}
+
+func (x *ListOptions) GetReleaseFrom() string {
+	if x != nil {
+		return x.ReleaseFrom
+	}
+	return ""
+}
+
+func (x *ListOptions) GetReleaseTo() string {
+	if x != nil {
+		return x.ReleaseTo
+	}
+	return ""
+}
+
+func (x *ListOptions) GetGenreIds() []int32 {
+	if x != nil {
+		return x.GenreIds
+	}
+	return nil
+}
+
+func (x *ListOptions) GetMinVotes() int64 {
+	if x != nil && x.MinVotes != nil {
+		return *x.MinVotes
+	}
+	return 0
+}
+
+func (x *ListOptions) GetVoted() bool {
+	if x != nil && x.Voted != nil {
+		return *x.Voted
+	}
+	return false
+}
+
+type ListActiveMoviesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Options       *ListOptions           `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActiveMoviesRequest) Reset() {
+	*x = ListActiveMoviesRequest{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActiveMoviesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActiveMoviesRequest) ProtoMessage() {}
+
+func (x *ListActiveMoviesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActiveMoviesRequest.ProtoReflect.Descriptor instead.
+func (*ListActiveMoviesRequest) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListActiveMoviesRequest) GetOptions() *ListOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type ListActiveMoviesResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Region string                 `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	Movies []*Movie               `protobuf:"bytes,2,rep,name=movies,proto3" json:"movies,omitempty"`
+	// Set when include_total was.
+	Total         *int64 `protobuf:"varint,3,opt,name=total,proto3,oneof" json:"total,omitempty"`
+	NextCursor    string `protobuf:"bytes,4,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	PrevCursor    string `protobuf:"bytes,5,opt,name=prev_cursor,json=prevCursor,proto3" json:"prev_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActiveMoviesResponse) Reset() {
+	*x = ListActiveMoviesResponse{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActiveMoviesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActiveMoviesResponse) ProtoMessage() {}
+
+func (x *ListActiveMoviesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActiveMoviesResponse.ProtoReflect.Descriptor instead.
+func (*ListActiveMoviesResponse) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListActiveMoviesResponse) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ListActiveMoviesResponse) GetMovies() []*Movie {
+	if x != nil {
+		return x.Movies
+	}
+	return nil
+}
+
+func (x *ListActiveMoviesResponse) GetTotal() int64 {
+	if x != nil && x.Total != nil {
+		return *x.Total
+	}
+	return 0
+}
+
+func (x *ListActiveMoviesResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *ListActiveMoviesResponse) GetPrevCursor() string {
+	if x != nil {
+		return x.PrevCursor
+	}
+	return ""
+}
+
+type GetMovieRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MovieId       int64                  `protobuf:"varint,1,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	Region        string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	Language      string                 `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMovieRequest) Reset() {
+	*x = GetMovieRequest{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMovieRequest) ProtoMessage() {}
+
+func (x *GetMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMovieRequest.ProtoReflect.Descriptor instead.
+func (*GetMovieRequest) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetMovieRequest) GetMovieId() int64 {
+	if x != nil {
+		return x.MovieId
+	}
+	return 0
+}
+
+func (x *GetMovieRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *GetMovieRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+type GetTalliesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MovieId       int64                  `protobuf:"varint,1,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	Region        string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTalliesRequest) Reset() {
+	*x = GetTalliesRequest{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTalliesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTalliesRequest) ProtoMessage() {}
+
+func (x *GetTalliesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTalliesRequest.ProtoReflect.Descriptor instead.
+func (*GetTalliesRequest) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetTalliesRequest) GetMovieId() int64 {
+	if x != nil {
+		return x.MovieId
+	}
+	return 0
+}
+
+func (x *GetTalliesRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+type ListSnapshotsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Year          int32                  `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	Month         int32                  `protobuf:"varint,2,opt,name=month,proto3" json:"month,omitempty"`
+	Options       *ListOptions           `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSnapshotsRequest) Reset() {
+	*x = ListSnapshotsRequest{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnapshotsRequest) ProtoMessage() {}
+
+func (x *ListSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListSnapshotsRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *ListSnapshotsRequest) GetMonth() int32 {
+	if x != nil {
+		return x.Month
+	}
+	return 0
+}
+
+func (x *ListSnapshotsRequest) GetOptions() *ListOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type ListSnapshotsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Region        string                 `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	Snapshots     []*Snapshot            `protobuf:"bytes,2,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	Total         *int64                 `protobuf:"varint,3,opt,name=total,proto3,oneof" json:"total,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,4,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	PrevCursor    string                 `protobuf:"bytes,5,opt,name=prev_cursor,json=prevCursor,proto3" json:"prev_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSnapshotsResponse) Reset() {
+	*x = ListSnapshotsResponse{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSnapshotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnapshotsResponse) ProtoMessage() {}
+
+func (x *ListSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListSnapshotsResponse) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ListSnapshotsResponse) GetSnapshots() []*Snapshot {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+func (x *ListSnapshotsResponse) GetTotal() int64 {
+	if x != nil && x.Total != nil {
+		return *x.Total
+	}
+	return 0
+}
+
+func (x *ListSnapshotsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *ListSnapshotsResponse) GetPrevCursor() string {
+	if x != nil {
+		return x.PrevCursor
+	}
+	return ""
+}
+
+type CastVoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MovieId       int64                  `protobuf:"varint,1,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	Region        string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	Category      Category               `protobuf:"varint,3,opt,name=category,proto3,enum=cinekami.v1.Category" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CastVoteRequest) Reset() {
+	*x = CastVoteRequest{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CastVoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CastVoteRequest) ProtoMessage() {}
+
+func (x *CastVoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CastVoteRequest.ProtoReflect.Descriptor instead.
+func (*CastVoteRequest) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CastVoteRequest) GetMovieId() int64 {
+	if x != nil {
+		return x.MovieId
+	}
+	return 0
+}
+
+func (x *CastVoteRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *CastVoteRequest) GetCategory() Category {
+	if x != nil {
+		return x.Category
+	}
+	return Category_CATEGORY_UNSPECIFIED
+}
+
+type CastVoteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False when the caller had already voted for the movie.
+	Inserted bool `protobuf:"varint,1,opt,name=inserted,proto3" json:"inserted,omitempty"`
+	// The movie's tallies after the vote; voted_category is the caller's vote,
+	// which differs from the requested one after a duplicate.
+	Tallies       *Tallies `protobuf:"bytes,2,opt,name=tallies,proto3" json:"tallies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CastVoteResponse) Reset() {
+	*x = CastVoteResponse{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CastVoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CastVoteResponse) ProtoMessage() {}
+
+func (x *CastVoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CastVoteResponse.ProtoReflect.Descriptor instead.
+func (*CastVoteResponse) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CastVoteResponse) GetInserted() bool {
+	if x != nil {
+		return x.Inserted
+	}
+	return false
+}
+
+func (x *CastVoteResponse) GetTallies() *Tallies {
+	if x != nil {
+		return x.Tallies
+	}
+	return nil
+}
+
+type WatchTalliesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At most the REST maximum page size of movies.
+	MovieIds      []int64 `protobuf:"varint,1,rep,packed,name=movie_ids,json=movieIds,proto3" json:"movie_ids,omitempty"`
+	Region        string  `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTalliesRequest) Reset() {
+	*x = WatchTalliesRequest{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTalliesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTalliesRequest) ProtoMessage() {}
+
+func (x *WatchTalliesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTalliesRequest.ProtoReflect.Descriptor instead.
+func (*WatchTalliesRequest) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchTalliesRequest) GetMovieIds() []int64 {
+	if x != nil {
+		return x.MovieIds
+	}
+	return nil
+}
+
+func (x *WatchTalliesRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+// Movie is a movie on a board; release_date is the release there.
+type Movie struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // TMDb id
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	ReleaseDate   string                 `protobuf:"bytes,3,opt,name=release_date,json=releaseDate,proto3" json:"release_date,omitempty"` // YYYY-MM-DD
+	Overview      string                 `protobuf:"bytes,4,opt,name=overview,proto3" json:"overview,omitempty"`
+	PosterPath    string                 `protobuf:"bytes,5,opt,name=poster_path,json=posterPath,proto3" json:"poster_path,omitempty"`
+	BackdropPath  string                 `protobuf:"bytes,6,opt,name=backdrop_path,json=backdropPath,proto3" json:"backdrop_path,omitempty"`
+	Popularity    float64                `protobuf:"fixed64,7,opt,name=popularity,proto3" json:"popularity,omitempty"`
+	GenreIds      []int32                `protobuf:"varint,8,rep,packed,name=genre_ids,json=genreIds,proto3" json:"genre_ids,omitempty"`
+	ImdbUrl       string                 `protobuf:"bytes,9,opt,name=imdb_url,json=imdbUrl,proto3" json:"imdb_url,omitempty"`
+	CinemagiaUrl  string                 `protobuf:"bytes,10,opt,name=cinemagia_url,json=cinemagiaUrl,proto3" json:"cinemagia_url,omitempty"`
+	Tallies       *Tallies               `protobuf:"bytes,11,opt,name=tallies,proto3" json:"tallies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Movie) Reset() {
+	*x = Movie{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Movie) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Movie) ProtoMessage() {}
+
+func (x *Movie) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Movie.ProtoReflect.Descriptor instead.
+func (*Movie) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Movie) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Movie) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Movie) GetReleaseDate() string {
+	if x != nil {
+		return x.ReleaseDate
+	}
+	return ""
+}
+
+func (x *Movie) GetOverview() string {
+	if x != nil {
+		return x.Overview
+	}
+	return ""
+}
+
+func (x *Movie) GetPosterPath() string {
+	if x != nil {
+		return x.PosterPath
+	}
+	return ""
+}
+
+func (x *Movie) GetBackdropPath() string {
+	if x != nil {
+		return x.BackdropPath
+	}
+	return ""
+}
+
+func (x *Movie) GetPopularity() float64 {
+	if x != nil {
+		return x.Popularity
+	}
+	return 0
+}
+
+func (x *Movie) GetGenreIds() []int32 {
+	if x != nil {
+		return x.GenreIds
+	}
+	return nil
+}
+
+func (x *Movie) GetImdbUrl() string {
+	if x != nil {
+		return x.ImdbUrl
+	}
+	return ""
+}
+
+func (x *Movie) GetCinemagiaUrl() string {
+	if x != nil {
+		return x.CinemagiaUrl
+	}
+	return ""
+}
+
+func (x *Movie) GetTallies() *Tallies {
+	if x != nil {
+		return x.Tallies
+	}
+	return nil
+}
+
+// Snapshot is a movie's archived result for a closed month.
+type Snapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Region        string                 `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	Month         string                 `protobuf:"bytes,2,opt,name=month,proto3" json:"month,omitempty"` // YYYY-MM
+	MovieId       int64                  `protobuf:"varint,3,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	ReleaseDate   string                 `protobuf:"bytes,5,opt,name=release_date,json=releaseDate,proto3" json:"release_date,omitempty"` // YYYY-MM-DD
+	PosterPath    string                 `protobuf:"bytes,6,opt,name=poster_path,json=posterPath,proto3" json:"poster_path,omitempty"`
+	Popularity    float64                `protobuf:"fixed64,7,opt,name=popularity,proto3" json:"popularity,omitempty"`
+	GenreIds      []int32                `protobuf:"varint,8,rep,packed,name=genre_ids,json=genreIds,proto3" json:"genre_ids,omitempty"`
+	ClosedAt      *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`
+	Tallies       []*Tally               `protobuf:"bytes,10,rep,name=tallies,proto3" json:"tallies,omitempty"`
+	TotalVotes    int64                  `protobuf:"varint,11,opt,name=total_votes,json=totalVotes,proto3" json:"total_votes,omitempty"`
+	Leading       *Leading               `protobuf:"bytes,12,opt,name=leading,proto3" json:"leading,omitempty"` // unset without votes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Snapshot) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Snapshot) GetMonth() string {
+	if x != nil {
+		return x.Month
+	}
+	return ""
+}
+
+func (x *Snapshot) GetMovieId() int64 {
+	if x != nil {
+		return x.MovieId
+	}
+	return 0
+}
+
+func (x *Snapshot) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Snapshot) GetReleaseDate() string {
+	if x != nil {
+		return x.ReleaseDate
+	}
+	return ""
+}
+
+func (x *Snapshot) GetPosterPath() string {
+	if x != nil {
+		return x.PosterPath
+	}
+	return ""
+}
+
+func (x *Snapshot) GetPopularity() float64 {
+	if x != nil {
+		return x.Popularity
+	}
+	return 0
+}
+
+func (x *Snapshot) GetGenreIds() []int32 {
+	if x != nil {
+		return x.GenreIds
+	}
+	return nil
+}
+
+func (x *Snapshot) GetClosedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClosedAt
+	}
+	return nil
+}
+
+func (x *Snapshot) GetTallies() []*Tally {
+	if x != nil {
+		return x.Tallies
+	}
+	return nil
+}
+
+func (x *Snapshot) GetTotalVotes() int64 {
+	if x != nil {
+		return x.TotalVotes
+	}
+	return 0
+}
+
+func (x *Snapshot) GetLeading() *Leading {
+	if x != nil {
+		return x.Leading
+	}
+	return nil
+}
+
+// Tallies are a movie's votes on a board, every category included.
+type Tallies struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	MovieId    int64                  `protobuf:"varint,1,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	Region     string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	Tallies    []*Tally               `protobuf:"bytes,3,rep,name=tallies,proto3" json:"tallies,omitempty"`
+	TotalVotes int64                  `protobuf:"varint,4,opt,name=total_votes,json=totalVotes,proto3" json:"total_votes,omitempty"`
+	Leading    *Leading               `protobuf:"bytes,5,opt,name=leading,proto3" json:"leading,omitempty"` // unset without votes
+	// The caller's vote; unset without one and in WatchTallies.
+	VotedCategory Category `protobuf:"varint,6,opt,name=voted_category,json=votedCategory,proto3,enum=cinekami.v1.Category" json:"voted_category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tallies) Reset() {
+	*x = Tallies{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tallies) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tallies) ProtoMessage() {}
+
+func (x *Tallies) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tallies.ProtoReflect.Descriptor instead.
+func (*Tallies) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Tallies) GetMovieId() int64 {
+	if x != nil {
+		return x.MovieId
+	}
+	return 0
+}
+
+func (x *Tallies) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Tallies) GetTallies() []*Tally {
+	if x != nil {
+		return x.Tallies
+	}
+	return nil
+}
+
+func (x *Tallies) GetTotalVotes() int64 {
+	if x != nil {
+		return x.TotalVotes
+	}
+	return 0
+}
+
+func (x *Tallies) GetLeading() *Leading {
+	if x != nil {
+		return x.Leading
+	}
+	return nil
+}
+
+func (x *Tallies) GetVotedCategory() Category {
+	if x != nil {
+		return x.VotedCategory
+	}
+	return Category_CATEGORY_UNSPECIFIED
+}
+
+// Tally is one category's votes with its share and 95% Wilson interval.
+type Tally struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      Category               `protobuf:"varint,1,opt,name=category,proto3,enum=cinekami.v1.Category" json:"category,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Share         float64                `protobuf:"fixed64,3,opt,name=share,proto3" json:"share,omitempty"`
+	ShareLow      float64                `protobuf:"fixed64,4,opt,name=share_low,json=shareLow,proto3" json:"share_low,omitempty"`
+	ShareHigh     float64                `protobuf:"fixed64,5,opt,name=share_high,json=shareHigh,proto3" json:"share_high,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tally) Reset() {
+	*x = Tally{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tally) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tally) ProtoMessage() {}
+
+func (x *Tally) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tally.ProtoReflect.Descriptor instead.
+func (*Tally) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Tally) GetCategory() Category {
+	if x != nil {
+		return x.Category
+	}
+	return Category_CATEGORY_UNSPECIFIED
+}
+
+func (x *Tally) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Tally) GetShare() float64 {
+	if x != nil {
+		return x.Share
+	}
+	return 0
+}
+
+func (x *Tally) GetShareLow() float64 {
+	if x != nil {
+		return x.ShareLow
+	}
+	return 0
+}
+
+func (x *Tally) GetShareHigh() float64 {
+	if x != nil {
+		return x.ShareHigh
+	}
+	return 0
+}
+
+// Leading is the category with the most votes; confident when its interval
+// lies entirely above the runner-up's.
+type Leading struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      Category               `protobuf:"varint,1,opt,name=category,proto3,enum=cinekami.v1.Category" json:"category,omitempty"`
+	Confident     bool                   `protobuf:"varint,2,opt,name=confident,proto3" json:"confident,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Leading) Reset() {
+	*x = Leading{}
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Leading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Leading) ProtoMessage() {}
+
+func (x *Leading) ProtoReflect() protoreflect.Message {
+	mi := &file_cinekami_v1_cinekami_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Leading.ProtoReflect.Descriptor instead.
+func (*Leading) Descriptor() ([]byte, []int) {
+	return file_cinekami_v1_cinekami_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Leading) GetCategory() Category {
+	if x != nil {
+		return x.Category
+	}
+	return Category_CATEGORY_UNSPECIFIED
+}
+
+func (x *Leading) GetConfident() bool {
+	if x != nil {
+		return x.Confident
+	}
+	return false
+}
+
+var File_cinekami_v1_cinekami_proto protoreflect.FileDescriptor
+
+const file_cinekami_v1_cinekami_proto_rawDesc = "" +
+	"\n" +
+	"\x1acinekami/v1/cinekami.proto\x12\vcinekami.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xfa\x03\n" +
+	"\vListOptions\x12\x16\n" +
+	"\x06region\x18\x01 \x01(\tR\x06region\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\x12#\n" +
+	"\rinclude_total\x18\x05 \x01(\bR\fincludeTotal\x12\x17\n" +
+	"\asort_by\x18\x06 \x01(\tR\x06sortBy\x12\x19\n" +
+	"\bsort_dir\x18\a \x01(\tR\asortDir\x12*\n" +
+	"\x0emin_popularity\x18\b \x01(\x01H\x00R\rminPopularity\x88\x01\x01\x12*\n" +
+	"\x0emax_popularity\x18\t \x01(\x01H\x01R\rmaxPopularity\x88\x01\x01\x12!\n" +
+	"\frelease_from\x18\n" +
+	" \x01(\tR\vreleaseFrom\x12\x1d\n" +
+	"\n" +
+	"release_to\x18\v \x01(\tR\treleaseTo\x12\x1b\n" +
+	"\tgenre_ids\x18\f \x03(\x05R\bgenreIds\x12 \n" +
+	"\tmin_votes\x18\r \x01(\x03H\x02R\bminVotes\x88\x01\x01\x12\x19\n" +
+	"\x05voted\x18\x0e \x01(\bH\x03R\x05voted\x88\x01\x01B\x11\n" +
+	"\x0f_min_popularityB\x11\n" +
+	"\x0f_max_popularityB\f\n" +
+	"\n" +
+	"_min_votesB\b\n" +
+	"\x06_voted\"M\n" +
+	"\x17ListActiveMoviesRequest\x122\n" +
+	"\aoptions\x18\x01 \x01(\v2\x18.cinekami.v1.ListOptionsR\aoptions\"\xc5\x01\n" +
+	"\x18ListActiveMoviesResponse\x12\x16\n" +
+	"\x06region\x18\x01 \x01(\tR\x06region\x12*\n" +
+	"\x06movies\x18\x02 \x03(\v2\x12.cinekami.v1.MovieR\x06movies\x12\x19\n" +
+	"\x05total\x18\x03 \x01(\x03H\x00R\x05total\x88\x01\x01\x12\x1f\n" +
+	"\vnext_cursor\x18\x04 \x01(\tR\n" +
+	"nextCursor\x12\x1f\n" +
+	"\vprev_cursor\x18\x05 \x01(\tR\n" +
+	"prevCursorB\b\n" +
+	"\x06_total\"`\n" +
+	"\x0fGetMovieRequest\x12\x19\n" +
+	"\bmovie_id\x18\x01 \x01(\x03R\amovieId\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\"F\n" +
+	"\x11GetTalliesRequest\x12\x19\n" +
+	"\bmovie_id\x18\x01 \x01(\x03R\amovieId\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\"t\n" +
+	"\x14ListSnapshotsRequest\x12\x12\n" +
+	"\x04year\x18\x01 \x01(\x05R\x04year\x12\x14\n" +
+	"\x05month\x18\x02 \x01(\x05R\x05month\x122\n" +
+	"\aoptions\x18\x03 \x01(\v2\x18.cinekami.v1.ListOptionsR\aoptions\"\xcb\x01\n" +
+	"\x15ListSnapshotsResponse\x12\x16\n" +
+	"\x06region\x18\x01 \x01(\tR\x06region\x123\n" +
+	"\tsnapshots\x18\x02 \x03(\v2\x15.cinekami.v1.SnapshotR\tsnapshots\x12\x19\n" +
+	"\x05total\x18\x03 \x01(\x03H\x00R\x05total\x88\x01\x01\x12\x1f\n" +
+	"\vnext_cursor\x18\x04 \x01(\tR\n" +
+	"nextCursor\x12\x1f\n" +
+	"\vprev_cursor\x18\x05 \x01(\tR\n" +
+	"prevCursorB\b\n" +
+	"\x06_total\"w\n" +
+	"\x0fCastVoteRequest\x12\x19\n" +
+	"\bmovie_id\x18\x01 \x01(\x03R\amovieId\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x121\n" +
+	"\bcategory\x18\x03 \x01(\x0e2\x15.cinekami.v1.CategoryR\bcategory\"^\n" +
+	"\x10CastVoteResponse\x12\x1a\n" +
+	"\binserted\x18\x01 \x01(\bR\binserted\x12.\n" +
+	"\atallies\x18\x02 \x01(\v2\x14.cinekami.v1.TalliesR\atallies\"J\n" +
+	"\x13WatchTalliesRequest\x12\x1b\n" +
+	"\tmovie_ids\x18\x01 \x03(\x03R\bmovieIds\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\"\xdf\x02\n" +
+	"\x05Movie\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12!\n" +
+	"\frelease_date\x18\x03 \x01(\tR\vreleaseDate\x12\x1a\n" +
+	"\boverview\x18\x04 \x01(\tR\boverview\x12\x1f\n" +
+	"\vposter_path\x18\x05 \x01(\tR\n" +
+	"posterPath\x12#\n" +
+	"\rbackdrop_path\x18\x06 \x01(\tR\fbackdropPath\x12\x1e\n" +
+	"\n" +
+	"popularity\x18\a \x01(\x01R\n" +
+	"popularity\x12\x1b\n" +
+	"\tgenre_ids\x18\b \x03(\x05R\bgenreIds\x12\x19\n" +
+	"\bimdb_url\x18\t \x01(\tR\aimdbUrl\x12#\n" +
+	"\rcinemagia_url\x18\n" +
+	" \x01(\tR\fcinemagiaUrl\x12.\n" +
+	"\atallies\x18\v \x01(\v2\x14.cinekami.v1.TalliesR\atallies\"\xa2\x03\n" +
+	"\bSnapshot\x12\x16\n" +
+	"\x06region\x18\x01 \x01(\tR\x06region\x12\x14\n" +
+	"\x05month\x18\x02 \x01(\tR\x05month\x12\x19\n" +
+	"\bmovie_id\x18\x03 \x01(\x03R\amovieId\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12!\n" +
+	"\frelease_date\x18\x05 \x01(\tR\vreleaseDate\x12\x1f\n" +
+	"\vposter_path\x18\x06 \x01(\tR\n" +
+	"posterPath\x12\x1e\n" +
+	"\n" +
+	"popularity\x18\a \x01(\x01R\n" +
+	"popularity\x12\x1b\n" +
+	"\tgenre_ids\x18\b \x03(\x05R\bgenreIds\x127\n" +
+	"\tclosed_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\bclosedAt\x12,\n" +
+	"\atallies\x18\n" +
+	" \x03(\v2\x12.cinekami.v1.TallyR\atallies\x12\x1f\n" +
+	"\vtotal_votes\x18\v \x01(\x03R\n" +
+	"totalVotes\x12.\n" +
+	"\aleading\x18\f \x01(\v2\x14.cinekami.v1.LeadingR\aleading\"\xf9\x01\n" +
+	"\aTallies\x12\x19\n" +
+	"\bmovie_id\x18\x01 \x01(\x03R\amovieId\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12,\n" +
+	"\atallies\x18\x03 \x03(\v2\x12.cinekami.v1.TallyR\atallies\x12\x1f\n" +
+	"\vtotal_votes\x18\x04 \x01(\x03R\n" +
+	"totalVotes\x12.\n" +
+	"\aleading\x18\x05 \x01(\v2\x14.cinekami.v1.LeadingR\aleading\x12<\n" +
+	"\x0evoted_category\x18\x06 \x01(\x0e2\x15.cinekami.v1.CategoryR\rvotedCategory\"\xa2\x01\n" +
+	"\x05Tally\x121\n" +
+	"\bcategory\x18\x01 \x01(\x0e2\x15.cinekami.v1.CategoryR\bcategory\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12\x14\n" +
+	"\x05share\x18\x03 \x01(\x01R\x05share\x12\x1b\n" +
+	"\tshare_low\x18\x04 \x01(\x01R\bshareLow\x12\x1d\n" +
+	"\n" +
+	"share_high\x18\x05 \x01(\x01R\tshareHigh\"Z\n" +
+	"\aLeading\x121\n" +
+	"\bcategory\x18\x01 \x01(\x0e2\x15.cinekami.v1.CategoryR\bcategory\x12\x1c\n" +
+	"\tconfident\x18\x02 \x01(\bR\tconfident*~\n" +
+	"\bCategory\x12\x18\n" +
+	"\x14CATEGORY_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15CATEGORY_SOLO_FRIENDS\x10\x01\x12\x13\n" +
+	"\x0fCATEGORY_COUPLE\x10\x02\x12\x16\n" +
+	"\x12CATEGORY_STREAMING\x10\x03\x12\x10\n" +
+	"\fCATEGORY_ARR\x10\x042\xd8\x03\n" +
+	"\bCinekami\x12_\n" +
+	"\x10ListActiveMovies\x12$.cinekami.v1.ListActiveMoviesRequest\x1a%.cinekami.v1.ListActiveMoviesResponse\x12<\n" +
+	"\bGetMovie\x12\x1c.cinekami.v1.GetMovieRequest\x1a\x12.cinekami.v1.Movie\x12B\n" +
+	"\n" +
+	"GetTallies\x12\x1e.cinekami.v1.GetTalliesRequest\x1a\x14.cinekami.v1.Tallies\x12V\n" +
+	"\rListSnapshots\x12!.cinekami.v1.ListSnapshotsRequest\x1a\".cinekami.v1.ListSnapshotsResponse\x12G\n" +
+	"\bCastVote\x12\x1c.cinekami.v1.CastVoteRequest\x1a\x1d.cinekami.v1.CastVoteResponse\x12H\n" +
+	"\fWatchTallies\x12 .cinekami.v1.WatchTalliesRequest\x1a\x14.cinekami.v1.Tallies0\x01B3Z1cinekami-server/internal/pb/cinekamiv1;cinekamiv1b\x06proto3"
+
+var (
+	file_cinekami_v1_cinekami_proto_rawDescOnce sync.Once
+	file_cinekami_v1_cinekami_proto_rawDescData []byte
+)
+
+func file_cinekami_v1_cinekami_proto_rawDescGZIP() []byte {
+	file_cinekami_v1_cinekami_proto_rawDescOnce.Do(func() {
+		file_cinekami_v1_cinekami_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cinekami_v1_cinekami_proto_rawDesc), len(file_cinekami_v1_cinekami_proto_rawDesc)))
+	})
+	return file_cinekami_v1_cinekami_proto_rawDescData
+}
+
+var file_cinekami_v1_cinekami_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_cinekami_v1_cinekami_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_cinekami_v1_cinekami_proto_goTypes = []any{
+	(Category)(0),                    // 0: cinekami.v1.Category
+	(*ListOptions)(nil),              // 1: cinekami.v1.ListOptions
+	(*ListActiveMoviesRequest)(nil),  // 2: cinekami.v1.ListActiveMoviesRequest
+	(*ListActiveMoviesResponse)(nil), // 3: cinekami.v1.ListActiveMoviesResponse
+	(*GetMovieRequest)(nil),          // 4: cinekami.v1.GetMovieRequest
+	(*GetTalliesRequest)(nil),        // 5: cinekami.v1.GetTalliesRequest
+	(*ListSnapshotsRequest)(nil),     // 6: cinekami.v1.ListSnapshotsRequest
+	(*ListSnapshotsResponse)(nil),    // 7: cinekami.v1.ListSnapshotsResponse
+	(*CastVoteRequest)(nil),          // 8: cinekami.v1.CastVoteRequest
+	(*CastVoteResponse)(nil),         // 9: cinekami.v1.CastVoteResponse
+	(*WatchTalliesRequest)(nil),      // 10: cinekami.v1.WatchTalliesRequest
+	(*Movie)(nil),                    // 11: cinekami.v1.Movie
+	(*Snapshot)(nil),                 // 12: cinekami.v1.Snapshot
+	(*Tallies)(nil),                  // 13: cinekami.v1.Tallies
+	(*Tally)(nil),                    // 14: cinekami.v1.Tally
+	(*Leading)(nil),                  // 15: cinekami.v1.Leading
+	(*timestamppb.Timestamp)(nil),    // 16: google.protobuf.Timestamp
+}
+var file_cinekami_v1_cinekami_proto_depIdxs = []int32{
+	1,  // 0: cinekami.v1.ListActiveMoviesRequest.options:type_name -> cinekami.v1.ListOptions
+	11, // 1: cinekami.v1.ListActiveMoviesResponse.movies:type_name -> cinekami.v1.Movie
+	1,  // 2: cinekami.v1.ListSnapshotsRequest.options:type_name -> cinekami.v1.ListOptions
+	12, // 3: cinekami.v1.ListSnapshotsResponse.snapshots:type_name -> cinekami.v1.Snapshot
+	0,  // 4: cinekami.v1.CastVoteRequest.category:type_name -> cinekami.v1.Category
+	13, // 5: cinekami.v1.CastVoteResponse.tallies:type_name -> cinekami.v1.Tallies
+	13, // 6: cinekami.v1.Movie.tallies:type_name -> cinekami.v1.Tallies
+	16, // 7: cinekami.v1.Snapshot.closed_at:type_name -> google.protobuf.Timestamp
+	14, // 8: cinekami.v1.Snapshot.tallies:type_name -> cinekami.v1.Tally
+	15, // 9: cinekami.v1.Snapshot.leading:type_name -> cinekami.v1.Leading
+	14, // 10: cinekami.v1.Tallies.tallies:type_name -> cinekami.v1.Tally
+	15, // 11: cinekami.v1.Tallies.leading:type_name -> cinekami.v1.Leading
+	0,  // 12: cinekami.v1.Tallies.voted_category:type_name -> cinekami.v1.Category
+	0,  // 13: cinekami.v1.Tally.category:type_name -> cinekami.v1.Category
+	0,  // 14: cinekami.v1.Leading.category:type_name -> cinekami.v1.Category
+	2,  // 15: cinekami.v1.Cinekami.ListActiveMovies:input_type -> cinekami.v1.ListActiveMoviesRequest
+	4,  // 16: cinekami.v1.Cinekami.GetMovie:input_type -> cinekami.v1.GetMovieRequest
+	5,  // 17: cinekami.v1.Cinekami.GetTallies:input_type -> cinekami.v1.GetTalliesRequest
+	6,  // 18: cinekami.v1.Cinekami.ListSnapshots:input_type -> cinekami.v1.ListSnapshotsRequest
+	8,  // 19: cinekami.v1.Cinekami.CastVote:input_type -> cinekami.v1.CastVoteRequest
+	10, // 20: cinekami.v1.Cinekami.WatchTallies:input_type -> cinekami.v1.WatchTalliesRequest
+	3,  // 21: cinekami.v1.Cinekami.ListActiveMovies:output_type -> cinekami.v1.ListActiveMoviesResponse
+	11, // 22: cinekami.v1.Cinekami.GetMovie:output_type -> cinekami.v1.Movie
+	13, // 23: cinekami.v1.Cinekami.GetTallies:output_type -> cinekami.v1.Tallies
+	7,  // 24: cinekami.v1.Cinekami.ListSnapshots:output_type -> cinekami.v1.ListSnapshotsResponse
+	9,  // 25: cinekami.v1.Cinekami.CastVote:output_type -> cinekami.v1.CastVoteResponse
+	13, // 26: cinekami.v1.Cinekami.WatchTallies:output_type -> cinekami.v1.Tallies
+	21, // [21:27] is the sub-list for method output_type
+	15, // [15:21] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_cinekami_v1_cinekami_proto_init() }
+func file_cinekami_v1_cinekami_proto_init() {
+	if File_cinekami_v1_cinekami_proto != nil {
+		return
+	}
+	file_cinekami_v1_cinekami_proto_msgTypes[0].OneofWrappers = []any{}
+	file_cinekami_v1_cinekami_proto_msgTypes[2].OneofWrappers = []any{}
+	file_cinekami_v1_cinekami_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cinekami_v1_cinekami_proto_rawDesc), len(file_cinekami_v1_cinekami_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cinekami_v1_cinekami_proto_goTypes,
+		DependencyIndexes: file_cinekami_v1_cinekami_proto_depIdxs,
+		EnumInfos:         file_cinekami_v1_cinekami_proto_enumTypes,
+		MessageInfos:      file_cinekami_v1_cinekami_proto_msgTypes,
+	}.Build()
+	File_cinekami_v1_cinekami_proto = out.File
+	file_cinekami_v1_cinekami_proto_goTypes = nil
+	file_cinekami_v1_cinekami_proto_depIdxs = nil
+}
@@ -0,0 +1,390 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/store"
+)
+
+// AdminRepo backs the /admin API: moderation of movies and votes plus the audit log.
+type AdminRepo struct {
+	db *pgxpool.Pool
+	q  *store.Queries
+}
+
+var (
+	ErrInvalidMerge     = errors.New("invalid merge")
+	ErrEmptyVoteFilter  = errors.New("vote filter must not be empty")
+	ErrAdminNotFound    = errors.New("admin user not found")
+	ErrInvalidVoterID   = errors.New("invalid voter id")
+	errMissingAuditInfo = errors.New("audit entry needs actor and action")
+)
+
+// MoviePatch lists the movie fields an admin may change; nil fields are left as is.
+type MoviePatch struct {
+	Title        *string    `json:"title"`
+	ReleaseDate  *time.Time `json:"release_date"`
+	Overview     *string    `json:"overview"`
+	PosterPath   *string    `json:"poster_path"`
+	BackdropPath *string    `json:"backdrop_path"`
+	Popularity   *float64   `json:"popularity"`
+	ImdbURL      *string    `json:"imdb_url"`
+	CinemagiaURL *string    `json:"cinemagia_url"`
+	Hidden       *bool      `json:"hidden"`
+	// Releases sets the release date per region code; regions not listed keep theirs.
+	Releases map[string]time.Time `json:"releases"`
+	// Locked defaults to true on every edit so the next TMDb sync does not undo it.
+	Locked *bool `json:"admin_locked"`
+}
+
+// VoteFilter selects votes for listing or voiding. Since is inclusive, Until exclusive.
+type VoteFilter struct {
+	VoterID     *string    `json:"voter_id,omitempty"`
+	Fingerprint *string    `json:"fingerprint,omitempty"`
+	MovieID     *int64     `json:"movie_id,omitempty"`
+	Region      *string    `json:"region,omitempty"`
+	Since       *time.Time `json:"since,omitempty"`
+	Until       *time.Time `json:"until,omitempty"`
+}
+
+// Empty reports whether the filter would match every vote.
+func (f VoteFilter) Empty() bool {
+	return f.VoterID == nil && f.Fingerprint == nil && f.MovieID == nil && f.Region == nil && f.Since == nil && f.Until == nil
+}
+
+// MergeResult summarises a movie merge.
+type MergeResult struct {
+	SourceID       int64 `json:"source_id"`
+	TargetID       int64 `json:"target_id"`
+	VotesMoved     int64 `json:"votes_moved"`
+	VotesDropped   int64 `json:"votes_dropped"` // voters who had already voted on the target
+	SnapshotsMoved int64 `json:"snapshots_moved"`
+}
+
+// VoidResult summarises a vote voiding run.
+type VoidResult struct {
+	Deleted  int     `json:"deleted"`
+	MovieIDs []int64 `json:"movie_ids"`
+}
+
+func (r *AdminRepo) GetMovie(ctx context.Context, id int64) (model.AdminMovie, error) {
+	m, err := r.q.GetMovieAdmin(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.AdminMovie{}, ErrMovieNotFound
+		}
+		return model.AdminMovie{}, err
+	}
+	return adminMovieWithReleases(ctx, r.q, m)
+}
+
+// UpdateMovie applies p and marks the movie as admin-locked unless p.Locked is false.
+// Regional release dates in p.Releases must name known regions.
+func (r *AdminRepo) UpdateMovie(ctx context.Context, id int64, p MoviePatch) (model.AdminMovie, error) {
+	var release pgtype.Date
+	if p.ReleaseDate != nil {
+		release = pgtype.Date{Time: *p.ReleaseDate, Valid: true}
+	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return model.AdminMovie{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	q := r.q.WithTx(tx)
+
+	if len(p.Releases) > 0 {
+		known, err := q.ListRegions(ctx)
+		if err != nil {
+			return model.AdminMovie{}, err
+		}
+		for code := range p.Releases {
+			if !slices.ContainsFunc(known, func(rg store.Region) bool { return rg.Code == code }) {
+				return model.AdminMovie{}, ErrInvalidRegion
+			}
+		}
+	}
+	m, err := q.UpdateMovieAdmin(ctx, store.UpdateMovieAdminParams{
+		Title:        optText(p.Title),
+		ReleaseDate:  release,
+		Overview:     optText(p.Overview),
+		PosterPath:   optText(p.PosterPath),
+		BackdropPath: optText(p.BackdropPath),
+		Popularity:   optFloat8(p.Popularity),
+		ImdbUrl:      optText(p.ImdbURL),
+		CinemagiaUrl: optText(p.CinemagiaURL),
+		Hidden:       optBool(p.Hidden),
+		AdminLocked:  optBool(p.Locked),
+		ID:           id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.AdminMovie{}, ErrMovieNotFound
+		}
+		return model.AdminMovie{}, err
+	}
+	for code, d := range p.Releases {
+		if err := q.SetMovieRelease(ctx, store.SetMovieReleaseParams{
+			MovieID:     id,
+			Region:      code,
+			ReleaseDate: pgtype.Date{Time: d, Valid: true},
+		}); err != nil {
+			return model.AdminMovie{}, err
+		}
+	}
+	out, err := adminMovieWithReleases(ctx, q, m)
+	if err != nil {
+		return model.AdminMovie{}, err
+	}
+	return out, tx.Commit(ctx)
+}
+
+// MergeMovies moves votes and snapshots from source onto target in one transaction.
+// A voter who voted on both keeps the target vote. The source is hidden and keeps
+// merged_into so the TMDb sync cannot resurrect it.
+func (r *AdminRepo) MergeMovies(ctx context.Context, sourceID, targetID int64) (MergeResult, error) {
+	res := MergeResult{SourceID: sourceID, TargetID: targetID}
+	if sourceID == targetID {
+		return res, ErrInvalidMerge
+	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	q := r.q.WithTx(tx)
+
+	if _, err := q.GetMovieAdmin(ctx, sourceID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return res, ErrMovieNotFound
+		}
+		return res, err
+	}
+	target, err := q.GetMovieAdmin(ctx, targetID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return res, ErrMovieNotFound
+		}
+		return res, err
+	}
+	if target.Hidden {
+		return res, ErrInvalidMerge
+	}
+
+	ids := store.MoveVotesToMovieParams{TargetID: targetID, SourceID: sourceID}
+	if res.VotesMoved, err = q.MoveVotesToMovie(ctx, ids); err != nil {
+		return res, err
+	}
+	if res.VotesDropped, err = q.DeleteVotesByMovie(ctx, sourceID); err != nil {
+		return res, err
+	}
+	if res.SnapshotsMoved, err = q.MoveSnapshotsToMovie(ctx, store.MoveSnapshotsToMovieParams(ids)); err != nil {
+		return res, err
+	}
+	if err := rebuildTallies(ctx, q, []int64{sourceID, targetID}); err != nil {
+		return res, err
+	}
+	if err := q.MarkMovieMerged(ctx, store.MarkMovieMergedParams{
+		TargetID: pgtype.Int8{Int64: targetID, Valid: true},
+		SourceID: sourceID,
+	}); err != nil {
+		return res, err
+	}
+	return res, tx.Commit(ctx)
+}
+
+func (r *AdminRepo) ListVotes(ctx context.Context, f VoteFilter, limit int32) ([]model.AdminVote, error) {
+	voterID, err := voterUUID(f.VoterID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.q.ListVotesAdmin(ctx, store.ListVotesAdminParams{
+		VoterID:     voterID,
+		Fingerprint: optText(f.Fingerprint),
+		MovieID:     optInt8(f.MovieID),
+		Region:      optText(f.Region),
+		Since:       optTimestamptz(f.Since),
+		Until:       optTimestamptz(f.Until),
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.AdminVote, 0, len(rows))
+	for _, v := range rows {
+		out = append(out, model.AdminVote{
+			ID:          v.ID.String(),
+			MovieID:     v.MovieID,
+			Region:      v.Region,
+			VoterID:     v.VoterID.String(),
+			Fingerprint: v.Fingerprint.String,
+			Category:    v.Category,
+			CreatedAt:   v.CreatedAt.Time,
+		})
+	}
+	return out, nil
+}
+
+// VoidVotes deletes every vote matching f and recomputes tallies of the affected movies.
+func (r *AdminRepo) VoidVotes(ctx context.Context, f VoteFilter) (VoidResult, error) {
+	res := VoidResult{MovieIDs: []int64{}}
+	if f.Empty() {
+		return res, ErrEmptyVoteFilter
+	}
+	voterID, err := voterUUID(f.VoterID)
+	if err != nil {
+		return res, err
+	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	q := r.q.WithTx(tx)
+
+	movieIDs, err := q.VoidVotes(ctx, store.VoidVotesParams{
+		VoterID:     voterID,
+		Fingerprint: optText(f.Fingerprint),
+		MovieID:     optInt8(f.MovieID),
+		Region:      optText(f.Region),
+		Since:       optTimestamptz(f.Since),
+		Until:       optTimestamptz(f.Until),
+	})
+	if err != nil {
+		return res, err
+	}
+	res.Deleted = len(movieIDs)
+	slices.Sort(movieIDs)
+	res.MovieIDs = slices.Compact(movieIDs)
+	if len(res.MovieIDs) > 0 {
+		if err := rebuildTallies(ctx, q, res.MovieIDs); err != nil {
+			return res, err
+		}
+	}
+	return res, tx.Commit(ctx)
+}
+
+// Audit records an admin action; details is marshalled to JSON.
+func (r *AdminRepo) Audit(ctx context.Context, actor, action, target string, details any) error {
+	if actor == "" || action == "" {
+		return errMissingAuditInfo
+	}
+	b := []byte("{}")
+	if details != nil {
+		var err error
+		if b, err = json.Marshal(details); err != nil {
+			return err
+		}
+	}
+	return r.q.InsertAuditLog(ctx, store.InsertAuditLogParams{Actor: actor, Action: action, Target: target, Details: b})
+}
+
+// ListAudit returns audit entries newest first; beforeID pages backwards.
+func (r *AdminRepo) ListAudit(ctx context.Context, beforeID *int64, limit int32) ([]model.AuditEntry, error) {
+	rows, err := r.q.ListAuditLog(ctx, store.ListAuditLogParams{BeforeID: optInt8(beforeID), Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.AuditEntry, 0, len(rows))
+	for _, e := range rows {
+		out = append(out, model.AuditEntry{
+			ID:        e.ID,
+			Actor:     e.Actor,
+			Action:    e.Action,
+			Target:    e.Target,
+			Details:   e.Details,
+			CreatedAt: e.CreatedAt.Time,
+		})
+	}
+	return out, nil
+}
+
+// AdminCredentials returns the password hash and salt of an admin user.
+func (r *AdminRepo) AdminCredentials(ctx context.Context, email string) (hash, salt string, err error) {
+	row, err := r.q.GetAdminCredentials(ctx, textVal(email))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", ErrAdminNotFound
+		}
+		return "", "", err
+	}
+	return row.PasswordHash.String, row.PasswordSalt.String, nil
+}
+
+// UpsertUser creates or updates a user's credentials and role.
+func (r *AdminRepo) UpsertUser(ctx context.Context, email, hash, salt, role string) (string, error) {
+	id, err := r.q.UpsertUserCredentials(ctx, store.UpsertUserCredentialsParams{
+		Email:        textVal(email),
+		PasswordHash: textVal(hash),
+		PasswordSalt: textVal(salt),
+		Role:         role,
+	})
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func rebuildTallies(ctx context.Context, q *store.Queries, movieIDs []int64) error {
+	if err := q.DeleteTalliesForMovies(ctx, movieIDs); err != nil {
+		return err
+	}
+	return q.RebuildTalliesForMovies(ctx, movieIDs)
+}
+
+func voterUUID(s *string) (pgtype.UUID, error) {
+	var id pgtype.UUID
+	if s == nil {
+		return id, nil
+	}
+	if err := id.Scan(*s); err != nil {
+		return id, ErrInvalidVoterID
+	}
+	return id, nil
+}
+
+func adminMovie(m store.Movie) model.AdminMovie {
+	out := model.AdminMovie{
+		Movie: model.Movie{
+			ID:           m.ID,
+			Title:        m.Title,
+			ReleaseDate:  m.ReleaseDate.Time,
+			Overview:     textPtr(m.Overview),
+			PosterPath:   textPtr(m.PosterPath),
+			BackdropPath: textPtr(m.BackdropPath),
+			Popularity:   m.Popularity.Float64,
+			ImdbURL:      textPtr(m.ImdbUrl),
+			CinemagiaURL: textPtr(m.CinemagiaUrl),
+			GenreIDs:     m.GenreIds,
+		},
+		Hidden:      m.Hidden,
+		AdminLocked: m.AdminLocked,
+		UpdatedAt:   m.UpdatedAt.Time,
+	}
+	if m.MergedInto.Valid {
+		id := m.MergedInto.Int64
+		out.MergedInto = &id
+	}
+	return out
+}
+
+func adminMovieWithReleases(ctx context.Context, q *store.Queries, m store.Movie) (model.AdminMovie, error) {
+	out := adminMovie(m)
+	rows, err := q.ListMovieReleases(ctx, m.ID)
+	if err != nil {
+		return out, err
+	}
+	out.Releases = make(map[string]time.Time, len(rows))
+	for _, rel := range rows {
+		out.Releases[rel.Region] = rel.ReleaseDate.Time
+	}
+	return out, nil
+}
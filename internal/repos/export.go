@@ -0,0 +1,190 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/store"
+)
+
+// ExportsRepo streams snapshots, tallies and anonymized votes for bulk exports.
+// Rows come from a server-side cursor in a read-only, repeatable-read
+// transaction, so an export is consistent and never held in memory whole.
+type ExportsRepo struct {
+	db *pgxpool.Pool
+	q  *store.Queries
+}
+
+var ErrInvalidExport = errors.New("invalid export range")
+
+// exportBatch is the number of rows fetched from the cursor at a time.
+const exportBatch = 1000
+
+// ExportFilter selects the months From to To (YYYY-MM, inclusive) of one region,
+// or of all regions when Region is empty.
+type ExportFilter struct {
+	From   string
+	To     string
+	Region string
+}
+
+// Validate checks that From and To are months and To is not before From.
+func (f ExportFilter) Validate() error {
+	_, _, err := f.bounds()
+	return err
+}
+
+// bounds returns the first instant of From and of the month after To.
+func (f ExportFilter) bounds() (time.Time, time.Time, error) {
+	from, err := time.Parse("2006-01", f.From)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from %q", ErrInvalidExport, f.From)
+	}
+	to, err := time.Parse("2006-01", f.To)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to %q", ErrInvalidExport, f.To)
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to is before from", ErrInvalidExport)
+	}
+	return from, to.AddDate(0, 1, 0), nil
+}
+
+// ExportSnapshots streams the archived snapshots of visible movies for the
+// filter's months, ordered by region, month and movie.
+func (r *ExportsRepo) ExportSnapshots(ctx context.Context, f ExportFilter, fn func(model.SnapshotExportRow) error) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	const sql = `SELECT st.region, st.month, st.movie_id, m.title, st.closed_at,
+       COALESCE((st.tallies ->> 'solo_friends')::bigint, 0),
+       COALESCE((st.tallies ->> 'couple')::bigint, 0),
+       COALESCE((st.tallies ->> 'streaming')::bigint, 0),
+       COALESCE((st.tallies ->> 'arr')::bigint, 0)
+FROM snapshots st
+JOIN movies m ON m.id = st.movie_id
+WHERE st.month BETWEEN $1 AND $2
+  AND ($3 = '' OR st.region = $3)
+  AND NOT m.hidden
+ORDER BY st.region, st.month, st.movie_id`
+	return r.stream(ctx, sql, []any{f.From, f.To, f.Region}, func(rows pgx.Rows) error {
+		var (
+			row    model.SnapshotExportRow
+			closed pgtype.Timestamptz
+		)
+		if err := rows.Scan(&row.Region, &row.Month, &row.MovieID, &row.Title, &closed,
+			&row.SoloFriends, &row.Couple, &row.Streaming, &row.Arr); err != nil {
+			return err
+		}
+		row.ClosedAt = closed.Time
+		row.TotalVotes = row.SoloFriends + row.Couple + row.Streaming + row.Arr
+		return fn(row)
+	})
+}
+
+// ExportTallies streams the current tallies of visible movies released in the
+// filter's months, ordered by region, release date and movie. Movies without
+// votes are included with zero counts.
+func (r *ExportsRepo) ExportTallies(ctx context.Context, f ExportFilter, fn func(model.TallyExportRow) error) error {
+	from, to, err := f.bounds()
+	if err != nil {
+		return err
+	}
+	const sql = `SELECT mr.region, mr.movie_id, m.title, mr.release_date,
+       COALESCE(SUM(t.count) FILTER (WHERE t.category = 'solo_friends'), 0)::bigint,
+       COALESCE(SUM(t.count) FILTER (WHERE t.category = 'couple'), 0)::bigint,
+       COALESCE(SUM(t.count) FILTER (WHERE t.category = 'streaming'), 0)::bigint,
+       COALESCE(SUM(t.count) FILTER (WHERE t.category = 'arr'), 0)::bigint
+FROM movie_releases mr
+JOIN movies m ON m.id = mr.movie_id
+LEFT JOIN vote_tallies t ON t.movie_id = mr.movie_id AND t.region = mr.region
+WHERE mr.release_date >= $1 AND mr.release_date < $2
+  AND ($3 = '' OR mr.region = $3)
+  AND NOT m.hidden
+GROUP BY mr.region, mr.movie_id, m.title, mr.release_date
+ORDER BY mr.region, mr.release_date, mr.movie_id`
+	args := []any{pgtype.Date{Time: from, Valid: true}, pgtype.Date{Time: to, Valid: true}, f.Region}
+	return r.stream(ctx, sql, args, func(rows pgx.Rows) error {
+		var (
+			row     model.TallyExportRow
+			release pgtype.Date
+		)
+		if err := rows.Scan(&row.Region, &row.MovieID, &row.Title, &release,
+			&row.SoloFriends, &row.Couple, &row.Streaming, &row.Arr); err != nil {
+			return err
+		}
+		row.ReleaseDate = release.Time.Format(time.DateOnly)
+		row.TotalVotes = row.SoloFriends + row.Couple + row.Streaming + row.Arr
+		return fn(row)
+	})
+}
+
+// ExportVotes streams the votes on visible movies cast in the filter's months
+// without any voter identity; vote times are truncated to the hour and rows are ordered by time,
+// not by insertion, so they cannot be linked back to a voter.
+func (r *ExportsRepo) ExportVotes(ctx context.Context, f ExportFilter, fn func(model.VoteExportRow) error) error {
+	from, to, err := f.bounds()
+	if err != nil {
+		return err
+	}
+	const sql = `SELECT v.movie_id, v.region, v.category::text, date_trunc('hour', v.created_at)
+FROM votes v
+JOIN movies m ON m.id = v.movie_id
+WHERE v.created_at >= $1 AND v.created_at < $2
+  AND ($3 = '' OR v.region = $3)
+  AND NOT m.hidden
+ORDER BY date_trunc('hour', v.created_at), v.region, v.movie_id, v.category`
+	args := []any{pgtype.Timestamptz{Time: from, Valid: true}, pgtype.Timestamptz{Time: to, Valid: true}, f.Region}
+	return r.stream(ctx, sql, args, func(rows pgx.Rows) error {
+		var (
+			row   model.VoteExportRow
+			voted pgtype.Timestamptz
+		)
+		if err := rows.Scan(&row.MovieID, &row.Region, &row.Category, &voted); err != nil {
+			return err
+		}
+		row.VotedAt = voted.Time.UTC()
+		return fn(row)
+	})
+}
+
+// stream declares a cursor for sql and hands its rows to scan, exportBatch at a time.
+func (r *ExportsRepo) stream(ctx context.Context, sql string, args []any, scan func(pgx.Rows) error) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, "DECLARE export_cursor NO SCROLL CURSOR FOR "+sql, args...); err != nil {
+		return err
+	}
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM export_cursor", exportBatch)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return err
+		}
+		n := 0
+		for rows.Next() {
+			n++
+			if err := scan(rows); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if n < exportBatch {
+			return tx.Commit(ctx)
+		}
+	}
+}
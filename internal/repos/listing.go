@@ -0,0 +1,147 @@
+package repos
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"cinekami-server/internal/listquery"
+
+	pkgstats "cinekami-server/pkg/stats"
+)
+
+// ErrCursorMismatch is returned when a cursor carries a different number of sort
+// keys than the query it is used with.
+var ErrCursorMismatch = errors.New("cursor does not match the sort keys")
+
+// listColumn is how a sort field is computed in the listing queries and the SQL
+// type its cursor value is cast back to. Every expression is non-null.
+type listColumn struct {
+	expr string
+	typ  string
+}
+
+// listColumns covers every listquery.Field over the columns both listing CTEs
+// expose: popularity, release_date, title, the four category counts and total_votes.
+var listColumns = map[listquery.Field]listColumn{
+	listquery.FieldPopularity:     {"popularity", "float8"},
+	listquery.FieldReleaseDate:    {"release_date", "date"},
+	listquery.FieldTitle:          {"title", "text"},
+	listquery.FieldTotalVotes:     {"total_votes", "bigint"},
+	listquery.FieldSoloFriends:    {"solo_friends", "bigint"},
+	listquery.FieldCouple:         {"couple", "bigint"},
+	listquery.FieldStreaming:      {"streaming", "bigint"},
+	listquery.FieldArr:            {"arr", "bigint"},
+	listquery.FieldSoloFriendsPct: {sharePct("solo_friends"), "numeric"},
+	listquery.FieldCouplePct:      {sharePct("couple"), "numeric"},
+	listquery.FieldStreamingPct:   {sharePct("streaming"), "numeric"},
+	listquery.FieldArrPct:         {sharePct("arr"), "numeric"},
+
+	listquery.FieldSoloFriendsLower: {pkgstats.WilsonLowerSQL("solo_friends", "total_votes"), "float8"},
+	listquery.FieldCoupleLower:      {pkgstats.WilsonLowerSQL("couple", "total_votes"), "float8"},
+	listquery.FieldStreamingLower:   {pkgstats.WilsonLowerSQL("streaming", "total_votes"), "float8"},
+	listquery.FieldArrLower:         {pkgstats.WilsonLowerSQL("arr", "total_votes"), "float8"},
+}
+
+func sharePct(col string) string {
+	return "(CASE WHEN total_votes = 0 THEN 0 ELSE round(100.0 * " + col + " / total_votes, 4) END)"
+}
+
+// listSQL assembles the dynamic tail of a listing query: filters, keyset
+// condition, ORDER BY and the sort key columns. Field names only ever come from
+// listColumns; values always go through placeholders.
+type listSQL struct {
+	args  []any
+	idCol string
+}
+
+func (b *listSQL) arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// where returns the filter conditions of q joined with AND.
+func (b *listSQL) where(q listquery.Query) string {
+	conds := []string{"TRUE"}
+	if q.MinPopularity != nil {
+		conds = append(conds, "popularity >= "+b.arg(*q.MinPopularity))
+	}
+	if q.MaxPopularity != nil {
+		conds = append(conds, "popularity <= "+b.arg(*q.MaxPopularity))
+	}
+	if q.ReleasedFrom != nil {
+		conds = append(conds, "release_date >= "+b.arg(pgtype.Date{Time: *q.ReleasedFrom, Valid: true}))
+	}
+	if q.ReleasedTo != nil {
+		conds = append(conds, "release_date <= "+b.arg(pgtype.Date{Time: *q.ReleasedTo, Valid: true}))
+	}
+	if len(q.Genres) > 0 {
+		conds = append(conds, "genre_ids && "+b.arg(q.Genres)+"::int[]")
+	}
+	if q.MinVotes != nil {
+		conds = append(conds, "total_votes >= "+b.arg(*q.MinVotes))
+	}
+	if q.Voted != nil {
+		if *q.Voted {
+			conds = append(conds, "voted_category <> ''")
+		} else {
+			conds = append(conds, "voted_category = ''")
+		}
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// sorts returns q's sort keys, reversed for backward pages.
+func sorts(q listquery.Query, backward bool) []listquery.Sort {
+	s := q.Sort
+	if len(s) == 0 {
+		s = listquery.DefaultSort
+	}
+	out := make([]listquery.Sort, len(s))
+	for i, k := range s {
+		if backward {
+			k.Dir = k.Dir.Reverse()
+		}
+		out[i] = k
+	}
+	return out
+}
+
+// keyset returns the condition selecting rows strictly after the cursor row
+// (keys, id) in the given order. The id tie-breaker follows the last key.
+func (b *listSQL) keyset(ss []listquery.Sort, keys []string, id int64) string {
+	cond := b.idCol + cmp(ss[len(ss)-1].Dir) + b.arg(id) + "::bigint"
+	for i := len(ss) - 1; i >= 0; i-- {
+		col := listColumns[ss[i].Field]
+		val := b.arg(keys[i]) + "::" + col.typ
+		cond = "(" + col.expr + cmp(ss[i].Dir) + val + " OR (" + col.expr + " = " + val + " AND " + cond + "))"
+	}
+	return cond
+}
+
+func cmp(d listquery.Dir) string {
+	if d == listquery.Asc {
+		return " > "
+	}
+	return " < "
+}
+
+func (b *listSQL) orderBy(ss []listquery.Sort) string {
+	parts := make([]string, 0, len(ss)+1)
+	for _, s := range ss {
+		parts = append(parts, listColumns[s.Field].expr+" "+strings.ToUpper(string(s.Dir)))
+	}
+	parts = append(parts, b.idCol+" "+strings.ToUpper(string(ss[len(ss)-1].Dir)))
+	return strings.Join(parts, ", ")
+}
+
+// keyColumns selects each sort key as text, which is what cursors carry.
+func keyColumns(ss []listquery.Sort) string {
+	var sb strings.Builder
+	for _, s := range ss {
+		sb.WriteString(", (" + listColumns[s.Field].expr + ")::text")
+	}
+	return sb.String()
+}
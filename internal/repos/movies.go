@@ -2,15 +2,19 @@ package repos
 
 import (
 	"context"
-	"math"
+	"errors"
 	"net/url"
+	"sort"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/sync/errgroup"
 
+	"cinekami-server/internal/listquery"
 	"cinekami-server/internal/model"
 	"cinekami-server/internal/store"
 
@@ -22,160 +26,193 @@ type MoviesRepo struct {
 	q  *store.Queries
 }
 
-type ActiveMoviesSortBy string
-
-type ActiveMoviesSortDir string
-
-const (
-	SortByPopularity  ActiveMoviesSortBy = "popularity"
-	SortByReleaseDate ActiveMoviesSortBy = "release_date"
-	SortBySoloFriends ActiveMoviesSortBy = model.CategorySoloFriends
-	SortByCouple      ActiveMoviesSortBy = model.CategoryCouple
-	SortByStreaming   ActiveMoviesSortBy = model.CategoryStreaming
-	SortByArr         ActiveMoviesSortBy = model.CategoryArr
-
-	SortDirDesc ActiveMoviesSortDir = "desc"
-	SortDirAsc  ActiveMoviesSortDir = "asc"
-)
-
 type ActiveMoviesFilter struct {
-	SortBy      ActiveMoviesSortBy
-	SortDir     ActiveMoviesSortDir
-	MinPop      *float64
-	MaxPop      *float64
-	CursorKey   *float64
+	Region      string
+	Language    string // translation to show; empty for the language movies are stored in
+	Query       listquery.Query
+	CursorKeys  []string // sort key values of the cursor row, one per Query.Sort key
 	CursorID    *int64
+	Backward    bool // page towards the start of the listing from the cursor
 	Limit       int32
 	Fingerprint *string
 }
 
-// ListActiveMoviesPageFiltered returns active movies for the current month with filters and sorting.
-func (r *MoviesRepo) ListActiveMoviesPageFiltered(ctx context.Context, now time.Time, f ActiveMoviesFilter) ([]model.Movie, float64, error) {
+// activeMoviesCTE selects the visible movies released in region $3 in the month
+// of $1 whose voting window there is still open, with the region's tallies and
+// the vote of fingerprint $2. release_date is the regional release date; title
+// and overview are in language $4 (see translatedColumns).
+const activeMoviesCTE = `WITH t AS (
+  SELECT movie_id,
+         SUM(CASE WHEN category = 'solo_friends' THEN count ELSE 0 END)::bigint AS solo_friends,
+         SUM(CASE WHEN category = 'couple' THEN count ELSE 0 END)::bigint AS couple,
+         SUM(CASE WHEN category = 'streaming' THEN count ELSE 0 END)::bigint AS streaming,
+         SUM(CASE WHEN category = 'arr' THEN count ELSE 0 END)::bigint AS arr
+  FROM vote_tallies
+  WHERE region = $3
+  GROUP BY movie_id
+), joined AS (
+  SELECT m.id, ` + translatedColumns + `, r.release_date, m.poster_path, m.backdrop_path, COALESCE(m.popularity, 0) AS popularity,
+         m.imdb_url, m.cinemagia_url, m.genre_ids,
+         COALESCE(t.solo_friends, 0) AS solo_friends, COALESCE(t.couple, 0) AS couple,
+         COALESCE(t.streaming, 0) AS streaming, COALESCE(t.arr, 0) AS arr
+  FROM movies m
+  JOIN movie_releases r ON r.movie_id = m.id AND r.region = $3
+  LEFT JOIN movie_translations tr ON tr.movie_id = m.id AND tr.language = $4
+  LEFT JOIN t ON t.movie_id = m.id
+  WHERE r.release_date >= date_trunc('month', $1::timestamptz)::date
+    AND r.release_date <= (date_trunc('month', $1::timestamptz)::date + interval '1 month - 1 second')
+    AND $1::timestamptz <= (r.release_date + interval '14 days')
+    AND NOT m.hidden
+), listed AS (
+  SELECT j.*, j.solo_friends + j.couple + j.streaming + j.arr AS total_votes, COALESCE(v.category::text, '') AS voted_category
+  FROM joined j
+  LEFT JOIN voters vr ON vr.fingerprint = $2
+  LEFT JOIN votes v ON v.movie_id = j.id AND v.region = $3 AND v.voter_id = vr.id
+)
+`
+
+func activeMoviesSQL(now time.Time, f ActiveMoviesFilter) *listSQL {
+	b := &listSQL{idCol: "id"}
+	b.arg(pgtype.Timestamptz{Time: now, Valid: true})
+	b.arg(f.Fingerprint)
+	b.arg(f.Region)
+	b.arg(f.Language)
+	return b
+}
+
+// translatedColumns selects title and overview from translation tr when the
+// language ($4) is not the stored one, falling back to the original title and
+// the stored overview where TMDb has no translation.
+const translatedColumns = `CASE WHEN $4::text = '' THEN m.title
+              ELSE COALESCE(NULLIF(tr.title, ''), NULLIF(m.original_title, ''), m.title) END AS title,
+         CASE WHEN $4::text = '' THEN m.overview ELSE COALESCE(NULLIF(tr.overview, ''), m.overview) END AS overview`
+
+// ListActiveMoviesPageFiltered returns a page of active movies for the current month
+// with filters and sorting. A backward page runs the keyset query in the opposite
+// direction from the cursor and is returned in display order.
+func (r *MoviesRepo) ListActiveMoviesPageFiltered(ctx context.Context, now time.Time, f ActiveMoviesFilter) (Page[model.Movie], error) {
 	if f.Limit <= 0 {
 		f.Limit = 20
 	}
-	if f.SortBy == "" {
-		f.SortBy = SortByPopularity
-	}
-	if f.SortDir != SortDirAsc && f.SortDir != SortDirDesc {
-		f.SortDir = SortDirDesc
-	}
-	minVal := math.Inf(-1)
-	if f.MinPop != nil {
-		minVal = *f.MinPop
-	}
-	maxVal := math.Inf(+1)
-	if f.MaxPop != nil {
-		maxVal = *f.MaxPop
-	}
-	curKey := func() float64 {
-		if f.CursorKey != nil {
-			return *f.CursorKey
-		}
-		if f.SortDir == SortDirDesc {
-			return math.Inf(+1)
-		}
-		return math.Inf(-1)
-	}()
-	curID := int64(0)
+	backward := f.Backward && f.CursorID != nil
+	ss := sorts(f.Query, backward)
+	b := activeMoviesSQL(now, f)
+	where := b.where(f.Query)
 	if f.CursorID != nil {
-		curID = *f.CursorID
-	}
-	fp := ""
-	if f.Fingerprint != nil {
-		fp = *f.Fingerprint
-	}
-	params := store.ListActiveMoviesFilteredPageParams{
-		Column1:     pgtype.Timestamptz{Time: now, Valid: true},
-		Column2:     minVal,
-		Column3:     maxVal,
-		Column4:     string(f.SortBy),
-		Column5:     string(f.SortDir),
-		Column6:     curKey,
-		Column7:     curID,
-		Limit:       f.Limit,
-		Fingerprint: fp,
+		if len(f.CursorKeys) != len(ss) {
+			return Page[model.Movie]{}, ErrCursorMismatch
+		}
+		where += " AND " + b.keyset(ss, f.CursorKeys, *f.CursorID)
 	}
-	rows, err := r.q.ListActiveMoviesFilteredPage(ctx, params)
+	sql := activeMoviesCTE + `SELECT id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url,
+       genre_ids, solo_friends, couple, streaming, arr, voted_category` + keyColumns(ss) + `
+FROM listed
+WHERE ` + where + `
+ORDER BY ` + b.orderBy(ss) + `
+LIMIT ` + b.arg(f.Limit+1) // one extra row tells whether another page exists
+	rows, err := r.db.Query(ctx, sql, b.args...)
 	if err != nil {
-		return nil, 0, err
+		return Page[model.Movie]{}, err
 	}
-	out := make([]model.Movie, 0, len(rows))
-	var lastKey float64
-	for _, rrow := range rows {
-		var votedPtr *string
-		if s := categoryToString(rrow.VotedCategory); s != "" {
-			v := s
-			votedPtr = &v
+	defer rows.Close()
+	var out []model.Movie
+	var keys [][]string
+	for rows.Next() {
+		var (
+			mv                                   model.Movie
+			overview, poster, backdrop, imdb, cm pgtype.Text
+			solo, couple, streaming, arr         int64
+			voted                                string
+			rowKeys                              = make([]string, len(ss))
+		)
+		dest := []any{&mv.ID, &mv.Title, &mv.ReleaseDate, &overview, &poster, &backdrop, &mv.Popularity, &imdb, &cm,
+			&mv.GenreIDs, &solo, &couple, &streaming, &arr, &voted}
+		for i := range rowKeys {
+			dest = append(dest, &rowKeys[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return Page[model.Movie]{}, err
+		}
+		mv.Overview, mv.PosterPath, mv.BackdropPath = textPtr(overview), textPtr(poster), textPtr(backdrop)
+		mv.ImdbURL, mv.CinemagiaURL = textPtr(imdb), textPtr(cm)
+		mv.Tallies = map[string]int64{
+			model.CategorySoloFriends: solo,
+			model.CategoryCouple:      couple,
+			model.CategoryStreaming:   streaming,
+			model.CategoryArr:         arr,
 		}
-		mv := model.Movie{
-			ID:           rrow.ID,
-			Title:        rrow.Title,
-			ReleaseDate:  rrow.ReleaseDate.Time,
-			Overview:     textPtr(rrow.Overview),
-			PosterPath:   textPtr(rrow.PosterPath),
-			BackdropPath: textPtr(rrow.BackdropPath),
-			Popularity:   rrow.Popularity.Float64,
-			Tallies: map[string]int64{
-				model.CategorySoloFriends: rrow.SoloFriends,
-				model.CategoryCouple:      rrow.Couple,
-				model.CategoryStreaming:   rrow.Streaming,
-				model.CategoryArr:         rrow.Arr,
-			},
-			VotedCategory: votedPtr,
-			ImdbURL:       textPtr(rrow.ImdbUrl),
-			CinemagiaURL:  textPtr(rrow.CinemagiaUrl),
+		mv.Stats = model.NewTallyStats(mv.Tallies)
+		if voted != "" {
+			mv.VotedCategory = &voted
 		}
 		out = append(out, mv)
-		lastKey = anyToFloat64(rrow.KeyValue)
+		keys = append(keys, rowKeys)
 	}
-	return out, lastKey, nil
+	if err := rows.Err(); err != nil {
+		return Page[model.Movie]{}, err
+	}
+	return keysetPage(out, keys, f.Limit, backward), nil
 }
 
-func (r *MoviesRepo) CountActiveMoviesFiltered(ctx context.Context, now time.Time, minPop, maxPop *float64) (int64, error) {
-	minVal := math.Inf(-1)
-	if minPop != nil {
-		minVal = *minPop
-	}
-	maxVal := math.Inf(+1)
-	if maxPop != nil {
-		maxVal = *maxPop
-	}
-	arg := store.CountActiveMoviesFilteredParams{
-		Column1: pgtype.Timestamptz{Time: now, Valid: true},
-		Column2: minVal,
-		Column3: maxVal,
-	}
-	return r.q.CountActiveMoviesFiltered(ctx, arg)
+// CountActiveMoviesFiltered counts the active movies matching f's filters.
+func (r *MoviesRepo) CountActiveMoviesFiltered(ctx context.Context, now time.Time, f ActiveMoviesFilter) (int64, error) {
+	b := activeMoviesSQL(now, f)
+	var n int64
+	err := r.db.QueryRow(ctx, activeMoviesCTE+"SELECT COUNT(*) FROM listed WHERE "+b.where(f.Query), b.args...).Scan(&n)
+	return n, err
 }
 
-// UpsertMovies inserts or updates movies by TMDB id. Returns count upserted.
-func (r *MoviesRepo) UpsertMovies(ctx context.Context, movies []pkgtmdb.Movie) (int, error) {
+// UpsertMovies inserts or updates movies by TMDB id, taking each ReleaseDate as
+// the release date in region. Movies new to the region's board are announced as
+// movies.added. Returns count upserted.
+func (r *MoviesRepo) UpsertMovies(ctx context.Context, region string, movies []pkgtmdb.Movie) (int, error) {
 	count := 0
+	var added []model.AddedMovie
+	var err error
 	for _, m := range movies {
-		if err := r.q.UpsertMovie(ctx, store.UpsertMovieParams{
-			ID:           int64(m.TMDBID),
-			Title:        m.Title,
-			ReleaseDate:  pgtype.Date{Time: m.ReleaseDate, Valid: true},
-			Overview:     textVal(m.Overview),
-			PosterPath:   textVal(m.PosterPath),
-			BackdropPath: textVal(m.BackdropPath),
-			Popularity:   pgtype.Float8{Float64: m.Popularity, Valid: true},
-			ImdbUrl:      pgtype.Text{Valid: false},
-			CinemagiaUrl: pgtype.Text{Valid: false},
+		if err = r.q.UpsertMovie(ctx, store.UpsertMovieParams{
+			ID:               int64(m.TMDBID),
+			Title:            m.Title,
+			ReleaseDate:      pgtype.Date{Time: m.ReleaseDate, Valid: true},
+			Overview:         textVal(m.Overview),
+			PosterPath:       textVal(m.PosterPath),
+			BackdropPath:     textVal(m.BackdropPath),
+			Popularity:       pgtype.Float8{Float64: m.Popularity, Valid: true},
+			ImdbUrl:          pgtype.Text{Valid: false},
+			CinemagiaUrl:     pgtype.Text{Valid: false},
+			GenreIds:         genreIDs(m.GenreIDs),
+			OriginalTitle:    textVal(m.OriginalTitle),
+			OriginalLanguage: textVal(m.OriginalLanguage),
 		}); err != nil {
-			return count, err
+			break
+		}
+		var isNew bool
+		if isNew, err = upsertRelease(ctx, r.q, int64(m.TMDBID), region, m.ReleaseDate); err != nil {
+			break
+		}
+		if isNew {
+			added = append(added, addedMovie(m, m.ReleaseDate))
 		}
 		count++
 	}
-	return count, nil
+	if aerr := announceAdded(context.WithoutCancel(ctx), r.q, region, added); aerr != nil && err == nil {
+		err = aerr
+	}
+	return count, err
 }
 
-// UpsertMoviesFromTMDB upserts movies and fetches external IDs from TMDb client to populate imdb and cinemagia URLs.
-func (r *MoviesRepo) UpsertMoviesFromTMDB(ctx context.Context, movies []pkgtmdb.Movie, c *pkgtmdb.Client) (int, error) {
+// UpsertMoviesFromTMDB upserts movies discovered for region and fetches external IDs from TMDb client to
+// populate imdb and cinemagia URLs, the regional release date (the primary one when TMDb has none) and
+// the title and overview in each of languages. Movies new to the region's board
+// are announced as one movies.added event, even when a later movie fails.
+func (r *MoviesRepo) UpsertMoviesFromTMDB(ctx context.Context, region string, movies []pkgtmdb.Movie, c *pkgtmdb.Client, languages []string) (int, error) {
 	// concurrency limit to avoid hammering TMDb or DB
 	const concurrency = 10
 	var count int64
+	var (
+		mu    sync.Mutex
+		added []model.AddedMovie
+	)
 	g, ctx := errgroup.WithContext(ctx)
 	sem := make(chan struct{}, concurrency)
 
@@ -190,42 +227,178 @@ func (r *MoviesRepo) UpsertMoviesFromTMDB(ctx context.Context, movies []pkgtmdb.
 		g.Go(func() error {
 			defer func() { <-sem }()
 
-			// fetch external ids if client present
+			// fetch external ids and regional release date if client present
 			imdbURL := ""
 			cinemagiaURL := ""
+			release := m.ReleaseDate
+			var translations []pkgtmdb.Translation
 			if c != nil {
-				if ext, err := c.GetExternalIDs(m.TMDBID); err == nil {
+				if ext, err := c.GetExternalIDs(ctx, m.TMDBID); err == nil {
 					if ext.ImdbID != "" {
 						imdbURL = "https://www.imdb.com/title/" + ext.ImdbID
 						// use URL-escaped title for Cinemagia search, wait for Cinemagia to provide a better solution
 						cinemagiaURL = "https://www.cinemagia.ro/cauta/?q=" + url.QueryEscape(m.Title)
 					}
 				}
+				if dates, err := c.GetRegionalReleaseDates(ctx, m.TMDBID); err == nil {
+					if d, ok := dates[region]; ok {
+						release = d
+					}
+				}
+				if len(languages) > 0 {
+					// a failed lookup keeps the translations of the previous sync
+					translations, _ = c.GetTranslations(ctx, m.TMDBID)
+				}
 			}
 
 			if err := r.q.UpsertMovie(ctx, store.UpsertMovieParams{
-				ID:           int64(m.TMDBID),
-				Title:        m.Title,
-				ReleaseDate:  pgtype.Date{Time: m.ReleaseDate, Valid: true},
-				Overview:     textVal(m.Overview),
-				PosterPath:   textVal(m.PosterPath),
-				BackdropPath: textVal(m.BackdropPath),
-				Popularity:   pgtype.Float8{Float64: m.Popularity, Valid: true},
-				ImdbUrl:      textVal(imdbURL),
-				CinemagiaUrl: textVal(cinemagiaURL),
+				ID:               int64(m.TMDBID),
+				Title:            m.Title,
+				ReleaseDate:      pgtype.Date{Time: m.ReleaseDate, Valid: true},
+				Overview:         textVal(m.Overview),
+				PosterPath:       textVal(m.PosterPath),
+				BackdropPath:     textVal(m.BackdropPath),
+				Popularity:       pgtype.Float8{Float64: m.Popularity, Valid: true},
+				ImdbUrl:          textVal(imdbURL),
+				CinemagiaUrl:     textVal(cinemagiaURL),
+				GenreIds:         genreIDs(m.GenreIDs),
+				OriginalTitle:    textVal(m.OriginalTitle),
+				OriginalLanguage: textVal(m.OriginalLanguage),
 			}); err != nil {
 				return err
 			}
+			for _, lang := range languages {
+				t, ok := pkgtmdb.PickTranslation(translations, lang)
+				if !ok {
+					continue
+				}
+				if err := r.q.UpsertMovieTranslation(ctx, store.UpsertMovieTranslationParams{
+					MovieID:  int64(m.TMDBID),
+					Language: lang,
+					Title:    t.Title,
+					Overview: t.Overview,
+				}); err != nil {
+					return err
+				}
+			}
+			isNew, err := upsertRelease(ctx, r.q, int64(m.TMDBID), region, release)
+			if err != nil {
+				return err
+			}
+			if isNew {
+				mu.Lock()
+				added = append(added, addedMovie(m, release))
+				mu.Unlock()
+			}
 			atomic.AddInt64(&count, 1)
 			return nil
 		})
 	}
 
 	// wait for remaining goroutines to finish
-	if err := g.Wait(); err != nil {
-		return int(count), err
+	err := g.Wait()
+	// the errgroup context is cancelled by now
+	if aerr := announceAdded(context.WithoutCancel(ctx), r.q, region, added); aerr != nil && err == nil {
+		err = aerr
+	}
+	return int(count), err
+}
+
+// upsertRelease stores a movie's release date in region and reports whether the
+// movie is new to the region's board. A locked movie keeps its release date.
+func upsertRelease(ctx context.Context, q *store.Queries, movieID int64, region string, date time.Time) (bool, error) {
+	inserted, err := q.UpsertMovieRelease(ctx, store.UpsertMovieReleaseParams{
+		MovieID:     movieID,
+		Region:      region,
+		ReleaseDate: pgtype.Date{Time: date, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
 	}
-	return int(count), nil
+	return inserted, err
+}
+
+// ArchiveMovie is a movie read from an archive or resolved from TMDb for one
+// region; Movie.ReleaseDate is the release date in Region.
+type ArchiveMovie struct {
+	Region string
+	Movie  pkgtmdb.Movie
+}
+
+// ImportMovies upserts archived movies and their regional release dates in one
+// transaction, rolled back without commit. Unlike the TMDb sync it announces
+// nothing: archives are history. Outcomes are in the order of movies.
+func (r *MoviesRepo) ImportMovies(ctx context.Context, movies []ArchiveMovie, commit bool) ([]model.ImportOutcome, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	q := r.q.WithTx(tx)
+	out := make([]model.ImportOutcome, 0, len(movies))
+	for _, m := range movies {
+		o, err := importMovie(ctx, q, m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	if !commit {
+		return out, nil
+	}
+	return out, tx.Commit(ctx)
+}
+
+// importMovie upserts m and its release in m.Region. The movie counts as
+// updated when either changed.
+func importMovie(ctx context.Context, q *store.Queries, m ArchiveMovie) (model.ImportOutcome, error) {
+	mv := m.Movie
+	var popularity pgtype.Float8
+	if mv.Popularity != 0 {
+		popularity = pgtype.Float8{Float64: mv.Popularity, Valid: true}
+	}
+	inserted, err := q.ImportMovie(ctx, store.ImportMovieParams{
+		ID:               int64(mv.TMDBID),
+		Title:            mv.Title,
+		ReleaseDate:      pgtype.Date{Time: mv.ReleaseDate, Valid: true},
+		Overview:         textVal(mv.Overview),
+		PosterPath:       textVal(mv.PosterPath),
+		BackdropPath:     textVal(mv.BackdropPath),
+		Popularity:       popularity,
+		GenreIds:         genreIDs(mv.GenreIDs),
+		OriginalTitle:    textVal(mv.OriginalTitle),
+		OriginalLanguage: textVal(mv.OriginalLanguage),
+	})
+	movie := outcome(inserted, err)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+	inserted, err = q.UpsertMovieRelease(ctx, store.UpsertMovieReleaseParams{
+		MovieID:     int64(mv.TMDBID),
+		Region:      m.Region,
+		ReleaseDate: pgtype.Date{Time: mv.ReleaseDate, Valid: true},
+	})
+	release := outcome(inserted, err)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+	if movie == model.ImportUnchanged && release != model.ImportUnchanged {
+		return model.ImportUpdated, nil
+	}
+	return movie, nil
+}
+
+func addedMovie(m pkgtmdb.Movie, release time.Time) model.AddedMovie {
+	return model.AddedMovie{ID: int64(m.TMDBID), Title: m.Title, ReleaseDate: release.Format(time.DateOnly)}
+}
+
+// announceAdded emits movies.added for the movies new to region's board, if any.
+func announceAdded(ctx context.Context, q *store.Queries, region string, added []model.AddedMovie) error {
+	if len(added) == 0 {
+		return nil
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].ID < added[j].ID })
+	return enqueueEvent(ctx, q, model.EventMoviesAdded, model.MoviesAddedEvent{Region: region, Movies: added})
 }
 
 func (r *MoviesRepo) HasMovies(ctx context.Context) (bool, error) {
@@ -233,40 +406,115 @@ func (r *MoviesRepo) HasMovies(ctx context.Context) (bool, error) {
 	return exists, err
 }
 
-func (r *MoviesRepo) CountActiveMovies(ctx context.Context, now time.Time) (int64, error) {
-	return r.q.CountActiveMovies(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+// Card returns a visible movie released in region, or ErrMovieNotFound.
+func (r *MoviesRepo) Card(ctx context.Context, id int64, region string) (model.MovieCard, error) {
+	row, err := r.q.GetMovieCard(ctx, store.GetMovieCardParams{MovieID: id, Region: region})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.MovieCard{}, ErrMovieNotFound
+	}
+	if err != nil {
+		return model.MovieCard{}, err
+	}
+	return model.MovieCard{ID: row.ID, Region: region, Title: row.Title, Overview: textPtr(row.Overview), PosterPath: textPtr(row.PosterPath),
+		ReleaseDate: row.ReleaseDate.Time}, nil
+}
+
+// movieSQL selects visible movie $1 released in region $3 with the vote of
+// fingerprint $2 there; title and overview are in language $4 (see
+// translatedColumns).
+const movieSQL = `SELECT m.id, ` + translatedColumns + `, r.release_date, m.poster_path, m.backdrop_path,
+       COALESCE(m.popularity, 0), m.imdb_url, m.cinemagia_url, m.genre_ids, COALESCE(v.category::text, '')
+FROM movies m
+JOIN movie_releases r ON r.movie_id = m.id AND r.region = $3
+LEFT JOIN movie_translations tr ON tr.movie_id = m.id AND tr.language = $4
+LEFT JOIN voters vr ON vr.fingerprint = $2
+LEFT JOIN votes v ON v.movie_id = m.id AND v.region = $3 AND v.voter_id = vr.id
+WHERE m.id = $1 AND NOT m.hidden`
+
+// Get returns a visible movie released in region as the active listing shows
+// it: in language (empty for the stored one), with its tallies there and the
+// vote of fingerprint when set. Movies not released in region are
+// ErrMovieNotFound.
+func (r *MoviesRepo) Get(ctx context.Context, id int64, region, language string, fingerprint *string) (model.Movie, error) {
+	var (
+		mv                                   model.Movie
+		overview, poster, backdrop, imdb, cm pgtype.Text
+		voted                                string
+	)
+	err := r.db.QueryRow(ctx, movieSQL, id, fingerprint, region, language).Scan(&mv.ID, &mv.Title, &mv.ReleaseDate, &overview, &poster, &backdrop,
+		&mv.Popularity, &imdb, &cm, &mv.GenreIDs, &voted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Movie{}, ErrMovieNotFound
+	}
+	if err != nil {
+		return model.Movie{}, err
+	}
+	mv.Overview, mv.PosterPath, mv.BackdropPath = textPtr(overview), textPtr(poster), textPtr(backdrop)
+	mv.ImdbURL, mv.CinemagiaURL = textPtr(imdb), textPtr(cm)
+	if voted != "" {
+		mv.VotedCategory = &voted
+	}
+	rows, err := r.q.GetTalliesForMovies(ctx, store.GetTalliesForMoviesParams{Column1: []int64{id}, Region: region})
+	if err != nil {
+		return model.Movie{}, err
+	}
+	mv.Tallies = make(map[string]int64, len(model.AllowedCategories))
+	for _, t := range rows {
+		mv.Tallies[t.Category] = t.Count
+	}
+	mv.Stats = model.NewTallyStats(mv.Tallies)
+	return mv, nil
 }
 
-// Existing method retained for backwards compatibility (unused by new route).
-func (r *MoviesRepo) ListActiveMoviesPage(ctx context.Context, now time.Time, cursorPop *float64, cursorID *int64, limit int32) ([]model.Movie, error) {
-	pop := 0.0
-	id := int64(0)
-	if cursorPop != nil {
-		pop = *cursorPop
+// RecentlyVotable returns the latest limit movies that became votable in region
+// up to the month of now, most recent first.
+func (r *MoviesRepo) RecentlyVotable(ctx context.Context, region string, now time.Time, limit int32) ([]model.VotableMovie, error) {
+	rows, err := r.q.ListRecentlyVotableMovies(ctx, store.ListRecentlyVotableMoviesParams{
+		Region:  region,
+		Column2: pgtype.Timestamptz{Time: now, Valid: true},
+		Limit:   limit,
+	})
+	if err != nil {
+		return nil, err
 	}
-	if cursorID != nil {
-		id = *cursorID
+	out := make([]model.VotableMovie, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, model.VotableMovie{
+			ID:           row.ID,
+			Title:        row.Title,
+			Overview:     textPtr(row.Overview),
+			PosterPath:   textPtr(row.PosterPath),
+			ReleaseDate:  row.ReleaseDate.Time,
+			VotableSince: row.VotableSince.Time.UTC(),
+		})
 	}
-	params := store.ListActiveMoviesPageParams{
-		pgtype.Timestamptz{Time: now, Valid: true},
-		pgtype.Float8{Float64: pop, Valid: true},
-		id,
-		limit,
+	return out, nil
+}
+
+// CalendarReleases returns the visible movies released in region from from up
+// to but excluding to, by date; with genres only those with any of them.
+func (r *MoviesRepo) CalendarReleases(ctx context.Context, region string, from, to time.Time, genres []int32) ([]model.CalendarRelease, error) {
+	if genres == nil {
+		genres = []int32{}
 	}
-	rows, err := r.q.ListActiveMoviesPage(ctx, params)
+	rows, err := r.q.ListCalendarReleases(ctx, store.ListCalendarReleasesParams{
+		Region:  region,
+		Column2: pgtype.Date{Time: from, Valid: true},
+		Column3: pgtype.Date{Time: to, Valid: true},
+		Column4: genres,
+	})
 	if err != nil {
 		return nil, err
 	}
-	out := make([]model.Movie, 0, len(rows))
-	for _, m := range rows {
-		out = append(out, model.Movie{
-			ID:           m.ID,
-			Title:        m.Title,
-			ReleaseDate:  m.ReleaseDate.Time,
-			Overview:     textPtr(m.Overview),
-			PosterPath:   textPtr(m.PosterPath),
-			BackdropPath: textPtr(m.BackdropPath),
-			Popularity:   m.Popularity.Float64,
+	out := make([]model.CalendarRelease, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, model.CalendarRelease{
+			MovieID:     row.ID,
+			Title:       row.Title,
+			Region:      region,
+			ReleaseDate: row.ReleaseDate.Time,
+			Revision:    int(row.Revision),
+			UpdatedAt:   row.UpdatedAt.Time.UTC(),
 		})
 	}
 	return out, nil
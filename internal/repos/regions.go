@@ -0,0 +1,77 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/store"
+)
+
+// RegionsRepo manages the regions that get their own board.
+type RegionsRepo struct {
+	db *pgxpool.Pool
+	q  *store.Queries
+}
+
+var ErrInvalidRegion = errors.New("invalid region")
+
+var regionCode = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// ValidRegionCode reports whether code looks like an ISO 3166-1 alpha-2 code.
+func ValidRegionCode(code string) bool { return regionCode.MatchString(code) }
+
+// ListRegions returns every region, enabled or not, by code.
+func (r *RegionsRepo) ListRegions(ctx context.Context) ([]model.Region, error) {
+	rows, err := r.q.ListRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Region, 0, len(rows))
+	for _, rg := range rows {
+		out = append(out, model.Region{Code: rg.Code, Name: rg.Name, Enabled: rg.Enabled})
+	}
+	return out, nil
+}
+
+// EnabledRegions returns the codes of the regions that are synced and listed.
+func (r *RegionsRepo) EnabledRegions(ctx context.Context) ([]string, error) {
+	return r.q.ListEnabledRegionCodes(ctx)
+}
+
+// UpsertRegion creates a region or updates its name and enabled flag.
+func (r *RegionsRepo) UpsertRegion(ctx context.Context, rg model.Region) error {
+	if !ValidRegionCode(rg.Code) || rg.Name == "" {
+		return ErrInvalidRegion
+	}
+	return r.q.UpsertRegion(ctx, store.UpsertRegionParams{Code: rg.Code, Name: rg.Name, Enabled: rg.Enabled})
+}
+
+// EnsureRegion creates an enabled region unless it exists, leaving an existing
+// one untouched; used for the configured default region at startup.
+func (r *RegionsRepo) EnsureRegion(ctx context.Context, code, name string) error {
+	if !ValidRegionCode(code) {
+		return ErrInvalidRegion
+	}
+	if name == "" {
+		name = code
+	}
+	return r.q.EnsureRegion(ctx, store.EnsureRegionParams{Code: code, Name: name})
+}
+
+// regionExists reports whether code is a known region, enabled or not.
+func (r *RegionsRepo) regionExists(ctx context.Context, code string) (bool, error) {
+	rows, err := r.q.ListRegions(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, rg := range rows {
+		if rg.Code == code {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -20,6 +20,11 @@ type Repository struct {
 	Votes     *VotesRepo
 	Tallies   *TalliesRepo
 	Snapshots *SnapshotsRepo
+	Regions   *RegionsRepo
+	Voters    *VotersRepo
+	Webhooks  *WebhooksRepo
+	Exports   *ExportsRepo
+	Admin     *AdminRepo
 }
 
 func New(db *pgxpool.Pool) *Repository {
@@ -29,73 +34,73 @@ func New(db *pgxpool.Pool) *Repository {
 	r.Votes = &VotesRepo{db: db, q: q}
 	r.Tallies = &TalliesRepo{db: db, q: q}
 	r.Snapshots = &SnapshotsRepo{db: db, q: q}
+	r.Regions = &RegionsRepo{db: db, q: q}
+	r.Voters = &VotersRepo{db: db, q: q}
+	r.Webhooks = &WebhooksRepo{db: db, q: q}
+	r.Exports = &ExportsRepo{db: db, q: q}
+	r.Admin = &AdminRepo{db: db, q: q}
 	return r
 }
 
-// Forwarders for compatibility
-func (r *Repository) ListActiveMoviesPage(ctx context.Context, now time.Time, cursorPop *float64, cursorID *int64, limit int32) ([]model.Movie, error) {
-	return r.Movies.ListActiveMoviesPage(ctx, now, cursorPop, cursorID, limit)
+// Ping checks that a pooled connection to Postgres can be used.
+func (r *Repository) Ping(ctx context.Context) error { return r.db.Ping(ctx) }
+
+// SchemaVersion reads the version recorded by golang-migrate in schema_migrations.
+func (r *Repository) SchemaVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	err = r.db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	return version, dirty, err
 }
-func (r *Repository) UpsertMovies(ctx context.Context, movies []pkgtmdb.Movie) (int, error) {
-	return r.Movies.UpsertMovies(ctx, movies)
+
+// Forwarders for compatibility
+func (r *Repository) UpsertMovies(ctx context.Context, region string, movies []pkgtmdb.Movie) (int, error) {
+	return r.Movies.UpsertMovies(ctx, region, movies)
 }
-func (r *Repository) UpsertMoviesFromTMDB(ctx context.Context, movies []pkgtmdb.Movie, c *pkgtmdb.Client) (int, error) {
-	return r.Movies.UpsertMoviesFromTMDB(ctx, movies, c)
+func (r *Repository) UpsertMoviesFromTMDB(ctx context.Context, region string, movies []pkgtmdb.Movie, c *pkgtmdb.Client, languages []string) (int, error) {
+	return r.Movies.UpsertMoviesFromTMDB(ctx, region, movies, c, languages)
 }
 func (r *Repository) HasMovies(ctx context.Context) (bool, error) { return r.Movies.HasMovies(ctx) }
-func (r *Repository) CountActiveMovies(ctx context.Context, now time.Time) (int64, error) {
-	return r.Movies.CountActiveMovies(ctx, now)
-}
 
 // New filtered/sorted active movies forwarders
-func (r *Repository) ListActiveMoviesPageFiltered(ctx context.Context, now time.Time, f ActiveMoviesFilter) ([]model.Movie, float64, error) {
+func (r *Repository) ListActiveMoviesPageFiltered(ctx context.Context, now time.Time, f ActiveMoviesFilter) (Page[model.Movie], error) {
 	return r.Movies.ListActiveMoviesPageFiltered(ctx, now, f)
 }
-func (r *Repository) CountActiveMoviesFiltered(ctx context.Context, now time.Time, minPop, maxPop *float64) (int64, error) {
-	return r.Movies.CountActiveMoviesFiltered(ctx, now, minPop, maxPop)
+func (r *Repository) CountActiveMoviesFiltered(ctx context.Context, now time.Time, f ActiveMoviesFilter) (int64, error) {
+	return r.Movies.CountActiveMoviesFiltered(ctx, now, f)
 }
 
-func (r *Repository) CreateVote(ctx context.Context, movieID int64, category, fingerprint string, now time.Time) (bool, error) {
-	return r.Votes.CreateVote(ctx, movieID, category, fingerprint, now)
+func (r *Repository) CreateVote(ctx context.Context, movieID int64, region, category, fingerprint string, now time.Time) (bool, error) {
+	return r.Votes.CreateVote(ctx, movieID, region, category, fingerprint, now)
 }
 
-func (r *Repository) GetTallies(ctx context.Context, movieID int64) ([]model.Tally, error) {
-	return r.Tallies.GetTallies(ctx, movieID)
-}
-func (r *Repository) ListTalliesByMoviePage(ctx context.Context, movieID int64, cursorCount *int64, cursorCategory *string, limit int32) ([]model.Tally, error) {
-	return r.Tallies.ListTalliesByMoviePage(ctx, movieID, cursorCount, cursorCategory, limit)
+func (r *Repository) GetTallies(ctx context.Context, movieID int64, region string) ([]model.Tally, error) {
+	return r.Tallies.GetTallies(ctx, movieID, region)
 }
-func (r *Repository) CountTalliesByMovie(ctx context.Context, movieID int64) (int64, error) {
-	return r.Tallies.CountTalliesByMovie(ctx, movieID)
+func (r *Repository) GetVoterCategory(ctx context.Context, movieID int64, region, fingerprint string) (*string, error) {
+	return r.Tallies.GetVoterCategory(ctx, movieID, region, fingerprint)
 }
-func (r *Repository) GetVoterCategory(ctx context.Context, movieID int64, fingerprint string) (*string, error) {
-	return r.Tallies.GetVoterCategory(ctx, movieID, fingerprint)
-}
-func (r *Repository) GetTalliesAllCategories(ctx context.Context, movieID int64) ([]model.Tally, error) {
-	return r.Tallies.GetTalliesAllCategories(ctx, movieID)
+func (r *Repository) GetTalliesAllCategories(ctx context.Context, movieID int64, region string) ([]model.Tally, error) {
+	return r.Tallies.GetTalliesAllCategories(ctx, movieID, region)
 }
 
-func (r *Repository) SnapshotMonth(ctx context.Context, year int, month time.Month) error {
-	return r.Snapshots.SnapshotMonth(ctx, year, month)
-}
-func (r *Repository) GetSnapshotsByMonth(ctx context.Context, month string) ([]model.Snapshot, error) {
-	return r.Snapshots.GetSnapshotsByMonth(ctx, month)
-}
-func (r *Repository) ListSnapshotsByMonthPage(ctx context.Context, month string, cursorMovieID *int64, limit int32) ([]model.Snapshot, error) {
-	return r.Snapshots.ListSnapshotsByMonthPage(ctx, month, cursorMovieID, limit)
+func (r *Repository) SnapshotMonth(ctx context.Context, region string, year int, month time.Month) error {
+	return r.Snapshots.SnapshotMonth(ctx, region, year, month)
 }
-func (r *Repository) CountSnapshotsByMonth(ctx context.Context, month string) (int64, error) {
-	return r.Snapshots.CountSnapshotsByMonth(ctx, month)
+func (r *Repository) GetSnapshotsByMonth(ctx context.Context, region, month string) ([]model.Snapshot, error) {
+	return r.Snapshots.GetSnapshotsByMonth(ctx, region, month)
 }
 
 // New filtered snapshot forwarders
-func (r *Repository) ListSnapshotsByMonthFiltered(ctx context.Context, f SnapshotsFilter) ([]model.Snapshot, float64, error) {
+func (r *Repository) ListSnapshotsByMonthFiltered(ctx context.Context, f SnapshotsFilter) (Page[model.Snapshot], error) {
 	return r.Snapshots.ListSnapshotsByMonthFiltered(ctx, f)
 }
-func (r *Repository) CountSnapshotsByMonthFiltered(ctx context.Context, month string, minPop, maxPop *float64) (int64, error) {
-	return r.Snapshots.CountSnapshotsByMonthFiltered(ctx, month, minPop, maxPop)
+func (r *Repository) CountSnapshotsByMonthFiltered(ctx context.Context, f SnapshotsFilter) (int64, error) {
+	return r.Snapshots.CountSnapshotsByMonthFiltered(ctx, f)
+}
+
+func (r *Repository) ListAvailableYearMonths(ctx context.Context, region string) ([]AvailableMonths, error) {
+	return r.Snapshots.ListAvailableYearMonths(ctx, region)
 }
 
-func (r *Repository) ListAvailableYearMonths(ctx context.Context) ([]AvailableMonths, error) {
-	return r.Snapshots.ListAvailableYearMonths(ctx)
+func (r *Repository) EnabledRegions(ctx context.Context) ([]string, error) {
+	return r.Regions.EnabledRegions(ctx)
 }
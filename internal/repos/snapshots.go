@@ -3,32 +3,59 @@ package repos
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math"
 	"sort"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"cinekami-server/internal/listquery"
 	"cinekami-server/internal/model"
 	"cinekami-server/internal/store"
 )
 
+var (
+	ErrInvalidSnapshot  = errors.New("invalid snapshot")
+	ErrMonthNotArchived = errors.New("month not archived")
+)
+
 type SnapshotsRepo struct {
 	db *pgxpool.Pool
 	q  *store.Queries
 }
 
-func (r *SnapshotsRepo) SnapshotMonth(ctx context.Context, year int, month time.Month) error {
-	monStart := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
-	ids, err := r.q.ListMovieIDsByMonth(ctx, pgtype.Date{Time: monStart, Valid: true})
+// SnapshotMonth archives the region's tallies of every visible movie released
+// there in the given month in one transaction. The first time the month is
+// archived with movies it is announced as snapshot.closed in the same
+// transaction; re-runs, concurrent ones included, refresh the snapshots without
+// announcing it again, even once every archived movie is hidden.
+func (r *SnapshotsRepo) SnapshotMonth(ctx context.Context, region string, year int, month time.Month) error {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	q := r.q.WithTx(tx)
+
+	// replicas and the admin trigger may close the same month at once; the
+	// first to lock it is the one to announce it
 	mon := fmt.Sprintf("%04d-%02d", year, int(month))
+	if err := q.LockSnapshotMonth(ctx, store.LockSnapshotMonthParams{Region: region, Month: mon}); err != nil {
+		return err
+	}
+	archived, err := q.CountMonthSnapshots(ctx, store.CountMonthSnapshotsParams{Region: region, Month: mon})
+	if err != nil {
+		return err
+	}
+	ids, err := movieIDsForMonth(ctx, q, region, year, month)
+	if err != nil {
+		return err
+	}
 	for _, id := range ids {
-		rows, err := r.q.GetTalliesByMovie(ctx, id)
+		rows, err := q.GetTalliesByMovie(ctx, store.GetTalliesByMovieParams{MovieID: id, Region: region})
 		if err != nil {
 			return err
 		}
@@ -38,151 +65,98 @@ func (r *SnapshotsRepo) SnapshotMonth(ctx context.Context, year int, month time.
 			m[cat] = t.Count.Int64
 		}
 		b, _ := json.Marshal(m)
-		if err := r.q.UpsertSnapshot(ctx, store.UpsertSnapshotParams{Month: mon, MovieID: id, Tallies: b}); err != nil {
+		if err := q.UpsertSnapshot(ctx, store.UpsertSnapshotParams{Region: region, Month: mon, MovieID: id, Tallies: b}); err != nil {
 			return err
 		}
 	}
-	return nil
-}
-
-func (r *SnapshotsRepo) GetSnapshotsByMonth(ctx context.Context, month string) ([]model.Snapshot, error) {
-	rows, err := r.q.GetSnapshotsByMonth(ctx, month)
-	if err != nil {
-		return nil, err
-	}
-	out := make([]model.Snapshot, 0, len(rows))
-	for _, s := range rows {
-		var tallies map[string]int64
-		if err := json.Unmarshal(s.Tallies, &tallies); err != nil {
-			return nil, fmt.Errorf("decode tallies: %w", err)
+	if archived == 0 && len(ids) > 0 {
+		if err := enqueueEvent(ctx, q, model.EventSnapshotClosed, model.SnapshotClosedEvent{Region: region, Month: mon, Movies: len(ids)}); err != nil {
+			return err
 		}
-		// Ensure all categories are present
-		zt := zeroTallies()
-		mergeTallies(zt, tallies)
-		out = append(out, model.Snapshot{Month: s.Month, MovieID: s.MovieID, Tallies: zt, Closed: s.ClosedAt.Time})
 	}
-	return out, nil
+	return tx.Commit(ctx)
 }
 
-type SnapshotSortBy string
-
-type SnapshotSortDir string
-
-const (
-	SnapSortByPopularity  SnapshotSortBy = "popularity"
-	SnapSortByReleaseDate SnapshotSortBy = "release_date"
-	SnapSortBySoloFriends SnapshotSortBy = model.CategorySoloFriends
-	SnapSortByCouple      SnapshotSortBy = model.CategoryCouple
-	SnapSortByStreaming   SnapshotSortBy = model.CategoryStreaming
-	SnapSortByArr         SnapshotSortBy = model.CategoryArr
-
-	SnapSortDirDesc SnapshotSortDir = "desc"
-	SnapSortDirAsc  SnapshotSortDir = "asc"
-)
+// MovieIDsForMonth lists the visible movies SnapshotMonth would archive.
+func (r *SnapshotsRepo) MovieIDsForMonth(ctx context.Context, region string, year int, month time.Month) ([]int64, error) {
+	return movieIDsForMonth(ctx, r.q, region, year, month)
+}
 
-type SnapshotsFilter struct {
-	Month     string
-	SortBy    SnapshotSortBy
-	SortDir   SnapshotSortDir
-	MinPop    *float64
-	MaxPop    *float64
-	CursorKey *float64
-	CursorID  *int64
-	Limit     int32
+func movieIDsForMonth(ctx context.Context, q *store.Queries, region string, year int, month time.Month) ([]int64, error) {
+	monStart := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return q.ListMovieIDsByMonth(ctx, store.ListMovieIDsByMonthParams{Column1: pgtype.Date{Time: monStart, Valid: true}, Region: region})
 }
 
-// ListSnapshotsByMonthFiltered returns snapshots for a month with filters and sorting.
-func (r *SnapshotsRepo) ListSnapshotsByMonthFiltered(ctx context.Context, f SnapshotsFilter) ([]model.Snapshot, float64, error) {
-	if f.Limit <= 0 {
-		f.Limit = 20
-	}
-	if f.SortBy == "" {
-		f.SortBy = SnapSortByPopularity
-	}
-	if f.SortDir != SnapSortDirAsc && f.SortDir != SnapSortDirDesc {
-		f.SortDir = SnapSortDirDesc
+// ValidateSnapshot checks an archived snapshot's region, month, categories and movie without writing.
+func (r *SnapshotsRepo) ValidateSnapshot(ctx context.Context, s model.Snapshot) error {
+	if _, err := time.Parse("2006-01", s.Month); err != nil {
+		return fmt.Errorf("%w: month %q", ErrInvalidSnapshot, s.Month)
 	}
-	minVal := math.Inf(-1)
-	if f.MinPop != nil {
-		minVal = *f.MinPop
-	}
-	maxVal := math.Inf(+1)
-	if f.MaxPop != nil {
-		maxVal = *f.MaxPop
+	regions := RegionsRepo{db: r.db, q: r.q}
+	if ok, err := regions.regionExists(ctx, s.Region); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%w: region %q", ErrInvalidSnapshot, s.Region)
 	}
-	curKey := func() float64 {
-		if f.CursorKey != nil {
-			return *f.CursorKey
+	for cat, n := range s.Tallies {
+		if _, ok := model.AllowedCategories[cat]; !ok {
+			return fmt.Errorf("%w: category %q", ErrInvalidSnapshot, cat)
 		}
-		if f.SortDir == SnapSortDirDesc {
-			return math.Inf(+1)
+		if n < 0 {
+			return fmt.Errorf("%w: negative count for %s", ErrInvalidSnapshot, cat)
 		}
-		return math.Inf(-1)
-	}()
-	curID := int64(0)
-	if f.CursorID != nil {
-		curID = *f.CursorID
-	}
-	params := store.ListSnapshotsByMonthFilteredPageParams{
-		Month:   f.Month,
-		Column2: minVal,
-		Column3: maxVal,
-		Column4: string(f.SortBy),
-		Column5: string(f.SortDir),
-		Column6: curKey,
-		MovieID: curID,
-		Limit:   f.Limit,
-	}
-	rows, err := r.q.ListSnapshotsByMonthFilteredPage(ctx, params)
-	if err != nil {
-		return nil, 0, err
 	}
-	out := make([]model.Snapshot, 0, len(rows))
-	var lastKey float64
-	for _, rr := range rows {
-		m := zeroTallies()
-		m[model.CategorySoloFriends] = anyToInt64(rr.SoloFriends)
-		m[model.CategoryCouple] = anyToInt64(rr.Couple)
-		m[model.CategoryStreaming] = anyToInt64(rr.Streaming)
-		m[model.CategoryArr] = anyToInt64(rr.Arr)
-		out = append(out, model.Snapshot{
-			Month:        rr.Month,
-			MovieID:      rr.MovieID,
-			Tallies:      m,
-			Closed:       rr.ClosedAt.Time,
-			Title:        rr.Title,
-			ReleaseDate:  rr.ReleaseDate.Time,
-			Overview:     textPtr(rr.Overview),
-			PosterPath:   textPtr(rr.PosterPath),
-			BackdropPath: textPtr(rr.BackdropPath),
-			Popularity:   rr.Popularity.Float64,
-			ImdbURL:      textPtr(rr.ImdbUrl),
-			CinemagiaURL: textPtr(rr.CinemagiaUrl),
-		})
-		lastKey = anyToFloat64(rr.KeyValue)
+	if _, err := r.q.GetMovieAdmin(ctx, s.MovieID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrMovieNotFound
+		}
+		return err
 	}
-	return out, lastKey, nil
+	return nil
 }
 
-func (r *SnapshotsRepo) CountSnapshotsByMonthFiltered(ctx context.Context, month string, minPop, maxPop *float64) (int64, error) {
-	minVal := math.Inf(-1)
-	if minPop != nil {
-		minVal = *minPop
+// ImportMonth applies one region and month of an archive in a transaction: the
+// movies resolved for it first, then the snapshots, which must have passed
+// ValidateSnapshot (or lack only their movie among movies). Without commit the
+// transaction is rolled back, so a dry run reports exactly what an import
+// would do. Outcomes are in the order of snaps.
+func (r *SnapshotsRepo) ImportMonth(ctx context.Context, movies []ArchiveMovie, snaps []model.Snapshot, commit bool) ([]model.ImportOutcome, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
 	}
-	maxVal := math.Inf(+1)
-	if maxPop != nil {
-		maxVal = *maxPop
+	defer func() { _ = tx.Rollback(ctx) }()
+	q := r.q.WithTx(tx)
+	for _, m := range movies {
+		if _, err := importMovie(ctx, q, m); err != nil {
+			return nil, err
+		}
+	}
+	out := make([]model.ImportOutcome, 0, len(snaps))
+	for _, s := range snaps {
+		tallies := zeroTallies()
+		mergeTallies(tallies, s.Tallies)
+		b, err := json.Marshal(tallies)
+		if err != nil {
+			return nil, err
+		}
+		inserted, err := q.ImportSnapshot(ctx, store.ImportSnapshotParams{
+			Region: s.Region, Month: s.Month, MovieID: s.MovieID, Tallies: b,
+			ClosedAt: pgtype.Timestamptz{Time: s.Closed, Valid: !s.Closed.IsZero()},
+		})
+		out = append(out, outcome(inserted, err))
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+	}
+	if !commit {
+		return out, nil
 	}
-	arg := store.CountSnapshotsByMonthFilteredParams{Month: month, Column2: minVal, Column3: maxVal}
-	return r.q.CountSnapshotsByMonthFiltered(ctx, arg)
+	return out, tx.Commit(ctx)
 }
 
-func (r *SnapshotsRepo) ListSnapshotsByMonthPage(ctx context.Context, month string, cursorMovieID *int64, limit int32) ([]model.Snapshot, error) {
-	cur := int64(0)
-	if cursorMovieID != nil {
-		cur = *cursorMovieID
-	}
-	rows, err := r.q.ListSnapshotsByMonthPage(ctx, store.ListSnapshotsByMonthPageParams{Month: month, Column2: cur, Limit: limit})
+func (r *SnapshotsRepo) GetSnapshotsByMonth(ctx context.Context, region, month string) ([]model.Snapshot, error) {
+	rows, err := r.q.GetSnapshotsByMonth(ctx, store.GetSnapshotsByMonthParams{Region: region, Month: month})
 	if err != nil {
 		return nil, err
 	}
@@ -195,13 +169,128 @@ func (r *SnapshotsRepo) ListSnapshotsByMonthPage(ctx context.Context, month stri
 		// Ensure all categories are present
 		zt := zeroTallies()
 		mergeTallies(zt, tallies)
-		out = append(out, model.Snapshot{Month: s.Month, MovieID: s.MovieID, Tallies: zt, Closed: s.ClosedAt.Time})
+		out = append(out, model.Snapshot{Region: s.Region, Month: s.Month, MovieID: s.MovieID, Tallies: zt, Closed: s.ClosedAt.Time})
 	}
 	return out, nil
 }
 
-func (r *SnapshotsRepo) CountSnapshotsByMonth(ctx context.Context, month string) (int64, error) {
-	return r.q.CountSnapshotsByMonth(ctx, month)
+type SnapshotsFilter struct {
+	Region      string
+	Language    string // as in ActiveMoviesFilter
+	Month       string
+	Query       listquery.Query
+	CursorKeys  []string // sort key values of the cursor row, one per Query.Sort key
+	CursorID    *int64
+	Backward    bool // page towards the start of the listing from the cursor
+	Limit       int32
+	Fingerprint *string // only used by the voted filter
+}
+
+// snapshotsCTE selects region $3's snapshots of month $1 for visible movies, with
+// the archived tallies and the vote of fingerprint $2. release_date is the
+// regional release date where known; title and overview are in language $4.
+const snapshotsCTE = `WITH joined AS (
+  SELECT st.region, st.month, st.movie_id, st.closed_at, COALESCE(m.popularity, 0) AS popularity,
+         ` + translatedColumns + `,
+         COALESCE(r.release_date, m.release_date) AS release_date,
+         m.poster_path, m.backdrop_path, m.imdb_url, m.cinemagia_url, m.genre_ids,
+         COALESCE((st.tallies ->> 'solo_friends')::bigint, 0) AS solo_friends,
+         COALESCE((st.tallies ->> 'couple')::bigint, 0) AS couple,
+         COALESCE((st.tallies ->> 'streaming')::bigint, 0) AS streaming,
+         COALESCE((st.tallies ->> 'arr')::bigint, 0) AS arr
+  FROM snapshots st
+  JOIN movies m ON m.id = st.movie_id
+  LEFT JOIN movie_releases r ON r.movie_id = st.movie_id AND r.region = st.region
+  LEFT JOIN movie_translations tr ON tr.movie_id = st.movie_id AND tr.language = $4
+  WHERE st.month = $1
+    AND st.region = $3
+    AND NOT m.hidden
+), listed AS (
+  SELECT j.*, j.solo_friends + j.couple + j.streaming + j.arr AS total_votes, COALESCE(v.category::text, '') AS voted_category
+  FROM joined j
+  LEFT JOIN voters vr ON vr.fingerprint = $2
+  LEFT JOIN votes v ON v.movie_id = j.movie_id AND v.region = j.region AND v.voter_id = vr.id
+)
+`
+
+func snapshotsSQL(f SnapshotsFilter) *listSQL {
+	b := &listSQL{idCol: "movie_id"}
+	b.arg(f.Month)
+	b.arg(f.Fingerprint)
+	b.arg(f.Region)
+	b.arg(f.Language)
+	return b
+}
+
+// ListSnapshotsByMonthFiltered returns a page of snapshots for a month with filters
+// and sorting; backward pages work as in ListActiveMoviesPageFiltered.
+func (r *SnapshotsRepo) ListSnapshotsByMonthFiltered(ctx context.Context, f SnapshotsFilter) (Page[model.Snapshot], error) {
+	if f.Limit <= 0 {
+		f.Limit = 20
+	}
+	backward := f.Backward && f.CursorID != nil
+	ss := sorts(f.Query, backward)
+	b := snapshotsSQL(f)
+	where := b.where(f.Query)
+	if f.CursorID != nil {
+		if len(f.CursorKeys) != len(ss) {
+			return Page[model.Snapshot]{}, ErrCursorMismatch
+		}
+		where += " AND " + b.keyset(ss, f.CursorKeys, *f.CursorID)
+	}
+	sql := snapshotsCTE + `SELECT movie_id, region, month, closed_at, popularity, title, release_date, overview, poster_path, backdrop_path,
+       imdb_url, cinemagia_url, genre_ids, solo_friends, couple, streaming, arr` + keyColumns(ss) + `
+FROM listed
+WHERE ` + where + `
+ORDER BY ` + b.orderBy(ss) + `
+LIMIT ` + b.arg(f.Limit+1) // one extra row tells whether another page exists
+	rows, err := r.db.Query(ctx, sql, b.args...)
+	if err != nil {
+		return Page[model.Snapshot]{}, err
+	}
+	defer rows.Close()
+	var out []model.Snapshot
+	var keys [][]string
+	for rows.Next() {
+		var (
+			sn                                   model.Snapshot
+			closed                               pgtype.Timestamptz
+			overview, poster, backdrop, imdb, cm pgtype.Text
+			solo, couple, streaming, arr         int64
+			rowKeys                              = make([]string, len(ss))
+		)
+		dest := []any{&sn.MovieID, &sn.Region, &sn.Month, &closed, &sn.Popularity, &sn.Title, &sn.ReleaseDate, &overview, &poster, &backdrop,
+			&imdb, &cm, &sn.GenreIDs, &solo, &couple, &streaming, &arr}
+		for i := range rowKeys {
+			dest = append(dest, &rowKeys[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return Page[model.Snapshot]{}, err
+		}
+		sn.Closed = closed.Time
+		sn.Overview, sn.PosterPath, sn.BackdropPath = textPtr(overview), textPtr(poster), textPtr(backdrop)
+		sn.ImdbURL, sn.CinemagiaURL = textPtr(imdb), textPtr(cm)
+		sn.Tallies = zeroTallies()
+		sn.Tallies[model.CategorySoloFriends] = solo
+		sn.Tallies[model.CategoryCouple] = couple
+		sn.Tallies[model.CategoryStreaming] = streaming
+		sn.Tallies[model.CategoryArr] = arr
+		sn.Stats = model.NewTallyStats(sn.Tallies)
+		out = append(out, sn)
+		keys = append(keys, rowKeys)
+	}
+	if err := rows.Err(); err != nil {
+		return Page[model.Snapshot]{}, err
+	}
+	return keysetPage(out, keys, f.Limit, backward), nil
+}
+
+// CountSnapshotsByMonthFiltered counts the snapshots matching f's month and filters.
+func (r *SnapshotsRepo) CountSnapshotsByMonthFiltered(ctx context.Context, f SnapshotsFilter) (int64, error) {
+	b := snapshotsSQL(f)
+	var n int64
+	err := r.db.QueryRow(ctx, snapshotsCTE+"SELECT COUNT(*) FROM listed WHERE "+b.where(f.Query), b.args...).Scan(&n)
+	return n, err
 }
 
 type AvailableMonths struct {
@@ -209,8 +298,8 @@ type AvailableMonths struct {
 	Months []int `json:"months"`
 }
 
-func (r *SnapshotsRepo) ListAvailableYearMonths(ctx context.Context) ([]AvailableMonths, error) {
-	rows, err := r.q.ListAvailableSnapshotYearMonths(ctx)
+func (r *SnapshotsRepo) ListAvailableYearMonths(ctx context.Context, region string) ([]AvailableMonths, error) {
+	rows, err := r.q.ListAvailableSnapshotYearMonths(ctx, region)
 	if err != nil {
 		return nil, err
 	}
@@ -237,3 +326,62 @@ func (r *SnapshotsRepo) ListAvailableYearMonths(ctx context.Context) ([]Availabl
 	}
 	return out, nil
 }
+
+// MonthResult returns a closed month of region with each category's winner and
+// the votes per category, or ErrMonthNotArchived.
+func (r *SnapshotsRepo) MonthResult(ctx context.Context, region, month string) (model.MonthResult, error) {
+	row, err := r.q.GetSnapshotMonth(ctx, store.GetSnapshotMonthParams{Region: region, Month: month})
+	if err != nil {
+		return model.MonthResult{}, err
+	}
+	if row.Movies == 0 {
+		return model.MonthResult{}, ErrMonthNotArchived
+	}
+	res := model.MonthResult{Region: region, Month: month, ClosedAt: row.ClosedAt.Time.UTC(), Movies: row.Movies, Winners: []model.CategoryWinner{},
+		Totals: map[string]int64{
+			model.CategorySoloFriends: row.SoloFriends,
+			model.CategoryCouple:      row.Couple,
+			model.CategoryStreaming:   row.Streaming,
+			model.CategoryArr:         row.Arr,
+		}}
+	winners, err := r.q.ListSnapshotWinners(ctx, store.ListSnapshotWinnersParams{Region: region, Column2: []string{month}})
+	if err != nil {
+		return model.MonthResult{}, err
+	}
+	for _, w := range winners {
+		res.Winners = append(res.Winners, model.CategoryWinner{Category: w.Category, MovieID: w.MovieID, Title: w.Title, Votes: w.Votes})
+	}
+	return res, nil
+}
+
+// MonthlyResults returns the latest limit closed months of region, newest
+// first, with each category's winner.
+func (r *SnapshotsRepo) MonthlyResults(ctx context.Context, region string, limit int32) ([]model.MonthResult, error) {
+	months, err := r.q.ListSnapshotMonths(ctx, store.ListSnapshotMonthsParams{Region: region, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.MonthResult, len(months))
+	index := make(map[string]int, len(months))
+	names := make([]string, len(months))
+	for i, m := range months {
+		out[i] = model.MonthResult{Region: region, Month: m.Month, ClosedAt: m.ClosedAt.Time.UTC(), Movies: m.Movies, Winners: []model.CategoryWinner{}}
+		index[m.Month] = i
+		names[i] = m.Month
+	}
+	if len(months) == 0 {
+		return out, nil
+	}
+	winners, err := r.q.ListSnapshotWinners(ctx, store.ListSnapshotWinnersParams{Region: region, Column2: names})
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range winners {
+		i, ok := index[w.Month]
+		if !ok {
+			continue
+		}
+		out[i].Winners = append(out[i].Winners, model.CategoryWinner{Category: w.Category, MovieID: w.MovieID, Title: w.Title, Votes: w.Votes})
+	}
+	return out, nil
+}
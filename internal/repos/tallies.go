@@ -5,7 +5,6 @@ import (
 	"errors"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"cinekami-server/internal/model"
@@ -17,8 +16,9 @@ type TalliesRepo struct {
 	q  *store.Queries
 }
 
-func (r *TalliesRepo) GetTallies(ctx context.Context, movieID int64) ([]model.Tally, error) {
-	rows, err := r.q.GetTalliesByMovie(ctx, movieID)
+// GetTallies returns the stored tallies of a movie in region; categories without votes are absent.
+func (r *TalliesRepo) GetTallies(ctx context.Context, movieID int64, region string) ([]model.Tally, error) {
+	rows, err := r.q.GetTalliesByMovie(ctx, store.GetTalliesByMovieParams{MovieID: movieID, Region: region})
 	if err != nil {
 		return nil, err
 	}
@@ -30,42 +30,14 @@ func (r *TalliesRepo) GetTallies(ctx context.Context, movieID int64) ([]model.Ta
 	return out, nil
 }
 
-func (r *TalliesRepo) ListTalliesByMoviePage(ctx context.Context, movieID int64, cursorCount *int64, cursorCategory *string, limit int32) ([]model.Tally, error) {
-	var countArg pgtype.Int8
-	catArg := ""
-	if cursorCount != nil {
-		countArg = pgtype.Int8{Int64: *cursorCount, Valid: true}
-	}
-	if cursorCategory != nil {
-		catArg = *cursorCategory
-	}
-	rows, err := r.q.ListTalliesByMoviePage(ctx, store.ListTalliesByMoviePageParams{
-		MovieID: movieID,
-		Count:   countArg,
-		Column3: catArg,
-		Limit:   limit,
-	})
-	if err != nil {
-		return nil, err
-	}
-	out := make([]model.Tally, 0, len(rows))
-	for _, t := range rows {
-		out = append(out, model.Tally{MovieID: t.MovieID, Category: categoryToString(t.Category), Count: t.Count.Int64})
-	}
-	return out, nil
-}
-
-func (r *TalliesRepo) CountTalliesByMovie(ctx context.Context, movieID int64) (int64, error) {
-	return r.q.CountTalliesByMovie(ctx, movieID)
-}
-
-func (r *TalliesRepo) GetVoterCategory(ctx context.Context, movieID int64, fingerprint string) (*string, error) {
+func (r *TalliesRepo) GetVoterCategory(ctx context.Context, movieID int64, region, fingerprint string) (*string, error) {
 	if fingerprint == "" {
 		return nil, nil
 	}
 	cat, err := r.q.GetVoterCategoryByMovieAndFingerprint(ctx, store.GetVoterCategoryByMovieAndFingerprintParams{
 		MovieID:     movieID,
-		Fingerprint: fingerprint,
+		Region:      region,
+		Fingerprint: textVal(fingerprint),
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -76,8 +48,8 @@ func (r *TalliesRepo) GetVoterCategory(ctx context.Context, movieID int64, finge
 	return &cat, nil
 }
 
-func (r *TalliesRepo) GetTalliesAllCategories(ctx context.Context, movieID int64) ([]model.Tally, error) {
-	rows, err := r.q.GetTalliesForMovies(ctx, []int64{movieID})
+func (r *TalliesRepo) GetTalliesAllCategories(ctx context.Context, movieID int64, region string) ([]model.Tally, error) {
+	rows, err := r.q.GetTalliesForMovies(ctx, store.GetTalliesForMoviesParams{Column1: []int64{movieID}, Region: region})
 	if err != nil {
 		return nil, err
 	}
@@ -87,3 +59,59 @@ func (r *TalliesRepo) GetTalliesAllCategories(ctx context.Context, movieID int64
 	}
 	return out, nil
 }
+
+// GetTalliesForMovies returns the tallies of several movies in region in one
+// query, keyed by movie id; every category is present, zero counts included.
+func (r *TalliesRepo) GetTalliesForMovies(ctx context.Context, movieIDs []int64, region string) (map[int64]map[string]int64, error) {
+	rows, err := r.q.GetTalliesForMovies(ctx, store.GetTalliesForMoviesParams{Column1: movieIDs, Region: region})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]map[string]int64, len(movieIDs))
+	for _, t := range rows {
+		id := anyToInt64(t.MovieID)
+		if out[id] == nil {
+			out[id] = make(map[string]int64, len(model.AllowedCategories))
+		}
+		out[id][t.Category] = t.Count
+	}
+	return out, nil
+}
+
+// TallyDrift is a vote_tallies row that disagrees with the votes it summarises.
+type TallyDrift struct {
+	MovieID  int64  `json:"movie_id"`
+	Region   string `json:"region"`
+	Category string `json:"category"`
+	Stored   int64  `json:"stored"`
+	Actual   int64  `json:"actual"`
+}
+
+// ReconcileTallies compares vote_tallies with counts from votes. When apply is set
+// the tallies of every drifted movie are rebuilt, in all regions, in one transaction.
+func (r *TalliesRepo) ReconcileTallies(ctx context.Context, apply bool) ([]TallyDrift, error) {
+	rows, err := r.q.ListTallyDrift(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]TallyDrift, 0, len(rows))
+	var movieIDs []int64
+	for _, d := range rows {
+		out = append(out, TallyDrift(d))
+		if len(movieIDs) == 0 || movieIDs[len(movieIDs)-1] != d.MovieID {
+			movieIDs = append(movieIDs, d.MovieID) // rows are ordered by movie
+		}
+	}
+	if !apply || len(movieIDs) == 0 {
+		return out, nil
+	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return out, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if err := rebuildTallies(ctx, r.q.WithTx(tx), movieIDs); err != nil {
+		return out, err
+	}
+	return out, tx.Commit(ctx)
+}
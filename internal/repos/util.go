@@ -2,14 +2,55 @@ package repos
 
 import (
 	"fmt"
-	"math"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"cinekami-server/internal/model"
 )
 
+// Page is one keyset page. HasMore reports rows beyond the page in the direction
+// it was fetched; FirstKeys and LastKeys are the sort keys of its first and last
+// item, as text.
+type Page[T any] struct {
+	Items     []T
+	FirstKeys []string
+	LastKeys  []string
+	HasMore   bool
+}
+
+// keysetPage trims a limit+1 fetch to limit and, for backward fetches (which run
+// in the opposite order), restores display order. keys holds each row's sort keys.
+func keysetPage[T any](items []T, keys [][]string, limit int32, backward bool) Page[T] {
+	p := Page[T]{Items: items}
+	if len(items) > int(limit) {
+		p.Items, keys, p.HasMore = items[:limit], keys[:limit], true
+	}
+	if backward {
+		slices.Reverse(p.Items)
+		slices.Reverse(keys)
+	}
+	if len(keys) > 0 {
+		p.FirstKeys, p.LastKeys = keys[0], keys[len(keys)-1]
+	}
+	return p
+}
+
+// outcome maps the result of an upsert RETURNING (xmax = 0), which returns no
+// row when nothing changed, to an import outcome.
+func outcome(inserted bool, err error) model.ImportOutcome {
+	switch {
+	case err != nil:
+		return model.ImportUnchanged
+	case inserted:
+		return model.ImportCreated
+	default:
+		return model.ImportUpdated
+	}
+}
+
 func textPtr(t pgtype.Text) *string {
 	if !t.Valid {
 		return nil
@@ -18,6 +59,14 @@ func textPtr(t pgtype.Text) *string {
 	return &s
 }
 
+// genreIDs keeps the NOT NULL genre_ids column happy: pgx sends a nil slice as NULL.
+func genreIDs(ids []int32) []int32 {
+	if ids == nil {
+		return []int32{}
+	}
+	return ids
+}
+
 func textVal(s string) pgtype.Text {
 	if s == "" {
 		return pgtype.Text{Valid: false}
@@ -25,6 +74,42 @@ func textVal(s string) pgtype.Text {
 	return pgtype.Text{String: s, Valid: true}
 }
 
+// Optional values for sqlc.narg parameters; nil maps to SQL NULL.
+func optText(s *string) pgtype.Text {
+	if s == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *s, Valid: true}
+}
+
+func optFloat8(f *float64) pgtype.Float8 {
+	if f == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *f, Valid: true}
+}
+
+func optBool(b *bool) pgtype.Bool {
+	if b == nil {
+		return pgtype.Bool{}
+	}
+	return pgtype.Bool{Bool: *b, Valid: true}
+}
+
+func optInt8(n *int64) pgtype.Int8 {
+	if n == nil {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: *n, Valid: true}
+}
+
+func optTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
 func categoryToString(v interface{}) string {
 	switch x := v.(type) {
 	case string:
@@ -52,33 +137,6 @@ func mergeTallies(dst map[string]int64, src map[string]int64) {
 	}
 }
 
-// anyToFloat64 converts a scanned SQL value to float64 when possible, else NaN.
-func anyToFloat64(v interface{}) float64 {
-	switch x := v.(type) {
-	case float64:
-		return x
-	case float32:
-		return float64(x)
-	case int64:
-		return float64(x)
-	case int32:
-		return float64(x)
-	case pgtype.Float8:
-		if x.Valid {
-			return x.Float64
-		}
-		return math.NaN()
-	default:
-		// Fallback: try parsing string
-		s := fmt.Sprint(x)
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			return math.NaN()
-		}
-		return f
-	}
-}
-
 // anyToInt64 converts a scanned SQL value to int64 when possible, else 0.
 func anyToInt64(v interface{}) int64 {
 	switch x := v.(type) {
@@ -0,0 +1,186 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/store"
+)
+
+// VotersRepo manages voters: fingerprint digests, exports, erasure and retention.
+// Fingerprints reach it already digested; only the legacy rehash sees raw ones.
+type VotersRepo struct {
+	db *pgxpool.Pool
+	q  *store.Queries
+}
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrVoterIdentity = errors.New("fingerprint or user required")
+)
+
+// rehashBatch bounds the rows rewritten per transaction by HashLegacyFingerprints.
+const rehashBatch = 500
+
+// VoterIdentity selects the voters of a client: by fingerprint digest, by user
+// id, or both.
+type VoterIdentity struct {
+	Fingerprint string
+	UserID      *string
+}
+
+// HashLegacyFingerprints replaces raw fingerprints stored before digests were
+// introduced with digest(raw). A legacy voter whose digest already exists is
+// merged into that voter: its votes move over (the existing vote wins on a
+// conflict), so does its account unless the voter has one, the legacy row is
+// deleted and the affected tallies are rebuilt. Every replica runs it at
+// startup; a batch locks its rows and skips those another one has locked.
+// Returns the number of voters rewritten or merged.
+func (r *VotersRepo) HashLegacyFingerprints(ctx context.Context, digest func(string) string) (int64, error) {
+	var total int64
+	for {
+		n, err := r.hashBatch(ctx, digest)
+		total += n
+		if err != nil || n == 0 {
+			return total, err
+		}
+	}
+}
+
+func (r *VotersRepo) hashBatch(ctx context.Context, digest func(string) string) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	q := r.q.WithTx(tx)
+
+	rows, err := q.ListUnhashedVoters(ctx, rehashBatch)
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+	var affected []int64
+	for _, v := range rows {
+		d := textVal(digest(v.Fingerprint))
+		n, err := q.SetVoterFingerprintDigest(ctx, store.SetVoterFingerprintDigestParams{Digest: d, ID: v.ID})
+		if err != nil {
+			return 0, err
+		}
+		if n == 1 {
+			continue
+		}
+		target, err := q.GetVoterByFingerprint(ctx, d)
+		if err != nil {
+			return 0, err
+		}
+		dropped, err := q.DeleteDuplicateVoterVotes(ctx, store.DeleteDuplicateVoterVotesParams{SourceID: v.ID, TargetID: target})
+		if err != nil {
+			return 0, err
+		}
+		affected = append(affected, dropped...)
+		if _, err := q.MoveVoterVotes(ctx, store.MoveVoterVotesParams{TargetID: target, SourceID: v.ID}); err != nil {
+			return 0, err
+		}
+		if err := q.DeleteVoter(ctx, v.ID); err != nil {
+			return 0, err
+		}
+		// an account is linked to one voter, so only once the legacy one is gone
+		if v.UserID.Valid {
+			if err := q.AdoptVoterUser(ctx, store.AdoptVoterUserParams{UserID: v.UserID, ID: target}); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if len(affected) > 0 {
+		if err := rebuildTallies(ctx, q, affected); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(rows)), tx.Commit(ctx)
+}
+
+// UserCredentials returns the id, password hash and salt of any user.
+func (r *VotersRepo) UserCredentials(ctx context.Context, email string) (id, hash, salt string, err error) {
+	row, err := r.q.GetUserCredentials(ctx, textVal(email))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", "", ErrUserNotFound
+		}
+		return "", "", "", err
+	}
+	return row.ID.String(), row.PasswordHash.String, row.PasswordSalt.String, nil
+}
+
+func (r *VotersRepo) voterIDs(ctx context.Context, q *store.Queries, id VoterIdentity) ([]pgtype.UUID, error) {
+	if id.Fingerprint == "" && id.UserID == nil {
+		return nil, ErrVoterIdentity
+	}
+	userID, err := voterUUID(id.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return q.ListVoterIDs(ctx, store.ListVoterIDsParams{Fingerprint: textVal(id.Fingerprint), UserID: userID})
+}
+
+// ExportVoter returns the voters matching id and all their votes.
+func (r *VotersRepo) ExportVoter(ctx context.Context, id VoterIdentity, now time.Time) (model.VoterExport, error) {
+	out := model.VoterExport{VoterIDs: []string{}, Votes: []model.VoterVote{}, ExportedAt: now}
+	ids, err := r.voterIDs(ctx, r.q, id)
+	if err != nil || len(ids) == 0 {
+		return out, err
+	}
+	for _, v := range ids {
+		out.VoterIDs = append(out.VoterIDs, v.String())
+	}
+	votes, err := r.q.ListVotesByVoters(ctx, ids)
+	if err != nil {
+		return out, err
+	}
+	for _, v := range votes {
+		out.Votes = append(out.Votes, model.VoterVote{
+			MovieID:   v.MovieID,
+			Title:     v.Title,
+			Region:    v.Region,
+			Category:  v.Category,
+			CreatedAt: v.CreatedAt.Time,
+		})
+	}
+	return out, nil
+}
+
+// EraseVoter anonymizes the voters matching id: the fingerprint and account
+// link are dropped while the votes stay, so tallies and snapshots are unchanged
+// but can no longer be tied to the client. Returns the voters anonymized.
+func (r *VotersRepo) EraseVoter(ctx context.Context, id VoterIdentity) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	q := r.q.WithTx(tx)
+	ids, err := r.voterIDs(ctx, q, id)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	n, err := q.AnonymizeVoters(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit(ctx)
+}
+
+// AnonymizeInactiveVoters anonymizes voters created before cutoff that have not
+// voted since. With dryRun it only counts them.
+func (r *VotersRepo) AnonymizeInactiveVoters(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error) {
+	ts := pgtype.Timestamptz{Time: cutoff, Valid: true}
+	if dryRun {
+		return r.q.CountInactiveVoters(ctx, ts)
+	}
+	return r.q.AnonymizeInactiveVoters(ctx, ts)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"cinekami-server/internal/model"
@@ -15,32 +16,51 @@ import (
 type VotesRepo struct {
 	db *pgxpool.Pool
 	q  *store.Queries
+
+	// Milestones are the total vote counts per movie and region announced as
+	// vote.milestone events, each once.
+	Milestones []int64
 }
 
-var ErrVotingClosed = errors.New("voting closed")
+var (
+	ErrVotingClosed  = errors.New("voting closed")
+	ErrMovieNotFound = errors.New("movie not found") // also returned for hidden movies
+)
+
+// VotingWindow is how long after its regional release date a movie takes votes.
+const VotingWindow = 14 * 24 * time.Hour
 
-// CreateVote inserts a vote (by fingerprint) if not already present and increments tallies.
-// Returns inserted=true if a new vote was recorded.
-func (r *VotesRepo) CreateVote(ctx context.Context, movieID int64, category string, fingerprint string, now time.Time) (bool, error) {
+// CreateVote inserts a vote (by fingerprint digest) in region if not already present and increments
+// the region's tallies. Voting is open for 14 days from the regional release date; a movie
+// not released in region is not found. Returns inserted=true if a new vote was recorded.
+// The vote, tally and any milestone event are written in one transaction.
+func (r *VotesRepo) CreateVote(ctx context.Context, movieID int64, region, category, fingerprint string, now time.Time) (bool, error) {
 	// Validate movie and openness
-	release, err := r.q.GetMovieReleaseDate(ctx, movieID)
-	if release.Valid == false && err == nil {
-		return false, errors.New("movie not found")
+	release, err := r.q.GetMovieReleaseDate(ctx, store.GetMovieReleaseDateParams{MovieID: movieID, Region: region})
+	if errors.Is(err, pgx.ErrNoRows) || (!release.Valid && err == nil) {
+		return false, ErrMovieNotFound
 	}
 	if err != nil {
 		return false, err
 	}
-	if now.After(release.Time.Add(14 * 24 * time.Hour)) {
+	if now.After(release.Time.Add(VotingWindow)) {
 		return false, ErrVotingClosed
 	}
 	if _, ok := model.AllowedCategories[category]; !ok {
 		return false, errors.New("invalid category")
 	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	q := r.q.WithTx(tx)
+
 	// Ensure voter exists (by fingerprint)
-	voterID, err := r.q.GetVoterByFingerprint(ctx, fingerprint)
+	voterID, err := q.GetVoterByFingerprint(ctx, textVal(fingerprint))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			voterID, err = r.q.InsertVoter(ctx, fingerprint)
+			voterID, err = q.InsertVoter(ctx, textVal(fingerprint))
 			if err != nil {
 				return false, err
 			}
@@ -49,8 +69,9 @@ func (r *VotesRepo) CreateVote(ctx context.Context, movieID int64, category stri
 		}
 	}
 	// Insert vote (may be duplicate)
-	_, err = r.q.InsertVote(ctx, store.InsertVoteParams{
+	_, err = q.InsertVote(ctx, store.InsertVoteParams{
 		MovieID:  movieID,
+		Region:   region,
 		VoterID:  voterID,
 		Category: category, // enum value as string
 	})
@@ -61,8 +82,29 @@ func (r *VotesRepo) CreateVote(ctx context.Context, movieID int64, category stri
 		return false, err
 	}
 	// Increment tally
-	if err := r.q.IncrementTally(ctx, store.IncrementTallyParams{MovieID: movieID, Category: category}); err != nil {
+	if err := q.IncrementTally(ctx, store.IncrementTallyParams{MovieID: movieID, Region: region, Category: category}); err != nil {
 		return false, err
 	}
-	return true, nil
+	if len(r.Milestones) > 0 {
+		reached, err := q.ReachVoteMilestones(ctx, store.ReachVoteMilestonesParams{MovieID: movieID, Region: region, Milestones: r.Milestones})
+		if err != nil {
+			return false, err
+		}
+		for _, m := range reached {
+			if err := enqueueEvent(ctx, q, model.EventVoteMilestone, model.VoteMilestoneEvent{MovieID: movieID, Region: region, Milestone: m}); err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, tx.Commit(ctx)
+}
+
+// PurgeOrphanVoters deletes anonymous voters created before cutoff that no longer
+// have any votes (e.g. after votes were voided). With dryRun it only counts them.
+func (r *VotesRepo) PurgeOrphanVoters(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error) {
+	ts := pgtype.Timestamptz{Time: cutoff, Valid: true}
+	if dryRun {
+		return r.q.CountOrphanVoters(ctx, ts)
+	}
+	return r.q.DeleteOrphanVoters(ctx, ts)
 }
@@ -0,0 +1,248 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/store"
+)
+
+// WebhooksRepo manages webhook subscriptions and their delivery log. Events are
+// written to the log (the outbox) by the repos that emit them, in the same
+// transaction where there is one; the delivery job sends them.
+type WebhooksRepo struct {
+	db *pgxpool.Pool
+	q  *store.Queries
+}
+
+var (
+	ErrInvalidWebhook  = errors.New("invalid webhook")
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+// PendingDelivery is a claimed delivery with what is needed to send it.
+type PendingDelivery struct {
+	ID        int64
+	Event     string
+	Payload   json.RawMessage
+	Attempts  int32 // before this one
+	CreatedAt time.Time
+	URL       string
+	Secret    string
+}
+
+// enqueueEvent records event for every enabled subscription that wants it.
+func enqueueEvent(ctx context.Context, q *store.Queries, event string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = q.EnqueueWebhookEvent(ctx, store.EnqueueWebhookEventParams{Event: event, Payload: b})
+	return err
+}
+
+// CreateSubscription stores a subscription for an absolute http(s) URL and
+// known events.
+func (r *WebhooksRepo) CreateSubscription(ctx context.Context, rawURL string, events []string, secret string) (model.WebhookSubscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return model.WebhookSubscription{}, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	if len(events) == 0 {
+		return model.WebhookSubscription{}, fmt.Errorf("%w: events required", ErrInvalidWebhook)
+	}
+	for _, e := range events {
+		if !slices.Contains(model.WebhookEvents, e) {
+			return model.WebhookSubscription{}, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, e)
+		}
+	}
+	if secret == "" {
+		return model.WebhookSubscription{}, fmt.Errorf("%w: secret required", ErrInvalidWebhook)
+	}
+	s, err := r.q.CreateWebhookSubscription(ctx, store.CreateWebhookSubscriptionParams{Url: rawURL, Events: events, Secret: secret})
+	if err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	out := webhookSubscription(s)
+	out.Secret = s.Secret
+	return out, nil
+}
+
+// ListSubscriptions returns every subscription, oldest first, without secrets.
+func (r *WebhooksRepo) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	rows, err := r.q.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.WebhookSubscription, 0, len(rows))
+	for _, s := range rows {
+		out = append(out, webhookSubscription(s))
+	}
+	return out, nil
+}
+
+// DeleteSubscription removes a subscription and its delivery log.
+func (r *WebhooksRepo) DeleteSubscription(ctx context.Context, id string) error {
+	uid, err := webhookUUID(id)
+	if err != nil {
+		return err
+	}
+	n, err := r.q.DeleteWebhookSubscription(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// ListDeliveries returns a subscription's deliveries, newest first, optionally
+// only those in status and with ids below beforeID.
+func (r *WebhooksRepo) ListDeliveries(ctx context.Context, subscriptionID string, status *string, beforeID *int64, limit int32) ([]model.WebhookDelivery, error) {
+	uid, err := webhookUUID(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.q.GetWebhookSubscription(ctx, uid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	rows, err := r.q.ListWebhookDeliveries(ctx, store.ListWebhookDeliveriesParams{
+		SubscriptionID: uid,
+		Status:         optText(status),
+		BeforeID:       optInt8(beforeID),
+		Limit:          limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.WebhookDelivery, 0, len(rows))
+	for _, d := range rows {
+		out = append(out, webhookDelivery(d))
+	}
+	return out, nil
+}
+
+// RetryDelivery puts a dead delivery back in the queue with fresh attempts.
+func (r *WebhooksRepo) RetryDelivery(ctx context.Context, id int64) error {
+	n, err := r.q.RetryWebhookDelivery(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// ClaimDeliveries takes up to limit due deliveries and hides them from other
+// workers until lease has passed, so a crashed worker's claims are retried.
+func (r *WebhooksRepo) ClaimDeliveries(ctx context.Context, limit int32, lease time.Duration) ([]PendingDelivery, error) {
+	rows, err := r.q.ClaimWebhookDeliveries(ctx, store.ClaimWebhookDeliveriesParams{
+		LeaseUntil: pgtype.Timestamptz{Time: time.Now().Add(lease), Valid: true},
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PendingDelivery, 0, len(rows))
+	for _, d := range rows {
+		out = append(out, PendingDelivery{
+			ID:        d.ID,
+			Event:     d.Event,
+			Payload:   d.Payload,
+			Attempts:  d.Attempts,
+			CreatedAt: d.CreatedAt.Time,
+			URL:       d.Url,
+			Secret:    d.Secret,
+		})
+	}
+	return out, nil
+}
+
+// MarkDelivered records a successful attempt.
+func (r *WebhooksRepo) MarkDelivered(ctx context.Context, id int64, status int) error {
+	return r.q.MarkWebhookDelivered(ctx, store.MarkWebhookDeliveredParams{ResponseStatus: optStatus(status), ID: id})
+}
+
+// MarkFailed records a failed attempt: the delivery is retried at next, or is
+// dead when next is nil.
+func (r *WebhooksRepo) MarkFailed(ctx context.Context, id int64, status int, cause string, next *time.Time) error {
+	p := store.MarkWebhookFailedParams{
+		Status:         model.DeliveryDead,
+		NextAttemptAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ResponseStatus: optStatus(status),
+		LastError:      textVal(cause),
+		ID:             id,
+	}
+	if next != nil {
+		p.Status = model.DeliveryPending
+		p.NextAttemptAt.Time = *next
+	}
+	return r.q.MarkWebhookFailed(ctx, p)
+}
+
+func optStatus(status int) pgtype.Int4 {
+	return pgtype.Int4{Int32: int32(status), Valid: status != 0}
+}
+
+func webhookUUID(s string) (pgtype.UUID, error) {
+	var id pgtype.UUID
+	if err := id.Scan(s); err != nil {
+		return id, ErrWebhookNotFound
+	}
+	return id, nil
+}
+
+func webhookSubscription(s store.WebhookSubscription) model.WebhookSubscription {
+	return model.WebhookSubscription{
+		ID:        s.ID.String(),
+		URL:       s.Url,
+		Events:    s.Events,
+		Enabled:   s.Enabled,
+		CreatedAt: s.CreatedAt.Time,
+	}
+}
+
+func webhookDelivery(d store.WebhookDelivery) model.WebhookDelivery {
+	out := model.WebhookDelivery{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID.String(),
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastAttemptAt:  timePtr(d.LastAttemptAt),
+		LastError:      textPtr(d.LastError),
+		CreatedAt:      d.CreatedAt.Time,
+		DeliveredAt:    timePtr(d.DeliveredAt),
+	}
+	if d.Status == model.DeliveryPending {
+		out.NextAttemptAt = timePtr(d.NextAttemptAt)
+	}
+	if d.ResponseStatus.Valid {
+		out.ResponseStatus = &d.ResponseStatus.Int32
+	}
+	return out
+}
+
+func timePtr(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time
+	return &v
+}
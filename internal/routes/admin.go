@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+
+	"cinekami-server/internal/deps"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+	pkgrequestctx "cinekami-server/pkg/requestctx"
+)
+
+// Shared helpers for the /admin handlers. Authentication happens in the server
+// middleware; handlers read the actor from the request context.
+
+const (
+	adminDefaultLimit = 100
+	adminMaxLimit     = 1000
+)
+
+// audit records an admin action. A failed write is logged but does not fail the
+// request, since the action itself already happened.
+func audit(d deps.ServerDeps, r *http.Request, action, target string, details any) {
+	actor := pkgrequestctx.Actor(r.Context())
+	if err := d.Repo.Admin.Audit(r.Context(), actor, action, target, details); err != nil {
+		log.Error().Err(err).
+			Str("correlation_id", pkgrequestctx.CorrelationID(r.Context())).
+			Str("actor", actor).
+			Str("action", action).
+			Str("target", target).
+			Msg("audit log write failed")
+	}
+}
+
+// decodeBody decodes a JSON request body, writing the error response on failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			pkghttpx.WriteError(w, r, pkghttpx.PayloadTooLarge("request body too large", err))
+			return false
+		}
+		pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid json", err))
+		return false
+	}
+	return true
+}
+
+// adminLimit parses ?limit= with the admin defaults.
+func adminLimit(r *http.Request) (int32, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return adminDefaultLimit, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || n <= 0 || n > adminMaxLimit {
+		return 0, errors.New("invalid limit")
+	}
+	return int32(n), nil
+}
+
+// invalidateListings drops cached public listings after admin changes.
+func invalidateListings(d deps.ServerDeps, r *http.Request) {
+	_ = d.Cache.DeletePrefix(r.Context(), "active_movies:")
+	_ = d.Cache.DeletePrefix(r.Context(), "snapshots:")
+	_ = d.Cache.DeletePrefix(r.Context(), "feeds:")
+	_ = d.Cache.DeletePrefix(r.Context(), "calendar:")
+	_ = d.Cache.DeletePrefix(r.Context(), "cards:")
+}
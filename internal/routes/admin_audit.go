@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"cinekami-server/internal/deps"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// AdminAuditLog handles GET /admin/audit. Entries are newest first; pass the
+// returned next_before as ?before= to page back.
+func AdminAuditLog(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, err := adminLimit(r)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest(err.Error(), err))
+			return
+		}
+		var before *int64
+		if v := r.URL.Query().Get("before"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid before", err))
+				return
+			}
+			before = &id
+		}
+		items, err := d.Repo.Admin.ListAudit(r.Context(), before, limit)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to list audit log", err))
+			return
+		}
+		resp := AdminAuditResponse{Items: items, Count: len(items)}
+		if len(items) == int(limit) {
+			last := items[len(items)-1].ID
+			resp.NextBefore = &last
+		}
+		pkghttpx.WriteJSON(w, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,149 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkgexport "cinekami-server/pkg/export"
+	pkghttpx "cinekami-server/pkg/httpx"
+	pkgrequestctx "cinekami-server/pkg/requestctx"
+)
+
+// exportWriteTimeout replaces the server's write timeout for an export, which
+// streams for as long as the database produces rows.
+const exportWriteTimeout = 30 * time.Minute
+
+type exportStream[T pkgexport.Record] func(ctx context.Context, f repos.ExportFilter, fn func(T) error) error
+
+// AdminExportSnapshots handles GET /admin/export/snapshots: the archived
+// snapshots of the months from..to.
+func AdminExportSnapshots(d deps.ServerDeps) http.HandlerFunc {
+	return serveExport(d, "snapshots", func(ctx context.Context, f repos.ExportFilter, fn func(model.SnapshotExportRow) error) error {
+		return d.Repo.Exports.ExportSnapshots(ctx, f, fn)
+	})
+}
+
+// AdminExportTallies handles GET /admin/export/tallies: current tallies of the
+// movies released in the months from..to.
+func AdminExportTallies(d deps.ServerDeps) http.HandlerFunc {
+	return serveExport(d, "tallies", func(ctx context.Context, f repos.ExportFilter, fn func(model.TallyExportRow) error) error {
+		return d.Repo.Exports.ExportTallies(ctx, f, fn)
+	})
+}
+
+// AdminExportVotes handles GET /admin/export/votes: the votes cast in the
+// months from..to, without voters and to the hour.
+func AdminExportVotes(d deps.ServerDeps) http.HandlerFunc {
+	return serveExport(d, "votes", func(ctx context.Context, f repos.ExportFilter, fn func(model.VoteExportRow) error) error {
+		return d.Repo.Exports.ExportVotes(ctx, f, fn)
+	})
+}
+
+// serveExport streams an export. Parameters: from (YYYY-MM, required), to
+// (defaults to from), region (all regions when empty) and format (csv, ndjson or
+// parquet), which wins over the Accept header.
+func serveExport[T pkgexport.Record](d deps.ServerDeps, kind string, stream exportStream[T]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		f := repos.ExportFilter{From: q.Get("from"), To: q.Get("to"), Region: strings.ToUpper(q.Get("region"))}
+		if f.From == "" {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("from is required", nil))
+			return
+		}
+		if f.To == "" {
+			f.To = f.From
+		}
+		if err := f.Validate(); err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest(err.Error(), err))
+			return
+		}
+		if f.Region != "" && !repos.ValidRegionCode(f.Region) {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid region", nil))
+			return
+		}
+		format, herr := exportFormat(r)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		audit(d, r, "export."+kind, "", map[string]any{"from": f.From, "to": f.To, "region": f.Region, "format": format})
+
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", `attachment; filename="`+exportFilename(kind, f, format)+`"`)
+		w.Header().Set("Cache-Control", "no-store")
+		ew := &exportWriter{w: w}
+		out := pkgexport.NewWriter[T](ew, format)
+		err := stream(r.Context(), f, out.Write)
+		if err == nil {
+			err = out.Close()
+		}
+		if err == nil {
+			return
+		}
+		if !ew.wrote {
+			w.Header().Del("Content-Disposition")
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("export failed", err))
+			return
+		}
+		// The status line is gone; cut the connection so the client does not
+		// take a truncated file for a complete one.
+		log.Error().Err(err).
+			Str("correlation_id", pkgrequestctx.CorrelationID(r.Context())).
+			Str("export", kind).
+			Msg("export aborted")
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportFormat reads ?format= or, without it, negotiates from Accept.
+func exportFormat(r *http.Request) (pkgexport.Format, *pkghttpx.HTTPError) {
+	if v := r.URL.Query().Get("format"); v != "" {
+		f, ok := pkgexport.ParseFormat(v)
+		if !ok {
+			he := pkghttpx.BadRequest("invalid format", nil)
+			he.Details = map[string]any{"param": "format", "allowed": pkgexport.Formats}
+			return "", he
+		}
+		return f, nil
+	}
+	f, ok := pkgexport.Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		he := pkghttpx.NotAcceptable("no acceptable export format", nil)
+		he.Details = map[string]any{"allowed": pkgexport.Formats}
+		return "", he
+	}
+	return f, nil
+}
+
+func exportFilename(kind string, f repos.ExportFilter, format pkgexport.Format) string {
+	name := "cinekami-" + kind + "-" + f.From
+	if f.To != f.From {
+		name += "_" + f.To
+	}
+	if f.Region != "" {
+		name += "-" + strings.ToLower(f.Region)
+	}
+	return name + "." + string(format)
+}
+
+// exportWriter records whether any of the body reached the client.
+type exportWriter struct {
+	w     http.ResponseWriter
+	wrote bool
+}
+
+func (e *exportWriter) Write(b []byte) (int, error) {
+	if len(b) > 0 {
+		e.wrote = true
+	}
+	return e.w.Write(b)
+}
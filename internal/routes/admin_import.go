@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/jobs"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkgexport "cinekami-server/pkg/export"
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+type importFunc func(ctx context.Context, r *repos.Repository, rd io.Reader, opts jobs.ImportOptions) (model.ImportSummary, error)
+
+// AdminImportSnapshots handles POST /admin/import/snapshots: a snapshot archive
+// as CSV or NDJSON. Movies it references that are missing are fetched from
+// TMDb when a client is configured.
+func AdminImportSnapshots(d deps.ServerDeps) http.HandlerFunc {
+	return serveImport(d, "snapshots", jobs.ImportSnapshots)
+}
+
+// AdminImportMovies handles POST /admin/import/movies: a movie archive as CSV
+// or NDJSON.
+func AdminImportMovies(d deps.ServerDeps) http.HandlerFunc {
+	return serveImport(d, "movies", jobs.ImportMovies)
+}
+
+// serveImport reads the archive in the body, in the format of ?format= or the
+// Content-Type. Parameters: region, for rows without one (default region), and
+// dry_run. Rejected rows do not fail the request; the summary lists them.
+func serveImport(d deps.ServerDeps, kind string, run importFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		format, ok := pkgexport.NDJSON, true
+		if v := q.Get("format"); v != "" {
+			format, ok = pkgexport.ParseFormat(v)
+		} else if ct := r.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "application/json") {
+			format, ok = pkgexport.ForMediaType(ct)
+		}
+		if !ok || format == pkgexport.Parquet {
+			pkghttpx.WriteError(w, r, pkghttpx.UnsupportedMediaType("archives must be CSV or NDJSON", nil))
+			return
+		}
+		region := d.DefaultRegion
+		if v := q.Get("region"); v != "" {
+			region = strings.ToUpper(v)
+			if !repos.ValidRegionCode(region) {
+				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid region", nil))
+				return
+			}
+		}
+		dryRun := false
+		if v := q.Get("dry_run"); v != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(v); err != nil {
+				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid dry_run", err))
+				return
+			}
+		}
+
+		sum, err := run(r.Context(), d.Repo, r.Body, jobs.ImportOptions{Format: format, Region: region, DryRun: dryRun, TMDB: d.TMDB})
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			switch {
+			case errors.As(err, &tooLarge):
+				pkghttpx.WriteError(w, r, pkghttpx.PayloadTooLarge("archive too large", err))
+			case errors.Is(err, jobs.ErrInvalidArchive):
+				pkghttpx.WriteError(w, r, pkghttpx.BadRequest(err.Error(), err))
+			default:
+				pkghttpx.WriteError(w, r, pkghttpx.Internal("import failed", err))
+			}
+			return
+		}
+		if !dryRun {
+			audit(d, r, "import."+kind, "", map[string]any{"region": region, "format": format,
+				"created": sum.Created, "updated": sum.Updated, "unchanged": sum.Unchanged, "rejected": sum.Rejected, "resolved_movies": sum.Resolved})
+			if sum.Created+sum.Updated > 0 {
+				invalidateListings(d, r)
+			}
+		}
+		pkghttpx.WriteJSON(w, http.StatusOK, sum)
+	}
+}
@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/jobs"
+	"cinekami-server/internal/listquery"
+	"cinekami-server/internal/repos"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// AdminTMDBSync handles POST /admin/tmdb/sync. The optional body {"year":2025,"month":10,"region":"RO"}
+// picks the month (default: current) and region (default: every enabled region).
+// The sync runs in the background; the response is 202 and the outcome shows up
+// in logs and job metrics.
+func AdminTMDBSync(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if d.TMDB == nil || d.Jobs == nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Unavailable("tmdb sync not configured", nil))
+			return
+		}
+		now := time.Now().UTC()
+		req := struct {
+			Year   int    `json:"year"`
+			Month  int    `json:"month"`
+			Region string `json:"region"`
+		}{Year: now.Year(), Month: int(now.Month())}
+		if r.ContentLength != 0 && !decodeBody(w, r, &req) {
+			return
+		}
+		if req.Month < 1 || req.Month > 12 || req.Year < 1900 {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid year or month", nil))
+			return
+		}
+		enabled, err := enabledRegions(r.Context(), d)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to load regions", err))
+			return
+		}
+		regions := enabled
+		if req.Region != "" {
+			code := strings.ToUpper(req.Region)
+			if !slices.Contains(enabled, code) {
+				pkghttpx.WriteError(w, r, queryError(&listquery.Error{Param: "region", Value: req.Region, Allowed: enabled}))
+				return
+			}
+			regions = []string{code}
+		}
+		month := fmt.Sprintf("%04d-%02d", req.Year, req.Month)
+		d.Jobs.Go("admin_tmdb_sync", func(ctx context.Context) error {
+			var errs []error
+			for _, region := range regions {
+				n, err := jobs.SyncTMDBMonth(ctx, d.Repo, d.TMDB, req.Year, time.Month(req.Month), region, d.Languages)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("region %s: %w", region, err))
+					continue
+				}
+				log.Info().Int("count", n).Str("month", month).Str("region", region).Msg("admin tmdb sync upserted movies")
+			}
+			_ = d.Cache.DeletePrefix(ctx, "active_movies:")
+			return errors.Join(errs...)
+		})
+		audit(d, r, "tmdb.sync", "month:"+month, map[string]any{"regions": regions})
+		pkghttpx.WriteJSON(w, http.StatusAccepted, AdminJobResponse{Job: "admin_tmdb_sync", Status: "accepted", Month: month, Regions: regions})
+	}
+}
+
+// AdminSnapshot handles POST /admin/snapshots/{year}/{month}?region=; it (re)builds
+// the region's snapshot of every visible movie released there that month from the
+// current tallies. The region defaults to the default region.
+func AdminSnapshot(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		year, yerr := strconv.Atoi(r.PathValue("year"))
+		month, merr := strconv.Atoi(r.PathValue("month"))
+		if yerr != nil || merr != nil || month < 1 || month > 12 {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid year or month", nil))
+			return
+		}
+		region, herr := requestRegion(d, r)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		mon := fmt.Sprintf("%04d-%02d", year, month)
+		if err := d.Repo.SnapshotMonth(r.Context(), region, year, time.Month(month)); err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("snapshot failed", err))
+			return
+		}
+		count, err := d.Repo.CountSnapshotsByMonthFiltered(r.Context(), repos.SnapshotsFilter{Region: region, Month: mon})
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to count snapshots", err))
+			return
+		}
+		audit(d, r, "snapshot.run", "month:"+mon, map[string]any{"region": region, "count": count})
+		_ = d.Cache.DeletePrefix(r.Context(), "snapshots:")
+		_ = d.Cache.DeletePrefix(r.Context(), "feeds:")
+		_ = d.Cache.DeletePrefix(r.Context(), "cards:snapshots:")
+		pkghttpx.WriteJSON(w, http.StatusOK, AdminSnapshotResponse{Region: region, Month: mon, Count: count})
+	}
+}
@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/repos"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// AdminMovie handles GET /admin/movies/{id}; hidden and merged movies are included.
+func AdminMovie(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid movie id", err))
+			return
+		}
+		m, err := d.Repo.Admin.GetMovie(r.Context(), id)
+		if err != nil {
+			writeAdminMovieError(w, r, err)
+			return
+		}
+		pkghttpx.WriteJSON(w, http.StatusOK, m)
+	}
+}
+
+// AdminMovieUpdate handles PATCH /admin/movies/{id}. Any movie field may be set,
+// including hidden; edited movies are locked against the TMDb sync unless the
+// body sets admin_locked to false.
+func AdminMovieUpdate(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid movie id", err))
+			return
+		}
+		var patch repos.MoviePatch
+		if !decodeBody(w, r, &patch) {
+			return
+		}
+		if patch.Title != nil && *patch.Title == "" {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("title must not be empty", nil))
+			return
+		}
+		m, err := d.Repo.Admin.UpdateMovie(r.Context(), id, patch)
+		if err != nil {
+			writeAdminMovieError(w, r, err)
+			return
+		}
+		audit(d, r, "movie.update", "movie:"+strconv.FormatInt(id, 10), patch)
+		invalidateListings(d, r)
+		pkghttpx.WriteJSON(w, http.StatusOK, m)
+	}
+}
+
+// AdminMovieMerge handles POST /admin/movies/{id}/merge with body {"into": <id>}.
+// Votes and snapshots move to the target and the source is hidden.
+func AdminMovieMerge(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid movie id", err))
+			return
+		}
+		var req struct {
+			Into int64 `json:"into"`
+		}
+		if !decodeBody(w, r, &req) {
+			return
+		}
+		if req.Into <= 0 {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("into is required", nil))
+			return
+		}
+		res, err := d.Repo.Admin.MergeMovies(r.Context(), id, req.Into)
+		if err != nil {
+			writeAdminMovieError(w, r, err)
+			return
+		}
+		audit(d, r, "movie.merge", "movie:"+strconv.FormatInt(id, 10), res)
+		invalidateListings(d, r)
+		pkghttpx.WriteJSON(w, http.StatusOK, res)
+	}
+}
+
+func writeAdminMovieError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, repos.ErrMovieNotFound):
+		pkghttpx.WriteError(w, r, pkghttpx.NotFound("movie not found", err))
+	case errors.Is(err, repos.ErrInvalidRegion):
+		pkghttpx.WriteError(w, r, pkghttpx.BadRequest("releases: unknown region", err))
+	case errors.Is(err, repos.ErrInvalidMerge):
+		pkghttpx.WriteError(w, r, pkghttpx.Conflict("cannot merge a movie into itself or into a hidden movie", err))
+	default:
+		pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to update movie", err))
+	}
+}
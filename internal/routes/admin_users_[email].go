@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"net/http"
+	"net/mail"
+
+	"cinekami-server/internal/deps"
+
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+const minAdminPasswordLen = 12
+
+// AdminUserPut handles PUT /admin/users/{email}: it creates the user or resets
+// their password and role. This is how the first admin user is bootstrapped
+// with an API key.
+func AdminUserPut(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		email := r.PathValue("email")
+		if _, err := mail.ParseAddress(email); err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid email", err))
+			return
+		}
+		var req struct {
+			Password string `json:"password"`
+			Role     string `json:"role"`
+		}
+		if !decodeBody(w, r, &req) {
+			return
+		}
+		if req.Role == "" {
+			req.Role = "admin"
+		}
+		if req.Role != "admin" && req.Role != "user" {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("role must be admin or user", nil))
+			return
+		}
+		if len(req.Password) < minAdminPasswordLen {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("password must be at least 12 characters", nil))
+			return
+		}
+		hash, salt, err := pkgcrypto.HashPassword(req.Password)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to hash password", err))
+			return
+		}
+		id, err := d.Repo.Admin.UpsertUser(r.Context(), email, hash, salt, req.Role)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to save user", err))
+			return
+		}
+		audit(d, r, "user.put", "user:"+email, map[string]any{"role": req.Role})
+		pkghttpx.WriteJSON(w, http.StatusOK, AdminUserResponse{ID: id, Email: email, Role: req.Role})
+	}
+}
@@ -0,0 +1,118 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/repos"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// AdminVotes handles GET /admin/votes. Filters: voter_id, fingerprint (the raw
+// client value, digested before matching), movie_id, region, since and until
+// (RFC 3339, until exclusive). Newest votes come first.
+func AdminVotes(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, err := voteFilterFromQuery(r)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest(err.Error(), err))
+			return
+		}
+		digestFilter(d, &f)
+		limit, err := adminLimit(r)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest(err.Error(), err))
+			return
+		}
+		items, err := d.Repo.Admin.ListVotes(r.Context(), f, limit)
+		if err != nil {
+			if errors.Is(err, repos.ErrInvalidVoterID) {
+				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid voter_id", err))
+				return
+			}
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to list votes", err))
+			return
+		}
+		pkghttpx.WriteJSON(w, http.StatusOK, AdminVotesResponse{Items: items, Count: len(items)})
+	}
+}
+
+// AdminVotesVoid handles POST /admin/votes/void. The body is a vote filter with the
+// same fields as the listing; at least one must be set. Tallies of every affected
+// movie are recomputed from the remaining votes.
+func AdminVotesVoid(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var f repos.VoteFilter
+		if !decodeBody(w, r, &f) {
+			return
+		}
+		digestFilter(d, &f)
+		if f.Empty() {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("at least one filter is required", repos.ErrEmptyVoteFilter))
+			return
+		}
+		res, err := d.Repo.Admin.VoidVotes(r.Context(), f)
+		if err != nil {
+			if errors.Is(err, repos.ErrInvalidVoterID) {
+				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid voter_id", err))
+				return
+			}
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to void votes", err))
+			return
+		}
+		audit(d, r, "votes.void", "", map[string]any{"filter": f, "result": res})
+		invalidateListings(d, r)
+		pkghttpx.WriteJSON(w, http.StatusOK, res)
+	}
+}
+
+// digestFilter swaps a raw fingerprint filter for its stored digest.
+func digestFilter(d deps.ServerDeps, f *repos.VoteFilter) {
+	if f.Fingerprint != nil {
+		v := d.Fingerprints.Digest(*f.Fingerprint)
+		f.Fingerprint = &v
+	}
+}
+
+func voteFilterFromQuery(r *http.Request) (repos.VoteFilter, error) {
+	q := r.URL.Query()
+	var f repos.VoteFilter
+	if v := q.Get("voter_id"); v != "" {
+		f.VoterID = &v
+	}
+	if v := q.Get("fingerprint"); v != "" {
+		f.Fingerprint = &v
+	}
+	if v := q.Get("movie_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return f, errors.New("invalid movie_id")
+		}
+		f.MovieID = &id
+	}
+	if v := q.Get("region"); v != "" {
+		v = strings.ToUpper(v)
+		if !repos.ValidRegionCode(v) {
+			return f, errors.New("invalid region")
+		}
+		f.Region = &v
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		if v := q.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return f, errors.New("invalid " + p.name + "; want RFC 3339")
+			}
+			*p.dst = &t
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,149 @@
+package routes
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// AdminWebhooks handles GET /admin/webhooks. Secrets are not returned.
+func AdminWebhooks(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		items, err := d.Repo.Webhooks.ListSubscriptions(r.Context())
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to list webhooks", err))
+			return
+		}
+		pkghttpx.WriteJSON(w, http.StatusOK, AdminWebhooksResponse{Items: items, Count: len(items)})
+	}
+}
+
+// AdminWebhookCreate handles POST /admin/webhooks with {url, events, secret}.
+// Without a secret one is generated; either way it is only returned here.
+func AdminWebhookCreate(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			URL    string   `json:"url"`
+			Events []string `json:"events"`
+			Secret string   `json:"secret"`
+		}
+		if !decodeBody(w, r, &req) {
+			return
+		}
+		if req.Secret == "" {
+			buf := make([]byte, 32)
+			if _, err := rand.Read(buf); err != nil {
+				pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to generate secret", err))
+				return
+			}
+			req.Secret = hex.EncodeToString(buf)
+		}
+		sub, err := d.Repo.Webhooks.CreateSubscription(r.Context(), req.URL, req.Events, req.Secret)
+		if err != nil {
+			if errors.Is(err, repos.ErrInvalidWebhook) {
+				he := pkghttpx.BadRequest(err.Error(), err)
+				he.Details = map[string]any{"events": model.WebhookEvents}
+				pkghttpx.WriteError(w, r, he)
+				return
+			}
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to create webhook", err))
+			return
+		}
+		audit(d, r, "webhook.create", "webhook:"+sub.ID, map[string]any{"url": sub.URL, "events": sub.Events})
+		pkghttpx.WriteJSON(w, http.StatusCreated, sub)
+	}
+}
+
+// AdminWebhookDelete handles DELETE /admin/webhooks/{id}; its delivery log goes too.
+func AdminWebhookDelete(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if err := d.Repo.Webhooks.DeleteSubscription(r.Context(), id); err != nil {
+			if errors.Is(err, repos.ErrWebhookNotFound) {
+				pkghttpx.WriteError(w, r, pkghttpx.NotFound("webhook not found", err))
+				return
+			}
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to delete webhook", err))
+			return
+		}
+		audit(d, r, "webhook.delete", "webhook:"+id, nil)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// AdminWebhookDeliveries handles GET /admin/webhooks/{id}/deliveries, the
+// delivery log: newest first, optionally filtered by ?status=, paged with
+// ?before= like the audit log.
+func AdminWebhookDeliveries(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, err := adminLimit(r)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest(err.Error(), err))
+			return
+		}
+		q := r.URL.Query()
+		var status *string
+		if v := q.Get("status"); v != "" {
+			if !slices.Contains([]string{model.DeliveryPending, model.DeliveryDelivered, model.DeliveryDead}, v) {
+				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("status must be pending, delivered or dead", nil))
+				return
+			}
+			status = &v
+		}
+		var before *int64
+		if v := q.Get("before"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid before", err))
+				return
+			}
+			before = &id
+		}
+		items, err := d.Repo.Webhooks.ListDeliveries(r.Context(), r.PathValue("id"), status, before, limit)
+		if err != nil {
+			if errors.Is(err, repos.ErrWebhookNotFound) {
+				pkghttpx.WriteError(w, r, pkghttpx.NotFound("webhook not found", err))
+				return
+			}
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to list deliveries", err))
+			return
+		}
+		resp := AdminWebhookDeliveriesResponse{Items: items, Count: len(items)}
+		if len(items) == int(limit) {
+			last := items[len(items)-1].ID
+			resp.NextBefore = &last
+		}
+		pkghttpx.WriteJSON(w, http.StatusOK, resp)
+	}
+}
+
+// AdminWebhookRetry handles POST /admin/webhooks/deliveries/{id}/retry: a dead
+// delivery is queued again with a fresh set of attempts.
+func AdminWebhookRetry(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid id", err))
+			return
+		}
+		if err := d.Repo.Webhooks.RetryDelivery(r.Context(), id); err != nil {
+			if errors.Is(err, repos.ErrWebhookNotFound) {
+				pkghttpx.WriteError(w, r, pkghttpx.NotFound("no dead delivery with this id", err))
+				return
+			}
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to retry delivery", err))
+			return
+		}
+		audit(d, r, "webhook.retry", "delivery:"+strconv.FormatInt(id, 10), nil)
+		pkghttpx.WriteJSON(w, http.StatusOK, AdminWebhookRetryResponse{ID: id, Status: model.DeliveryPending})
+	}
+}
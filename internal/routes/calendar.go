@@ -0,0 +1,150 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/listquery"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+	pkgical "cinekami-server/pkg/ical"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+// Calendar subscriptions: which events a variant carries.
+const (
+	calendarAll       = "all"
+	calendarReleases  = "releases"
+	calendarDeadlines = "deadlines"
+)
+
+const (
+	calendarPast    = 30 * 24 * time.Hour  // deadlines this long ago stay listed
+	calendarAhead   = 183 * 24 * time.Hour // releases this far ahead are listed
+	calendarRefresh = 12 * time.Hour       // suggested polling interval
+	calendarRemind  = 24 * time.Hour       // reminder before a voting deadline
+)
+
+// Calendar handles GET /calendar.ics: release dates and voting deadlines.
+func Calendar(d deps.ServerDeps) http.HandlerFunc { return serveCalendar(d, calendarAll) }
+
+// CalendarReleases handles GET /calendar/releases.ics: release dates only.
+func CalendarReleases(d deps.ServerDeps) http.HandlerFunc {
+	return serveCalendar(d, calendarReleases)
+}
+
+// CalendarDeadlines handles GET /calendar/deadlines.ics: voting deadlines only,
+// each with a reminder a day before.
+func CalendarDeadlines(d deps.ServerDeps) http.HandlerFunc {
+	return serveCalendar(d, calendarDeadlines)
+}
+
+// serveCalendar answers with an iCalendar of region's releases, optionally only
+// movies of the genres in ?genre=. Event UIDs depend on movie, region and kind
+// only, and SEQUENCE counts release date changes, so a client updates events
+// when the TMDb sync moves a date.
+func serveCalendar(d deps.ServerDeps, kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		region, herr := requestRegion(d, r)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		genres, err := listquery.ParseGenres(r.URL.Query().Get("genre"))
+		if err != nil {
+			pkghttpx.WriteError(w, r, queryError(err))
+			return
+		}
+		ids := make([]string, len(genres))
+		for i, g := range genres {
+			ids[i] = strconv.Itoa(int(g))
+		}
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(d.ActiveMoviesTTL.Seconds())))
+		w.Header().Set("Content-Disposition", `inline; filename="cinekami-`+strings.ToLower(region)+`.ics"`)
+		// Last-Modified is left out: events also leave the window as time passes,
+		// which no stored timestamp records. The ETag covers it.
+		cacheKey := "calendar:" + kind + ":" + region + ":" + strings.Join(ids, ",")
+		if cached, ok := d.Cache.Get(ctx, cacheKey); ok {
+			pkghttpx.WriteConditional(w, r, pkgical.ContentType, []byte(cached), time.Time{})
+			return
+		}
+
+		now := time.Now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		releases, err := d.Repo.Movies.CalendarReleases(ctx, region, today.Add(-repos.VotingWindow-calendarPast), today.Add(calendarAhead), genres)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to list releases", err))
+			return
+		}
+		cal := pkgical.Calendar{
+			ProdID:  "-//cinekami//calendar//EN",
+			Name:    calendarName(kind, region),
+			Refresh: calendarRefresh,
+		}
+		if len(genres) > 0 {
+			cal.Name += ", genres " + strings.Join(ids, ",")
+		}
+		for _, rel := range releases {
+			if kind != calendarDeadlines && !rel.ReleaseDate.Before(today.Add(-calendarPast)) {
+				cal.Events = append(cal.Events, releaseEvent(rel))
+			}
+			if kind != calendarReleases {
+				cal.Events = append(cal.Events, deadlineEvent(rel))
+			}
+		}
+		body := cal.Encode()
+		_ = d.Cache.Set(ctx, cacheKey, string(body), d.ActiveMoviesTTL)
+		pkghttpx.WriteConditional(w, r, pkgical.ContentType, body, time.Time{})
+	}
+}
+
+func calendarName(kind, region string) string {
+	switch kind {
+	case calendarReleases:
+		return "cinekami releases (" + region + ")"
+	case calendarDeadlines:
+		return "cinekami voting deadlines (" + region + ")"
+	default:
+		return "cinekami releases and voting deadlines (" + region + ")"
+	}
+}
+
+func releaseEvent(rel model.CalendarRelease) pkgical.Event {
+	return pkgical.Event{
+		UID:      fmt.Sprintf("release-%d-%s@cinekami", rel.MovieID, rel.Region),
+		Sequence: rel.Revision,
+		Stamp:    rel.UpdatedAt,
+		Summary:  fmt.Sprintf("%s in cinemas (%s)", rel.Title, rel.Region),
+		Description: fmt.Sprintf("%s is released in %s. Voting is open until %s.",
+			rel.Title, rel.Region, rel.ReleaseDate.Add(repos.VotingWindow).Format("2006-01-02 15:04 MST")),
+		URL:        pkgtmdb.MovieURL(rel.MovieID),
+		Categories: []string{"Release", rel.Region},
+		AllDay:     true,
+		Start:      rel.ReleaseDate,
+		End:        rel.ReleaseDate.AddDate(0, 0, 1),
+	}
+}
+
+// deadlineEvent is the last hour of voting on a movie in its region.
+func deadlineEvent(rel model.CalendarRelease) pkgical.Event {
+	closes := rel.ReleaseDate.Add(repos.VotingWindow)
+	return pkgical.Event{
+		UID:         fmt.Sprintf("vote-close-%d-%s@cinekami", rel.MovieID, rel.Region),
+		Sequence:    rel.Revision,
+		Stamp:       rel.UpdatedAt,
+		Summary:     fmt.Sprintf("Last call to vote: %s (%s)", rel.Title, rel.Region),
+		Description: fmt.Sprintf("Voting on %s in %s closes at %s.", rel.Title, rel.Region, closes.Format("2006-01-02 15:04 MST")),
+		URL:         pkgtmdb.MovieURL(rel.MovieID),
+		Categories:  []string{"Voting deadline", rel.Region},
+		Start:       closes.Add(-time.Hour),
+		End:         closes,
+		Alarm:       calendarRemind,
+	}
+}
@@ -0,0 +1,262 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkgcard "cinekami-server/pkg/card"
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// Share cards are served outside /v1 at fixed URLs for link previews: a PNG
+// and an HTML page with its Open Graph tags per movie and closed month, and
+// for movies a widget to embed. All take the region parameter and answer
+// conditional GETs. They are cached under cards:, which vote and admin changes
+// invalidate.
+const (
+	cardBrand    = "cinekami"
+	embedRefresh = time.Minute // how often an embedded widget reloads
+)
+
+// cardCategories are the categories in the order cards draw them.
+var cardCategories = []struct{ key, label string }{
+	{model.CategorySoloFriends, "Solo / friends"},
+	{model.CategoryCouple, "Couple"},
+	{model.CategoryStreaming, "Streaming"},
+	{model.CategoryArr, "Arr"},
+}
+
+// cachedCard is a rendered card or page as kept in the response cache.
+type cachedCard struct {
+	Updated time.Time `json:"updated"`
+	Body    []byte    `json:"body"`
+}
+
+// shareCard is what the image, page and widget of a share are made from.
+type shareCard struct {
+	card    pkgcard.Card
+	meta    pkgcard.Meta
+	updated time.Time // zero when unknown
+}
+
+// cardBuilder resolves the request's path values to the share's cache key and
+// page, relative to the public URL, without querying; load builds the share and
+// runs on cache misses only.
+type cardBuilder func(r *http.Request, region string) (key, path string, load cardLoader, herr *pkghttpx.HTTPError)
+
+type cardLoader func(ctx context.Context, d deps.ServerDeps) (shareCard, *pkghttpx.HTTPError)
+
+// MovieCardPNG handles GET /movies/{id}/card.png: the movie's current tallies.
+func MovieCardPNG(d deps.ServerDeps) http.HandlerFunc {
+	return serveCard(d, "png", movieCard, d.ActiveMoviesTTL)
+}
+
+// MovieCardPage handles GET /movies/{id}, the movie's share page.
+func MovieCardPage(d deps.ServerDeps) http.HandlerFunc {
+	return serveCard(d, "html", movieCard, d.ActiveMoviesTTL)
+}
+
+// EmbedMovie handles GET /embed/movies/{id}: the movie's votes per category as
+// an HTML widget for iframes, reloading every minute.
+func EmbedMovie(d deps.ServerDeps) http.HandlerFunc {
+	return serveCard(d, "embed", movieCard, d.ActiveMoviesTTL)
+}
+
+// SnapshotCardPNG handles GET /snapshots/{year}/{month}/card.png: the votes of
+// a closed month per category with each category's winner.
+func SnapshotCardPNG(d deps.ServerDeps) http.HandlerFunc {
+	return serveCard(d, "png", snapshotCard, d.SnapshotsTTL)
+}
+
+// SnapshotCardPage handles GET /snapshots/{year}/{month}, the month's share page.
+func SnapshotCardPage(d deps.ServerDeps) http.HandlerFunc {
+	return serveCard(d, "html", snapshotCard, d.SnapshotsTTL)
+}
+
+func serveCard(d deps.ServerDeps, format string, build cardBuilder, ttl time.Duration) http.HandlerFunc {
+	contentType := pkgcard.HTMLContentType
+	if format == "png" {
+		contentType = pkgcard.PNGContentType
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		region, herr := requestRegion(d, r)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		key, path, load, herr := build(r, region)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		base := publicURL(d, r)
+		if format != "png" {
+			key += ":" + base // for the absolute links
+		}
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(ttl.Seconds())))
+		cacheKey := "cards:" + key + ":" + format
+		if cached, ok := d.Cache.Get(ctx, cacheKey); ok {
+			var c cachedCard
+			if json.Unmarshal([]byte(cached), &c) == nil {
+				pkghttpx.WriteConditional(w, r, contentType, c.Body, c.Updated)
+				return
+			}
+		}
+		s, herr := load(ctx, d)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+
+		var (
+			body []byte
+			err  error
+		)
+		query := "?region=" + region
+		switch format {
+		case "html":
+			s.meta.SiteName = cardBrand
+			s.meta.URL = base + path + query
+			s.meta.Image = base + path + "/card.png" + query
+			s.meta.OEmbed = base + "/oembed?url=" + url.QueryEscape(s.meta.URL)
+			body, err = pkgcard.Page(s.meta)
+		case "embed":
+			body, err = pkgcard.Widget(s.card, base+path+query, embedRefresh)
+		default:
+			body, err = pkgcard.Render(s.card)
+		}
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to render card", err))
+			return
+		}
+		b, _ := json.Marshal(cachedCard{Updated: s.updated, Body: body})
+		_ = d.Cache.Set(ctx, cacheKey, string(b), ttl)
+		pkghttpx.WriteConditional(w, r, contentType, body, s.updated)
+	}
+}
+
+func movieCard(r *http.Request, region string) (string, string, cardLoader, *pkghttpx.HTTPError) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return "", "", nil, pkghttpx.BadRequest("invalid ID", err)
+	}
+	load := func(ctx context.Context, d deps.ServerDeps) (shareCard, *pkghttpx.HTTPError) {
+		return loadMovieCard(ctx, d, id, region)
+	}
+	return "movies:" + region + ":" + strconv.FormatInt(id, 10), "/movies/" + strconv.FormatInt(id, 10), load, nil
+}
+
+func loadMovieCard(ctx context.Context, d deps.ServerDeps, id int64, region string) (shareCard, *pkghttpx.HTTPError) {
+	m, err := d.Repo.Movies.Card(ctx, id, region)
+	if errors.Is(err, repos.ErrMovieNotFound) {
+		return shareCard{}, pkghttpx.NotFound("movie not found", err)
+	}
+	if err != nil {
+		return shareCard{}, pkghttpx.Internal("failed to load movie", err)
+	}
+	rows, err := d.Repo.Tallies.GetTalliesAllCategories(ctx, id, region)
+	if err != nil {
+		return shareCard{}, pkghttpx.Internal("failed to get tallies", err)
+	}
+	tallies := make(map[string]int64, len(rows))
+	for _, t := range rows {
+		tallies[t.Category] = t.Count
+	}
+	stats := model.NewTallyStats(tallies)
+	s := shareCard{card: pkgcard.Card{
+		Title:    m.Title,
+		Subtitle: fmt.Sprintf("Released %s in %s · %s", m.ReleaseDate.Format("2 January 2006"), region, votesText(stats.TotalVotes)),
+		Brand:    cardBrand,
+		Footer:   "No votes yet",
+	}}
+	for _, c := range cardCategories {
+		leading := stats.Leading != nil && stats.Leading.Category == c.key
+		s.card.Bars = append(s.card.Bars, pkgcard.Bar{Label: c.label, Value: tallies[c.key], Leading: leading})
+		if leading {
+			s.card.Footer = "Leading: " + c.label
+		}
+	}
+	s.meta = pkgcard.Meta{
+		Title:       m.Title + " on " + cardBrand,
+		Description: s.card.Subtitle + ". " + s.card.Footer + ".",
+		ImageAlt:    "Votes per category for " + m.Title,
+	}
+	return s, nil
+}
+
+func snapshotCard(r *http.Request, region string) (string, string, cardLoader, *pkghttpx.HTTPError) {
+	year, yerr := strconv.Atoi(r.PathValue("year"))
+	month, merr := strconv.Atoi(r.PathValue("month"))
+	if yerr != nil || merr != nil || month < 1 || month > 12 {
+		return "", "", nil, pkghttpx.BadRequest("invalid year/month", nil)
+	}
+	load := func(ctx context.Context, d deps.ServerDeps) (shareCard, *pkghttpx.HTTPError) {
+		return loadSnapshotCard(ctx, d, year, month, region)
+	}
+	return fmt.Sprintf("snapshots:%s:%04d-%02d", region, year, month), fmt.Sprintf("/snapshots/%d/%d", year, month), load, nil
+}
+
+func loadSnapshotCard(ctx context.Context, d deps.ServerDeps, year, month int, region string) (shareCard, *pkghttpx.HTTPError) {
+	mon := fmt.Sprintf("%04d-%02d", year, month)
+	res, err := d.Repo.Snapshots.MonthResult(ctx, region, mon)
+	if errors.Is(err, repos.ErrMonthNotArchived) {
+		return shareCard{}, pkghttpx.NotFound("month not archived", err)
+	}
+	if err != nil {
+		return shareCard{}, pkghttpx.Internal("failed to load results", err)
+	}
+	winners := make(map[string]model.CategoryWinner, len(res.Winners))
+	for _, w := range res.Winners {
+		winners[w.Category] = w
+	}
+	stats := model.NewTallyStats(res.Totals)
+	title := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).Format("January 2006") + " results"
+	s := shareCard{card: pkgcard.Card{
+		Title:    title,
+		Subtitle: fmt.Sprintf("%s · %d movies · %s", region, res.Movies, votesText(stats.TotalVotes)),
+		Brand:    cardBrand,
+		Footer:   "No votes were cast",
+	}, updated: res.ClosedAt}
+	var lines []string
+	for _, c := range cardCategories {
+		leading := stats.Leading != nil && stats.Leading.Category == c.key
+		b := pkgcard.Bar{Label: c.label, Value: res.Totals[c.key], Leading: leading}
+		if w, ok := winners[c.key]; ok {
+			b.Detail = w.Title
+			lines = append(lines, c.label+": "+w.Title)
+		}
+		s.card.Bars = append(s.card.Bars, b)
+		if leading {
+			s.card.Footer = "Most voted: " + c.label
+		}
+	}
+	description := s.card.Subtitle + "."
+	if len(lines) > 0 {
+		description += " " + strings.Join(lines, ", ") + "."
+	}
+	s.meta = pkgcard.Meta{
+		Title:       title + " (" + region + ") on " + cardBrand,
+		Description: description,
+		ImageAlt:    "Votes per category and winners of " + title,
+	}
+	return s, nil
+}
+
+func votesText(n int64) string {
+	if n == 1 {
+		return "1 vote"
+	}
+	return strconv.FormatInt(n, 10) + " votes"
+}
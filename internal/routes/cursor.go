@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"errors"
+
+	"cinekami-server/internal/listquery"
+
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// cursorScope binds a cursor to the listing, region, language, month, sort and
+// filters that produced it, so it cannot be replayed against a different query.
+// The language matters because titles sort differently once translated.
+func cursorScope(kind, region, lang, month string, q listquery.Query) string {
+	return pkgcrypto.Scope(kind, append([]string{region, lang, month}, q.Canonical()...)...)
+}
+
+// cursorError maps a codec failure to a 400 with code invalid_cursor; the message
+// says why (expired, other query, ...) so clients know to restart from page one.
+func cursorError(err error) *pkghttpx.HTTPError {
+	msg := "invalid cursor"
+	if errors.Is(err, pkgcrypto.ErrInvalidCursor) {
+		msg = err.Error()
+	}
+	return pkghttpx.InvalidCursor(msg, err)
+}
@@ -0,0 +1,194 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkgfeed "cinekami-server/pkg/feed"
+	pkghttpx "cinekami-server/pkg/httpx"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+// Feeds are served outside /v1 at fixed URLs for feed readers and bots. Each
+// takes the region parameter and answers conditional GETs.
+const (
+	feedMonths       = 24 // closed months in the results feeds
+	feedMovies       = 50 // movies in the votable feed
+	feedAuthor       = "cinekami"
+	feedExtensionKey = "_cinekami" // JSON Feed extension carrying the raw results
+)
+
+// cachedFeed is a rendered feed as kept in the response cache.
+type cachedFeed struct {
+	Updated time.Time `json:"updated"`
+	Body    string    `json:"body"`
+}
+
+type feedBuilder func(ctx context.Context, d deps.ServerDeps, region, base string) (pkgfeed.Feed, error)
+
+// FeedSnapshotsAtom handles GET /feeds/snapshots.atom: one entry per closed month.
+func FeedSnapshotsAtom(d deps.ServerDeps) http.HandlerFunc {
+	return serveFeed(d, "snapshots", "atom", snapshotsFeed)
+}
+
+// FeedSnapshotsJSON handles GET /feeds/snapshots.json, the JSON Feed 1.1
+// version of the results feed; items carry the winners under _cinekami.
+func FeedSnapshotsJSON(d deps.ServerDeps) http.HandlerFunc {
+	return serveFeed(d, "snapshots", "json", snapshotsFeed)
+}
+
+// FeedActiveAtom handles GET /feeds/active.atom: movies as they become votable.
+func FeedActiveAtom(d deps.ServerDeps) http.HandlerFunc {
+	return serveFeed(d, "active", "atom", activeFeed)
+}
+
+func serveFeed(d deps.ServerDeps, name, format string, build feedBuilder) http.HandlerFunc {
+	contentType := pkgfeed.AtomContentType
+	if format == "json" {
+		contentType = pkgfeed.JSONContentType
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		region, herr := requestRegion(d, r)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		base := publicURL(d, r)
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(d.ActiveMoviesTTL.Seconds())))
+		cacheKey := "feeds:" + name + ":" + format + ":" + region + ":" + base
+		if cached, ok := d.Cache.Get(ctx, cacheKey); ok {
+			var c cachedFeed
+			if json.Unmarshal([]byte(cached), &c) == nil {
+				pkghttpx.WriteConditional(w, r, contentType, []byte(c.Body), c.Updated)
+				return
+			}
+		}
+
+		f, err := build(ctx, d, region, base)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to build feed", err))
+			return
+		}
+		f.Self = base + r.URL.Path
+		if r.URL.Query().Get("region") != "" {
+			f.Self += "?region=" + region
+		}
+		var body []byte
+		if format == "json" {
+			body, err = f.JSON()
+		} else {
+			body, err = f.Atom()
+		}
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to render feed", err))
+			return
+		}
+		b, _ := json.Marshal(cachedFeed{Updated: f.Updated, Body: string(body)})
+		_ = d.Cache.Set(ctx, cacheKey, string(b), d.ActiveMoviesTTL)
+		pkghttpx.WriteConditional(w, r, contentType, body, f.Updated)
+	}
+}
+
+func snapshotsFeed(ctx context.Context, d deps.ServerDeps, region, base string) (pkgfeed.Feed, error) {
+	results, err := d.Repo.Snapshots.MonthlyResults(ctx, region, feedMonths)
+	if err != nil {
+		return pkgfeed.Feed{}, err
+	}
+	f := pkgfeed.Feed{
+		ID:           "urn:cinekami:feeds:snapshots:" + region,
+		Title:        "cinekami monthly results (" + region + ")",
+		Description:  "The most voted movie of every category once a month's voting closes.",
+		Link:         base + "/v1/snapshots/available?region=" + region,
+		Author:       feedAuthor,
+		Updated:      d.StartedAt,
+		ExtensionKey: feedExtensionKey,
+	}
+	for i, res := range results {
+		t, _ := time.Parse("2006-01", res.Month)
+		if i == 0 || res.ClosedAt.After(f.Updated) {
+			f.Updated = res.ClosedAt
+		}
+		f.Entries = append(f.Entries, pkgfeed.Entry{
+			ID:        "urn:cinekami:snapshots:" + region + ":" + res.Month,
+			Title:     fmt.Sprintf("%s %d results (%s)", t.Month(), t.Year(), region),
+			Link:      fmt.Sprintf("%s/v1/snapshots/%d/%d?region=%s", base, t.Year(), int(t.Month()), region),
+			Summary:   resultSummary(res),
+			Published: res.ClosedAt,
+			Updated:   res.ClosedAt,
+			Extension: res,
+		})
+	}
+	return f, nil
+}
+
+func resultSummary(res model.MonthResult) string {
+	if len(res.Winners) == 0 {
+		return fmt.Sprintf("No votes were cast among the %d movies of %s.", res.Movies, res.Month)
+	}
+	lines := make([]string, 0, len(res.Winners))
+	for _, w := range res.Winners {
+		lines = append(lines, fmt.Sprintf("%s: %s (%d votes)", w.Category, w.Title, w.Votes))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func activeFeed(ctx context.Context, d deps.ServerDeps, region, base string) (pkgfeed.Feed, error) {
+	movies, err := d.Repo.Movies.RecentlyVotable(ctx, region, time.Now().UTC(), feedMovies)
+	if err != nil {
+		return pkgfeed.Feed{}, err
+	}
+	f := pkgfeed.Feed{
+		ID:          "urn:cinekami:feeds:active:" + region,
+		Title:       "Now votable on cinekami (" + region + ")",
+		Description: "Movies released this month, announced when voting on them opens.",
+		Link:        base + "/v1/movies/active?region=" + region,
+		Author:      feedAuthor,
+		Updated:     d.StartedAt,
+	}
+	for i, m := range movies {
+		if i == 0 || m.VotableSince.After(f.Updated) {
+			f.Updated = m.VotableSince
+		}
+		summary := fmt.Sprintf("Released %s in %s; voting closes %s.", m.ReleaseDate.Format("2006-01-02"), region,
+			m.ReleaseDate.Add(repos.VotingWindow).Format("2006-01-02"))
+		if m.Overview != nil && *m.Overview != "" {
+			summary += "\n\n" + *m.Overview
+		}
+		e := pkgfeed.Entry{
+			ID:        fmt.Sprintf("urn:cinekami:movies:%s:%d", region, m.ID),
+			Title:     m.Title,
+			Link:      pkgtmdb.MovieURL(m.ID),
+			Summary:   summary,
+			Published: m.VotableSince,
+			Updated:   m.VotableSince,
+		}
+		if m.PosterPath != nil && *m.PosterPath != "" {
+			e.Image = pkgtmdb.ImageURL(*m.PosterPath, "w500")
+		}
+		f.Entries = append(f.Entries, e)
+	}
+	return f, nil
+}
+
+// publicURL returns the configured public base URL or, without one, the
+// scheme and host the request was made to.
+func publicURL(d deps.ServerDeps, r *http.Request) string {
+	if d.PublicURL != "" {
+		return strings.TrimSuffix(d.PublicURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
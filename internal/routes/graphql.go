@@ -0,0 +1,286 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/rs/zerolog/log"
+
+	"cinekami-server/internal/deps"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+	pkgrequestctx "cinekami-server/pkg/requestctx"
+)
+
+// graphQLParams is a GraphQL request: from the JSON body of a POST, the body
+// of an application/graphql POST or the query string of a GET.
+type graphQLParams struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// GraphQL handles GET and POST /graphql: movies, tallies and snapshots in one
+// round trip, and the vote mutation (POST only). The caller is identified by
+// X-Fingerprint and the language negotiated as on the REST listings. Requests
+// that do not parse, validate or stay within the depth and complexity limits
+// fail with 400 before anything runs; errors while running come back with 200
+// next to the data that did resolve. Every error carries the REST error code
+// in extensions.code.
+func GraphQL(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		schema, err := gqlSchema()
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to build GraphQL schema", err))
+			return
+		}
+		params, herr := parseGraphQLParams(r)
+		if herr != nil {
+			writeGraphQLError(w, r, herr)
+			return
+		}
+		lang, herr := requestLanguage(d, r)
+		if herr != nil {
+			writeGraphQLError(w, r, herr)
+			return
+		}
+		doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(params.Query), Name: "GraphQL request"})})
+		if err != nil {
+			writeGraphQLErrors(w, r, http.StatusBadRequest, gqlerrors.FormatErrors(err))
+			return
+		}
+		if v := graphql.ValidateDocument(&schema, doc, nil); !v.IsValid {
+			writeGraphQLErrors(w, r, http.StatusBadRequest, v.Errors)
+			return
+		}
+		op := graphQLOperation(doc, params.OperationName)
+		if op != nil && op.Operation == ast.OperationTypeMutation && r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeGraphQLError(w, r, &pkghttpx.HTTPError{StatusCode: http.StatusMethodNotAllowed, Message: "mutations require POST", Code: "method_not_allowed"})
+			return
+		}
+		if herr := checkQueryCost(doc, op, params.Variables, d); herr != nil {
+			writeGraphQLError(w, r, herr)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), gqlRequestKey{}, &gqlRequest{d: d, fingerprint: requestFingerprint(d, r), language: lang, tallies: newTallyLoader(d)})
+		res := graphql.Execute(graphql.ExecuteParams{Schema: schema, AST: doc, OperationName: params.OperationName, Args: params.Variables, Context: ctx})
+		for i, fe := range res.Errors {
+			res.Errors[i] = graphQLError(r, fe)
+		}
+		setLanguageHeaders(w, lang)
+		w.Header().Set("Cache-Control", "no-store")
+		pkghttpx.WriteJSON(w, http.StatusOK, res)
+	}
+}
+
+func parseGraphQLParams(r *http.Request) (graphQLParams, *pkghttpx.HTTPError) {
+	var p graphQLParams
+	if r.Method == http.MethodGet {
+		v := r.URL.Query()
+		p.Query, p.OperationName = v.Get("query"), v.Get("operationName")
+		if s := v.Get("variables"); s != "" {
+			if err := json.Unmarshal([]byte(s), &p.Variables); err != nil {
+				return p, pkghttpx.BadRequest("variables must be a JSON object", err)
+			}
+		}
+	} else {
+		ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		var err error
+		switch ct {
+		case "application/json", "":
+			err = json.NewDecoder(r.Body).Decode(&p)
+		case "application/graphql":
+			var b []byte
+			b, err = io.ReadAll(r.Body)
+			p.Query = string(b)
+		default:
+			return p, pkghttpx.UnsupportedMediaType("send application/json or application/graphql", nil)
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return p, pkghttpx.PayloadTooLarge("request body too large", err)
+		}
+		if err != nil {
+			return p, pkghttpx.BadRequest("invalid json", err)
+		}
+	}
+	if strings.TrimSpace(p.Query) == "" {
+		return p, pkghttpx.BadRequest("query is required", nil)
+	}
+	return p, nil
+}
+
+// graphQLOperation returns the operation to run, or nil when name does not
+// pick exactly one; Execute reports that.
+func graphQLOperation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok || (name != "" && (op.Name == nil || op.Name.Value != name)) {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = op
+	}
+	return found
+}
+
+// queryCost measures an operation: its depth is the deepest nesting of fields
+// and its complexity one per field, with the selections of a connection
+// counted once per item of the page it asks for. Introspection is free.
+type queryCost struct {
+	fragments   map[string]*ast.FragmentDefinition
+	variables   map[string]any
+	defaultPage int
+	maxPage     int
+}
+
+func checkQueryCost(doc *ast.Document, op *ast.OperationDefinition, variables map[string]any, d deps.ServerDeps) *pkghttpx.HTTPError {
+	if op == nil {
+		return nil
+	}
+	c := queryCost{fragments: map[string]*ast.FragmentDefinition{}, variables: variables, defaultPage: d.DefaultPageSize, maxPage: d.MaxPageSize}
+	for _, def := range doc.Definitions {
+		if f, ok := def.(*ast.FragmentDefinition); ok {
+			c.fragments[f.Name.Value] = f
+		}
+	}
+	depth, complexity := c.selections(op.SelectionSet)
+	if d.GraphQLMaxDepth > 0 && depth > d.GraphQLMaxDepth {
+		he := pkghttpx.BadRequest(fmt.Sprintf("query depth %d exceeds the limit of %d", depth, d.GraphQLMaxDepth), nil)
+		he.Code = "query_too_deep"
+		return he
+	}
+	if d.GraphQLMaxComplexity > 0 && complexity > d.GraphQLMaxComplexity {
+		he := pkghttpx.BadRequest(fmt.Sprintf("query complexity %d exceeds the limit of %d", complexity, d.GraphQLMaxComplexity), nil)
+		he.Code = "query_too_complex"
+		return he
+	}
+	return nil
+}
+
+func (c queryCost) selections(set *ast.SelectionSet) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, sel := range set.Selections {
+		var d, n int
+		switch s := sel.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			d, n = c.selections(s.SelectionSet)
+			d, n = d+1, 1+c.pageSize(s)*n
+		case *ast.InlineFragment:
+			d, n = c.selections(s.SelectionSet)
+		case *ast.FragmentSpread:
+			if f := c.fragments[s.Name.Value]; f != nil {
+				d, n = c.selections(f.SelectionSet)
+			}
+		}
+		depth, complexity = max(depth, d), complexity+n
+	}
+	return depth, complexity
+}
+
+// pageSize is how many items a connection field returns at most: its first
+// argument, bounded like the REST limit, or the default page size. Other
+// fields count once.
+func (c queryCost) pageSize(f *ast.Field) int {
+	if !gqlConnections[f.Name.Value] {
+		return 1
+	}
+	n := c.defaultPage
+	for _, a := range f.Arguments {
+		if a.Name.Value != "first" {
+			continue
+		}
+		switch v := a.Value.(type) {
+		case *ast.IntValue:
+			n, _ = strconv.Atoi(v.Value)
+		case *ast.Variable:
+			if x, ok := c.variables[v.Name.Value].(float64); ok {
+				n = int(x)
+			}
+		}
+	}
+	return min(max(n, 1), max(c.maxPage, 1))
+}
+
+// graphQLError gives a resolver's HTTPError its message, code and details in
+// the errors array instead of the wrapped cause. Internal errors are logged with
+// the correlation id as pkghttpx.WriteError does; only their message is sent.
+func graphQLError(r *http.Request, fe gqlerrors.FormattedError) gqlerrors.FormattedError {
+	var he *pkghttpx.HTTPError
+	if !errors.As(graphQLCause(fe), &he) {
+		return fe
+	}
+	fe.Message = he.Message
+	fe.Extensions = map[string]any{"code": he.Code}
+	for k, v := range he.Details {
+		fe.Extensions[k] = v
+	}
+	if he.StatusCode >= http.StatusInternalServerError {
+		log.Error().Str("correlation_id", pkgrequestctx.CorrelationID(r.Context())).Str("trace_id", pkgrequestctx.TraceID(r.Context())).
+			Str("code", he.Code).Err(he.Err).Msg(he.Message)
+	}
+	return fe
+}
+
+// graphQLCause unwraps the error a resolver or thunk returned from the layers
+// graphql-go puts around it, which do not implement Unwrap.
+func graphQLCause(err error) error {
+	for {
+		switch e := err.(type) {
+		case gqlerrors.FormattedError:
+			if e.OriginalError() == nil {
+				return err
+			}
+			err = e.OriginalError()
+		case *gqlerrors.Error:
+			if e.OriginalError == nil {
+				return err
+			}
+			err = e.OriginalError
+		default:
+			return err
+		}
+	}
+}
+
+func writeGraphQLError(w http.ResponseWriter, r *http.Request, he *pkghttpx.HTTPError) {
+	writeGraphQLErrors(w, r, he.StatusCode, []gqlerrors.FormattedError{graphQLError(r, gqlerrors.FormatError(he))})
+}
+
+// writeGraphQLErrors answers a request that did not run with the GraphQL
+// response shape, so clients read errors the same way either way.
+func writeGraphQLErrors(w http.ResponseWriter, r *http.Request, status int, errs []gqlerrors.FormattedError) {
+	for i, fe := range errs {
+		if fe.Extensions == nil {
+			errs[i].Extensions = map[string]any{"code": "bad_request"}
+		}
+	}
+	if cid := pkgrequestctx.CorrelationID(r.Context()); cid != "" {
+		w.Header().Set("X-Correlation-Id", cid)
+	}
+	pkghttpx.WriteJSON(w, status, struct {
+		Errors []gqlerrors.FormattedError `json:"errors"`
+	}{errs})
+}
@@ -0,0 +1,563 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// gqlRequest is what resolvers need from the HTTP request: the dependencies,
+// the caller's fingerprint digest and language, and the loader that batches
+// tallies across the whole query.
+type gqlRequest struct {
+	d           deps.ServerDeps
+	fingerprint string
+	language    string
+	tallies     *tallyLoader
+}
+
+type gqlRequestKey struct{}
+
+func gqlFrom(ctx context.Context) *gqlRequest {
+	return ctx.Value(gqlRequestKey{}).(*gqlRequest)
+}
+
+// tallyLoader batches the tallies Movie fields ask for. Resolvers queue their
+// movie and return a thunk; graphql-go runs the thunks of a level only after
+// every field of it was resolved, so the first thunk loads all queued movies of
+// its region with one GetTalliesForMovies instead of a query per movie.
+type tallyLoader struct {
+	d      deps.ServerDeps
+	mu     sync.Mutex
+	queued map[string][]int64                    // region -> movie ids
+	loaded map[string]map[int64]map[string]int64 // region -> movie id -> counts
+	failed map[string]error                      // region -> error of its last batch
+}
+
+func newTallyLoader(d deps.ServerDeps) *tallyLoader {
+	return &tallyLoader{d: d, queued: map[string][]int64{}, loaded: map[string]map[int64]map[string]int64{}, failed: map[string]error{}}
+}
+
+// load queues movie id of region and returns a thunk for its counts.
+func (l *tallyLoader) load(ctx context.Context, region string, id int64) func() (map[string]int64, error) {
+	l.mu.Lock()
+	if _, ok := l.loaded[region][id]; !ok && !slices.Contains(l.queued[region], id) {
+		l.queued[region] = append(l.queued[region], id)
+	}
+	l.mu.Unlock()
+	return func() (map[string]int64, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if ids := l.queued[region]; len(ids) > 0 {
+			delete(l.queued, region)
+			rows, err := l.d.Repo.Tallies.GetTalliesForMovies(ctx, ids, region)
+			if err != nil {
+				l.failed[region] = pkghttpx.Internal("failed to get tallies", err)
+			} else {
+				if l.loaded[region] == nil {
+					l.loaded[region] = make(map[int64]map[string]int64, len(ids))
+				}
+				for _, id := range ids {
+					l.loaded[region][id] = rows[id]
+				}
+			}
+		}
+		if counts, ok := l.loaded[region][id]; ok {
+			return counts, nil
+		}
+		return nil, l.failed[region]
+	}
+}
+
+// gqlMovie is a movie on the board of region. Its Tallies are set when the
+// listing that produced it carried them and are loaded otherwise.
+type gqlMovie struct {
+	model.Movie
+	region string
+}
+
+// gqlSnapshot is a movie's archived result for a month.
+type gqlSnapshot struct {
+	model.Snapshot
+}
+
+// gqlTally is one category of a movie's tallies with its share of the votes.
+type gqlTally struct {
+	Category  string
+	Count     int64
+	Share     float64
+	ShareLow  float64
+	ShareHigh float64
+}
+
+// gqlConnection is a page of a listing. Nodes are gqlMovie or gqlSnapshot.
+type gqlConnection struct {
+	Nodes    []any
+	PageInfo gqlPageInfo
+	count    func(context.Context) (int64, error)
+}
+
+// gqlPageInfo carries the listing's signed cursors: EndCursor is next_cursor,
+// to pass as after, and StartCursor prev_cursor, to pass as before.
+type gqlPageInfo struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	StartCursor     *string
+	EndCursor       *string
+}
+
+// gqlVote is the outcome of the vote mutation.
+type gqlVote struct {
+	Inserted      bool
+	VotedCategory string // "" when unknown
+	counts        map[string]int64
+}
+
+// gqlConnections are the fields that return a page; the query complexity of
+// their selections is multiplied by the page size.
+var gqlConnections = map[string]bool{"activeMovies": true, "snapshots": true}
+
+// gqlSchema is built once; resolvers reach the dependencies through the
+// gqlRequest in their context.
+var gqlSchema = sync.OnceValues(func() (graphql.Schema, error) {
+	categoryValues := graphql.EnumValueConfigMap{}
+	for _, c := range cardCategories {
+		categoryValues[strings.ToUpper(c.key)] = &graphql.EnumValueConfig{Value: c.key, Description: c.label}
+	}
+	category := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "Category",
+		Description: "Where a movie is best watched; what votes are cast for.",
+		Values:      categoryValues,
+	})
+
+	tally := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Tally",
+		Description: "Votes for one category with its share of all votes and the share's 95% Wilson interval.",
+		Fields: graphql.Fields{
+			"category":  {Type: graphql.NewNonNull(category)},
+			"count":     {Type: graphql.NewNonNull(graphql.Int)},
+			"share":     {Type: graphql.NewNonNull(graphql.Float), Description: "0-1; 0 without votes"},
+			"shareLow":  {Type: graphql.NewNonNull(graphql.Float)},
+			"shareHigh": {Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+	leading := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Leading",
+		Description: "The category with the most votes; confident when its interval lies above the runner-up's.",
+		Fields: graphql.Fields{
+			"category":  {Type: graphql.NewNonNull(category)},
+			"confident": {Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+	// tallyFields are the fields derived from a set of counts, which counts
+	// returns as a thunk so that loaded ones are batched.
+	tallyFields := func(fields graphql.Fields, counts func(graphql.ResolveParams) func() (map[string]int64, error)) graphql.Fields {
+		resolve := func(f func(map[string]int64) any) graphql.FieldResolveFn {
+			return func(p graphql.ResolveParams) (any, error) {
+				load := counts(p)
+				return func() (any, error) {
+					c, err := load()
+					if err != nil {
+						return nil, err
+					}
+					return f(c), nil
+				}, nil
+			}
+		}
+		fields["tallies"] = &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tally))),
+			Description: "Every category, zero counts included, in a fixed order.",
+			Resolve: resolve(func(c map[string]int64) any {
+				stats := model.NewTallyStats(c)
+				out := make([]gqlTally, 0, len(cardCategories))
+				for _, cat := range cardCategories {
+					sh := stats.Shares[cat.key]
+					out = append(out, gqlTally{Category: cat.key, Count: c[cat.key], Share: sh.Share, ShareLow: sh.Low, ShareHigh: sh.High})
+				}
+				return out
+			}),
+		}
+		fields["totalVotes"] = &graphql.Field{
+			Type:    graphql.NewNonNull(graphql.Int),
+			Resolve: resolve(func(c map[string]int64) any { return model.NewTallyStats(c).TotalVotes }),
+		}
+		fields["leading"] = &graphql.Field{
+			Type:        leading,
+			Description: "Null without votes.",
+			Resolve: resolve(func(c map[string]int64) any {
+				if l := model.NewTallyStats(c).Leading; l != nil {
+					return *l
+				}
+				return nil
+			}),
+		}
+		return fields
+	}
+
+	movieField := func(t graphql.Output, f func(model.Movie) any) *graphql.Field {
+		return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (any, error) {
+			return f(p.Source.(gqlMovie).Movie), nil
+		}}
+	}
+	movie := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Movie",
+		Description: "A movie on a region's board, with its current tallies there.",
+		Fields: tallyFields(graphql.Fields{
+			"id":           movieField(graphql.NewNonNull(graphql.ID), func(m model.Movie) any { return m.ID }),
+			"title":        movieField(graphql.NewNonNull(graphql.String), func(m model.Movie) any { return m.Title }),
+			"releaseDate":  movieField(graphql.NewNonNull(graphql.DateTime), func(m model.Movie) any { return m.ReleaseDate }),
+			"overview":     movieField(graphql.String, func(m model.Movie) any { return m.Overview }),
+			"posterPath":   movieField(graphql.String, func(m model.Movie) any { return m.PosterPath }),
+			"backdropPath": movieField(graphql.String, func(m model.Movie) any { return m.BackdropPath }),
+			"popularity":   movieField(graphql.NewNonNull(graphql.Float), func(m model.Movie) any { return m.Popularity }),
+			"imdbUrl":      movieField(graphql.String, func(m model.Movie) any { return m.ImdbURL }),
+			"cinemagiaUrl": movieField(graphql.String, func(m model.Movie) any { return m.CinemagiaURL }),
+			"genreIds": movieField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.Int))), func(m model.Movie) any {
+				if m.GenreIDs == nil {
+					return []int32{}
+				}
+				return m.GenreIDs
+			}),
+			"votedCategory": {
+				Type:        category,
+				Description: "The caller's vote, by X-Fingerprint; only set on activeMovies.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if v := p.Source.(gqlMovie).VotedCategory; v != nil && *v != "" {
+						return *v, nil
+					}
+					return nil, nil
+				},
+			},
+		}, func(p graphql.ResolveParams) func() (map[string]int64, error) {
+			m := p.Source.(gqlMovie)
+			if m.Tallies != nil {
+				return func() (map[string]int64, error) { return m.Tallies, nil }
+			}
+			return gqlFrom(p.Context).tallies.load(p.Context, m.region, m.ID)
+		}),
+	})
+
+	snapshot := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Snapshot",
+		Description: "A movie's tallies as archived when its month closed.",
+		Fields: tallyFields(graphql.Fields{
+			"region": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(gqlSnapshot).Region, nil
+			}},
+			"month": {Type: graphql.NewNonNull(graphql.String), Description: "YYYY-MM", Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(gqlSnapshot).Month, nil
+			}},
+			"closedAt": {Type: graphql.NewNonNull(graphql.DateTime), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(gqlSnapshot).Closed, nil
+			}},
+			"movie": {
+				Type:        graphql.NewNonNull(movie),
+				Description: "The movie with its current tallies, which may have changed since.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					s := p.Source.(gqlSnapshot).Snapshot
+					return gqlMovie{region: s.Region, Movie: model.Movie{ID: s.MovieID, Title: s.Title, ReleaseDate: s.ReleaseDate, Overview: s.Overview,
+						PosterPath: s.PosterPath, BackdropPath: s.BackdropPath, Popularity: s.Popularity, ImdbURL: s.ImdbURL, CinemagiaURL: s.CinemagiaURL,
+						GenreIDs: s.GenreIDs}}, nil
+				},
+			},
+		}, func(p graphql.ResolveParams) func() (map[string]int64, error) {
+			counts := p.Source.(gqlSnapshot).Tallies
+			return func() (map[string]int64, error) { return counts, nil }
+		}),
+	})
+
+	pageInfo := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "PageInfo",
+		Description: "Signed cursors of the page, the same as the REST listing's next_cursor (endCursor) and prev_cursor (startCursor).",
+		Fields: graphql.Fields{
+			"hasNextPage":     {Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasPreviousPage": {Type: graphql.NewNonNull(graphql.Boolean)},
+			"startCursor":     {Type: graphql.String, Description: "Pass as before for the previous page."},
+			"endCursor":       {Type: graphql.String, Description: "Pass as after for the next page."},
+		},
+	})
+	connection := func(name, items string, node *graphql.Object) *graphql.Object {
+		return graphql.NewObject(graphql.ObjectConfig{
+			Name: name,
+			Fields: graphql.Fields{
+				"nodes":    {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(node)))},
+				"pageInfo": {Type: graphql.NewNonNull(pageInfo)},
+				"totalCount": {
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Items across all pages; costs a count query.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						n, err := p.Source.(gqlConnection).count(p.Context)
+						if err != nil {
+							return nil, pkghttpx.Internal("failed to count "+items, err)
+						}
+						return n, nil
+					},
+				},
+			},
+		})
+	}
+
+	availableMonths := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AvailableMonths",
+		Description: "The closed months of a year that have snapshots.",
+		Fields: graphql.Fields{
+			"year":   {Type: graphql.NewNonNull(graphql.Int)},
+			"months": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.Int)))},
+		},
+	})
+	vote := graphql.NewObject(graphql.ObjectConfig{
+		Name: "VotePayload",
+		Fields: tallyFields(graphql.Fields{
+			"inserted": {Type: graphql.NewNonNull(graphql.Boolean), Description: "False when the caller had already voted for the movie."},
+			"votedCategory": {Type: category, Description: "The caller's vote, which differs from the requested one after a duplicate.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if v := p.Source.(gqlVote).VotedCategory; v != "" {
+						return v, nil
+					}
+					return nil, nil
+				}},
+		}, func(p graphql.ResolveParams) func() (map[string]int64, error) {
+			counts := p.Source.(gqlVote).counts
+			return func() (map[string]int64, error) { return counts, nil }
+		}),
+	})
+
+	region := &graphql.ArgumentConfig{Type: graphql.String, Description: "ISO 3166-1 code of the board; the default region when omitted."}
+	listArgs := func(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"region":        region,
+			"first":         {Type: graphql.Int, Description: "Page size; the REST default and maximum apply."},
+			"after":         {Type: graphql.String, Description: "endCursor of the previous page."},
+			"before":        {Type: graphql.String, Description: "startCursor of the next page."},
+			"sortBy":        {Type: graphql.String, Description: "As sort_by: up to three comma-separated fields."},
+			"sortDir":       {Type: graphql.String, Description: "As sort_dir."},
+			"minPopularity": {Type: graphql.Float},
+			"maxPopularity": {Type: graphql.Float},
+			"releaseFrom":   {Type: graphql.String, Description: "YYYY-MM-DD, inclusive."},
+			"releaseTo":     {Type: graphql.String, Description: "YYYY-MM-DD, inclusive."},
+			"genres":        {Type: graphql.NewList(graphql.NewNonNull(graphql.Int)), Description: "TMDb genre ids; movies with any of them."},
+			"minVotes":      {Type: graphql.Int},
+			"voted":         {Type: graphql.Boolean, Description: "Voted (or not) by the caller's X-Fingerprint."},
+		}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"activeMovies": {
+				Type:        graphql.NewNonNull(connection("MovieConnection", "active movies", movie)),
+				Description: "Movies of the current month that can still be voted on, like GET /v1/movies/active.",
+				Args:        listArgs(nil),
+				Resolve:     resolveActiveMovies,
+			},
+			"snapshots": {
+				Type:        graphql.NewNonNull(connection("SnapshotConnection", "snapshots", snapshot)),
+				Description: "Archived results of a closed month, like GET /v1/snapshots/{year}/{month}.",
+				Args: listArgs(graphql.FieldConfigArgument{
+					"year":  {Type: graphql.NewNonNull(graphql.Int)},
+					"month": {Type: graphql.NewNonNull(graphql.Int)},
+				}),
+				Resolve: resolveSnapshots,
+			},
+			"availableMonths": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(availableMonths))),
+				Description: "Months with snapshots, like GET /v1/snapshots/available.",
+				Args:        graphql.FieldConfigArgument{"region": region},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					d := gqlFrom(p.Context).d
+					reg, herr := regionParam(p.Context, d, gqlString(p.Args, "region"))
+					if herr != nil {
+						return nil, herr
+					}
+					rows, err := d.Repo.ListAvailableYearMonths(p.Context, reg)
+					if err != nil {
+						return nil, pkghttpx.Internal("failed to list available months", err)
+					}
+					return rows, nil
+				},
+			},
+			"categories": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(category))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					out := make([]string, len(cardCategories))
+					for i, c := range cardCategories {
+						out[i] = c.key
+					}
+					return out, nil
+				},
+			},
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"vote": {
+				Type:        graphql.NewNonNull(vote),
+				Description: "Votes as the caller's X-Fingerprint, like POST /v1/movies/{id}/votes.",
+				Args: graphql.FieldConfigArgument{
+					"movieId":  {Type: graphql.NewNonNull(graphql.ID)},
+					"category": {Type: graphql.NewNonNull(category)},
+					"region":   region,
+				},
+				Resolve: resolveVote,
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+})
+
+func resolveActiveMovies(p graphql.ResolveParams) (any, error) {
+	gr := gqlFrom(p.Context)
+	d := gr.d
+	now := time.Now().UTC()
+	lr, herr := gqlListRequest(p, "active_movies", now.Format("2006-01"), pkgcrypto.Codec.DecodeMoviesCursor)
+	if herr != nil {
+		return nil, herr
+	}
+	f := repos.ActiveMoviesFilter{Region: lr.region, Language: translation(d, lr.language), Query: lr.query, Limit: lr.limit}
+	f.CursorKeys, f.CursorID, f.Backward = lr.cursorKeys()
+	if lr.fingerprint != "" {
+		f.Fingerprint = &lr.fingerprint
+	}
+	page, err := d.Repo.ListActiveMoviesPageFiltered(p.Context, now, f)
+	if err != nil {
+		return nil, pkghttpx.Internal("failed to list active movies", err)
+	}
+	next, prev := pageCursors(page, lr.cursor != "", f.Backward, func(m model.Movie) int64 { return m.ID },
+		func(c pkgcrypto.KeysetCursor) string { return d.Codec.EncodeMoviesCursor(lr.scope, c) }, d.Codec != nil)
+	nodes := make([]any, len(page.Items))
+	for i, m := range page.Items {
+		nodes[i] = gqlMovie{Movie: m, region: lr.region}
+	}
+	return gqlConnection{Nodes: nodes, PageInfo: gqlPageInfo{HasNextPage: next != nil, HasPreviousPage: prev != nil, StartCursor: prev, EndCursor: next},
+		count: func(ctx context.Context) (int64, error) { return d.Repo.CountActiveMoviesFiltered(ctx, now, f) }}, nil
+}
+
+func resolveSnapshots(p graphql.ResolveParams) (any, error) {
+	d := gqlFrom(p.Context).d
+	year, month := p.Args["year"].(int), p.Args["month"].(int)
+	if month < 1 || month > 12 {
+		return nil, pkghttpx.BadRequest("invalid year/month", nil)
+	}
+	mon := fmt.Sprintf("%04d-%02d", year, month)
+	lr, herr := gqlListRequest(p, "snapshots", mon, pkgcrypto.Codec.DecodeSnapshotsCursor)
+	if herr != nil {
+		return nil, herr
+	}
+	f := repos.SnapshotsFilter{Region: lr.region, Language: translation(d, lr.language), Month: mon, Query: lr.query, Limit: lr.limit}
+	f.CursorKeys, f.CursorID, f.Backward = lr.cursorKeys()
+	if lr.query.Voted != nil {
+		f.Fingerprint = &lr.fingerprint
+	}
+	page, err := d.Repo.ListSnapshotsByMonthFiltered(p.Context, f)
+	if err != nil {
+		return nil, pkghttpx.Internal("failed to get snapshots", err)
+	}
+	next, prev := pageCursors(page, lr.cursor != "", f.Backward, func(s model.Snapshot) int64 { return s.MovieID },
+		func(c pkgcrypto.KeysetCursor) string { return d.Codec.EncodeSnapshotsCursor(lr.scope, c) }, d.Codec != nil)
+	nodes := make([]any, len(page.Items))
+	for i, s := range page.Items {
+		nodes[i] = gqlSnapshot{s}
+	}
+	return gqlConnection{Nodes: nodes, PageInfo: gqlPageInfo{HasNextPage: next != nil, HasPreviousPage: prev != nil, StartCursor: prev, EndCursor: next},
+		count: func(ctx context.Context) (int64, error) { return d.Repo.CountSnapshotsByMonthFiltered(ctx, f) }}, nil
+}
+
+func resolveVote(p graphql.ResolveParams) (any, error) {
+	gr := gqlFrom(p.Context)
+	d := gr.d
+	id, err := strconv.ParseInt(gqlString(p.Args, "movieId"), 10, 64)
+	if err != nil || id <= 0 {
+		return nil, pkghttpx.BadRequest("invalid movieId", err)
+	}
+	if gr.fingerprint == "" {
+		return nil, pkghttpx.BadRequest("vote requires the X-Fingerprint header", nil)
+	}
+	reg, herr := regionParam(p.Context, d, gqlString(p.Args, "region"))
+	if herr != nil {
+		return nil, herr
+	}
+	inserted, herr := castVote(p.Context, d, id, reg, gqlString(p.Args, "category"), gr.fingerprint)
+	if herr != nil {
+		return nil, herr
+	}
+	rows, err := d.Repo.GetTalliesAllCategories(p.Context, id, reg)
+	if err != nil {
+		return nil, pkghttpx.Internal("failed to load tallies", err)
+	}
+	v := gqlVote{Inserted: inserted, counts: make(map[string]int64, len(rows))}
+	for _, t := range rows {
+		v.counts[t.Category] = t.Count
+	}
+	if cat, err := d.Repo.GetVoterCategory(p.Context, id, reg, gr.fingerprint); err == nil && cat != nil {
+		v.VotedCategory = *cat
+	}
+	return v, nil
+}
+
+// gqlListRequest validates the paging, sort and filter arguments of a
+// connection as the REST listing validates its query parameters, so errors name
+// those parameters and cursors are interchangeable between the two.
+func gqlListRequest(p graphql.ResolveParams, kind, month string, decode func(pkgcrypto.Codec, string, string) (pkgcrypto.KeysetCursor, error)) (listRequest, *pkghttpx.HTTPError) {
+	gr := gqlFrom(p.Context)
+	after, before := gqlString(p.Args, "after"), gqlString(p.Args, "before")
+	if after != "" && before != "" {
+		return listRequest{}, pkghttpx.BadRequest("after and before are mutually exclusive", nil)
+	}
+	v := url.Values{}
+	set := func(param, arg string) {
+		if x, ok := p.Args[arg]; ok && x != nil {
+			v.Set(param, fmt.Sprint(x))
+		}
+	}
+	set("region", "region")
+	set("limit", "first")
+	set("sort_by", "sortBy")
+	set("sort_dir", "sortDir")
+	set("min_popularity", "minPopularity")
+	set("max_popularity", "maxPopularity")
+	set("release_from", "releaseFrom")
+	set("release_to", "releaseTo")
+	set("min_votes", "minVotes")
+	set("voted", "voted")
+	if genres, ok := p.Args["genres"].([]any); ok && len(genres) > 0 {
+		ids := make([]string, len(genres))
+		for i, g := range genres {
+			ids[i] = fmt.Sprint(g)
+		}
+		v.Set("genre", strings.Join(ids, ","))
+	}
+	v.Set("cursor", after+before)
+	lr, herr := listRequestFrom(p.Context, gr.d, v, gr.language, gr.fingerprint, kind, month, decode)
+	if herr != nil {
+		return listRequest{}, herr
+	}
+	if lr.keyset != nil && lr.keyset.Backward != (before != "") {
+		return listRequest{}, pkghttpx.InvalidCursor("pass endCursor as after and startCursor as before", nil)
+	}
+	return lr, nil
+}
+
+// gqlString returns a string or ID argument, "" when absent.
+func gqlString(args map[string]any, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
@@ -0,0 +1,411 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/pb/cinekamiv1"
+	"cinekami-server/internal/repos"
+
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// cinekamiRPC serves the gRPC API with the REST handlers' validation, so
+// errors, limits and cursors are the same on both.
+type cinekamiRPC struct {
+	cinekamiv1.UnimplementedCinekamiServer
+	d deps.ServerDeps
+}
+
+// GRPC returns the gRPC API over d. Methods fail with *pkghttpx.HTTPError like
+// the REST handlers; the server's interceptors turn them into statuses.
+func GRPC(d deps.ServerDeps) cinekamiv1.CinekamiServer {
+	return &cinekamiRPC{d: d}
+}
+
+// ListActiveMovies is GET /v1/movies/active.
+func (s *cinekamiRPC) ListActiveMovies(ctx context.Context, req *cinekamiv1.ListActiveMoviesRequest) (*cinekamiv1.ListActiveMoviesResponse, error) {
+	d := s.d
+	now := time.Now().UTC()
+	lr, herr := rpcListRequest(ctx, d, req.GetOptions(), "active_movies", now.Format("2006-01"), pkgcrypto.Codec.DecodeMoviesCursor)
+	if herr != nil {
+		return nil, herr
+	}
+	f := repos.ActiveMoviesFilter{Region: lr.region, Language: translation(d, lr.language), Query: lr.query, Limit: lr.limit}
+	f.CursorKeys, f.CursorID, f.Backward = lr.cursorKeys()
+	if lr.fingerprint != "" {
+		f.Fingerprint = &lr.fingerprint
+	}
+	page, err := d.Repo.ListActiveMoviesPageFiltered(ctx, now, f)
+	if err != nil {
+		return nil, pkghttpx.Internal("failed to list active movies", err)
+	}
+	resp := &cinekamiv1.ListActiveMoviesResponse{Region: lr.region}
+	if lr.includeTotal {
+		n, err := d.Repo.CountActiveMoviesFiltered(ctx, now, f)
+		if err != nil {
+			return nil, pkghttpx.Internal("failed to count active movies", err)
+		}
+		resp.Total = &n
+	}
+	next, prev := pageCursors(page, lr.cursor != "", f.Backward, func(m model.Movie) int64 { return m.ID },
+		func(c pkgcrypto.KeysetCursor) string { return d.Codec.EncodeMoviesCursor(lr.scope, c) }, d.Codec != nil)
+	resp.NextCursor, resp.PrevCursor = rpcString(next), rpcString(prev)
+	for _, m := range page.Items {
+		resp.Movies = append(resp.Movies, rpcMovie(m, lr.region))
+	}
+	return resp, nil
+}
+
+// GetMovie returns a movie released on the board of the requested region, in
+// the requested language, with its tallies there.
+func (s *cinekamiRPC) GetMovie(ctx context.Context, req *cinekamiv1.GetMovieRequest) (*cinekamiv1.Movie, error) {
+	d := s.d
+	if req.GetMovieId() <= 0 {
+		return nil, pkghttpx.BadRequest("invalid movie_id", nil)
+	}
+	region, herr := regionParam(ctx, d, req.GetRegion())
+	if herr != nil {
+		return nil, herr
+	}
+	lang, herr := rpcLanguage(ctx, d, req.GetLanguage())
+	if herr != nil {
+		return nil, herr
+	}
+	var fingerprint *string
+	if fp := rpcFingerprint(ctx, d); fp != "" {
+		fingerprint = &fp
+	}
+	m, err := d.Repo.Movies.Get(ctx, req.GetMovieId(), region, translation(d, lang), fingerprint)
+	if errors.Is(err, repos.ErrMovieNotFound) {
+		return nil, pkghttpx.NotFound("movie not found", err)
+	}
+	if err != nil {
+		return nil, pkghttpx.Internal("failed to load movie", err)
+	}
+	return rpcMovie(m, region), nil
+}
+
+// GetTallies is GET /v1/movies/{id}/tallies.
+func (s *cinekamiRPC) GetTallies(ctx context.Context, req *cinekamiv1.GetTalliesRequest) (*cinekamiv1.Tallies, error) {
+	d := s.d
+	if req.GetMovieId() <= 0 {
+		return nil, pkghttpx.BadRequest("invalid movie_id", nil)
+	}
+	region, herr := regionParam(ctx, d, req.GetRegion())
+	if herr != nil {
+		return nil, herr
+	}
+	return rpcCurrentTallies(ctx, d, req.GetMovieId(), region, rpcFingerprint(ctx, d))
+}
+
+// ListSnapshots is GET /v1/snapshots/{year}/{month}.
+func (s *cinekamiRPC) ListSnapshots(ctx context.Context, req *cinekamiv1.ListSnapshotsRequest) (*cinekamiv1.ListSnapshotsResponse, error) {
+	d := s.d
+	if req.GetMonth() < 1 || req.GetMonth() > 12 {
+		return nil, pkghttpx.BadRequest("invalid year/month", nil)
+	}
+	mon := fmt.Sprintf("%04d-%02d", req.GetYear(), req.GetMonth())
+	lr, herr := rpcListRequest(ctx, d, req.GetOptions(), "snapshots", mon, pkgcrypto.Codec.DecodeSnapshotsCursor)
+	if herr != nil {
+		return nil, herr
+	}
+	f := repos.SnapshotsFilter{Region: lr.region, Language: translation(d, lr.language), Month: mon, Query: lr.query, Limit: lr.limit}
+	f.CursorKeys, f.CursorID, f.Backward = lr.cursorKeys()
+	if lr.query.Voted != nil {
+		f.Fingerprint = &lr.fingerprint
+	}
+	page, err := d.Repo.ListSnapshotsByMonthFiltered(ctx, f)
+	if err != nil {
+		return nil, pkghttpx.Internal("failed to get snapshots", err)
+	}
+	resp := &cinekamiv1.ListSnapshotsResponse{Region: lr.region}
+	if lr.includeTotal {
+		n, err := d.Repo.CountSnapshotsByMonthFiltered(ctx, f)
+		if err != nil {
+			return nil, pkghttpx.Internal("failed to count snapshots", err)
+		}
+		resp.Total = &n
+	}
+	next, prev := pageCursors(page, lr.cursor != "", f.Backward, func(s model.Snapshot) int64 { return s.MovieID },
+		func(c pkgcrypto.KeysetCursor) string { return d.Codec.EncodeSnapshotsCursor(lr.scope, c) }, d.Codec != nil)
+	resp.NextCursor, resp.PrevCursor = rpcString(next), rpcString(prev)
+	for _, sn := range page.Items {
+		resp.Snapshots = append(resp.Snapshots, rpcSnapshot(sn))
+	}
+	return resp, nil
+}
+
+// CastVote is POST /v1/movies/{id}/votes as the caller's x-fingerprint.
+func (s *cinekamiRPC) CastVote(ctx context.Context, req *cinekamiv1.CastVoteRequest) (*cinekamiv1.CastVoteResponse, error) {
+	d := s.d
+	fingerprint := rpcFingerprint(ctx, d)
+	if fingerprint == "" {
+		return nil, pkghttpx.BadRequest("CastVote requires the x-fingerprint metadata", nil)
+	}
+	if req.GetMovieId() <= 0 {
+		return nil, pkghttpx.BadRequest("invalid movie_id", nil)
+	}
+	category := categoryKey(req.GetCategory())
+	if category == "" {
+		return nil, pkghttpx.BadRequest("invalid category; allowed: "+allowedCategoriesList(), nil)
+	}
+	region, herr := regionParam(ctx, d, req.GetRegion())
+	if herr != nil {
+		return nil, herr
+	}
+	inserted, herr := castVote(ctx, d, req.GetMovieId(), region, category, fingerprint)
+	if herr != nil {
+		return nil, herr
+	}
+	tallies, err := rpcCurrentTallies(ctx, d, req.GetMovieId(), region, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	return &cinekamiv1.CastVoteResponse{Inserted: inserted, Tallies: tallies}, nil
+}
+
+// WatchTallies polls the watched movies' tallies every TallyWatchInterval with
+// one query and sends those that changed since the last message, all of them
+// the first time. While the server drains the stream ends with Unavailable so
+// clients reconnect elsewhere.
+func (s *cinekamiRPC) WatchTallies(req *cinekamiv1.WatchTalliesRequest, stream grpc.ServerStreamingServer[cinekamiv1.Tallies]) error {
+	d := s.d
+	ctx := stream.Context()
+	ids := slices.Compact(slices.Sorted(slices.Values(req.GetMovieIds())))
+	if len(ids) == 0 || len(ids) > d.MaxPageSize {
+		return pkghttpx.BadRequest(fmt.Sprintf("movie_ids must list 1 to %d movies", d.MaxPageSize), nil)
+	}
+	if ids[0] <= 0 {
+		return pkghttpx.BadRequest("invalid movie_ids", nil)
+	}
+	region, herr := regionParam(ctx, d, req.GetRegion())
+	if herr != nil {
+		return herr
+	}
+	sent := make(map[int64]map[string]int64, len(ids))
+	tick := time.NewTicker(d.TallyWatchInterval)
+	defer tick.Stop()
+	for {
+		if d.Draining.Load() {
+			return pkghttpx.Unavailable("server is shutting down; reconnect", nil)
+		}
+		rows, err := d.Repo.Tallies.GetTalliesForMovies(ctx, ids, region)
+		if err != nil {
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			return pkghttpx.Internal("failed to get tallies", err)
+		}
+		for _, id := range ids {
+			if last, ok := sent[id]; ok && maps.Equal(last, rows[id]) {
+				continue
+			}
+			if err := stream.Send(rpcTallies(id, region, rows[id], "")); err != nil {
+				return err
+			}
+			sent[id] = rows[id]
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-tick.C:
+		}
+	}
+}
+
+// rpcListRequest validates ListOptions as the REST listing validates its query
+// parameters, so errors name those parameters and cursors are interchangeable.
+func rpcListRequest(ctx context.Context, d deps.ServerDeps, o *cinekamiv1.ListOptions, kind, month string, decode func(pkgcrypto.Codec, string, string) (pkgcrypto.KeysetCursor, error)) (listRequest, *pkghttpx.HTTPError) {
+	lang, herr := rpcLanguage(ctx, d, o.GetLanguage())
+	if herr != nil {
+		return listRequest{}, herr
+	}
+	v := url.Values{}
+	set := func(param, value string) {
+		if value != "" {
+			v.Set(param, value)
+		}
+	}
+	set("region", o.GetRegion())
+	set("cursor", o.GetCursor())
+	set("sort_by", o.GetSortBy())
+	set("sort_dir", o.GetSortDir())
+	set("release_from", o.GetReleaseFrom())
+	set("release_to", o.GetReleaseTo())
+	if o.GetLimit() != 0 {
+		v.Set("limit", strconv.Itoa(int(o.GetLimit())))
+	}
+	if o.MinPopularity != nil {
+		v.Set("min_popularity", strconv.FormatFloat(o.GetMinPopularity(), 'f', -1, 64))
+	}
+	if o.MaxPopularity != nil {
+		v.Set("max_popularity", strconv.FormatFloat(o.GetMaxPopularity(), 'f', -1, 64))
+	}
+	if o.MinVotes != nil {
+		v.Set("min_votes", strconv.FormatInt(o.GetMinVotes(), 10))
+	}
+	if o.Voted != nil {
+		v.Set("voted", strconv.FormatBool(o.GetVoted()))
+	}
+	if len(o.GetGenreIds()) > 0 {
+		ids := make([]string, len(o.GetGenreIds()))
+		for i, g := range o.GetGenreIds() {
+			ids[i] = strconv.Itoa(int(g))
+		}
+		v.Set("genre", strings.Join(ids, ","))
+	}
+	v.Set("include_total", strconv.FormatBool(o.GetIncludeTotal()))
+	return listRequestFrom(ctx, d, v, lang, rpcFingerprint(ctx, d), kind, month, decode)
+}
+
+// rpcMetadata returns the first value of key in the call's metadata, "" without it.
+func rpcMetadata(ctx context.Context, key string) string {
+	if v := metadata.ValueFromIncomingContext(ctx, key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// rpcFingerprint returns the digest of the x-fingerprint metadata, or "" without one.
+func rpcFingerprint(ctx context.Context, d deps.ServerDeps) string {
+	return d.Fingerprints.Digest(rpcMetadata(ctx, "x-fingerprint"))
+}
+
+// rpcLanguage negotiates lang, falling back to the accept-language metadata.
+func rpcLanguage(ctx context.Context, d deps.ServerDeps, lang string) (string, *pkghttpx.HTTPError) {
+	return negotiateLanguage(d, lang, rpcMetadata(ctx, "accept-language"))
+}
+
+// rpcCurrentTallies loads a movie's tallies in region with the vote of
+// fingerprint, as GET /v1/movies/{id}/tallies does.
+func rpcCurrentTallies(ctx context.Context, d deps.ServerDeps, movieID int64, region, fingerprint string) (*cinekamiv1.Tallies, error) {
+	rows, err := d.Repo.GetTalliesAllCategories(ctx, movieID, region)
+	if err != nil {
+		return nil, pkghttpx.Internal("failed to get tallies", err)
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, t := range rows {
+		counts[t.Category] = t.Count
+	}
+	var voted string
+	if fingerprint != "" {
+		if cat, err := d.Repo.GetVoterCategory(ctx, movieID, region, fingerprint); err == nil && cat != nil {
+			voted = *cat
+		}
+	}
+	return rpcTallies(movieID, region, counts, voted), nil
+}
+
+// rpcCategories pairs the stored categories with their enum values, in enum order.
+var rpcCategories = []struct {
+	key  string
+	enum cinekamiv1.Category
+}{
+	{model.CategorySoloFriends, cinekamiv1.Category_CATEGORY_SOLO_FRIENDS},
+	{model.CategoryCouple, cinekamiv1.Category_CATEGORY_COUPLE},
+	{model.CategoryStreaming, cinekamiv1.Category_CATEGORY_STREAMING},
+	{model.CategoryArr, cinekamiv1.Category_CATEGORY_ARR},
+}
+
+// rpcCategory returns the enum value of a stored category, unspecified for "".
+func rpcCategory(key string) cinekamiv1.Category {
+	for _, c := range rpcCategories {
+		if c.key == key {
+			return c.enum
+		}
+	}
+	return cinekamiv1.Category_CATEGORY_UNSPECIFIED
+}
+
+// categoryKey returns the stored category of an enum value, "" for unspecified
+// or unknown values.
+func categoryKey(c cinekamiv1.Category) string {
+	for _, rc := range rpcCategories {
+		if rc.enum == c {
+			return rc.key
+		}
+	}
+	return ""
+}
+
+// rpcTallies shapes counts, which may lack categories without votes, with
+// every category's share and the leading one.
+func rpcTallies(movieID int64, region string, counts map[string]int64, voted string) *cinekamiv1.Tallies {
+	stats := model.NewTallyStats(counts)
+	t := &cinekamiv1.Tallies{MovieId: movieID, Region: region, TotalVotes: stats.TotalVotes, Leading: rpcLeading(stats.Leading),
+		VotedCategory: rpcCategory(voted)}
+	for _, c := range rpcCategories {
+		sh := stats.Shares[c.key]
+		t.Tallies = append(t.Tallies, &cinekamiv1.Tally{Category: c.enum, Count: counts[c.key], Share: sh.Share, ShareLow: sh.Low, ShareHigh: sh.High})
+	}
+	return t
+}
+
+func rpcLeading(l *model.Leading) *cinekamiv1.Leading {
+	if l == nil {
+		return nil
+	}
+	return &cinekamiv1.Leading{Category: rpcCategory(l.Category), Confident: l.Confident}
+}
+
+func rpcMovie(m model.Movie, region string) *cinekamiv1.Movie {
+	var voted string
+	if m.VotedCategory != nil {
+		voted = *m.VotedCategory
+	}
+	return &cinekamiv1.Movie{
+		Id:           m.ID,
+		Title:        m.Title,
+		ReleaseDate:  m.ReleaseDate.Format(time.DateOnly),
+		Overview:     rpcString(m.Overview),
+		PosterPath:   rpcString(m.PosterPath),
+		BackdropPath: rpcString(m.BackdropPath),
+		Popularity:   m.Popularity,
+		GenreIds:     m.GenreIDs,
+		ImdbUrl:      rpcString(m.ImdbURL),
+		CinemagiaUrl: rpcString(m.CinemagiaURL),
+		Tallies:      rpcTallies(m.ID, region, m.Tallies, voted),
+	}
+}
+
+func rpcSnapshot(s model.Snapshot) *cinekamiv1.Snapshot {
+	t := rpcTallies(s.MovieID, s.Region, s.Tallies, "")
+	return &cinekamiv1.Snapshot{
+		Region:      s.Region,
+		Month:       s.Month,
+		MovieId:     s.MovieID,
+		Title:       s.Title,
+		ReleaseDate: s.ReleaseDate.Format(time.DateOnly),
+		PosterPath:  rpcString(s.PosterPath),
+		Popularity:  s.Popularity,
+		GenreIds:    s.GenreIDs,
+		ClosedAt:    timestamppb.New(s.Closed),
+		Tallies:     t.Tallies,
+		TotalVotes:  t.TotalVotes,
+		Leading:     t.Leading,
+	}
+}
+
+// rpcString unwraps an optional string; proto3 strings are "" when absent.
+func rpcString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -1,10 +1,14 @@
 package routes
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"cinekami-server/internal/deps"
+	"cinekami-server/internal/migrate"
 
 	pkghttpx "cinekami-server/pkg/httpx"
 )
@@ -13,10 +17,148 @@ import (
 func Health(d deps.ServerDeps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		uptime := int64(time.Since(d.StartedAt).Seconds())
-		pkghttpx.WriteJSON(w, http.StatusOK, map[string]any{
-			"status":         "ok",
-			"service":        d.Name,
-			"uptime_seconds": uptime,
+		pkghttpx.WriteJSON(w, http.StatusOK, HealthResponse{
+			Status:        "ok",
+			Service:       d.Name,
+			UptimeSeconds: uptime,
 		})
 	}
 }
+
+// Check statuses reported by /readyz.
+const (
+	CheckOK       = "ok"
+	CheckDegraded = "degraded"
+	CheckFail     = "fail"
+	CheckSkipped  = "skipped"
+)
+
+const (
+	readyCheckTimeout = 2 * time.Second
+	tmdbCheckTTL      = 10 * time.Minute
+)
+
+// Livez handles GET /livez; it only proves the process is serving requests.
+func Livez(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pkghttpx.WriteJSON(w, http.StatusOK, LivenessResponse{
+			Status:        CheckOK,
+			UptimeSeconds: int64(time.Since(d.StartedAt).Seconds()),
+		})
+	}
+}
+
+// Readyz handles GET /readyz. Postgres and the schema version must be healthy for
+// a 200; cache and TMDb problems only degrade the status because requests still
+// succeed without them. It reports 503 while the server drains on shutdown.
+func Readyz(d deps.ServerDeps) http.HandlerFunc {
+	tmdb := &memoCheck{ttl: tmdbCheckTTL}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		checks := map[string]CheckResult{}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		run := func(name string, fn func(context.Context) CheckResult) {
+			wg.Go(func() {
+				cctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+				defer cancel()
+				start := time.Now()
+				res := fn(cctx)
+				res.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+				mu.Lock()
+				checks[name] = res
+				mu.Unlock()
+			})
+		}
+
+		run("postgres", func(ctx context.Context) CheckResult {
+			if err := d.Repo.Ping(ctx); err != nil {
+				return CheckResult{Status: CheckFail, Error: err.Error()}
+			}
+			return CheckResult{Status: CheckOK}
+		})
+		run("migrations", func(ctx context.Context) CheckResult {
+			return checkMigrations(ctx, d)
+		})
+		run("cache", func(ctx context.Context) CheckResult {
+			if err := d.Cache.Ping(ctx); err != nil {
+				return CheckResult{Status: CheckDegraded, Error: "cache unreachable; serving from postgres: " + err.Error()}
+			}
+			if d.CacheFallback {
+				return CheckResult{Status: CheckDegraded, Error: "valkey unavailable; in-memory fallback active"}
+			}
+			return CheckResult{Status: CheckOK}
+		})
+		run("tmdb", func(ctx context.Context) CheckResult {
+			if !d.CheckTMDB || d.TMDB == nil {
+				return CheckResult{Status: CheckSkipped}
+			}
+			return tmdb.get(func() CheckResult {
+				if err := d.TMDB.ValidateKey(ctx); err != nil {
+					return CheckResult{Status: CheckDegraded, Error: err.Error()}
+				}
+				return CheckResult{Status: CheckOK}
+			})
+		})
+		wg.Wait()
+
+		status := CheckOK
+		for _, c := range checks {
+			switch c.Status {
+			case CheckFail:
+				status = CheckFail
+			case CheckDegraded:
+				if status == CheckOK {
+					status = CheckDegraded
+				}
+			}
+		}
+		resp := ReadinessResponse{Status: status, Draining: d.Draining != nil && d.Draining.Load(), Checks: checks}
+		code := http.StatusOK
+		if resp.Draining {
+			resp.Status = CheckFail
+		}
+		if resp.Status == CheckFail {
+			code = http.StatusServiceUnavailable
+		}
+		pkghttpx.WriteJSON(w, code, resp)
+	}
+}
+
+func checkMigrations(ctx context.Context, d deps.ServerDeps) CheckResult {
+	latest, err := migrate.LatestVersion()
+	if err != nil {
+		return CheckResult{Status: CheckFail, Error: err.Error()}
+	}
+	version, dirty, err := d.Repo.SchemaVersion(ctx)
+	if err != nil {
+		return CheckResult{Status: CheckFail, Error: err.Error()}
+	}
+	switch {
+	case dirty:
+		return CheckResult{Status: CheckFail, Error: fmt.Sprintf("schema version %d is dirty", version)}
+	case uint(version) < latest:
+		return CheckResult{Status: CheckFail, Error: fmt.Sprintf("schema version %d behind %d", version, latest)}
+	}
+	return CheckResult{Status: CheckOK}
+}
+
+// memoCheck caches a check result so external services are not hit on every probe.
+type memoCheck struct {
+	ttl time.Duration
+
+	mu  sync.Mutex
+	at  time.Time
+	res CheckResult
+}
+
+func (m *memoCheck) get(fn func() CheckResult) CheckResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.at.IsZero() && time.Since(m.at) < m.ttl {
+		return m.res
+	}
+	m.res = fn()
+	m.at = time.Now()
+	return m.res
+}
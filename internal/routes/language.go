@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/listquery"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// languageMatchers caches a matcher per served language list; the list is
+// fixed for the life of the process.
+var languageMatchers sync.Map
+
+func languageMatcher(langs []string) language.Matcher {
+	key := strings.Join(langs, ",")
+	if m, ok := languageMatchers.Load(key); ok {
+		return m.(language.Matcher)
+	}
+	tags := make([]language.Tag, len(langs))
+	for i, l := range langs {
+		tags[i] = language.Make(l)
+	}
+	m := language.NewMatcher(tags)
+	languageMatchers.Store(key, m)
+	return m
+}
+
+// requestLanguage picks the served language for a request: ?lang= when set,
+// which must match one of them (ro matches ro-RO), otherwise the best match for
+// Accept-Language, falling back to the language movies are stored in.
+func requestLanguage(d deps.ServerDeps, r *http.Request) (string, *pkghttpx.HTTPError) {
+	return negotiateLanguage(d, r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+}
+
+// negotiateLanguage picks the served language from an explicit choice lang and
+// an Accept-Language value as requestLanguage does.
+func negotiateLanguage(d deps.ServerDeps, lang, accept string) (string, *pkghttpx.HTTPError) {
+	if len(d.Languages) == 0 {
+		return "", nil
+	}
+	m := languageMatcher(d.Languages)
+	if lang != "" {
+		if tag, err := language.Parse(lang); err == nil {
+			if _, i, conf := m.Match(tag); conf != language.No {
+				return d.Languages[i], nil
+			}
+		}
+		return "", queryError(&listquery.Error{Param: "lang", Value: lang, Allowed: d.Languages})
+	}
+	// a malformed header counts as absent
+	tags, _, _ := language.ParseAcceptLanguage(accept)
+	_, i, _ := m.Match(tags...)
+	return d.Languages[i], nil
+}
+
+// translation maps a served language to the repository's Language filter, which
+// is empty for the language movies are stored in.
+func translation(d deps.ServerDeps, lang string) string {
+	if len(d.Languages) == 0 || lang == d.Languages[0] {
+		return ""
+	}
+	return lang
+}
+
+// setLanguageHeaders marks a response as localized in lang.
+func setLanguageHeaders(w http.ResponseWriter, lang string) {
+	w.Header().Add("Vary", "Accept-Language")
+	if lang != "" {
+		w.Header().Set("Content-Language", lang)
+	}
+}
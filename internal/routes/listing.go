@@ -0,0 +1,151 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/listquery"
+	"cinekami-server/internal/repos"
+
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// listRequest is the query string shared by the movie and snapshot listings.
+type listRequest struct {
+	region       string
+	language     string
+	query        listquery.Query
+	limit        int32
+	includeTotal bool
+	fingerprint  string
+	cursor       string
+	keyset       *pkgcrypto.KeysetCursor // nil on the first page
+	scope        string
+}
+
+// parseListRequest validates the listing parameters and decodes the cursor with
+// decode (pkgcrypto.Codec.DecodeMoviesCursor or DecodeSnapshotsCursor). The
+// cursor must have been issued for the same kind, region, language, month and
+// query.
+func parseListRequest(d deps.ServerDeps, r *http.Request, kind, month string, decode func(pkgcrypto.Codec, string, string) (pkgcrypto.KeysetCursor, error)) (listRequest, *pkghttpx.HTTPError) {
+	lang, herr := requestLanguage(d, r)
+	if herr != nil {
+		return listRequest{}, herr
+	}
+	return listRequestFrom(r.Context(), d, r.URL.Query(), lang, requestFingerprint(d, r), kind, month, decode)
+}
+
+// listRequestFrom validates the listing parameters in v for a caller with the
+// given language and fingerprint digest; see parseListRequest.
+func listRequestFrom(ctx context.Context, d deps.ServerDeps, v url.Values, lang, fingerprint, kind, month string, decode func(pkgcrypto.Codec, string, string) (pkgcrypto.KeysetCursor, error)) (listRequest, *pkghttpx.HTTPError) {
+	q, err := listquery.Parse(v)
+	if err != nil {
+		return listRequest{}, queryError(err)
+	}
+	region, herr := regionParam(ctx, d, v.Get("region"))
+	if herr != nil {
+		return listRequest{}, herr
+	}
+	lr := listRequest{region: region, language: lang, query: q, fingerprint: fingerprint, cursor: v.Get("cursor")}
+	if q.Voted != nil && lr.fingerprint == "" {
+		return listRequest{}, pkghttpx.BadRequest("voted requires the X-Fingerprint header", nil)
+	}
+
+	limitStr := v.Get("limit")
+	if limitStr == "" {
+		limitStr = strconv.Itoa(d.DefaultPageSize)
+	}
+	lim64, err := strconv.ParseInt(limitStr, 10, 32)
+	if err != nil || lim64 <= 0 || lim64 > int64(d.MaxPageSize) {
+		return listRequest{}, pkghttpx.BadRequest("invalid limit", err)
+	}
+	lr.limit = int32(lim64)
+
+	lr.includeTotal = true
+	if s := v.Get("include_total"); s != "" {
+		if lr.includeTotal, err = strconv.ParseBool(s); err != nil {
+			return listRequest{}, pkghttpx.BadRequest("invalid include_total", err)
+		}
+	}
+
+	lr.scope = cursorScope(kind, region, lang, month, q)
+	if lr.cursor != "" {
+		if d.Codec == nil {
+			return listRequest{}, pkghttpx.Internal("codec crypto not configured", nil)
+		}
+		c, err := decode(d.Codec, lr.scope, lr.cursor)
+		if err != nil {
+			return listRequest{}, cursorError(err)
+		}
+		if len(c.Keys) != len(q.Sort) {
+			return listRequest{}, cursorError(pkgcrypto.ErrCursorMalformed)
+		}
+		lr.keyset = &c
+	}
+	return lr, nil
+}
+
+// requestFingerprint returns the digest of the X-Fingerprint header, which is
+// what voters are stored by, or "" without one.
+func requestFingerprint(d deps.ServerDeps, r *http.Request) string {
+	return d.Fingerprints.Digest(r.Header.Get("X-Fingerprint"))
+}
+
+// cacheKey identifies the response for lr; extra carries per-caller parts such
+// as the fingerprint. It starts with kind:region:month: so a vote can drop every
+// listing of its board and month by prefix.
+func (lr listRequest) cacheKey(kind, month string, extra ...string) string {
+	parts := append([]string{kind, lr.region, month, lr.language}, lr.query.Canonical()...)
+	parts = append(parts,
+		"cursor="+lr.cursor,
+		"limit="+strconv.Itoa(int(lr.limit)),
+		"total="+strconv.FormatBool(lr.includeTotal),
+	)
+	return strings.Join(append(parts, extra...), ":")
+}
+
+// cursorKeys unpacks the decoded cursor for the repository filters.
+func (lr listRequest) cursorKeys() (keys []string, id *int64, backward bool) {
+	if lr.keyset == nil {
+		return nil, nil, false
+	}
+	return lr.keyset.Keys, &lr.keyset.ID, lr.keyset.Backward
+}
+
+// queryError turns a listquery validation error into a 400 whose details name
+// the parameter and, where there is a fixed set, the allowed values.
+func queryError(err error) *pkghttpx.HTTPError {
+	he := pkghttpx.BadRequest(err.Error(), err)
+	var qe *listquery.Error
+	if errors.As(err, &qe) {
+		he.Details = map[string]any{"param": qe.Param}
+		if len(qe.Allowed) > 0 {
+			he.Details["allowed"] = qe.Allowed
+		}
+	}
+	return he
+}
+
+// pageCursors derives next_cursor and prev_cursor for a keyset page. A forward
+// page always has rows behind it once a cursor was used; a backward page always
+// has rows ahead of it, since it was reached from there.
+func pageCursors[T any](p repos.Page[T], hadCursor, backward bool, id func(T) int64, encode func(pkgcrypto.KeysetCursor) string, enabled bool) (next, prev *string) {
+	if !enabled || len(p.Items) == 0 {
+		return nil, nil
+	}
+	if backward || p.HasMore {
+		s := encode(pkgcrypto.KeysetCursor{Keys: p.LastKeys, ID: id(p.Items[len(p.Items)-1])})
+		next = &s
+	}
+	if (backward && p.HasMore) || (!backward && hadCursor) {
+		s := encode(pkgcrypto.KeysetCursor{Keys: p.FirstKeys, ID: id(p.Items[0]), Backward: true})
+		prev = &s
+	}
+	return next, prev
+}
@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/repos"
+
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// MeExport handles GET /v1/me/export: every vote of the caller, identified by
+// X-Fingerprint and/or account credentials (HTTP Basic).
+func MeExport(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, herr := voterIdentity(d, w, r)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		data, err := d.Repo.Voters.ExportVoter(r.Context(), id, time.Now().UTC())
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to export votes", err))
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Disposition", `attachment; filename="cinekami-votes.json"`)
+		pkghttpx.WriteJSON(w, http.StatusOK, data)
+	}
+}
+
+// MeDelete handles DELETE /v1/me: the caller's voters are anonymized. Votes are
+// kept without the fingerprint or account, so tallies do not change.
+func MeDelete(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, herr := voterIdentity(d, w, r)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		n, err := d.Repo.Voters.EraseVoter(r.Context(), id)
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to erase voter", err))
+			return
+		}
+		// Listings cached for this fingerprint still carry its voted categories.
+		_ = d.Cache.DeletePrefix(r.Context(), "active_movies:")
+		pkghttpx.WriteJSON(w, http.StatusOK, MeDeleteResponse{Anonymized: n})
+	}
+}
+
+// voterIdentity reads the caller's fingerprint and, with Basic credentials, their
+// account. At least one is required; wrong credentials are a 401. Credentials
+// are throttled, and an unknown email costs the same password check as a known
+// one, so neither timing nor volume reveals which accounts exist.
+func voterIdentity(d deps.ServerDeps, w http.ResponseWriter, r *http.Request) (repos.VoterIdentity, *pkghttpx.HTTPError) {
+	id := repos.VoterIdentity{Fingerprint: requestFingerprint(d, r)}
+	if email, password, ok := r.BasicAuth(); ok {
+		if herr := ThrottleCredentials(d, w, r, email); herr != nil {
+			return id, herr
+		}
+		userID, hash, salt, err := d.Repo.Voters.UserCredentials(r.Context(), email)
+		if err != nil && !errors.Is(err, repos.ErrUserNotFound) {
+			return id, pkghttpx.Internal("failed to check credentials", err)
+		}
+		if err != nil {
+			hash, salt = pkgcrypto.DummyPassword()
+		}
+		match, verr := pkgcrypto.VerifyPassword(password, hash, salt)
+		if err == nil {
+			err = verr
+		}
+		if err != nil || !match {
+			return id, pkghttpx.Unauthorized("invalid credentials", err)
+		}
+		id.UserID = &userID
+	}
+	if id.Fingerprint == "" && id.UserID == nil {
+		return id, pkghttpx.BadRequest("X-Fingerprint header or account credentials required", repos.ErrVoterIdentity)
+	}
+	return id, nil
+}
+
+// ThrottleCredentials counts a credential attempt against the client address
+// and the email, for /v1/me and the admin API alike; over either limit it is a
+// 429 with Retry-After.
+func ThrottleCredentials(d deps.ServerDeps, w http.ResponseWriter, r *http.Request, email string) *pkghttpx.HTTPError {
+	if d.CredentialAttempts == nil {
+		return nil
+	}
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+	for _, key := range []string{"client:" + client, "email:" + strings.ToLower(email)} {
+		if ok, wait := d.CredentialAttempts.Allow(key); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			return pkghttpx.TooManyRequests("too many credential attempts; retry later", nil)
+		}
+	}
+	return nil
+}
@@ -1,17 +1,17 @@
 package routes
 
 import (
-	"encoding/json"
 	"net/http"
 	"sort"
 	"strconv"
 
 	"cinekami-server/internal/deps"
+	"cinekami-server/internal/model"
 
 	pkghttpx "cinekami-server/pkg/httpx"
 )
 
-// MovieTallies handles GET /movies/{id}/tallies
+// MovieTallies handles GET /v1/movies/{id}/tallies; tallies are those of the requested region.
 func MovieTallies(d deps.ServerDeps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -21,17 +21,22 @@ func MovieTallies(d deps.ServerDeps) http.HandlerFunc {
 			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid ID", err))
 			return
 		}
-		fingerprint := r.Header.Get("X-Fingerprint")
+		region, herr := requestRegion(d, r)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		fingerprint := requestFingerprint(d, r)
 
 		// Determine voter's selected category (if any)
 		var selected string
 		if fingerprint != "" {
-			if cat, err := d.Repo.GetVoterCategory(ctx, ID, fingerprint); err == nil && cat != nil {
+			if cat, err := d.Repo.GetVoterCategory(ctx, ID, region, fingerprint); err == nil && cat != nil {
 				selected = *cat
 			}
 		}
 		// Fetch all tallies (including zero) for the movie
-		tallies, err := d.Repo.GetTalliesAllCategories(ctx, ID)
+		tallies, err := d.Repo.GetTalliesAllCategories(ctx, ID, region)
 		if err != nil {
 			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to get tallies", err))
 			return
@@ -43,27 +48,25 @@ func MovieTallies(d deps.ServerDeps) http.HandlerFunc {
 			}
 			return tallies[i].Count > tallies[j].Count
 		})
-		// Shape response with voter_choice per item
-		type item struct {
-			MovieID     int64  `json:"movie_id"`
-			Category    string `json:"category"`
-			Count       int64  `json:"count"`
-			VoterChoice bool   `json:"voter_choice"`
+		counts := make(map[string]int64, len(tallies))
+		for _, t := range tallies {
+			counts[t.Category] = t.Count
 		}
-		respItems := make([]item, 0, len(tallies))
+		stats := model.NewTallyStats(counts)
+		// Shape response with shares and voter_choice per item
+		respItems := make([]TallyItem, 0, len(tallies))
 		for _, t := range tallies {
-			respItems = append(respItems, item{
+			sh := stats.Shares[t.Category]
+			respItems = append(respItems, TallyItem{
 				MovieID:     t.MovieID,
 				Category:    t.Category,
 				Count:       t.Count,
+				Share:       sh.Share,
+				ShareLow:    sh.Low,
+				ShareHigh:   sh.High,
 				VoterChoice: selected != "" && selected == t.Category,
 			})
 		}
-		b, _ := json.Marshal(map[string]any{
-			"items": respItems,
-		})
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write(b)
+		pkghttpx.WriteJSON(w, http.StatusOK, MovieTalliesResponse{Region: region, Items: respItems, TotalVotes: stats.TotalVotes, Leading: stats.Leading})
 	}
 }
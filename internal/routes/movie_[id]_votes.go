@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"cinekami-server/internal/repos"
 
 	pkghttpx "cinekami-server/pkg/httpx"
+	pkgmetrics "cinekami-server/pkg/metrics"
 )
 
 // category enforces that only allowed values are accepted in JSON
@@ -44,25 +46,25 @@ func allowedCategoriesList() string {
 	return strings.Join(keys, ", ")
 }
 
-// MovieVote handles POST /movies/{id}/votes
+// MovieVote handles POST /v1/movies/{id}/votes; the vote counts on the board of
+// the requested region, where the movie must have been released.
 func MovieVote(d deps.ServerDeps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		type voteReq struct {
 			Category    category `json:"category"`
 			Fingerprint string   `json:"fingerprint"`
 		}
-		type voteResp struct {
-			Inserted      bool             `json:"inserted"`
-			Message       string           `json:"message"`
-			Tallies       map[string]int64 `json:"tallies"`
-			VotedCategory string           `json:"voted_category"`
-		}
 
 		ctx := r.Context()
 		idStr := r.PathValue("id")
-		ID, err := strconv.ParseInt(idStr, 10, 64)
+		ID, _ := strconv.ParseInt(idStr, 10, 64) // 0 when invalid, rejected below
 		var req voteReq
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				pkghttpx.WriteError(w, r, pkghttpx.PayloadTooLarge("request body too large", err))
+				return
+			}
 			if errors.Is(err, errInvalidCategory) {
 				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid category; allowed: "+allowedCategoriesList(), err))
 				return
@@ -71,33 +73,26 @@ func MovieVote(d deps.ServerDeps) http.HandlerFunc {
 			return
 		}
 		// Prefer header for fingerprint, fallback to body for compatibility
-		fingerprint := r.Header.Get("X-Fingerprint")
+		fingerprint := requestFingerprint(d, r)
 		if fingerprint == "" {
-			fingerprint = req.Fingerprint
+			fingerprint = d.Fingerprints.Digest(req.Fingerprint)
 		}
 		if ID == 0 || fingerprint == "" { // Category validated by JSON unmarshal
 			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("missing fields", nil))
 			return
 		}
-		inserted, err := d.Repo.CreateVote(ctx, ID, string(req.Category), fingerprint, time.Now().UTC())
-		if err != nil {
-			if errors.Is(err, repos.ErrVotingClosed) {
-				pkghttpx.WriteError(w, r, pkghttpx.Forbidden("voting closed", err))
-				return
-			}
-			if err.Error() == "movie not found" {
-				pkghttpx.WriteError(w, r, pkghttpx.NotFound("movie not found", err))
-				return
-			}
-			if err.Error() == "invalid category" {
-				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid category; allowed: "+allowedCategoriesList(), err))
-				return
-			}
-			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to record vote", err))
+		region, herr := requestRegion(d, r)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		inserted, herr := castVote(ctx, d, ID, region, string(req.Category), fingerprint)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
 			return
 		}
 		// Fetch current tallies for this movie (includes zeros)
-		rows, terr := d.Repo.GetTalliesAllCategories(ctx, ID)
+		rows, terr := d.Repo.GetTalliesAllCategories(ctx, ID, region)
 		if terr != nil {
 			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to load tallies", terr))
 			return
@@ -112,16 +107,41 @@ func MovieVote(d deps.ServerDeps) http.HandlerFunc {
 		}
 		// Determine current user's category from DB (may differ if duplicate vote)
 		voted := ""
-		if cat, gerr := d.Repo.GetVoterCategory(ctx, ID, fingerprint); gerr == nil && cat != nil {
+		if cat, gerr := d.Repo.GetVoterCategory(ctx, ID, region, fingerprint); gerr == nil && cat != nil {
 			voted = *cat
 		}
-		// Invalidate caches
-		_ = d.Cache.DeletePrefix(ctx, "active_movies:"+time.Now().UTC().Format("2006-01"))
-		pkghttpx.WriteJSON(w, http.StatusOK, voteResp{Inserted: inserted, Message: func() string {
+		pkghttpx.WriteJSON(w, http.StatusOK, VoteResponse{Region: region, Inserted: inserted, Message: func() string {
 			if inserted {
 				return "vote recorded"
 			}
 			return "duplicate ignored"
-		}(), Tallies: tallyMap, VotedCategory: voted})
+		}(), Tallies: tallyMap, Stats: model.NewTallyStats(tallyMap), VotedCategory: voted})
+	}
+}
+
+// castVote records a vote by fingerprint on the board of region, counts it in
+// the metrics and drops the cached listings and cards it changes. inserted is
+// false when the voter had already voted for the movie.
+func castVote(ctx context.Context, d deps.ServerDeps, movieID int64, region, category, fingerprint string) (bool, *pkghttpx.HTTPError) {
+	inserted, err := d.Repo.CreateVote(ctx, movieID, region, category, fingerprint, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, repos.ErrVotingClosed) {
+			return false, pkghttpx.Forbidden("voting closed", err)
+		}
+		if errors.Is(err, repos.ErrMovieNotFound) {
+			return false, pkghttpx.NotFound("movie not found", err)
+		}
+		if err.Error() == "invalid category" {
+			return false, pkghttpx.BadRequest("invalid category; allowed: "+allowedCategoriesList(), err)
+		}
+		return false, pkghttpx.Internal("failed to record vote", err)
+	}
+	if inserted {
+		pkgmetrics.VotesRecorded.WithLabelValues(category).Inc()
+	} else {
+		pkgmetrics.VotesDuplicate.Inc()
 	}
+	_ = d.Cache.DeletePrefix(ctx, "active_movies:"+region+":"+time.Now().UTC().Format("2006-01")+":")
+	_ = d.Cache.DeletePrefix(ctx, "cards:movies:"+region+":"+strconv.FormatInt(movieID, 10)+":")
+	return inserted, nil
 }
@@ -0,0 +1,148 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/repos"
+
+	pkgcard "cinekami-server/pkg/card"
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+// Default size of an embedded widget.
+const (
+	embedWidth  = 480
+	embedHeight = 280
+)
+
+// OEmbed handles GET /oembed?url=&maxwidth=&maxheight=, the oEmbed provider
+// for the share pages of this server: a movie (/movies/{id}) is a rich embed
+// of its widget, or a photo of its card when embedding is disabled, and a
+// month (/snapshots/{year}/{month}) a photo of its card. Only JSON is served.
+func OEmbed(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		q := r.URL.Query()
+		if f := q.Get("format"); f != "" && f != "json" {
+			pkghttpx.WriteError(w, r, pkghttpx.NotImplemented("only format=json is supported", nil))
+			return
+		}
+		maxWidth, werr := oembedBound(q.Get("maxwidth"))
+		maxHeight, herr := oembedBound(q.Get("maxheight"))
+		if werr != nil || herr != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid maxwidth or maxheight", errors.Join(werr, herr)))
+			return
+		}
+		base := publicURL(d, r)
+		target, err := url.Parse(q.Get("url"))
+		if q.Get("url") == "" || err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("url is required", err))
+			return
+		}
+		if b, _ := url.Parse(base); target.Host != b.Host {
+			pkghttpx.WriteError(w, r, pkghttpx.NotFound("url is not served here", nil))
+			return
+		}
+		region, rerr := regionParam(ctx, d, target.Query().Get("region"))
+		if rerr != nil {
+			pkghttpx.WriteError(w, r, rerr)
+			return
+		}
+
+		resp := OEmbedResponse{Version: "1.0", ProviderName: cardBrand, ProviderURL: base}
+		query := "?region=" + region
+		parts := strings.Split(strings.TrimSuffix(strings.Trim(target.Path, "/"), "/card.png"), "/")
+		switch {
+		case len(parts) == 2 && parts[0] == "movies":
+			id, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				pkghttpx.WriteError(w, r, pkghttpx.NotFound("url is not a movie or month", err))
+				return
+			}
+			m, err := d.Repo.Movies.Card(ctx, id, region)
+			if errors.Is(err, repos.ErrMovieNotFound) {
+				pkghttpx.WriteError(w, r, pkghttpx.NotFound("movie not found", err))
+				return
+			}
+			if err != nil {
+				pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to load movie", err))
+				return
+			}
+			resp.Title = m.Title
+			resp.CacheAge = int(d.ActiveMoviesTTL.Seconds())
+			path := "/movies/" + strconv.FormatInt(id, 10)
+			if len(d.EmbedOrigins) == 0 {
+				oembedPhoto(&resp, base+path+"/card.png"+query, maxWidth, maxHeight)
+				break
+			}
+			resp.Type = "rich"
+			resp.Width, resp.Height = embedWidth, embedHeight
+			if maxWidth > 0 {
+				resp.Width = min(resp.Width, maxWidth)
+			}
+			if maxHeight > 0 {
+				resp.Height = min(resp.Height, maxHeight)
+			}
+			resp.HTML = fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" title="%s" loading="lazy" style="border:0"></iframe>`,
+				html.EscapeString(base+"/embed"+path+query), resp.Width, resp.Height, html.EscapeString("Votes for "+m.Title+" on "+cardBrand))
+			resp.ThumbnailURL, resp.ThumbnailWidth, resp.ThumbnailHeight = base+path+"/card.png"+query, pkgcard.Width, pkgcard.Height
+		case len(parts) == 3 && parts[0] == "snapshots":
+			year, yerr := strconv.Atoi(parts[1])
+			month, merr := strconv.Atoi(parts[2])
+			if yerr != nil || merr != nil || month < 1 || month > 12 {
+				pkghttpx.WriteError(w, r, pkghttpx.NotFound("url is not a movie or month", nil))
+				return
+			}
+			_, err := d.Repo.Snapshots.MonthResult(ctx, region, fmt.Sprintf("%04d-%02d", year, month))
+			if errors.Is(err, repos.ErrMonthNotArchived) {
+				pkghttpx.WriteError(w, r, pkghttpx.NotFound("month not archived", err))
+				return
+			}
+			if err != nil {
+				pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to load results", err))
+				return
+			}
+			resp.Title = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).Format("January 2006") + " results (" + region + ")"
+			resp.CacheAge = int(d.SnapshotsTTL.Seconds())
+			oembedPhoto(&resp, fmt.Sprintf("%s/snapshots/%d/%d/card.png%s", base, year, month, query), maxWidth, maxHeight)
+		default:
+			pkghttpx.WriteError(w, r, pkghttpx.NotFound("url is not a movie or month", nil))
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(resp.CacheAge))
+		pkghttpx.WriteJSON(w, http.StatusOK, resp)
+	}
+}
+
+// oembedPhoto makes resp a photo of a card, scaled down to fit the bounds.
+func oembedPhoto(resp *OEmbedResponse, src string, maxWidth, maxHeight int) {
+	resp.Type, resp.URL = "photo", src
+	scale := 1.0
+	if maxWidth > 0 {
+		scale = min(scale, float64(maxWidth)/pkgcard.Width)
+	}
+	if maxHeight > 0 {
+		scale = min(scale, float64(maxHeight)/pkgcard.Height)
+	}
+	resp.Width, resp.Height = int(pkgcard.Width*scale), int(pkgcard.Height*scale)
+}
+
+// oembedBound parses maxwidth or maxheight; 0 means unbounded.
+func oembedBound(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("want a positive integer, got %q", v)
+	}
+	return n, nil
+}
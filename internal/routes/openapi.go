@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"net/http"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/openapi"
+)
+
+// OpenAPI handles GET /v1/openapi.json
+func OpenAPI(_ deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(openapi.Spec)
+	}
+}
@@ -0,0 +1,82 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"cinekami-server/internal/deps"
+	"cinekami-server/internal/listquery"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+)
+
+const (
+	regionsCacheKey = "regions:enabled"
+	regionsTTL      = 5 * time.Minute
+)
+
+// Regions handles GET /v1/regions
+func Regions(d deps.ServerDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		all, err := d.Repo.Regions.ListRegions(r.Context())
+		if err != nil {
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to list regions", err))
+			return
+		}
+		items := make([]model.Region, 0, len(all))
+		for _, rg := range all {
+			if rg.Enabled {
+				items = append(items, rg)
+			}
+		}
+		pkghttpx.WriteJSON(w, http.StatusOK, RegionsResponse{Items: items, Default: d.DefaultRegion})
+	}
+}
+
+// requestRegion returns the board a request is for: ?region= (any case) or the
+// default region. Other regions must be enabled; unknown ones get a 400 whose
+// details list the enabled regions.
+func requestRegion(d deps.ServerDeps, r *http.Request) (string, *pkghttpx.HTTPError) {
+	return regionParam(r.Context(), d, r.URL.Query().Get("region"))
+}
+
+// regionParam validates a region parameter v as requestRegion does.
+func regionParam(ctx context.Context, d deps.ServerDeps, v string) (string, *pkghttpx.HTTPError) {
+	if v == "" {
+		return d.DefaultRegion, nil
+	}
+	code := strings.ToUpper(v)
+	if !repos.ValidRegionCode(code) {
+		return "", queryError(&listquery.Error{Param: "region", Value: v, Reason: "expected an ISO 3166-1 alpha-2 code"})
+	}
+	if code == d.DefaultRegion {
+		return code, nil
+	}
+	enabled, err := enabledRegions(ctx, d)
+	if err != nil {
+		return "", pkghttpx.Internal("failed to load regions", err)
+	}
+	if !slices.Contains(enabled, code) {
+		return "", queryError(&listquery.Error{Param: "region", Value: v, Allowed: enabled})
+	}
+	return code, nil
+}
+
+// enabledRegions returns the enabled region codes, cached briefly since every
+// request with ?region= needs them.
+func enabledRegions(ctx context.Context, d deps.ServerDeps) ([]string, error) {
+	if cached, ok := d.Cache.Get(ctx, regionsCacheKey); ok {
+		return strings.Split(cached, ","), nil
+	}
+	codes, err := d.Repo.EnabledRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_ = d.Cache.Set(ctx, regionsCacheKey, strings.Join(codes, ","), regionsTTL)
+	return codes, nil
+}
@@ -5,16 +5,16 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
-	"time"
 
 	"cinekami-server/internal/deps"
+	"cinekami-server/internal/model"
 	"cinekami-server/internal/repos"
 
+	pkgcrypto "cinekami-server/pkg/crypto"
 	pkghttpx "cinekami-server/pkg/httpx"
 )
 
-// Snapshots handles GET /snapshots/{year}/{month}
+// Snapshots handles GET /v1/snapshots/{year}/{month}
 func Snapshots(d deps.ServerDeps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -28,83 +28,19 @@ func Snapshots(d deps.ServerDeps) http.HandlerFunc {
 		}
 		mon := fmt.Sprintf("%04d-%02d", year, month)
 
-		// Parse filters
-		sortBy := strings.ToLower(r.URL.Query().Get("sort_by"))
-		if sortBy == "" {
-			sortBy = string(repos.SnapSortByPopularity)
-		}
-		sortDir := strings.ToLower(r.URL.Query().Get("sort_dir"))
-		if sortDir == "" {
-			sortDir = string(repos.SnapSortDirDesc)
-		}
-		var minPop *float64
-		if v := r.URL.Query().Get("min_popularity"); v != "" {
-			if f, err := strconv.ParseFloat(v, 64); err == nil {
-				minPop = &f
-			} else {
-				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid min_popularity", err))
-				return
-			}
-		}
-		var maxPop *float64
-		if v := r.URL.Query().Get("max_popularity"); v != "" {
-			if f, err := strconv.ParseFloat(v, 64); err == nil {
-				maxPop = &f
-			} else {
-				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid max_popularity", err))
-				return
-			}
-		}
-		if minPop != nil && maxPop != nil && *minPop > *maxPop {
-			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("min_popularity > max_popularity", nil))
+		lr, herr := parseListRequest(d, r, "snapshots", mon, pkgcrypto.Codec.DecodeSnapshotsCursor)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
 			return
 		}
 
-		cursor := r.URL.Query().Get("cursor")
-		limitStr := r.URL.Query().Get("limit")
-		if limitStr == "" {
-			limitStr = "20"
-		}
-		lim64, err := strconv.ParseInt(limitStr, 10, 32)
-		if err != nil || lim64 <= 0 || lim64 > 100 {
-			pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid limit", err))
-			return
-		}
-		var curKey *float64
-		var curID *int64
-		if cursor != "" {
-			if d.Codec == nil {
-				pkghttpx.WriteError(w, r, pkghttpx.Internal("codec crypto not configured", nil))
-				return
-			}
-			k, id, decErr := d.Codec.DecodeSnapshotsCursor(cursor)
-			if decErr != nil {
-				pkghttpx.WriteError(w, r, pkghttpx.BadRequest("invalid cursor", decErr))
-				return
-			}
-			curKey = &k
-			curID = &id
+		// Only the voted filter depends on the caller
+		var extra []string
+		if lr.query.Voted != nil {
+			extra = append(extra, "fp="+lr.fingerprint)
 		}
-
-		cacheKey := strings.Join([]string{
-			"snapshots:", mon,
-			":sort:", sortBy,
-			":dir:", sortDir,
-			":min:", func() string {
-				if minPop != nil {
-					return strconv.FormatFloat(*minPop, 'f', -1, 64)
-				}
-				return ""
-			}(),
-			":max:", func() string {
-				if maxPop != nil {
-					return strconv.FormatFloat(*maxPop, 'f', -1, 64)
-				}
-				return ""
-			}(),
-			":cursor:", cursor,
-			":limit:", strconv.FormatInt(lim64, 10),
-		}, "")
+		cacheKey := lr.cacheKey("snapshots", mon, extra...)
+		setLanguageHeaders(w, lr.language)
 		if cached, ok := d.Cache.Get(ctx, cacheKey); ok {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
@@ -112,66 +48,66 @@ func Snapshots(d deps.ServerDeps) http.HandlerFunc {
 			return
 		}
 
-		items, lastKey, err := d.Repo.ListSnapshotsByMonthFiltered(ctx, repos.SnapshotsFilter{
-			Month:     mon,
-			SortBy:    repos.SnapshotSortBy(sortBy),
-			SortDir:   repos.SnapshotSortDir(sortDir),
-			MinPop:    minPop,
-			MaxPop:    maxPop,
-			CursorKey: curKey,
-			CursorID:  curID,
-			Limit:     int32(lim64),
-		})
-		if err != nil {
-			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to get snapshots", err))
-			return
+		f := repos.SnapshotsFilter{Region: lr.region, Language: translation(d, lr.language), Month: mon, Query: lr.query, Limit: lr.limit}
+		f.CursorKeys, f.CursorID, f.Backward = lr.cursorKeys()
+		if lr.query.Voted != nil {
+			f.Fingerprint = &lr.fingerprint
 		}
-		total, err := d.Repo.CountSnapshotsByMonthFiltered(ctx, mon, minPop, maxPop)
+		page, err := d.Repo.ListSnapshotsByMonthFiltered(ctx, f)
 		if err != nil {
-			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to count snapshots", err))
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to get snapshots", err))
 			return
 		}
-		var next *string
-		if len(items) == int(lim64) && d.Codec != nil {
-			last := items[len(items)-1]
-			nextVal := d.Codec.EncodeSnapshotsCursor(lastKey, last.MovieID)
-			next = &nextVal
-		}
-		resp := map[string]any{
-			"items": items,
-			"count": len(items),
-			"total": total,
-		}
-		if next != nil {
-			resp["next_cursor"] = *next
-		}
-		b, _ := json.Marshal(resp)
-		_ = d.Cache.Set(ctx, cacheKey, string(b), 24*time.Hour)
+		var total *int64
+		if lr.includeTotal {
+			n, err := d.Repo.CountSnapshotsByMonthFiltered(ctx, f)
+			if err != nil {
+				pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to count snapshots", err))
+				return
+			}
+			total = &n
+		}
+		next, prev := pageCursors(page, lr.cursor != "", f.Backward, func(s model.Snapshot) int64 { return s.MovieID },
+			func(c pkgcrypto.KeysetCursor) string { return d.Codec.EncodeSnapshotsCursor(lr.scope, c) }, d.Codec != nil)
+		b, _ := json.Marshal(SnapshotsPage{
+			Region:     lr.region,
+			Items:      page.Items,
+			Count:      len(page.Items),
+			Total:      total,
+			HasMore:    next != nil,
+			NextCursor: next,
+			PrevCursor: prev,
+		})
+		_ = d.Cache.Set(ctx, cacheKey, string(b), d.SnapshotsTTL)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write(b)
 	}
 }
 
-// SnapshotsAvailable handles GET /snapshots/available
+// SnapshotsAvailable handles GET /v1/snapshots/available
 func SnapshotsAvailable(d deps.ServerDeps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		cacheKey := "snapshots:available"
+		region, herr := requestRegion(d, r)
+		if herr != nil {
+			pkghttpx.WriteError(w, r, herr)
+			return
+		}
+		cacheKey := "snapshots:available:" + region
 		if cached, ok := d.Cache.Get(ctx, cacheKey); ok {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write([]byte(cached))
 			return
 		}
-		rows, err := d.Repo.ListAvailableYearMonths(ctx)
+		rows, err := d.Repo.ListAvailableYearMonths(ctx, region)
 		if err != nil {
 			pkghttpx.WriteError(w, r, pkghttpx.Internal("failed to list available months", err))
 			return
 		}
-		resp := map[string]any{"items": rows}
-		b, _ := json.Marshal(resp)
-		_ = d.Cache.Set(ctx, cacheKey, string(b), 24*time.Hour)
+		b, _ := json.Marshal(SnapshotsAvailableResponse{Region: region, Items: rows})
+		_ = d.Cache.Set(ctx, cacheKey, string(b), d.SnapshotsTTL)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write(b)
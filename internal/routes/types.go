@@ -0,0 +1,179 @@
+package routes
+
+import (
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+)
+
+// Response shapes for the public API. Each type has a matching schema under
+// components/schemas in internal/openapi/openapi.json; keep them in sync.
+
+// HealthResponse is returned by GET /v1/health.
+type HealthResponse struct {
+	Status        string `json:"status"`
+	Service       string `json:"service"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+}
+
+// LivenessResponse is returned by GET /livez.
+type LivenessResponse struct {
+	Status        string `json:"status"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+}
+
+// CheckResult is the outcome of a single readiness check.
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessResponse is returned by GET /readyz.
+type ReadinessResponse struct {
+	Status   string                 `json:"status"`
+	Draining bool                   `json:"draining"`
+	Checks   map[string]CheckResult `json:"checks"`
+}
+
+// MoviesPage is returned by GET /v1/movies/active.
+type MoviesPage struct {
+	Region     string        `json:"region"`
+	Items      []model.Movie `json:"items"`
+	Count      int           `json:"count"`
+	Total      *int64        `json:"total,omitempty"` // omitted with include_total=false
+	HasMore    bool          `json:"has_more"`
+	NextCursor *string       `json:"next_cursor,omitempty"`
+	PrevCursor *string       `json:"prev_cursor,omitempty"`
+}
+
+// TallyItem is a single category count with its share of all votes, the share's
+// 95% Wilson interval and the caller's selection flag.
+type TallyItem struct {
+	MovieID     int64   `json:"movie_id"`
+	Category    string  `json:"category"`
+	Count       int64   `json:"count"`
+	Share       float64 `json:"share"`
+	ShareLow    float64 `json:"share_low"`
+	ShareHigh   float64 `json:"share_high"`
+	VoterChoice bool    `json:"voter_choice"`
+}
+
+// MovieTalliesResponse is returned by GET /v1/movies/{id}/tallies.
+type MovieTalliesResponse struct {
+	Region     string         `json:"region"`
+	Items      []TallyItem    `json:"items"`
+	TotalVotes int64          `json:"total_votes"`
+	Leading    *model.Leading `json:"leading,omitempty"`
+}
+
+// VoteResponse is returned by POST /v1/movies/{id}/votes.
+type VoteResponse struct {
+	Region        string            `json:"region"`
+	Inserted      bool              `json:"inserted"`
+	Message       string            `json:"message"`
+	Tallies       map[string]int64  `json:"tallies"`
+	Stats         *model.TallyStats `json:"stats"`
+	VotedCategory string            `json:"voted_category"`
+}
+
+// SnapshotsPage is returned by GET /v1/snapshots/{year}/{month}.
+type SnapshotsPage struct {
+	Region     string           `json:"region"`
+	Items      []model.Snapshot `json:"items"`
+	Count      int              `json:"count"`
+	Total      *int64           `json:"total,omitempty"` // omitted with include_total=false
+	HasMore    bool             `json:"has_more"`
+	NextCursor *string          `json:"next_cursor,omitempty"`
+	PrevCursor *string          `json:"prev_cursor,omitempty"`
+}
+
+// SnapshotsAvailableResponse is returned by GET /v1/snapshots/available.
+type SnapshotsAvailableResponse struct {
+	Region string                  `json:"region"`
+	Items  []repos.AvailableMonths `json:"items"`
+}
+
+// RegionsResponse is returned by GET /v1/regions.
+type RegionsResponse struct {
+	Items   []model.Region `json:"items"`
+	Default string         `json:"default"` // used when a request has no region parameter
+}
+
+// Admin API responses. The /admin routes are internal and not part of the public spec.
+
+// AdminVotesResponse is returned by GET /admin/votes.
+type AdminVotesResponse struct {
+	Items []model.AdminVote `json:"items"`
+	Count int               `json:"count"`
+}
+
+// AdminAuditResponse is returned by GET /admin/audit.
+type AdminAuditResponse struct {
+	Items      []model.AuditEntry `json:"items"`
+	Count      int                `json:"count"`
+	NextBefore *int64             `json:"next_before,omitempty"`
+}
+
+// AdminJobResponse is returned when an admin triggers a background job.
+type AdminJobResponse struct {
+	Job     string   `json:"job"`
+	Status  string   `json:"status"`
+	Month   string   `json:"month,omitempty"`
+	Regions []string `json:"regions,omitempty"`
+}
+
+// AdminSnapshotResponse is returned by POST /admin/snapshots/{year}/{month}.
+type AdminSnapshotResponse struct {
+	Region string `json:"region"`
+	Month  string `json:"month"`
+	Count  int64  `json:"count"`
+}
+
+// AdminUserResponse is returned by PUT /admin/users/{email}.
+type AdminUserResponse struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// MeDeleteResponse is returned by DELETE /v1/me.
+type MeDeleteResponse struct {
+	Anonymized int64 `json:"anonymized"` // voters whose fingerprint and account were dropped
+}
+
+// AdminWebhooksResponse is returned by GET /admin/webhooks.
+type AdminWebhooksResponse struct {
+	Items []model.WebhookSubscription `json:"items"`
+	Count int                         `json:"count"`
+}
+
+// AdminWebhookDeliveriesResponse is returned by GET /admin/webhooks/{id}/deliveries.
+type AdminWebhookDeliveriesResponse struct {
+	Items      []model.WebhookDelivery `json:"items"`
+	Count      int                     `json:"count"`
+	NextBefore *int64                  `json:"next_before,omitempty"`
+}
+
+// AdminWebhookRetryResponse is returned by POST /admin/webhooks/deliveries/{id}/retry.
+type AdminWebhookRetryResponse struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+// OEmbedResponse is an oEmbed 1.0 response, returned by GET /oembed. Photos
+// carry URL, rich embeds HTML.
+type OEmbedResponse struct {
+	Type            string `json:"type"` // photo or rich
+	Version         string `json:"version"`
+	Title           string `json:"title,omitempty"`
+	ProviderName    string `json:"provider_name"`
+	ProviderURL     string `json:"provider_url"`
+	CacheAge        int    `json:"cache_age,omitempty"`
+	URL             string `json:"url,omitempty"`
+	HTML            string `json:"html,omitempty"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int    `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight int    `json:"thumbnail_height,omitempty"`
+}
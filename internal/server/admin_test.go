@@ -0,0 +1,364 @@
+package server_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"cinekami-server/internal/jobs"
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/repos"
+	"cinekami-server/internal/server"
+
+	pkgcache "cinekami-server/pkg/cache"
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgratelimit "cinekami-server/pkg/ratelimit"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+	pkgwebhook "cinekami-server/pkg/webhook"
+)
+
+func TestAdminRequiresCredentials(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.AdminKeys = map[string]string{"test-admin-key": "ci"}
+	r := s.Router()
+
+	cases := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{"missing", nil, http.StatusUnauthorized},
+		{"wrong key", map[string]string{"Authorization": "Bearer nope"}, http.StatusUnauthorized},
+		{"basic without repo", map[string]string{"Authorization": "Basic YUBiLmM6cHc="}, http.StatusUnauthorized},
+		// a valid key gets past auth and fails validation instead
+		{"valid key", map[string]string{"Authorization": "Bearer test-admin-key"}, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := serve(r, http.MethodGet, "/admin/movies/abc", "", tc.headers)
+			if w.Code != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+			if tc.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Fatalf("expected WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestAdminCredentialAttemptsThrottled(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.AdminKeys = map[string]string{"test-admin-key": "ci"}
+	s.CredentialAttempts = pkgratelimit.New(0, time.Minute)
+	r := s.Router()
+
+	w := serve(r, http.MethodGet, "/admin/movies/abc", "", map[string]string{"Authorization": "Basic YUBiLmM6cHc="})
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("basic: expected 429 with Retry-After, got %d %v", w.Code, w.Header())
+	}
+	// API keys are not credential attempts
+	if w = serve(r, http.MethodGet, "/admin/movies/abc", "", map[string]string{"Authorization": "Bearer test-admin-key"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("key: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// TestAdminUnknownEmailVerified checks a password for an unknown email is
+// verified like one for an admin, so the time taken does not tell admins
+// apart. Set TEST_DATABASE_URL to run it.
+func TestAdminUnknownEmailVerified(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	email := fmt.Sprintf("admin-%d@example.com", time.Now().UnixNano())
+	hash, salt, err := pkgcrypto.HashPassword("right")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if _, err := repo.Admin.UpsertUser(ctx, email, hash, salt, "admin"); err != nil {
+		t.Fatalf("insert admin: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := pool.Exec(context.Background(), "DELETE FROM users WHERE email = $1", email); err != nil {
+			t.Errorf("cleanup user: %v", err)
+		}
+	})
+	r := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil).Router()
+	basic := func(email, password string) map[string]string {
+		return map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(email+":"+password))}
+	}
+	if w := serve(r, http.MethodGet, "/admin/movies/abc", "", basic(email, "right")); w.Code != http.StatusBadRequest {
+		t.Fatalf("admin: expected 400 past auth, got %d: %s", w.Code, w.Body.String())
+	}
+
+	pkgcrypto.DummyPassword()
+	fastest := func(email string) time.Duration {
+		best := time.Duration(math.MaxInt64)
+		for range 2 {
+			started := time.Now()
+			if w := serve(r, http.MethodGet, "/admin/movies/abc", "", basic(email, "wrong")); w.Code != http.StatusUnauthorized {
+				t.Fatalf("%s: expected 401, got %d", email, w.Code)
+			}
+			best = min(best, time.Since(started))
+		}
+		return best
+	}
+	known, unknown := fastest(email), fastest("nobody-"+email)
+	if unknown < known/2 {
+		t.Fatalf("unknown email answered in %s, an admin's wrong password in %s", unknown, known)
+	}
+}
+
+// TestAdminModeration merges a duplicate movie, voids a voter's votes and checks
+// tallies, listings and the audit log. Set TEST_DATABASE_URL to run it.
+func TestAdminModeration(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	targetID := testMovieIDs(t, pool, 2)
+	sourceID := targetID + 1
+	release := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if _, err := repo.UpsertMovies(ctx, "RO", []pkgtmdb.Movie{
+		{TMDBID: int32(targetID), Title: "Admin Target", ReleaseDate: release, Popularity: 2e6},
+		{TMDBID: int32(sourceID), Title: "Admin Duplicate", ReleaseDate: release, Popularity: 2e6},
+	}); err != nil {
+		t.Fatalf("insert movies: %v", err)
+	}
+
+	s := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.AdminKeys = map[string]string{"test-admin-key": "ci"}
+	r := s.Router()
+	auth := map[string]string{"Authorization": "Bearer test-admin-key"}
+	src, dst := strconv.FormatInt(sourceID, 10), strconv.FormatInt(targetID, 10)
+	fpA, fpB := fmt.Sprintf("admin-a-%d", targetID), fmt.Sprintf("admin-b-%d", targetID)
+
+	for _, v := range []struct{ movie, fp, cat string }{
+		{src, fpA, "couple"}, {src, fpB, "couple"}, {dst, fpA, "streaming"},
+	} {
+		if w := serve(r, http.MethodPost, "/v1/movies/"+v.movie+"/votes", `{"category":"`+v.cat+`"}`, map[string]string{"X-Fingerprint": v.fp}); w.Code != http.StatusOK {
+			t.Fatalf("vote: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	w := serve(r, http.MethodPost, "/admin/movies/"+src+"/merge", `{"into":`+dst+`}`, auth)
+	if w.Code != http.StatusOK {
+		t.Fatalf("merge: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var merged repos.MergeResult
+	_ = json.Unmarshal(w.Body.Bytes(), &merged)
+	if merged.VotesMoved != 1 || merged.VotesDropped != 1 {
+		t.Fatalf("expected 1 vote moved and 1 dropped, got %+v", merged)
+	}
+	if tallies := talliesOf(t, repo, targetID); tallies["couple"] != 1 || tallies["streaming"] != 1 {
+		t.Fatalf("unexpected target tallies after merge: %v", tallies)
+	}
+
+	// The duplicate is hidden: no votes, no listing
+	w = serve(r, http.MethodPost, "/v1/movies/"+src+"/votes", `{"category":"arr"}`, map[string]string{"X-Fingerprint": fpA})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("vote on hidden movie: expected 404, got %d", w.Code)
+	}
+	w = serve(r, http.MethodGet, "/v1/movies/active?limit=100&min_popularity=1999999", "", nil)
+	if contains(w.Body.String(), `"id":`+src+`,`) {
+		t.Fatalf("hidden movie still listed: %s", w.Body.String())
+	}
+
+	w = serve(r, http.MethodGet, "/admin/votes?fingerprint="+fpB, "", auth)
+	if w.Code != http.StatusOK || !contains(w.Body.String(), `"count":1`) {
+		t.Fatalf("list votes: got %d: %s", w.Code, w.Body.String())
+	}
+	w = serve(r, http.MethodPost, "/admin/votes/void", `{"fingerprint":"`+fpB+`"}`, auth)
+	if w.Code != http.StatusOK || !contains(w.Body.String(), `"deleted":1`) {
+		t.Fatalf("void: got %d: %s", w.Code, w.Body.String())
+	}
+	if tallies := talliesOf(t, repo, targetID); tallies["couple"] != 0 || tallies["streaming"] != 1 {
+		t.Fatalf("unexpected target tallies after void: %v", tallies)
+	}
+	if w = serve(r, http.MethodPost, "/admin/votes/void", `{}`, auth); w.Code != http.StatusBadRequest {
+		t.Fatalf("empty void filter: expected 400, got %d", w.Code)
+	}
+
+	w = serve(r, http.MethodPatch, "/admin/movies/"+dst, `{"title":"Admin Target (fixed)"}`, auth)
+	if w.Code != http.StatusOK || !contains(w.Body.String(), `"admin_locked":true`) {
+		t.Fatalf("patch: got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serve(r, http.MethodGet, "/admin/audit?limit=10", "", auth)
+	for _, action := range []string{"movie.merge", "votes.void", "movie.update"} {
+		if !contains(w.Body.String(), `"action":"`+action+`"`) {
+			t.Fatalf("audit log missing %s: %s", action, w.Body.String())
+		}
+	}
+	if !contains(w.Body.String(), `"actor":"api_key:ci"`) {
+		t.Fatalf("audit log missing actor: %s", w.Body.String())
+	}
+}
+
+func talliesOf(t *testing.T, repo *repos.Repository, movieID int64) map[string]int64 {
+	t.Helper()
+	rows, err := repo.GetTalliesAllCategories(context.Background(), movieID, "RO")
+	if err != nil {
+		t.Fatalf("tallies: %v", err)
+	}
+	out := map[string]int64{}
+	for _, r := range rows {
+		out[r.Category] = r.Count
+	}
+	return out
+}
+
+// TestAdminWebhooks subscribes a receiver to vote milestones, casts the first
+// vote of a movie and checks the signed delivery and the delivery log. Set
+// TEST_DATABASE_URL to run it.
+func TestAdminWebhooks(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	repo.Votes.Milestones = []int64{1}
+	now := time.Now().UTC()
+	movieID := testMovieIDs(t, pool, 1)
+	release := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if _, err := repo.UpsertMovies(ctx, "RO", []pkgtmdb.Movie{
+		{TMDBID: int32(movieID), Title: "Webhook Movie", ReleaseDate: release, Popularity: 1},
+	}); err != nil {
+		t.Fatalf("insert movie: %v", err)
+	}
+
+	var secret string
+	received := make(chan model.VoteMilestoneEvent, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := pkgwebhook.Verify(secret, r.Header.Get(pkgwebhook.SignatureHeader), body, time.Now(), time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var env struct {
+			Data model.VoteMilestoneEvent `json:"data"`
+		}
+		_ = json.Unmarshal(body, &env)
+		received <- env.Data
+	}))
+	defer receiver.Close()
+
+	s := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.AdminKeys = map[string]string{"test-admin-key": "ci"}
+	r := s.Router()
+	auth := map[string]string{"Authorization": "Bearer test-admin-key"}
+
+	if w := serve(r, http.MethodPost, "/admin/webhooks", `{"url":"`+receiver.URL+`","events":["nope"]}`, auth); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown event: expected 400, got %d", w.Code)
+	}
+	w := serve(r, http.MethodPost, "/admin/webhooks", `{"url":"`+receiver.URL+`","events":["vote.milestone"]}`, auth)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var sub model.WebhookSubscription
+	_ = json.Unmarshal(w.Body.Bytes(), &sub)
+	if sub.Secret == "" {
+		t.Fatalf("expected a generated secret: %s", w.Body.String())
+	}
+	secret = sub.Secret
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), "DELETE FROM webhook_subscriptions WHERE id = $1", sub.ID)
+	})
+	if w = serve(r, http.MethodGet, "/admin/webhooks", "", auth); contains(w.Body.String(), secret) {
+		t.Fatalf("secret leaked in listing: %s", w.Body.String())
+	}
+
+	if w = serve(r, http.MethodPost, fmt.Sprintf("/v1/movies/%d/votes", movieID), `{"category":"couple"}`, map[string]string{"X-Fingerprint": fmt.Sprintf("webhook-%d", movieID)}); w.Code != http.StatusOK {
+		t.Fatalf("vote: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := jobs.DeliverWebhooks(ctx, repo, &pkgwebhook.Sender{Client: receiver.Client()}, 3); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	select {
+	case ev := <-received:
+		if ev.MovieID != movieID || ev.Milestone != 1 || ev.Region != "RO" {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("receiver got no delivery")
+	}
+
+	w = serve(r, http.MethodGet, "/admin/webhooks/"+sub.ID+"/deliveries?status=delivered", "", auth)
+	if w.Code != http.StatusOK || !contains(w.Body.String(), `"count":1`) || !contains(w.Body.String(), `"response_status":200`) {
+		t.Fatalf("delivery log: got %d: %s", w.Code, w.Body.String())
+	}
+	if w = serve(r, http.MethodDelete, "/admin/webhooks/"+sub.ID, "", auth); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", w.Code)
+	}
+	if w = serve(r, http.MethodGet, "/admin/webhooks/"+sub.ID+"/deliveries", "", auth); w.Code != http.StatusNotFound {
+		t.Fatalf("deliveries of deleted webhook: expected 404, got %d", w.Code)
+	}
+}
+
+// TestSnapshotClosedAnnouncedOnce closes a month several times at once, again
+// after its archived movie was hidden and another released, and an empty month
+// once, and checks a subscriber is told about the first close only. Set
+// TEST_DATABASE_URL to run it.
+func TestSnapshotClosedAnnouncedOnce(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	region := testRegion(t, pool, "XS", "Snapshot event test")
+	movieID := testMovieIDs(t, pool, 2)
+	release := time.Date(2001, time.April, 10, 0, 0, 0, 0, time.UTC)
+	if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{
+		{TMDBID: int32(movieID), Title: "Closed Once", ReleaseDate: release, Popularity: 1},
+	}); err != nil {
+		t.Fatalf("insert movie: %v", err)
+	}
+	sub, err := repo.Webhooks.CreateSubscription(ctx, "https://partner.example.com/hook", []string{model.EventSnapshotClosed}, "secret")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := pool.Exec(context.Background(), "DELETE FROM webhook_subscriptions WHERE id = $1", sub.ID); err != nil {
+			t.Errorf("cleanup webhook: %v", err)
+		}
+	})
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			if err := repo.SnapshotMonth(ctx, region, release.Year(), release.Month()); err != nil {
+				t.Errorf("snapshot: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if _, err := pool.Exec(ctx, "UPDATE movies SET hidden = true WHERE id = $1", movieID); err != nil {
+		t.Fatalf("hide movie: %v", err)
+	}
+	if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{
+		{TMDBID: int32(movieID + 1), Title: "Closed Later", ReleaseDate: release, Popularity: 1},
+	}); err != nil {
+		t.Fatalf("insert movie: %v", err)
+	}
+	if err := repo.SnapshotMonth(ctx, region, release.Year(), release.Month()); err != nil {
+		t.Fatalf("snapshot after hiding: %v", err)
+	}
+	if err := repo.SnapshotMonth(ctx, region, release.Year(), release.Month()+1); err != nil {
+		t.Fatalf("snapshot empty month: %v", err)
+	}
+	var months []string
+	rows, err := pool.Query(ctx, "SELECT payload->>'month' FROM webhook_deliveries WHERE subscription_id = $1 AND payload->>'region' = $2 ORDER BY id", sub.ID, region)
+	if err != nil {
+		t.Fatalf("deliveries: %v", err)
+	}
+	for rows.Next() {
+		var m string
+		if err := rows.Scan(&m); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		months = append(months, m)
+	}
+	if rows.Err() != nil || len(months) != 1 || months[0] != "2001-04" {
+		t.Fatalf("expected one snapshot.closed for 2001-04, got %v (%v)", months, rows.Err())
+	}
+}
@@ -0,0 +1,181 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"cinekami-server/internal/routes"
+	"cinekami-server/internal/server"
+
+	pkgcache "cinekami-server/pkg/cache"
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+func TestCardParams(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	r := s.Router()
+	for _, url := range []string{"/movies/abc/card.png", "/movies/abc", "/snapshots/2025/13/card.png", "/snapshots/x/1"} {
+		if w := serve(r, http.MethodGet, url, "", nil); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", url, w.Code, w.Body.String())
+		}
+	}
+}
+
+// TestCardServedFromCache checks a cached card is served without loading the
+// movie: the server has no database.
+func TestCardServedFromCache(t *testing.T) {
+	c := pkgcache.NewInMemory()
+	cached, _ := json.Marshal(map[string]any{"updated": time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), "body": []byte("cached png")})
+	if err := c.Set(context.Background(), "cards:movies:RO:42:png", string(cached), time.Minute); err != nil {
+		t.Fatalf("cache: %v", err)
+	}
+	r := server.New(nil, c, pkgcrypto.NewHMAC([]byte("test")), nil).Router()
+	w := serve(r, http.MethodGet, "/movies/42/card.png", "", nil)
+	if w.Code != http.StatusOK || w.Body.String() != "cached png" || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("expected the cached card, got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
+
+func TestEmbedFraming(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	w := serve(s.Router(), http.MethodGet, "/embed/movies/abc", "", nil)
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.HasSuffix(csp, "frame-ancestors *") || w.Header().Get("X-Frame-Options") != "" {
+		t.Fatalf("widget not frameable by default: %v", w.Header())
+	}
+
+	s.EmbedOrigins = []string{"https://blog.example.com", "https://news.example.org"}
+	r := s.Router()
+	w = serve(r, http.MethodGet, "/embed/movies/abc", "", nil)
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.HasSuffix(csp, "frame-ancestors https://blog.example.com https://news.example.org") ||
+		!strings.Contains(csp, "style-src 'unsafe-inline'") {
+		t.Fatalf("unexpected widget CSP %q", csp)
+	}
+	w = serve(r, http.MethodGet, "/movies/abc", "", nil)
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "img-src 'self'") || !strings.HasSuffix(csp, "frame-ancestors 'none'") ||
+		w.Header().Get("X-Frame-Options") != "DENY" {
+		t.Fatalf("share page may be framed: %v", w.Header())
+	}
+	w = serve(r, http.MethodGet, "/v1/health", "", nil)
+	if w.Header().Get("Content-Security-Policy") != "default-src 'none'; base-uri 'none'; frame-ancestors 'none'" || w.Header().Get("X-Frame-Options") != "DENY" {
+		t.Fatalf("API headers changed: %v", w.Header())
+	}
+
+	s.EmbedOrigins = nil
+	if csp := serve(s.Router(), http.MethodGet, "/embed/movies/abc", "", nil).Header().Get("Content-Security-Policy"); !strings.HasSuffix(csp, "frame-ancestors 'none'") {
+		t.Fatalf("embedding not disabled: %q", csp)
+	}
+}
+
+func TestOEmbedParams(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.PublicURL = "https://api.example.com"
+	r := s.Router()
+	cases := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"xml", "url=https://api.example.com/movies/1&format=xml", http.StatusNotImplemented},
+		{"missing url", "", http.StatusBadRequest},
+		{"bad maxwidth", "url=https://api.example.com/movies/1&maxwidth=-5", http.StatusBadRequest},
+		{"other host", "url=https://elsewhere.example.com/movies/1", http.StatusNotFound},
+		{"other path", "url=https://api.example.com/v1/regions", http.StatusNotFound},
+		{"bad month", "url=https://api.example.com/snapshots/2025/13", http.StatusNotFound},
+		{"bad region", "url=https://api.example.com/movies/1%3Fregion%3DROU", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if w := serve(r, http.MethodGet, "/oembed?"+tc.query, "", nil); w.Code != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestCards renders the card and share page of a movie and a closed month in a
+// throwaway region and checks that a vote replaces the cached movie card. Set
+// TEST_DATABASE_URL to run it.
+func TestCards(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	region := testRegion(t, pool, "XC", "Card test")
+	now := time.Now().UTC()
+	movieID := testMovieIDs(t, pool, 1)
+	id := strconv.FormatInt(movieID, 10)
+	release := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{
+		{TMDBID: int32(movieID), Title: "Cards & Co", ReleaseDate: release, Popularity: 1},
+	}); err != nil {
+		t.Fatalf("insert movie: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `INSERT INTO snapshots (region, month, movie_id, tallies) VALUES ($1, '2001-02', $2, '{"couple":3,"arr":1}')`, region, movieID); err != nil {
+		t.Fatalf("insert snapshot: %v", err)
+	}
+
+	s := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.PublicURL = "https://api.example.com"
+	r := s.Router()
+
+	w := serve(r, http.MethodGet, "/movies/"+id+"/card.png?region="+region, "", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("movie card: got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	if _, err := png.Decode(w.Body); err != nil {
+		t.Fatalf("movie card is not a PNG: %v", err)
+	}
+	etag := w.Header().Get("ETag")
+	if w = serve(r, http.MethodGet, "/movies/"+id+"/card.png?region="+region, "", map[string]string{"If-None-Match": etag}); w.Code != http.StatusNotModified {
+		t.Fatalf("If-None-Match: expected 304, got %d", w.Code)
+	}
+	if w = serve(r, http.MethodPost, "/v1/movies/"+id+"/votes?region="+region, `{"category":"couple"}`, map[string]string{"X-Fingerprint": "card-" + id}); w.Code != http.StatusOK {
+		t.Fatalf("vote: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w = serve(r, http.MethodGet, "/movies/"+id+"/card.png?region="+region, "", map[string]string{"If-None-Match": etag}); w.Code != http.StatusOK {
+		t.Fatalf("card after vote: expected a new card, got %d", w.Code)
+	}
+
+	w = serve(r, http.MethodGet, "/movies/"+id+"?region="+region, "", nil)
+	want := `<meta property="og:image" content="https://api.example.com/movies/` + id + `/card.png?region=` + region + `">`
+	if w.Code != http.StatusOK || !contains(w.Body.String(), want) || !contains(w.Body.String(), "Cards &amp; Co on cinekami") ||
+		!contains(w.Body.String(), "Leading: Couple") || !contains(w.Body.String(), `type="application/json+oembed"`) {
+		t.Fatalf("movie page: got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serve(r, http.MethodGet, "/snapshots/2001/2?region="+region, "", nil)
+	if w.Code != http.StatusOK || !contains(w.Body.String(), "February 2001 results (XC)") || !contains(w.Body.String(), "Couple: Cards &amp; Co") {
+		t.Fatalf("month page: got %d: %s", w.Code, w.Body.String())
+	}
+	if w = serve(r, http.MethodGet, "/snapshots/2001/2/card.png?region="+region, "", nil); w.Code != http.StatusOK || w.Header().Get("Last-Modified") == "" {
+		t.Fatalf("month card: got %d %v", w.Code, w.Header())
+	}
+
+	w = serve(r, http.MethodGet, "/embed/movies/"+id+"?region="+region, "", nil)
+	if w.Code != http.StatusOK || !contains(w.Body.String(), "<span>Couple</span><span>1 · 100%</span>") {
+		t.Fatalf("widget: got %d: %s", w.Code, w.Body.String())
+	}
+	var oe routes.OEmbedResponse
+	w = serve(r, http.MethodGet, "/oembed?maxwidth=400&url="+url.QueryEscape("https://api.example.com/movies/"+id+"?region="+region), "", nil)
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &oe) != nil || oe.Type != "rich" || oe.Width != 400 ||
+		!contains(oe.HTML, `src="https://api.example.com/embed/movies/`+id+`?region=`+region+`"`) || oe.Title != "Cards & Co" {
+		t.Fatalf("movie oEmbed: got %d: %s", w.Code, w.Body.String())
+	}
+	w = serve(r, http.MethodGet, "/oembed?maxwidth=600&url="+url.QueryEscape("https://api.example.com/snapshots/2001/2?region="+region), "", nil)
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &oe) != nil || oe.Type != "photo" || oe.Width != 600 || oe.Height != 315 ||
+		oe.URL != "https://api.example.com/snapshots/2001/2/card.png?region="+region {
+		t.Fatalf("month oEmbed: got %d: %s", w.Code, w.Body.String())
+	}
+	if w = serve(r, http.MethodGet, "/snapshots/2001/3/card.png?region="+region, "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("month without snapshots: expected 404, got %d", w.Code)
+	}
+	if w = serve(r, http.MethodGet, "/movies/1/card.png?region="+region, "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown movie: expected 404, got %d", w.Code)
+	}
+}
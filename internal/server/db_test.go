@@ -0,0 +1,109 @@
+package server_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"cinekami-server/internal/migrate"
+	"cinekami-server/internal/repos"
+
+	pkgdb "cinekami-server/pkg/db"
+)
+
+// testDatabase migrates and connects to TEST_DATABASE_URL, skipping the test
+// without it. The pool closes after every cleanup the test registers later, so
+// those can still delete their fixture rows.
+func testDatabase(t *testing.T) (*pgxpool.Pool, *repos.Repository) {
+	t.Helper()
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	if err := migrate.Up(dbURL); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	pool, err := pkgdb.Connect(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool, repos.New(pool)
+}
+
+// testTracedRepo is a repository over a pool of its own whose queries tracer
+// sees; call it after testDatabase.
+func testTracedRepo(t *testing.T, tracer pgx.QueryTracer) *repos.Repository {
+	t.Helper()
+	cfg, err := pgxpool.ParseConfig(os.Getenv("TEST_DATABASE_URL"))
+	if err != nil {
+		t.Fatalf("parse database url: %v", err)
+	}
+	cfg.ConnConfig.Tracer = tracer
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return repos.New(pool)
+}
+
+// queryCounter is a tracer counting the queries of one sqlc query name.
+type queryCounter struct {
+	name string
+	n    atomic.Int64
+}
+
+func (c *queryCounter) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if strings.HasPrefix(data.SQL, "-- name: "+c.name+" ") {
+		c.n.Add(1)
+	}
+	return ctx
+}
+
+func (c *queryCounter) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+// testRegion adds a throwaway board and removes it at cleanup with everything
+// still recorded for it.
+func testRegion(t *testing.T, pool *pgxpool.Pool, code, name string) string {
+	t.Helper()
+	if _, err := pool.Exec(context.Background(), "INSERT INTO regions (code, name) VALUES ($1, $2) ON CONFLICT DO NOTHING", code, name); err != nil {
+		t.Fatalf("insert region: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, table := range []string{"snapshots", "vote_milestones", "votes", "vote_tallies", "movie_releases"} {
+			if _, err := pool.Exec(context.Background(), "DELETE FROM "+table+" WHERE region = $1", code); err != nil {
+				t.Errorf("cleanup %s: %v", table, err)
+			}
+		}
+		if _, err := pool.Exec(context.Background(), "DELETE FROM regions WHERE code = $1", code); err != nil {
+			t.Errorf("cleanup region: %v", err)
+		}
+	})
+	return code
+}
+
+// fixtureIDs hands out movie ids far above TMDb's, distinct within a run and
+// unlikely to repeat across runs.
+var fixtureIDs atomic.Int64
+
+func init() { fixtureIDs.Store(1_000_000_000 + time.Now().Unix()%100_000*1_000) }
+
+// testMovieIDs reserves n consecutive movie ids and returns the first; the
+// movies with them are deleted at cleanup, their votes and snapshots with them.
+func testMovieIDs(t *testing.T, pool *pgxpool.Pool, n int) int64 {
+	t.Helper()
+	first := fixtureIDs.Add(int64(n)) - int64(n)
+	t.Cleanup(func() {
+		if _, err := pool.Exec(context.Background(), "DELETE FROM movies WHERE id >= $1 AND id < $2", first, first+int64(n)); err != nil {
+			t.Errorf("cleanup movies: %v", err)
+		}
+	})
+	return first
+}
@@ -0,0 +1,124 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/server"
+
+	pkgcache "cinekami-server/pkg/cache"
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+func TestAdminExportParams(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.AdminKeys = map[string]string{"test-admin-key": "ci"}
+	r := s.Router()
+
+	cases := []struct {
+		name   string
+		url    string
+		accept string
+		status int
+	}{
+		{"missing from", "/admin/export/votes", "", http.StatusBadRequest},
+		{"bad month", "/admin/export/votes?from=2025-13", "", http.StatusBadRequest},
+		{"to before from", "/admin/export/votes?from=2025-05&to=2025-04", "", http.StatusBadRequest},
+		{"bad region", "/admin/export/tallies?from=2025-05&region=ROU", "", http.StatusBadRequest},
+		{"bad format", "/admin/export/snapshots?from=2025-05&format=xlsx", "", http.StatusBadRequest},
+		{"not acceptable", "/admin/export/snapshots?from=2025-05", "text/html", http.StatusNotAcceptable},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := map[string]string{"Authorization": "Bearer test-admin-key"}
+			if tc.accept != "" {
+				h["Accept"] = tc.accept
+			}
+			if w := serve(r, http.MethodGet, tc.url, "", h); w.Code != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestAdminExport exports a throwaway region's snapshots, tallies and votes in
+// each format. Set TEST_DATABASE_URL to run it.
+func TestAdminExport(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	region := testRegion(t, pool, "XE", "Export test")
+	now := time.Now().UTC()
+	movieID := testMovieIDs(t, pool, 1)
+	release := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := release.Format("2006-01")
+	if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{
+		{TMDBID: int32(movieID), Title: "Export, Quoted", ReleaseDate: release, Popularity: 1},
+	}); err != nil {
+		t.Fatalf("insert movie: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `INSERT INTO snapshots (region, month, movie_id, tallies) VALUES ($1, $2, $3, '{"couple":3,"arr":1}')`, region, month, movieID); err != nil {
+		t.Fatalf("insert snapshot: %v", err)
+	}
+
+	s := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.AdminKeys = map[string]string{"test-admin-key": "ci"}
+	r := s.Router()
+	auth := map[string]string{"Authorization": "Bearer test-admin-key"}
+	id := strconv.FormatInt(movieID, 10)
+
+	if w := serve(r, http.MethodPost, "/v1/movies/"+id+"/votes?region="+region, `{"category":"streaming"}`, map[string]string{"X-Fingerprint": "export-" + id}); w.Code != http.StatusOK {
+		t.Fatalf("vote: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := serve(r, http.MethodGet, "/admin/export/snapshots?format=csv&from="+month+"&region="+region, "", auth)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("snapshots csv: got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, `filename="cinekami-snapshots-`+month+`-xe.csv"`) {
+		t.Fatalf("unexpected Content-Disposition %q", cd)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "region,month,movie_id") ||
+		!strings.HasPrefix(lines[1], region+","+month+","+id+`,"Export, Quoted",`) || !strings.HasSuffix(lines[1], ",0,3,0,1,4") {
+		t.Fatalf("unexpected snapshots csv:\n%s", w.Body.String())
+	}
+
+	w = serve(r, http.MethodGet, "/admin/export/tallies?from="+month+"&region="+region, "", map[string]string{
+		"Authorization": "Bearer test-admin-key", "Accept": "application/x-ndjson"})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"title":"Export, Quoted","release_date":"`+release.Format("2006-01-02")+`","solo_friends":0,"couple":0,"streaming":1`) {
+		t.Fatalf("tallies ndjson: got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serve(r, http.MethodGet, "/admin/export/votes?format=parquet&from="+month+"&region="+region, "", auth)
+	if w.Code != http.StatusOK {
+		t.Fatalf("votes parquet: got %d: %s", w.Code, w.Body.String())
+	}
+	votes, err := parquet.Read[model.VoteExportRow](bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("read parquet: %v", err)
+	}
+	if len(votes) != 1 || votes[0].MovieID != movieID || votes[0].Category != "streaming" ||
+		!votes[0].VotedAt.Equal(votes[0].VotedAt.Truncate(time.Hour)) {
+		t.Fatalf("unexpected votes: %+v", votes)
+	}
+
+	// A hidden movie leaves every export, its votes included.
+	if _, err := pool.Exec(ctx, "UPDATE movies SET hidden = true WHERE id = $1", movieID); err != nil {
+		t.Fatalf("hide movie: %v", err)
+	}
+	for _, kind := range []string{"snapshots", "tallies", "votes"} {
+		w = serve(r, http.MethodGet, "/admin/export/"+kind+"?format=ndjson&from="+month+"&region="+region, "", auth)
+		if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "" {
+			t.Fatalf("%s of a hidden movie: got %d: %s", kind, w.Code, w.Body.String())
+		}
+	}
+}
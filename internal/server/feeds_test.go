@@ -0,0 +1,135 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"cinekami-server/internal/server"
+
+	pkgcache "cinekami-server/pkg/cache"
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+// TestFeeds checks the results and votable feeds of a throwaway region and
+// their conditional GET support. Set TEST_DATABASE_URL to run it.
+func TestFeeds(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	region := testRegion(t, pool, "XF", "Feed test")
+	now := time.Now().UTC()
+	movieID := testMovieIDs(t, pool, 1)
+	release := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{
+		{TMDBID: int32(movieID), Title: "Feed & Friends", ReleaseDate: release, Popularity: 1},
+	}); err != nil {
+		t.Fatalf("insert movie: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `INSERT INTO snapshots (region, month, movie_id, tallies) VALUES ($1, '2001-01', $2, '{"couple":3,"arr":0}')`, region, movieID); err != nil {
+		t.Fatalf("insert snapshot: %v", err)
+	}
+
+	s := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.PublicURL = "https://api.example.com"
+	r := s.Router()
+
+	w := serve(r, http.MethodGet, "/feeds/active.atom?region="+region, "", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/atom+xml; charset=utf-8" {
+		t.Fatalf("active feed: got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	body := w.Body.String()
+	for _, want := range []string{"Feed &amp; Friends", fmt.Sprintf("urn:cinekami:movies:%s:%d", region, movieID), `href="https://api.example.com/feeds/active.atom?region=XF"`} {
+		if !contains(body, want) {
+			t.Fatalf("active feed lacks %s:\n%s", want, body)
+		}
+	}
+	etag, modified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if etag == "" || modified == "" {
+		t.Fatalf("missing validators: %v", w.Header())
+	}
+	if w = serve(r, http.MethodGet, "/feeds/active.atom?region="+region, "", map[string]string{"If-None-Match": etag}); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("If-None-Match: expected empty 304, got %d", w.Code)
+	}
+	if w = serve(r, http.MethodGet, "/feeds/active.atom?region="+region, "", map[string]string{"If-Modified-Since": modified}); w.Code != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since: expected 304, got %d", w.Code)
+	}
+	if w = serve(r, http.MethodGet, "/feeds/active.atom?region="+region, "", map[string]string{"If-None-Match": `"stale"`}); w.Code != http.StatusOK {
+		t.Fatalf("stale ETag: expected 200, got %d", w.Code)
+	}
+
+	w = serve(r, http.MethodGet, "/feeds/snapshots.atom?region="+region, "", nil)
+	if w.Code != http.StatusOK || !contains(w.Body.String(), "January 2001 results (XF)") || !contains(w.Body.String(), "couple: Feed &amp; Friends (3 votes)") {
+		t.Fatalf("snapshots feed: got %d: %s", w.Code, w.Body.String())
+	}
+	if contains(w.Body.String(), "arr:") {
+		t.Fatalf("category without votes listed: %s", w.Body.String())
+	}
+	w = serve(r, http.MethodGet, "/feeds/snapshots.json?region="+region, "", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/feed+json; charset=utf-8" ||
+		!contains(w.Body.String(), `"_cinekami"`) || !contains(w.Body.String(), `"category": "couple"`) {
+		t.Fatalf("JSON feed: got %d: %s", w.Code, w.Body.String())
+	}
+	if w = serve(r, http.MethodGet, "/feeds/snapshots.json?region=zz", "", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown region: expected 400, got %d", w.Code)
+	}
+}
+
+// TestCalendar checks that a moved release date updates the calendar event in
+// place: same UID, higher SEQUENCE. Set TEST_DATABASE_URL to run it.
+func TestCalendar(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	region := testRegion(t, pool, "XC", "Calendar test")
+	now := time.Now().UTC()
+	movieID := testMovieIDs(t, pool, 1)
+	release := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 3)
+	movie := pkgtmdb.Movie{TMDBID: int32(movieID), Title: "Calendar, Movie", ReleaseDate: release, Popularity: 1, GenreIDs: []int32{18}}
+	if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{movie}); err != nil {
+		t.Fatalf("insert movie: %v", err)
+	}
+	get := func(target string) string {
+		t.Helper()
+		r := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil).Router()
+		w := serve(r, http.MethodGet, target, "", nil)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+			t.Fatalf("%s: got %d %q: %s", target, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+		return w.Body.String()
+	}
+
+	releaseUID := fmt.Sprintf("UID:release-%d-%s@cinekami\r\n", movieID, region)
+	closeUID := fmt.Sprintf("UID:vote-close-%d-%s@cinekami\r\n", movieID, region)
+	body := get("/calendar.ics?region=" + region)
+	for _, want := range []string{releaseUID, closeUID, "SEQUENCE:0\r\n", "DTSTART;VALUE=DATE:" + release.Format("20060102"), "Calendar\\, Movie", "TRIGGER:-P1D"} {
+		if !contains(body, want) {
+			t.Fatalf("calendar lacks %q:\n%s", want, body)
+		}
+	}
+	if body = get("/calendar/releases.ics?region=" + region); !contains(body, releaseUID) || contains(body, closeUID) {
+		t.Fatalf("releases calendar:\n%s", body)
+	}
+	if body = get("/calendar/deadlines.ics?region=" + region + "&genre=28"); contains(body, closeUID) {
+		t.Fatalf("genre filter ignored:\n%s", body)
+	}
+
+	// The sync moves the date: same UID, next sequence; an unchanged sync does not count
+	moved := release.AddDate(0, 0, 7)
+	movie.ReleaseDate = moved
+	for range 2 {
+		if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{movie}); err != nil {
+			t.Fatalf("move release: %v", err)
+		}
+	}
+	body = get("/calendar.ics?region=" + region + "&genre=18,28")
+	if !contains(body, releaseUID) || !contains(body, "SEQUENCE:1\r\n") || !contains(body, "DTSTART;VALUE=DATE:"+moved.Format("20060102")) {
+		t.Fatalf("moved release not updated in place:\n%s", body)
+	}
+
+	r := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil).Router()
+	if w := serve(r, http.MethodGet, "/calendar.ics?genre=drama", "", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid genre: expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,210 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"cinekami-server/internal/server"
+
+	pkgcache "cinekami-server/pkg/cache"
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func graphQL(t *testing.T, h http.Handler, query string, variables map[string]any, headers map[string]string) (int, graphQLResponse) {
+	t.Helper()
+	b, _ := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	headers["Content-Type"] = "application/json"
+	w := serve(h, http.MethodPost, "/graphql", string(b), headers)
+	var resp graphQLResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+func TestGraphQLRequests(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	r := s.Router()
+
+	code, resp := graphQL(t, r, `{ categories __schema { queryType { name } } }`, nil, nil)
+	if code != http.StatusOK || len(resp.Errors) != 0 || string(resp.Data["categories"]) != `["SOLO_FRIENDS","COUPLE","STREAMING","ARR"]` {
+		t.Fatalf("categories: got %d %+v", code, resp)
+	}
+
+	cases := []struct {
+		name   string
+		query  string
+		status int
+		code   string
+	}{
+		{"syntax", `{ categories`, http.StatusBadRequest, "bad_request"},
+		{"unknown field", `{ movies { id } }`, http.StatusBadRequest, "bad_request"},
+		{"limit", `{ activeMovies(first: 1000) { nodes { id } } }`, http.StatusOK, "bad_request"},
+		{"both cursors", `{ activeMovies(after: "a", before: "b") { nodes { id } } }`, http.StatusOK, "bad_request"},
+		{"cursor", `{ snapshots(year: 2025, month: 1, after: "garbage") { nodes { month } } }`, http.StatusOK, "invalid_cursor"},
+		{"month", `{ snapshots(year: 2025, month: 13) { nodes { month } } }`, http.StatusOK, "bad_request"},
+		{"vote without fingerprint", `mutation { vote(movieId: "1", category: COUPLE) { inserted } }`, http.StatusOK, "bad_request"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, resp := graphQL(t, r, tc.query, nil, nil)
+			if code != tc.status || len(resp.Errors) == 0 || resp.Errors[0].Extensions["code"] != tc.code {
+				t.Fatalf("expected %d %s, got %d %+v", tc.status, tc.code, code, resp)
+			}
+		})
+	}
+
+	if w := serve(r, http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation { vote(movieId: "1", category: ARR) { inserted } }`), "", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("mutation over GET: expected 405, got %d", w.Code)
+	}
+	if w := serve(r, http.MethodGet, "/graphql?query="+url.QueryEscape(`{ categories }`), "", nil); w.Code != http.StatusOK {
+		t.Fatalf("query over GET: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPost, "/graphql", `{ categories }`, map[string]string{"Content-Type": "text/plain"}); w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("text/plain: expected 415, got %d", w.Code)
+	}
+	if w := serve(r, http.MethodPost, "/graphql", `{ categories }`, map[string]string{"Content-Type": "application/graphql"}); w.Code != http.StatusOK {
+		t.Fatalf("application/graphql: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.GraphQLMaxDepth, s.GraphQLMaxComplexity = 4, 200
+	r := s.Router()
+
+	deep := `{ snapshots(year: 2025, month: 1) { nodes { movie { tallies { count } } } } }`
+	if code, resp := graphQL(t, r, deep, nil, nil); code != http.StatusBadRequest || resp.Errors[0].Extensions["code"] != "query_too_deep" {
+		t.Fatalf("depth: got %d %+v", code, resp)
+	}
+	// 1 + 20 * (1 + 10) over the limit through a fragment and a variable
+	wide := `query($n: Int) { activeMovies(first: $n) { nodes { ...m } } } fragment m on Movie { id title releaseDate overview posterPath popularity genreIds totalVotes leading { category } }`
+	if code, resp := graphQL(t, r, wide, map[string]any{"n": 20}, nil); code != http.StatusBadRequest || resp.Errors[0].Extensions["code"] != "query_too_complex" {
+		t.Fatalf("complexity: got %d %+v", code, resp)
+	}
+	if code, resp := graphQL(t, r, wide, map[string]any{"n": 10}, nil); code != http.StatusOK || len(resp.Errors) == 0 || resp.Errors[0].Extensions["code"] == "query_too_complex" {
+		// within the limits it runs; without a database the resolver fails
+		t.Fatalf("complexity within the limit: got %d %+v", code, resp)
+	}
+	if code, _ := graphQL(t, r, `{ __schema { types { fields { type { ofType { ofType { name } } } } } } }`, nil, nil); code != http.StatusOK {
+		t.Fatalf("introspection counted against the limits: got %d", code)
+	}
+}
+
+// TestGraphQL votes through the mutation and reads the movie back from the
+// active listing and a closed month, paging with the signed cursors, in a
+// throwaway region. Set TEST_DATABASE_URL to run it.
+func TestGraphQL(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	region := testRegion(t, pool, "XQ", "GraphQL test")
+	now := time.Now().UTC()
+	first := testMovieIDs(t, pool, 2)
+	release := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{
+		{TMDBID: int32(first), Title: "Graph One", ReleaseDate: release, Popularity: 2},
+		{TMDBID: int32(first + 1), Title: "Graph Two", ReleaseDate: release, Popularity: 1},
+	}); err != nil {
+		t.Fatalf("insert movies: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `INSERT INTO snapshots (region, month, movie_id, tallies) VALUES ($1, '2001-02', $2, '{"arr":2}'), ($1, '2001-02', $3, '{"couple":1}')`,
+		region, first, first+1); err != nil {
+		t.Fatalf("insert snapshots: %v", err)
+	}
+
+	s := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	r := s.Router()
+	fp := map[string]string{"X-Fingerprint": "graphql-" + strconv.FormatInt(first, 10)}
+
+	vote := `mutation($id: ID!) { vote(movieId: $id, category: COUPLE, region: "` + region + `") { inserted votedCategory totalVotes tallies { category count } } }`
+	code, resp := graphQL(t, r, vote, map[string]any{"id": strconv.FormatInt(first, 10)}, fp)
+	var v struct {
+		Inserted      bool   `json:"inserted"`
+		VotedCategory string `json:"votedCategory"`
+		TotalVotes    int    `json:"totalVotes"`
+	}
+	if code != http.StatusOK || len(resp.Errors) != 0 || json.Unmarshal(resp.Data["vote"], &v) != nil || !v.Inserted || v.VotedCategory != "COUPLE" || v.TotalVotes != 1 {
+		t.Fatalf("vote: got %d %+v", code, resp)
+	}
+
+	type movies struct {
+		Nodes []struct {
+			ID            string `json:"id"`
+			VotedCategory string `json:"votedCategory"`
+			Leading       *struct {
+				Category string `json:"category"`
+			} `json:"leading"`
+		} `json:"nodes"`
+		PageInfo struct {
+			HasNextPage bool   `json:"hasNextPage"`
+			EndCursor   string `json:"endCursor"`
+		} `json:"pageInfo"`
+		TotalCount int `json:"totalCount"`
+	}
+	page := `query($after: String) { activeMovies(region: "` + region + `", first: 1, after: $after, sortBy: "popularity") {
+		nodes { id votedCategory leading { category } } pageInfo { hasNextPage endCursor } totalCount } }`
+	var m movies
+	code, resp = graphQL(t, r, page, nil, fp)
+	if code != http.StatusOK || len(resp.Errors) != 0 || json.Unmarshal(resp.Data["activeMovies"], &m) != nil || len(m.Nodes) != 1 ||
+		m.Nodes[0].VotedCategory != "COUPLE" || m.Nodes[0].Leading == nil || !m.PageInfo.HasNextPage || m.TotalCount != 2 {
+		t.Fatalf("first page: got %d %+v", code, resp)
+	}
+	code, resp = graphQL(t, r, page, map[string]any{"after": m.PageInfo.EndCursor}, fp)
+	if code != http.StatusOK || len(resp.Errors) != 0 || json.Unmarshal(resp.Data["activeMovies"], &m) != nil || len(m.Nodes) != 1 ||
+		m.Nodes[0].ID != strconv.FormatInt(first+1, 10) || m.Nodes[0].Leading != nil {
+		t.Fatalf("second page: got %d %+v", code, resp)
+	}
+
+	// the archived tallies next to the current ones, loaded for both movies at once
+	snaps := `{ snapshots(region: "` + region + `", year: 2001, month: 2, sortBy: "title", sortDir: "asc") {
+		nodes { leading { category } movie { title leading { category } } } } }`
+	var sn struct {
+		Nodes []struct {
+			Leading struct {
+				Category string `json:"category"`
+			} `json:"leading"`
+			Movie struct {
+				Title   string `json:"title"`
+				Leading *struct {
+					Category string `json:"category"`
+				} `json:"leading"`
+			} `json:"movie"`
+		} `json:"nodes"`
+	}
+	code, resp = graphQL(t, r, snaps, nil, nil)
+	if code != http.StatusOK || len(resp.Errors) != 0 || json.Unmarshal(resp.Data["snapshots"], &sn) != nil || len(sn.Nodes) != 2 ||
+		sn.Nodes[0].Leading.Category != "ARR" || sn.Nodes[0].Movie.Leading == nil || sn.Nodes[0].Movie.Leading.Category != "COUPLE" ||
+		sn.Nodes[1].Movie.Title != "Graph Two" || sn.Nodes[1].Movie.Leading != nil {
+		t.Fatalf("snapshots: got %d %+v", code, resp)
+	}
+
+	// Movies without tallies from their listing load them together: one query
+	// for the region, not one per movie.
+	counter := &queryCounter{name: "GetTalliesForMovies"}
+	counted := server.New(testTracedRepo(t, counter), pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil).Router()
+	both := `{ activeMovies(region: "` + region + `", first: 10) { nodes { id tallies { count } } }
+		snapshots(region: "` + region + `", year: 2001, month: 2) { nodes { movie { tallies { count } } } } }`
+	if code, resp = graphQL(t, counted, both, nil, nil); code != http.StatusOK || len(resp.Errors) != 0 {
+		t.Fatalf("batched tallies: got %d %+v", code, resp)
+	}
+	if n := counter.n.Load(); n != 1 {
+		t.Fatalf("expected one tallies query for the region, got %d", n)
+	}
+}
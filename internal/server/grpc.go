@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"cinekami-server/internal/pb/cinekamiv1"
+	"cinekami-server/internal/routes"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+	pkgrequestctx "cinekami-server/pkg/requestctx"
+	pkgtracing "cinekami-server/pkg/tracing"
+)
+
+// GRPCServer builds the gRPC server of the internal API over the same
+// dependencies as Router; opts are appended to the interceptors. The caller
+// owns Serve and GracefulStop.
+func (s *Server) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	// Same order as the HTTP middleware: tracing -> correlation id -> logging -> errors -> recovery
+	chain := []rpcMiddleware{rpcTracing, rpcCorrelationID, rpcLogging, rpcErrors, rpcRecovery}
+	unary := make([]grpc.UnaryServerInterceptor, len(chain))
+	stream := make([]grpc.StreamServerInterceptor, len(chain))
+	for i, m := range chain {
+		unary[i], stream[i] = m.unary, m.stream
+	}
+	srv := grpc.NewServer(append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...)}, opts...)...)
+	cinekamiv1.RegisterCinekamiServer(srv, routes.GRPC(s.ServerDeps))
+	return srv
+}
+
+// rpcMiddleware wraps a call, unary or streaming, as HTTP middleware wraps a
+// handler; next runs the rest of the chain with ctx.
+type rpcMiddleware func(ctx context.Context, method string, next func(context.Context) error) error
+
+func (m rpcMiddleware) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	err = m(ctx, info.FullMethod, func(ctx context.Context) error {
+		var herr error
+		resp, herr = handler(ctx, req)
+		return herr
+	})
+	return resp, err
+}
+
+func (m rpcMiddleware) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return m(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	})
+}
+
+// contextStream hands a stream handler the context the interceptors derived.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+// metadataCarrier lets the propagator read traceparent from incoming metadata,
+// whose keys are lower case.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) { metadata.MD(c).Set(key, value) }
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// tracing interceptor; extracts W3C traceparent and opens the server span named after the method
+func rpcTracing(ctx context.Context, method string, next func(context.Context) error) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := pkgtracing.Tracer().Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+		))
+	defer span.End()
+	err := next(ctx)
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+	return err
+}
+
+// correlation id interceptor; echoes x-correlation-id (or a new one) in the response headers
+func rpcCorrelationID(ctx context.Context, method string, next func(context.Context) error) error {
+	var cid string
+	if v := metadata.ValueFromIncomingContext(ctx, "x-correlation-id"); len(v) > 0 {
+		cid = v[0]
+	}
+	if cid == "" {
+		cid = xid.New().String()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-correlation-id", cid))
+	ctx = pkgrequestctx.WithCorrelationID(ctx, cid)
+	// Link the correlation id with the active trace, both ways
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		span.SetAttributes(attribute.String("correlation_id", cid))
+		ctx = pkgrequestctx.WithTraceID(ctx, span.SpanContext().TraceID().String())
+	}
+	return next(ctx)
+}
+
+// logging interceptor
+func rpcLogging(ctx context.Context, method string, next func(context.Context) error) error {
+	start := time.Now()
+	err := next(ctx)
+	dur := time.Since(start)
+	var remote, agent string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remote = p.Addr.String()
+	}
+	if v := metadata.ValueFromIncomingContext(ctx, "user-agent"); len(v) > 0 {
+		agent = v[0]
+	}
+	log.Info().
+		Str("method", method).
+		Str("correlation_id", pkgrequestctx.CorrelationID(ctx)).
+		Str("trace_id", pkgrequestctx.TraceID(ctx)).
+		Str("remote_ip", remote).
+		Str("user_agent", agent).
+		Str("code", status.Code(err).String()).
+		Dur("duration", dur).
+		Msg("grpc_request")
+	return err
+}
+
+// errors interceptor; turns the *pkghttpx.HTTPError a method fails with into a
+// status carrying the REST error code and logs it as pkghttpx.WriteError does
+func rpcErrors(ctx context.Context, method string, next func(context.Context) error) error {
+	err := next(ctx)
+	var he *pkghttpx.HTTPError
+	if !errors.As(err, &he) {
+		return err
+	}
+	log.Error().Str("correlation_id", pkgrequestctx.CorrelationID(ctx)).Str("trace_id", pkgrequestctx.TraceID(ctx)).Str("code", he.Code).Err(he.Err).Msg(he.Message)
+	return grpcStatus(he).Err()
+}
+
+// recovery interceptor; turns method panics into an internal error and logs the stack
+func rpcRecovery(ctx context.Context, method string, next func(context.Context) error) (err error) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+		log.Error().
+			Str("correlation_id", pkgrequestctx.CorrelationID(ctx)).
+			Str("trace_id", pkgrequestctx.TraceID(ctx)).
+			Str("method", method).
+			Interface("panic", rec).
+			Bytes("stack", debug.Stack()).
+			Msg("panic recovered")
+		err = pkghttpx.Internal("internal server error", fmt.Errorf("panic: %v", rec))
+	}()
+	return next(ctx)
+}
+
+// grpcCodes maps the HTTP statuses of HTTPError to status codes; other 4xx are
+// InvalidArgument and other 5xx Internal.
+var grpcCodes = map[int]codes.Code{
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusNotImplemented:        codes.Unimplemented,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+}
+
+// grpcStatus is the status of he: its message, and an ErrorInfo whose reason is
+// the REST error code and whose metadata are the error details.
+func grpcStatus(he *pkghttpx.HTTPError) *status.Status {
+	code, ok := grpcCodes[he.StatusCode]
+	if !ok {
+		code = codes.Internal
+		if he.StatusCode >= 400 && he.StatusCode < 500 {
+			code = codes.InvalidArgument
+		}
+	}
+	info := &errdetails.ErrorInfo{Reason: he.Code, Domain: "cinekami"}
+	if len(he.Details) > 0 {
+		info.Metadata = make(map[string]string, len(he.Details))
+		for k, v := range he.Details {
+			if list, ok := v.([]string); ok {
+				info.Metadata[k] = strings.Join(list, ",")
+			} else {
+				info.Metadata[k] = fmt.Sprint(v)
+			}
+		}
+	}
+	st := status.New(code, he.Message)
+	if ds, err := st.WithDetails(info); err == nil {
+		return ds
+	}
+	return st
+}
@@ -0,0 +1,240 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"cinekami-server/internal/pb/cinekamiv1"
+	"cinekami-server/internal/server"
+
+	pkgcache "cinekami-server/pkg/cache"
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+// grpcClient serves s's gRPC API over an in-memory listener for the test.
+func grpcClient(t *testing.T, s *server.Server) cinekamiv1.CinekamiClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := s.GRPCServer()
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return cinekamiv1.NewCinekamiClient(conn)
+}
+
+// rpcReason returns the status code of err and the REST error code it carries.
+func rpcReason(err error) (codes.Code, string) {
+	st := status.Convert(err)
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return st.Code(), info.Reason
+		}
+	}
+	return st.Code(), ""
+}
+
+func TestGRPCRequests(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	c := grpcClient(t, s)
+	ctx := context.Background()
+	fp := metadata.AppendToOutgoingContext(ctx, "x-fingerprint", "grpc-test")
+	month := int32(1)
+
+	cases := []struct {
+		name   string
+		call   func() error
+		code   codes.Code
+		reason string
+	}{
+		{"limit", func() error {
+			_, err := c.ListActiveMovies(ctx, &cinekamiv1.ListActiveMoviesRequest{Options: &cinekamiv1.ListOptions{Limit: 1000}})
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		{"sort", func() error {
+			_, err := c.ListActiveMovies(ctx, &cinekamiv1.ListActiveMoviesRequest{Options: &cinekamiv1.ListOptions{SortBy: "rating"}})
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		{"voted without fingerprint", func() error {
+			voted := true
+			_, err := c.ListActiveMovies(ctx, &cinekamiv1.ListActiveMoviesRequest{Options: &cinekamiv1.ListOptions{Voted: &voted}})
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		{"cursor", func() error {
+			_, err := c.ListSnapshots(ctx, &cinekamiv1.ListSnapshotsRequest{Year: 2025, Month: month, Options: &cinekamiv1.ListOptions{Cursor: "garbage"}})
+			return err
+		}, codes.InvalidArgument, "invalid_cursor"},
+		{"month", func() error {
+			_, err := c.ListSnapshots(ctx, &cinekamiv1.ListSnapshotsRequest{Year: 2025, Month: 13})
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		{"region", func() error {
+			_, err := c.GetTallies(ctx, &cinekamiv1.GetTalliesRequest{MovieId: 1, Region: "R0"})
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		{"language", func() error {
+			_, err := c.GetMovie(ctx, &cinekamiv1.GetMovieRequest{MovieId: 1, Language: "xx"})
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		{"movie id", func() error {
+			_, err := c.GetMovie(ctx, &cinekamiv1.GetMovieRequest{})
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		{"vote without fingerprint", func() error {
+			_, err := c.CastVote(ctx, &cinekamiv1.CastVoteRequest{MovieId: 1, Category: cinekamiv1.Category_CATEGORY_ARR})
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		{"vote without category", func() error {
+			_, err := c.CastVote(fp, &cinekamiv1.CastVoteRequest{MovieId: 1})
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		{"watch nothing", func() error {
+			stream, err := c.WatchTallies(ctx, &cinekamiv1.WatchTalliesRequest{})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		{"watch too many", func() error {
+			ids := make([]int64, 101)
+			for i := range ids {
+				ids[i] = int64(i + 1)
+			}
+			stream, err := c.WatchTallies(ctx, &cinekamiv1.WatchTalliesRequest{MovieIds: ids})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, codes.InvalidArgument, "bad_request"},
+		// without a database the repository panics; the call fails, the server stays up
+		{"panic", func() error {
+			_, err := c.GetTallies(ctx, &cinekamiv1.GetTalliesRequest{MovieId: 1})
+			return err
+		}, codes.Internal, "internal"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, reason := rpcReason(tc.call())
+			if code != tc.code || reason != tc.reason {
+				t.Fatalf("expected %s %s, got %s %s", tc.code, tc.reason, code, reason)
+			}
+		})
+	}
+
+	var header metadata.MD
+	cid := metadata.AppendToOutgoingContext(ctx, "x-correlation-id", "grpc-cid")
+	if _, err := c.GetMovie(cid, &cinekamiv1.GetMovieRequest{}, grpc.Header(&header)); err == nil || header.Get("x-correlation-id")[0] != "grpc-cid" {
+		t.Fatalf("correlation id not echoed: %v %v", err, header)
+	}
+	header = nil
+	if _, err := c.GetMovie(ctx, &cinekamiv1.GetMovieRequest{}, grpc.Header(&header)); err == nil || len(header.Get("x-correlation-id")) != 1 || header.Get("x-correlation-id")[0] == "" {
+		t.Fatalf("correlation id not generated: %v %v", err, header)
+	}
+	stream, err := c.WatchTallies(cid, &cinekamiv1.WatchTalliesRequest{})
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if header, err := stream.Header(); err != nil || header.Get("x-correlation-id")[0] != "grpc-cid" {
+		t.Fatalf("correlation id not echoed on the stream: %v %v", err, header)
+	}
+}
+
+// TestGRPC votes, reads the movie back through every method and watches the
+// tallies change in a throwaway region. Set TEST_DATABASE_URL to run it.
+func TestGRPC(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	region := testRegion(t, pool, "XP", "gRPC test")
+	now := time.Now().UTC()
+	first := testMovieIDs(t, pool, 2)
+	release := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{
+		{TMDBID: int32(first), Title: "Remote One", ReleaseDate: release, Popularity: 2},
+		{TMDBID: int32(first + 1), Title: "Remote Two", ReleaseDate: release, Popularity: 1},
+	}); err != nil {
+		t.Fatalf("insert movies: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `INSERT INTO snapshots (region, month, movie_id, tallies) VALUES ($1, '2001-03', $2, '{"streaming":3}')`, region, first); err != nil {
+		t.Fatalf("insert snapshot: %v", err)
+	}
+
+	s := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.TallyWatchInterval = 50 * time.Millisecond
+	c := grpcClient(t, s)
+	fp := metadata.AppendToOutgoingContext(ctx, "x-fingerprint", "grpc-"+strconv.FormatInt(first, 10))
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.WatchTallies(watchCtx, &cinekamiv1.WatchTalliesRequest{MovieIds: []int64{first + 1, first, first}, Region: region})
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	for _, want := range []int64{first, first + 1} {
+		if msg, err := stream.Recv(); err != nil || msg.MovieId != want || msg.TotalVotes != 0 || len(msg.Tallies) != 4 {
+			t.Fatalf("initial tallies: got %v %v", msg, err)
+		}
+	}
+
+	vote, err := c.CastVote(fp, &cinekamiv1.CastVoteRequest{MovieId: first, Region: region, Category: cinekamiv1.Category_CATEGORY_COUPLE})
+	if err != nil || !vote.Inserted || vote.Tallies.TotalVotes != 1 || vote.Tallies.VotedCategory != cinekamiv1.Category_CATEGORY_COUPLE {
+		t.Fatalf("vote: got %v %v", vote, err)
+	}
+	msg, err := stream.Recv()
+	if err != nil || msg.MovieId != first || msg.TotalVotes != 1 || msg.Leading.GetCategory() != cinekamiv1.Category_CATEGORY_COUPLE {
+		t.Fatalf("watched vote: got %v %v", msg, err)
+	}
+	if _, err := c.CastVote(fp, &cinekamiv1.CastVoteRequest{MovieId: first, Region: region, Category: cinekamiv1.Category_CATEGORY_ARR}); err != nil {
+		t.Fatalf("duplicate vote: %v", err)
+	}
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled && !errors.Is(err, io.EOF) {
+		t.Fatalf("watch after cancel: %v", err)
+	}
+
+	tallies, err := c.GetTallies(fp, &cinekamiv1.GetTalliesRequest{MovieId: first, Region: region})
+	if err != nil || tallies.TotalVotes != 1 || tallies.VotedCategory != cinekamiv1.Category_CATEGORY_COUPLE {
+		t.Fatalf("tallies: got %v %v", tallies, err)
+	}
+	movie, err := c.GetMovie(fp, &cinekamiv1.GetMovieRequest{MovieId: first, Region: region})
+	if err != nil || movie.Title != "Remote One" || movie.ReleaseDate != release.Format(time.DateOnly) || movie.Tallies.VotedCategory != cinekamiv1.Category_CATEGORY_COUPLE {
+		t.Fatalf("movie: got %v %v", movie, err)
+	}
+	if _, err := c.GetMovie(ctx, &cinekamiv1.GetMovieRequest{MovieId: first}); status.Code(err) != codes.NotFound {
+		t.Fatalf("movie on another board: expected NotFound, got %v", err)
+	}
+
+	page, err := c.ListActiveMovies(fp, &cinekamiv1.ListActiveMoviesRequest{Options: &cinekamiv1.ListOptions{Region: region, Limit: 1, SortBy: "popularity", IncludeTotal: true}})
+	if err != nil || len(page.Movies) != 1 || page.Movies[0].Id != first || page.Total == nil || *page.Total != 2 || page.NextCursor == "" {
+		t.Fatalf("first page: got %v %v", page, err)
+	}
+	page, err = c.ListActiveMovies(fp, &cinekamiv1.ListActiveMoviesRequest{Options: &cinekamiv1.ListOptions{Region: region, Limit: 1, SortBy: "popularity", Cursor: page.NextCursor}})
+	if err != nil || len(page.Movies) != 1 || page.Movies[0].Id != first+1 || page.Total != nil || page.NextCursor != "" || page.PrevCursor == "" {
+		t.Fatalf("second page: got %v %v", page, err)
+	}
+
+	snaps, err := c.ListSnapshots(ctx, &cinekamiv1.ListSnapshotsRequest{Year: 2001, Month: 3, Options: &cinekamiv1.ListOptions{Region: region}})
+	if err != nil || len(snaps.Snapshots) != 1 || snaps.Snapshots[0].TotalVotes != 3 || snaps.Snapshots[0].Leading.GetCategory() != cinekamiv1.Category_CATEGORY_STREAMING {
+		t.Fatalf("snapshots: got %v %v", snaps, err)
+	}
+}
@@ -2,21 +2,32 @@ package server_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"cinekami-server/internal/server"
 
 	pkgcache "cinekami-server/pkg/cache"
 	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgtmdb "cinekami-server/pkg/tmdb"
 )
 
 func TestHealth(t *testing.T) {
 	signer := pkgcrypto.NewHMAC([]byte("test-secret"))
-	s := server.New(nil, pkgcache.NewInMemory(), signer)
+	s := server.New(nil, pkgcache.NewInMemory(), signer, nil)
 	r := s.Router()
-	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req := httptest.NewRequest(http.MethodGet, "/v1/health", nil)
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	if w.Code != http.StatusOK {
@@ -28,10 +39,10 @@ func TestHealth(t *testing.T) {
 }
 
 func TestVoteInvalidCategory(t *testing.T) {
-	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")))
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
 	r := s.Router()
 	body := bytes.NewBufferString(`{"movie_id": 1, "category": "not_valid", "fingerprint": "abc"}`)
-	req := httptest.NewRequest(http.MethodPost, "/movies/1/votes", body)
+	req := httptest.NewRequest(http.MethodPost, "/v1/movies/1/votes", body)
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	if w.Code != http.StatusBadRequest {
@@ -47,3 +58,190 @@ func TestVoteInvalidCategory(t *testing.T) {
 }
 
 func contains(s, substr string) bool { return bytes.Contains([]byte(s), []byte(substr)) }
+
+func TestMetricsUseRoutePattern(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	r := s.Router()
+	req := httptest.NewRequest(http.MethodGet, "/v1/movies/abc/tallies", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	w := httptest.NewRecorder()
+	s.AdminRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	want := `cinekami_http_request_duration_seconds_count{method="GET",route="/v1/movies/{id}/tallies",status="400"}`
+	if !contains(w.Body.String(), want) {
+		t.Fatalf("expected metrics to contain %s", want)
+	}
+}
+
+func TestTracingHonoursTraceparent(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	req := httptest.NewRequest(http.MethodGet, "/v1/health", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Correlation-Id", "cid-123")
+	s.Router().ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	sp := spans[0]
+	if got := sp.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected incoming trace id, got %s", got)
+	}
+	if sp.Name() != "GET /v1/health" {
+		t.Fatalf("expected span named after route, got %q", sp.Name())
+	}
+	found := false
+	for _, a := range sp.Attributes() {
+		if a.Key == "correlation_id" && a.Value.AsString() == "cid-123" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected correlation_id attribute on span")
+	}
+}
+
+func TestLivez(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	w := httptest.NewRecorder()
+	s.Router().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestReadyzFailsWhileDraining(t *testing.T) {
+	_, repo := testDatabase(t)
+
+	s := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.CacheFallback = true
+	r := s.Router()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK || !contains(w.Body.String(), `"status":"degraded"`) {
+		t.Fatalf("expected 200 degraded, got %d: %s", w.Code, w.Body.String())
+	}
+
+	s.Draining.Store(true)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while draining, got %d", w.Code)
+	}
+}
+
+// TestVoteRefreshesCachedListing caches the active listing of a throwaway
+// region, votes and checks the listing is served fresh for the voter and for
+// everyone else. Set TEST_DATABASE_URL to run it.
+func TestVoteRefreshesCachedListing(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	region := testRegion(t, pool, "XV", "Cache test")
+	movieID := testMovieIDs(t, pool, 1)
+	now := time.Now().UTC()
+	if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{
+		{TMDBID: int32(movieID), Title: "Cached Movie", ReleaseDate: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), Popularity: 1},
+	}); err != nil {
+		t.Fatalf("insert movie: %v", err)
+	}
+
+	r := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil).Router()
+	voter := map[string]string{"X-Fingerprint": fmt.Sprintf("cache-%d", movieID)}
+	other := map[string]string{"X-Fingerprint": fmt.Sprintf("cache-other-%d", movieID)}
+	listing := "/v1/movies/active?region=" + region
+	for _, h := range []map[string]string{voter, other} {
+		if w := serve(r, http.MethodGet, listing, "", h); w.Code != http.StatusOK || contains(w.Body.String(), `"couple":1`) {
+			t.Fatalf("listing before the vote: got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	if w := serve(r, http.MethodPost, fmt.Sprintf("/v1/movies/%d/votes?region=%s", movieID, region), `{"category":"couple"}`, voter); w.Code != http.StatusOK {
+		t.Fatalf("vote: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodGet, listing, "", voter); !contains(w.Body.String(), `"couple":1`) || !contains(w.Body.String(), `"voted_category":"couple"`) {
+		t.Fatalf("voter got a stale listing: %s", w.Body.String())
+	}
+	if w := serve(r, http.MethodGet, listing, "", other); !contains(w.Body.String(), `"couple":1`) {
+		t.Fatalf("other caller got a stale listing: %s", w.Body.String())
+	}
+}
+
+func TestInvalidCursorCode(t *testing.T) {
+	signer := pkgcrypto.NewHMAC([]byte("test"))
+	s := server.New(nil, pkgcache.NewInMemory(), signer, nil)
+	r := s.Router()
+
+	foreign := signer.EncodeSnapshotsCursor(pkgcrypto.Scope("other"), pkgcrypto.KeysetCursor{Keys: []string{"1"}, ID: 1})
+	for _, target := range []string{
+		"/v1/movies/active?cursor=not-a-cursor",
+		"/v1/snapshots/2025/09?sort_by=popularity&cursor=" + foreign,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest || !contains(w.Body.String(), `"code":"invalid_cursor"`) {
+			t.Fatalf("%s: expected 400 invalid_cursor, got %d: %s", target, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestInvalidIncludeTotal(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	r := s.Router()
+	for _, target := range []string{"/v1/movies/active?include_total=maybe", "/v1/snapshots/2025/09?include_total=2"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest || !contains(w.Body.String(), "invalid include_total") {
+			t.Fatalf("%s: expected 400 invalid include_total, got %d: %s", target, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestInvalidSortListsAllowedValues(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	r := s.Router()
+	for _, target := range []string{"/v1/movies/active?sort_by=rating", "/v1/snapshots/2025/09?sort_by=popularity,rating"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		var body struct {
+			Error struct {
+				Details struct {
+					Param   string   `json:"param"`
+					Allowed []string `json:"allowed"`
+				} `json:"details"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		if w.Code != http.StatusBadRequest || body.Error.Details.Param != "sort_by" || !slices.Contains(body.Error.Details.Allowed, "total_votes") {
+			t.Fatalf("%s: expected 400 listing allowed sort_by values, got %d: %s", target, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/movies/active?region=R0", nil))
+	if w.Code != http.StatusBadRequest || !contains(w.Body.String(), `"param":"region"`) {
+		t.Fatalf("malformed region: expected 400 naming region, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/movies/active?lang=de", nil))
+	if w.Code != http.StatusBadRequest || !contains(w.Body.String(), `"param":"lang"`) || !contains(w.Body.String(), "en-US") {
+		t.Fatalf("unserved lang: expected 400 listing en-US, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/movies/active?voted=true", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("voted without fingerprint: expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,100 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"cinekami-server/internal/model"
+	"cinekami-server/internal/server"
+
+	pkgcache "cinekami-server/pkg/cache"
+	pkgcrypto "cinekami-server/pkg/crypto"
+)
+
+func TestAdminImportParams(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.AdminKeys = map[string]string{"test-admin-key": "ci"}
+	r := s.Router()
+
+	cases := []struct {
+		name        string
+		url         string
+		contentType string
+		status      int
+	}{
+		{"parquet", "/admin/import/snapshots?format=parquet", "", http.StatusUnsupportedMediaType},
+		{"unknown format", "/admin/import/movies?format=xlsx", "", http.StatusUnsupportedMediaType},
+		{"unknown content type", "/admin/import/movies", "application/xml", http.StatusUnsupportedMediaType},
+		{"bad region", "/admin/import/snapshots?region=ROU", "text/csv", http.StatusBadRequest},
+		{"bad dry_run", "/admin/import/snapshots?dry_run=maybe", "", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := map[string]string{"Authorization": "Bearer test-admin-key"}
+			if tc.contentType != "" {
+				h["Content-Type"] = tc.contentType
+			}
+			if w := serve(r, http.MethodPost, tc.url, "", h); w.Code != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestAdminImport imports a movie and snapshot archive into a throwaway region
+// twice and checks the second run changes nothing. Set TEST_DATABASE_URL to run
+// it.
+func TestAdminImport(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	region := testRegion(t, pool, "XI", "Import test")
+	movieID := testMovieIDs(t, pool, 1)
+	id := strconv.FormatInt(movieID, 10)
+	s := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.AdminKeys = map[string]string{"test-admin-key": "ci"}
+	r := s.Router()
+	run := func(url, contentType, body string) model.ImportSummary {
+		t.Helper()
+		w := serve(r, http.MethodPost, url, body, map[string]string{"Authorization": "Bearer test-admin-key", "Content-Type": contentType})
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", url, w.Code, w.Body.String())
+		}
+		var sum model.ImportSummary
+		if err := json.Unmarshal(w.Body.Bytes(), &sum); err != nil {
+			t.Fatalf("decode summary: %v", err)
+		}
+		return sum
+	}
+
+	movies := "id,title,release_date,popularity,genre_ids\n" + id + ",Imported,2025-03-14,2.5,18|35\n0,Broken,2025-03-14,,\n"
+	if sum := run("/admin/import/movies?region="+region+"&dry_run=true", "text/csv", movies); !sum.DryRun || sum.Created != 1 || sum.Rejected != 1 || sum.Errors[0].Line != 3 {
+		t.Fatalf("unexpected dry run: %+v", sum)
+	}
+	var n int
+	if err := pool.QueryRow(ctx, "SELECT count(*) FROM movies WHERE id = $1", movieID).Scan(&n); err != nil || n != 0 {
+		t.Fatalf("dry run wrote the movie (%d, %v)", n, err)
+	}
+	if sum := run("/admin/import/movies?region="+region, "text/csv", movies); sum.Created != 1 || sum.Rejected != 1 {
+		t.Fatalf("unexpected movie import: %+v", sum)
+	}
+
+	snaps := `{"region":"` + region + `","month":"2025-03","movie_id":` + id + `,"tallies":{"couple":2,"arr":1}}` + "\n" +
+		`{"region":"` + region + `","month":"2025-03","movie_id":` + id + `,"couple":2,"total_votes":5}` + "\n"
+	sum := run("/admin/import/snapshots", "application/x-ndjson", snaps)
+	if sum.Created != 1 || sum.Rejected != 1 || len(sum.Months) != 1 || sum.Months[0].Month != "2025-03" {
+		t.Fatalf("unexpected snapshot import: %+v", sum)
+	}
+	if sum := run("/admin/import/snapshots", "application/x-ndjson", snaps); sum.Created != 0 || sum.Updated != 0 || sum.Unchanged != 1 {
+		t.Fatalf("second import was not a no-op: %+v", sum)
+	}
+	if sum := run("/admin/import/movies?region="+region, "text/csv", movies); sum.Unchanged != 1 {
+		t.Fatalf("second movie import was not a no-op: %+v", sum)
+	}
+	if !strings.Contains(serve(r, http.MethodGet, "/admin/audit?limit=5", "", map[string]string{"Authorization": "Bearer test-admin-key"}).Body.String(), "import.snapshots") {
+		t.Fatalf("import not audited")
+	}
+}
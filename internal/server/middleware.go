@@ -1,29 +1,40 @@
 package server
 
 import (
-	"context"
+	"fmt"
 	"net/http"
+	"runtime/debug"
 	"strings"
 	"time"
 
 	"github.com/rs/xid"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
+	"cinekami-server/internal/config"
+
+	pkghttpx "cinekami-server/pkg/httpx"
+	pkgmetrics "cinekami-server/pkg/metrics"
 	pkgrequestctx "cinekami-server/pkg/requestctx"
+	pkgtracing "cinekami-server/pkg/tracing"
 )
 
-// StartHTTP starts the HTTP server and blocks until it stops.
-func StartHTTP(ctx context.Context, addr string, h http.Handler) error {
-	srv := &http.Server{Addr: addr, Handler: h}
-	go func() {
-		<-ctx.Done()
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := srv.Shutdown(shutdownCtx); err != nil {
-			log.Error().Err(err).Msg("server shutdown error")
-		}
-	}()
-	return srv.ListenAndServe()
+// NewHTTPServer builds an http.Server with the configured timeouts and header limit.
+// The caller owns ListenAndServe and Shutdown so shutdown can be ordered with other resources.
+func NewHTTPServer(addr string, h http.Handler, cfg config.HTTPConfig) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
 }
 
 // correlation id middleware
@@ -36,7 +47,46 @@ func withCorrelationID(next http.Handler) http.Handler {
 		// Set on response and ensure request carries it for downstream handlers
 		w.Header().Set("X-Correlation-Id", cid)
 		r.Header.Set("X-Correlation-Id", cid)
-		next.ServeHTTP(w, r.WithContext(pkgrequestctx.WithCorrelationID(r.Context(), cid)))
+		ctx := pkgrequestctx.WithCorrelationID(r.Context(), cid)
+		// Link the correlation id with the active trace, both ways
+		if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+			span.SetAttributes(attribute.String("correlation_id", cid))
+			ctx = pkgrequestctx.WithTraceID(ctx, span.SpanContext().TraceID().String())
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// tracing middleware; extracts W3C traceparent and opens the server span for the request
+func withTracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := pkgtracing.Tracer().Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			))
+		defer span.End()
+		sw := &statusWriter{ResponseWriter: w, status: 200}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+		span.SetAttributes(attribute.Int("http.response.status_code", sw.status))
+		if sw.status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(sw.status))
+		}
+	})
+}
+
+// span route middleware; must wrap the mux directly so r.Pattern is populated after routing
+func withSpanRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if r.Pattern == "" {
+			return
+		}
+		span := trace.SpanFromContext(r.Context())
+		_, route, _ := strings.Cut(r.Pattern, " ")
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(attribute.String("http.route", route))
 	})
 }
 
@@ -53,6 +103,9 @@ func (sw *statusWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (sw *statusWriter) Unwrap() http.ResponseWriter { return sw.ResponseWriter }
+
 // logging middleware
 func withLogging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -65,6 +118,7 @@ func withLogging(next http.Handler) http.Handler {
 			Str("method", r.Method).
 			Str("path", r.URL.Path).
 			Str("correlation_id", cid).
+			Str("trace_id", pkgrequestctx.TraceID(r.Context())).
 			Str("remote_ip", r.RemoteAddr).
 			Str("user_agent", r.UserAgent()).
 			Int("status", sw.status).
@@ -74,6 +128,64 @@ func withLogging(next http.Handler) http.Handler {
 	})
 }
 
+// metrics middleware; must wrap the mux directly so r.Pattern is populated after routing
+func withMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: 200}
+		next.ServeHTTP(sw, r)
+		route := "unmatched"
+		if r.Pattern != "" {
+			// Patterns are registered as "METHOD /path"; the method is its own label
+			_, path, _ := strings.Cut(r.Pattern, " ")
+			route = path
+		}
+		pkgmetrics.ObserveHTTP(route, r.Method, sw.status, time.Since(start))
+	})
+}
+
+// recovery middleware; turns handler panics into an internal error and logs the stack
+func withRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec) // deliberate abort; let net/http handle it
+			}
+			log.Error().
+				Str("correlation_id", pkgrequestctx.CorrelationID(r.Context())).
+				Str("trace_id", pkgrequestctx.TraceID(r.Context())).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Interface("panic", rec).
+				Bytes("stack", debug.Stack()).
+				Msg("panic recovered")
+			pkghttpx.WriteError(w, r, pkghttpx.Internal("internal server error", fmt.Errorf("panic: %v", rec)))
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// body limit middleware; caps request bodies so handlers never decode unbounded
+// input. Archive imports under /admin/import/ get importBytes instead.
+func withBodyLimit(maxBytes, importBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := maxBytes
+			if strings.HasPrefix(r.URL.Path, "/admin/import/") {
+				limit = importBytes
+			}
+			if limit > 0 && r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // withCORS adds CORS headers and handles preflight.
 func withCORS(allowedOrigins []string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -99,8 +211,8 @@ func withCORS(allowedOrigins []string) func(http.Handler) http.Handler {
 						w.Header().Set("Access-Control-Allow-Origin", origin)
 						w.Header().Add("Vary", "Origin")
 					}
-					w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-					w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Fingerprint, X-Correlation-Id")
+					w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+					w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Fingerprint, X-Correlation-Id, Traceparent, Tracestate")
 					w.Header().Set("Access-Control-Expose-Headers", "X-Correlation-Id")
 					w.Header().Set("Access-Control-Max-Age", "600")
 				}
@@ -116,16 +228,42 @@ func withCORS(allowedOrigins []string) func(http.Handler) http.Handler {
 }
 
 // withSecurityHeaders sets common security headers for an API.
+// apiCSP is the Content-Security-Policy of responses that are not pages; the
+// frame-ancestors directive is added separately.
+const apiCSP = "default-src 'none'; base-uri 'none'"
+
 func withSecurityHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-Content-Type-Options", "nosniff")
 		w.Header().Set("X-Frame-Options", "DENY")
 		w.Header().Set("Referrer-Policy", "no-referrer")
 		w.Header().Set("Permissions-Policy", "geolocation=(), microphone=(), camera=()")
-		// Minimal CSP for API responses
-		w.Header().Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'; base-uri 'none'")
+		// Minimal CSP for API responses; withPolicy replaces it per route
+		w.Header().Set("Content-Security-Policy", apiCSP+"; frame-ancestors 'none'")
 		// HSTS (harmless if HTTP, useful if behind TLS)
 		w.Header().Set("Strict-Transport-Security", "max-age=15552000; includeSubDomains")
 		next.ServeHTTP(w, r)
 	})
 }
+
+// withPolicy overrides the Content-Security-Policy withSecurityHeaders sets for
+// the responses of one route. Without frameAncestors they may not be framed;
+// with them, framing by those origins ("*" for any) is allowed and
+// X-Frame-Options, which cannot express that, is dropped.
+func withPolicy(csp string, frameAncestors []string) func(http.Handler) http.Handler {
+	ancestors := "'none'"
+	if len(frameAncestors) > 0 {
+		ancestors = strings.Join(frameAncestors, " ")
+	}
+	policy := csp + "; frame-ancestors " + ancestors
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("Content-Security-Policy", policy)
+			if len(frameAncestors) > 0 {
+				h.Del("X-Frame-Options")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"cinekami-server/internal/openapi"
+	"cinekami-server/internal/server"
+
+	pkgcache "cinekami-server/pkg/cache"
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+const specURL = "mem://openapi.json"
+
+// specDoc parses the embedded OpenAPI document.
+func specDoc(t *testing.T) map[string]any {
+	t.Helper()
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(openapi.Spec))
+	if err != nil {
+		t.Fatalf("parse openapi.json: %v", err)
+	}
+	m, ok := doc.(map[string]any)
+	if !ok {
+		t.Fatalf("openapi.json is not an object")
+	}
+	return m
+}
+
+// responseSchema compiles the JSON schema declared for path/method/status.
+func responseSchema(t *testing.T, path, method string, status int) *jsonschema.Schema {
+	t.Helper()
+	doc := specDoc(t)
+	paths, _ := doc["paths"].(map[string]any)
+	item, ok := paths[path].(map[string]any)
+	if !ok {
+		t.Fatalf("path %s not documented", path)
+	}
+	op, ok := item[strings.ToLower(method)].(map[string]any)
+	if !ok {
+		t.Fatalf("%s %s not documented", method, path)
+	}
+	responses, _ := op["responses"].(map[string]any)
+	code := strconv.Itoa(status)
+	resp, ok := responses[code].(map[string]any)
+	if !ok {
+		t.Fatalf("%s %s has no %d response", method, path, status)
+	}
+	pointer := "/paths/" + escapePointer(path) + "/" + strings.ToLower(method) + "/responses/" + code
+	if ref, ok := resp["$ref"].(string); ok {
+		pointer = strings.TrimPrefix(ref, "#")
+	}
+	pointer += "/content/application~1json/schema"
+
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	c.AssertFormat()
+	if err := c.AddResource(specURL, doc); err != nil {
+		t.Fatalf("add spec resource: %v", err)
+	}
+	sch, err := c.Compile(specURL + "#" + pointer)
+	if err != nil {
+		t.Fatalf("compile %s: %v", pointer, err)
+	}
+	return sch
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+// assertMatchesSpec checks the recorded response against the documented schema.
+func assertMatchesSpec(t *testing.T, path, method string, w *httptest.ResponseRecorder) {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("%s %s: expected application/json, got %q", method, path, ct)
+	}
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("%s %s: invalid json: %v", method, path, err)
+	}
+	if err := responseSchema(t, path, method, w.Code).Validate(v); err != nil {
+		t.Fatalf("%s %s (%d) does not match spec: %v\nbody: %s", method, path, w.Code, err, w.Body.String())
+	}
+}
+
+func serve(h http.Handler, method, target string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestOpenAPIDocumentServed(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	w := serve(s.Router(), http.MethodGet, "/v1/openapi.json", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if v, _ := doc["openapi"].(string); !strings.HasPrefix(v, "3.1") {
+		t.Fatalf("expected OpenAPI 3.1 document, got %q", v)
+	}
+	paths, _ := doc["paths"].(map[string]any)
+	for p := range paths {
+		if !strings.HasPrefix(p, "/v1/") {
+			t.Fatalf("path %s is not versioned", p)
+		}
+	}
+}
+
+func TestHandlersWithoutDatabaseMatchSpec(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	r := s.Router()
+
+	cases := []struct {
+		method, target, path, body string
+		status                     int
+	}{
+		{http.MethodGet, "/v1/health", "/v1/health", "", http.StatusOK},
+		{http.MethodGet, "/v1/movies/active?limit=0", "/v1/movies/active", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/movies/active?cursor=bogus", "/v1/movies/active", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/movies/abc/tallies", "/v1/movies/{id}/tallies", "", http.StatusBadRequest},
+		{http.MethodPost, "/v1/movies/1/votes", "/v1/movies/{id}/votes", `{"category":"nope","fingerprint":"f"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/movies/1/votes", "/v1/movies/{id}/votes", `{"category":"couple"}`, http.StatusBadRequest},
+		{http.MethodGet, "/v1/snapshots/2025/13", "/v1/snapshots/{year}/{month}", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/snapshots/available?region=romania", "/v1/snapshots/available", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/snapshots/2025/09?lang=xx", "/v1/snapshots/{year}/{month}", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/me/export", "/v1/me/export", "", http.StatusBadRequest},
+		{http.MethodDelete, "/v1/me", "/v1/me", "", http.StatusBadRequest},
+		// nil repository panics; the recovery middleware must answer with the error envelope
+		{http.MethodGet, "/v1/movies/1/tallies", "/v1/movies/{id}/tallies", "", http.StatusInternalServerError},
+		{http.MethodPost, "/v1/movies/1/votes", "/v1/movies/{id}/votes", `{"category":"couple","fingerprint":"` + strings.Repeat("x", 2<<20) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.target, func(t *testing.T) {
+			w := serve(r, tc.method, tc.target, tc.body, nil)
+			if w.Code != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+			assertMatchesSpec(t, tc.path, tc.method, w)
+		})
+	}
+}
+
+// TestHandlersWithDatabaseMatchSpec exercises every data-backed handler against a
+// real Postgres. Set TEST_DATABASE_URL to run it.
+func TestHandlersWithDatabaseMatchSpec(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	movieID := testMovieIDs(t, pool, 1)
+	if _, err := repo.UpsertMovies(ctx, "RO", []pkgtmdb.Movie{{
+		TMDBID:      int32(movieID),
+		Title:       "Spec Test Movie",
+		ReleaseDate: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		Overview:    "fixture",
+		Popularity:  1e6,
+		GenreIDs:    []int32{18},
+	}}); err != nil {
+		t.Fatalf("insert movie: %v", err)
+	}
+
+	if _, err := pool.Exec(ctx, "INSERT INTO movie_translations (movie_id, language, title) VALUES ($1, 'ro-RO', 'Film de test')", movieID); err != nil {
+		t.Fatalf("insert translation: %v", err)
+	}
+
+	s := server.New(repo, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.Languages = []string{"en-US", "ro-RO"}
+	r := s.Router()
+	fp := map[string]string{"X-Fingerprint": fmt.Sprintf("spec-test-%d", movieID)}
+	id := strconv.FormatInt(movieID, 10)
+
+	w := serve(r, http.MethodPost, "/v1/movies/"+id+"/votes", `{"category":"couple"}`, fp)
+	if w.Code != http.StatusOK {
+		t.Fatalf("vote: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	assertMatchesSpec(t, "/v1/movies/{id}/votes", http.MethodPost, w)
+
+	w = serve(r, http.MethodPost, "/v1/movies/1/votes", `{"category":"couple"}`, fp)
+	assertMatchesSpec(t, "/v1/movies/{id}/votes", http.MethodPost, w)
+
+	w = serve(r, http.MethodGet, "/v1/movies/"+id+"/tallies", "", fp)
+	assertMatchesSpec(t, "/v1/movies/{id}/tallies", http.MethodGet, w)
+
+	w = serve(r, http.MethodGet, "/v1/movies/active?limit=1", "", fp)
+	assertMatchesSpec(t, "/v1/movies/active", http.MethodGet, w)
+	var page struct {
+		NextCursor string `json:"next_cursor"`
+		PrevCursor string `json:"prev_cursor"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
+	if page.NextCursor != "" {
+		w = serve(r, http.MethodGet, "/v1/movies/active?limit=1&cursor="+page.NextCursor, "", fp)
+		assertMatchesSpec(t, "/v1/movies/active", http.MethodGet, w)
+		page.PrevCursor = ""
+		_ = json.Unmarshal(w.Body.Bytes(), &page)
+		if page.PrevCursor == "" {
+			t.Fatalf("second page has no prev_cursor: %s", w.Body.String())
+		}
+		w = serve(r, http.MethodGet, "/v1/movies/active?limit=1&cursor="+page.PrevCursor, "", fp)
+		assertMatchesSpec(t, "/v1/movies/active", http.MethodGet, w)
+	}
+
+	w = serve(r, http.MethodGet, "/v1/movies/active?limit=1&include_total=false", "", fp)
+	assertMatchesSpec(t, "/v1/movies/active", http.MethodGet, w)
+
+	w = serve(r, http.MethodGet, "/v1/movies/active?limit=100", "", map[string]string{"Accept-Language": "ro;q=0.9, de"})
+	assertMatchesSpec(t, "/v1/movies/active", http.MethodGet, w)
+	if w.Header().Get("Content-Language") != "ro-RO" || !strings.Contains(w.Body.String(), `"title":"Film de test"`) {
+		t.Fatalf("expected the ro-RO title, got Content-Language %q: %s", w.Header().Get("Content-Language"), w.Body.String())
+	}
+
+	w = serve(r, http.MethodGet, "/v1/regions", "", nil)
+	assertMatchesSpec(t, "/v1/regions", http.MethodGet, w)
+
+	// The vote went to the default board; another region's board does not see it.
+	w = serve(r, http.MethodGet, "/v1/movies/"+id+"/tallies?region=us", "", fp)
+	assertMatchesSpec(t, "/v1/movies/{id}/tallies", http.MethodGet, w)
+	if !strings.Contains(w.Body.String(), `"region":"US"`) || !strings.Contains(w.Body.String(), `"total_votes":0`) {
+		t.Fatalf("US tallies should be empty: %s", w.Body.String())
+	}
+	w = serve(r, http.MethodGet, "/v1/movies/active?region=zz", "", fp)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("disabled region: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Multi-key sort with every filter; the cursor carries both keys.
+	filtered := "/v1/movies/active?limit=1&sort_by=couple_pct,title&sort_dir=desc,asc&genre=18,35&min_votes=1&voted=true&release_from=" +
+		now.AddDate(0, 0, -40).Format(time.DateOnly)
+	w = serve(r, http.MethodGet, filtered, "", fp)
+	assertMatchesSpec(t, "/v1/movies/active", http.MethodGet, w)
+	page.NextCursor = ""
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
+	if !strings.Contains(w.Body.String(), `"id":`+id) {
+		t.Fatalf("voted movie missing from filtered listing: %s", w.Body.String())
+	}
+	if page.NextCursor != "" {
+		w = serve(r, http.MethodGet, filtered+"&cursor="+page.NextCursor, "", fp)
+		assertMatchesSpec(t, "/v1/movies/active", http.MethodGet, w)
+	}
+
+	if err := repo.SnapshotMonth(ctx, "RO", now.Year(), now.Month()); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	w = serve(r, http.MethodGet, "/v1/snapshots/available", "", nil)
+	assertMatchesSpec(t, "/v1/snapshots/available", http.MethodGet, w)
+
+	w = serve(r, http.MethodGet, fmt.Sprintf("/v1/snapshots/%d/%d?sort_by=couple", now.Year(), int(now.Month())), "", nil)
+	assertMatchesSpec(t, "/v1/snapshots/{year}/{month}", http.MethodGet, w)
+
+	w = serve(r, http.MethodGet, fmt.Sprintf("/v1/snapshots/%d/%d?sort_by=couple_lower_bound,total_votes&min_votes=1&voted=true", now.Year(), int(now.Month())), "", fp)
+	assertMatchesSpec(t, "/v1/snapshots/{year}/{month}", http.MethodGet, w)
+
+	// Voters are stored by digest, never by the raw fingerprint.
+	var stored int
+	if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM voters WHERE fingerprint = $1", fp["X-Fingerprint"]).Scan(&stored); err != nil || stored != 0 {
+		t.Fatalf("raw fingerprint stored (%d, %v)", stored, err)
+	}
+	w = serve(r, http.MethodGet, "/v1/me/export", "", fp)
+	assertMatchesSpec(t, "/v1/me/export", http.MethodGet, w)
+	if !strings.Contains(w.Body.String(), `"movie_id":`+id) {
+		t.Fatalf("export misses the vote: %s", w.Body.String())
+	}
+	w = serve(r, http.MethodDelete, "/v1/me", "", fp)
+	assertMatchesSpec(t, "/v1/me", http.MethodDelete, w)
+	if !strings.Contains(w.Body.String(), `"anonymized":1`) {
+		t.Fatalf("expected one voter anonymized: %s", w.Body.String())
+	}
+	// The anonymized vote still counts.
+	w = serve(r, http.MethodGet, "/v1/movies/"+id+"/tallies", "", fp)
+	if !strings.Contains(w.Body.String(), `"total_votes":1`) || !strings.Contains(w.Body.String(), `"voted_category":""`) {
+		t.Fatalf("tallies after erasure: %s", w.Body.String())
+	}
+	w = serve(r, http.MethodGet, "/v1/me/export", "", map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte("nobody@example.com:wrong"))})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown account: expected 401, got %d", w.Code)
+	}
+	assertMatchesSpec(t, "/v1/me/export", http.MethodGet, w)
+}
@@ -1,37 +1,133 @@
 package server
 
 import (
+	"crypto/rand"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	"cinekami-server/internal/config"
 	"cinekami-server/internal/deps"
 	"cinekami-server/internal/repos"
 	"cinekami-server/internal/routes"
 
 	pkgcache "cinekami-server/pkg/cache"
+	pkgcard "cinekami-server/pkg/card"
 	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgmetrics "cinekami-server/pkg/metrics"
+	pkgratelimit "cinekami-server/pkg/ratelimit"
 )
 
 type Server struct {
 	deps.ServerDeps
 }
 
+// New returns a Server with the defaults of config.Defaults, which
+// cmd/api-server overrides from the loaded configuration. Fingerprints are
+// keyed with a random key until FINGERPRINT_SECRET replaces it, so digests are
+// never unkeyed, only unstable across restarts.
 func New(r *repos.Repository, c pkgcache.Cache, signer pkgcrypto.Codec, allowedOrigins []string) *Server {
-	return &Server{ServerDeps: deps.ServerDeps{Repo: r, Cache: c, Codec: signer, Name: "cinekami-server", StartedAt: time.Now().UTC(), AllowedOrigins: allowedOrigins}}
+	cfg := config.Defaults()
+	return &Server{ServerDeps: deps.ServerDeps{
+		Repo:                 r,
+		Cache:                c,
+		Codec:                signer,
+		Name:                 "cinekami-server",
+		StartedAt:            time.Now().UTC(),
+		AllowedOrigins:       allowedOrigins,
+		MaxBodyBytes:         cfg.HTTP.MaxBodyBytes,
+		MaxImportBytes:       cfg.HTTP.MaxImportBytes,
+		Draining:             &atomic.Bool{},
+		DefaultPageSize:      cfg.Paging.DefaultLimit,
+		MaxPageSize:          cfg.Paging.MaxLimit,
+		ActiveMoviesTTL:      cfg.Cache.ActiveMoviesTTL,
+		SnapshotsTTL:         cfg.Cache.SnapshotsTTL,
+		DefaultRegion:        cfg.TMDBRegion,
+		Languages:            cfg.Languages(),
+		Fingerprints:         pkgcrypto.NewFingerprinter([]byte(rand.Text())),
+		EmbedOrigins:         cfg.EmbedAllowedOrigins,
+		GraphQLMaxDepth:      cfg.GraphQL.MaxDepth,
+		GraphQLMaxComplexity: cfg.GraphQL.MaxComplexity,
+		TallyWatchInterval:   cfg.GRPC.WatchInterval,
+		CredentialAttempts:   pkgratelimit.New(10, time.Minute),
+	}}
 }
 
 func (s *Server) Router() http.Handler {
 	mux := http.NewServeMux()
 	sd := s.ServerDeps
 
-	// Endpoints declared here for easy scanning
-	mux.HandleFunc("GET /health", routes.Health(sd))
-	mux.HandleFunc("GET /movies/active", routes.MoviesActive(sd))
-	mux.HandleFunc("GET /movies/{id}/tallies", routes.MovieTallies(sd))
-	mux.HandleFunc("POST /movies/{id}/votes", routes.MovieVote(sd))
-	mux.HandleFunc("GET /snapshots/available", routes.SnapshotsAvailable(sd))
-	mux.HandleFunc("GET /snapshots/{year}/{month}", routes.Snapshots(sd))
+	// Probes for the orchestrator; unversioned and not part of the public API
+	mux.HandleFunc("GET /livez", routes.Livez(sd))
+	mux.HandleFunc("GET /readyz", routes.Readyz(sd))
 
-	// Wrap with middleware: correlation id -> CORS -> security -> logging
-	return withCorrelationID(withCORS(sd.AllowedOrigins)(withSecurityHeaders(withLogging(mux))))
+	// Endpoints declared here for easy scanning; every public route lives under /v1
+	// and must be described in internal/openapi/openapi.json.
+	mux.HandleFunc("GET /v1/health", routes.Health(sd))
+	mux.HandleFunc("GET /v1/openapi.json", routes.OpenAPI(sd))
+	mux.HandleFunc("GET /v1/regions", routes.Regions(sd))
+	mux.HandleFunc("GET /v1/movies/active", routes.MoviesActive(sd))
+	mux.HandleFunc("GET /v1/movies/{id}/tallies", routes.MovieTallies(sd))
+	mux.HandleFunc("POST /v1/movies/{id}/votes", routes.MovieVote(sd))
+	mux.HandleFunc("GET /v1/me/export", routes.MeExport(sd))
+	mux.HandleFunc("DELETE /v1/me", routes.MeDelete(sd))
+	mux.HandleFunc("GET /v1/snapshots/available", routes.SnapshotsAvailable(sd))
+	mux.HandleFunc("GET /v1/snapshots/{year}/{month}", routes.Snapshots(sd))
+
+	// GraphQL over the same data for clients that want it in one round trip;
+	// unversioned like the schema it serves, which evolves by addition.
+	mux.HandleFunc("GET /graphql", routes.GraphQL(sd))
+	mux.HandleFunc("POST /graphql", routes.GraphQL(sd))
+
+	// Syndication feeds and calendars for readers, bots and calendar apps; unversioned, fixed URLs.
+	mux.HandleFunc("GET /feeds/snapshots.atom", routes.FeedSnapshotsAtom(sd))
+	mux.HandleFunc("GET /feeds/snapshots.json", routes.FeedSnapshotsJSON(sd))
+	mux.HandleFunc("GET /feeds/active.atom", routes.FeedActiveAtom(sd))
+	mux.HandleFunc("GET /calendar.ics", routes.Calendar(sd))
+	mux.HandleFunc("GET /calendar/releases.ics", routes.CalendarReleases(sd))
+	mux.HandleFunc("GET /calendar/deadlines.ics", routes.CalendarDeadlines(sd))
+
+	// Share pages and their PNG cards for link previews, the embeddable widget and
+	// its oEmbed provider; unversioned, fixed URLs. Pages get their own CSP and
+	// only the widget may be framed, by the configured origins.
+	page := withPolicy(pkgcard.PageCSP, nil)
+	embed := withPolicy(pkgcard.WidgetCSP, sd.EmbedOrigins)
+	mux.Handle("GET /movies/{id}", page(routes.MovieCardPage(sd)))
+	mux.HandleFunc("GET /movies/{id}/card.png", routes.MovieCardPNG(sd))
+	mux.Handle("GET /snapshots/{year}/{month}", page(routes.SnapshotCardPage(sd)))
+	mux.HandleFunc("GET /snapshots/{year}/{month}/card.png", routes.SnapshotCardPNG(sd))
+	mux.Handle("GET /embed/movies/{id}", embed(routes.EmbedMovie(sd)))
+	mux.HandleFunc("GET /oembed", routes.OEmbed(sd))
+
+	// Admin API; every route requires an API key or an admin user and is audited.
+	admin := withAdminAuth(sd)
+	mux.Handle("GET /admin/movies/{id}", admin(routes.AdminMovie(sd)))
+	mux.Handle("PATCH /admin/movies/{id}", admin(routes.AdminMovieUpdate(sd)))
+	mux.Handle("POST /admin/movies/{id}/merge", admin(routes.AdminMovieMerge(sd)))
+	mux.Handle("GET /admin/votes", admin(routes.AdminVotes(sd)))
+	mux.Handle("POST /admin/votes/void", admin(routes.AdminVotesVoid(sd)))
+	mux.Handle("POST /admin/tmdb/sync", admin(routes.AdminTMDBSync(sd)))
+	mux.Handle("POST /admin/snapshots/{year}/{month}", admin(routes.AdminSnapshot(sd)))
+	mux.Handle("GET /admin/audit", admin(routes.AdminAuditLog(sd)))
+	mux.Handle("GET /admin/export/snapshots", admin(routes.AdminExportSnapshots(sd)))
+	mux.Handle("GET /admin/export/tallies", admin(routes.AdminExportTallies(sd)))
+	mux.Handle("GET /admin/export/votes", admin(routes.AdminExportVotes(sd)))
+	mux.Handle("POST /admin/import/snapshots", admin(routes.AdminImportSnapshots(sd)))
+	mux.Handle("POST /admin/import/movies", admin(routes.AdminImportMovies(sd)))
+	mux.Handle("PUT /admin/users/{email}", admin(routes.AdminUserPut(sd)))
+	mux.Handle("GET /admin/webhooks", admin(routes.AdminWebhooks(sd)))
+	mux.Handle("POST /admin/webhooks", admin(routes.AdminWebhookCreate(sd)))
+	mux.Handle("DELETE /admin/webhooks/{id}", admin(routes.AdminWebhookDelete(sd)))
+	mux.Handle("GET /admin/webhooks/{id}/deliveries", admin(routes.AdminWebhookDeliveries(sd)))
+	mux.Handle("POST /admin/webhooks/deliveries/{id}/retry", admin(routes.AdminWebhookRetry(sd)))
+
+	// Wrap with middleware: tracing -> correlation id -> CORS -> security -> body limit -> logging -> metrics -> span route -> recovery
+	return withTracing(withCorrelationID(withCORS(sd.AllowedOrigins)(withSecurityHeaders(withBodyLimit(sd.MaxBodyBytes, sd.MaxImportBytes)(withLogging(withMetrics(withSpanRoute(withRecovery(mux)))))))))
+}
+
+// AdminRouter serves operational endpoints on the separate admin listener.
+func (s *Server) AdminRouter() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", pkgmetrics.Handler())
+	return mux
 }
@@ -0,0 +1,140 @@
+package server_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cinekami-server/internal/repos"
+	"cinekami-server/internal/server"
+
+	pkgcache "cinekami-server/pkg/cache"
+	pkgcrypto "cinekami-server/pkg/crypto"
+	pkgratelimit "cinekami-server/pkg/ratelimit"
+	pkgtmdb "cinekami-server/pkg/tmdb"
+)
+
+func TestCredentialAttemptsThrottled(t *testing.T) {
+	s := server.New(nil, pkgcache.NewInMemory(), pkgcrypto.NewHMAC([]byte("test")), nil)
+	s.CredentialAttempts = pkgratelimit.New(0, time.Minute)
+	r := s.Router()
+	auth := map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte("someone@example.com:guess"))}
+	for _, target := range []string{"/v1/me/export", "/v1/me"} {
+		method := http.MethodGet
+		if target == "/v1/me" {
+			method = http.MethodDelete
+		}
+		w := serve(r, method, target, "", auth)
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" || !contains(w.Body.String(), `"code":"too_many_requests"`) {
+			t.Fatalf("%s: expected 429 with Retry-After, got %d %v: %s", target, w.Code, w.Header(), w.Body.String())
+		}
+		assertMatchesSpec(t, target, method, w)
+	}
+}
+
+// TestHashLegacyFingerprints merges a legacy voter, stored with its raw
+// fingerprint, into the voter of its digest while another legacy voter is
+// rewritten, with replicas rehashing at once: the duplicate vote is dropped and
+// the tallies rebuilt, the other vote and the account move over. Set
+// TEST_DATABASE_URL to run it.
+func TestHashLegacyFingerprints(t *testing.T) {
+	pool, repo := testDatabase(t)
+	ctx := context.Background()
+	region := testRegion(t, pool, "XL", "Rehash test")
+	first := testMovieIDs(t, pool, 2)
+	now := time.Now().UTC()
+	release := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if _, err := repo.UpsertMovies(ctx, region, []pkgtmdb.Movie{
+		{TMDBID: int32(first), Title: "Rehash One", ReleaseDate: release, Popularity: 1},
+		{TMDBID: int32(first + 1), Title: "Rehash Two", ReleaseDate: release, Popularity: 1},
+	}); err != nil {
+		t.Fatalf("insert movies: %v", err)
+	}
+	digest := pkgcrypto.NewFingerprinter([]byte("rehash-test")).Digest
+	raw, other := fmt.Sprintf("legacy-%d", first), fmt.Sprintf("legacy-other-%d", first)
+
+	// The legacy voter votes on both movies; the digest's voter already voted on the first.
+	for _, v := range []struct {
+		movie       int64
+		category    string
+		fingerprint string
+	}{{first, "arr", raw}, {first + 1, "streaming", raw}, {first, "couple", digest(raw)}, {first, "solo_friends", other}} {
+		if _, err := repo.CreateVote(ctx, v.movie, region, v.category, v.fingerprint, now); err != nil {
+			t.Fatalf("vote: %v", err)
+		}
+	}
+	var userID string
+	if err := pool.QueryRow(ctx, "INSERT INTO users (email) VALUES ($1) RETURNING id::text", raw+"@example.com").Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "UPDATE voters SET fingerprint_hashed = false, user_id = CASE WHEN fingerprint = $1 THEN $3::uuid END WHERE fingerprint IN ($1, $2)", raw, other, userID); err != nil {
+		t.Fatalf("mark legacy voters: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := pool.Exec(context.Background(), "DELETE FROM voters WHERE fingerprint IN ($1, $2, $3, $4)", raw, digest(raw), other, digest(other)); err != nil {
+			t.Errorf("cleanup voters: %v", err)
+		}
+		if _, err := pool.Exec(context.Background(), "DELETE FROM users WHERE id = $1", userID); err != nil {
+			t.Errorf("cleanup user: %v", err)
+		}
+	})
+
+	var (
+		wg       sync.WaitGroup
+		rehashed atomic.Int64
+	)
+	for range 4 {
+		wg.Go(func() {
+			n, err := repo.Voters.HashLegacyFingerprints(ctx, digest)
+			if err != nil {
+				t.Errorf("rehash: %v", err)
+			}
+			rehashed.Add(n)
+		})
+	}
+	wg.Wait()
+	if n := rehashed.Load(); n < 2 {
+		t.Fatalf("rehashed %d voters, expected both legacy ones", n)
+	}
+	var legacy int
+	if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM voters WHERE fingerprint IN ($1, $2)", raw, other).Scan(&legacy); err != nil || legacy != 0 {
+		t.Fatalf("legacy voters kept (%d, %v)", legacy, err)
+	}
+	tallies := func(movieID int64) map[string]int64 {
+		t.Helper()
+		rows, err := repo.GetTalliesAllCategories(ctx, movieID, region)
+		if err != nil {
+			t.Fatalf("tallies: %v", err)
+		}
+		out := map[string]int64{}
+		for _, r := range rows {
+			out[r.Category] = r.Count
+		}
+		return out
+	}
+	if got := tallies(first); got["couple"] != 1 || got["arr"] != 0 {
+		t.Fatalf("duplicate vote still counted: %v", got)
+	}
+	if got := tallies(first + 1); got["streaming"] != 1 {
+		t.Fatalf("moved vote lost: %v", got)
+	}
+	if got := tallies(first); got["solo_friends"] != 1 {
+		t.Fatalf("rewritten voter's vote lost: %v", got)
+	}
+	export, err := repo.Voters.ExportVoter(ctx, repos.VoterIdentity{UserID: &userID}, now)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	votes := map[int64]string{}
+	for _, v := range export.Votes {
+		votes[v.MovieID] = v.Category
+	}
+	if len(export.VoterIDs) != 1 || len(votes) != 2 || votes[first] != "couple" || votes[first+1] != "streaming" {
+		t.Fatalf("unexpected merged voter: %+v", export)
+	}
+}
@@ -10,18 +10,57 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AdminAuditLog struct {
+	ID        int64              `json:"id"`
+	Actor     string             `json:"actor"`
+	Action    string             `json:"action"`
+	Target    string             `json:"target"`
+	Details   json.RawMessage    `json:"details"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type Movie struct {
-	ID           int64              `json:"id"`
-	Title        string             `json:"title"`
-	ReleaseDate  pgtype.Date        `json:"release_date"`
-	Overview     pgtype.Text        `json:"overview"`
-	PosterPath   pgtype.Text        `json:"poster_path"`
-	BackdropPath pgtype.Text        `json:"backdrop_path"`
-	Popularity   pgtype.Float8      `json:"popularity"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
-	ImdbUrl      pgtype.Text        `json:"imdb_url"`
-	CinemagiaUrl pgtype.Text        `json:"cinemagia_url"`
+	ID               int64              `json:"id"`
+	Title            string             `json:"title"`
+	ReleaseDate      pgtype.Date        `json:"release_date"`
+	Overview         pgtype.Text        `json:"overview"`
+	PosterPath       pgtype.Text        `json:"poster_path"`
+	BackdropPath     pgtype.Text        `json:"backdrop_path"`
+	Popularity       pgtype.Float8      `json:"popularity"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	ImdbUrl          pgtype.Text        `json:"imdb_url"`
+	CinemagiaUrl     pgtype.Text        `json:"cinemagia_url"`
+	Hidden           bool               `json:"hidden"`
+	AdminLocked      bool               `json:"admin_locked"`
+	MergedInto       pgtype.Int8        `json:"merged_into"`
+	GenreIds         []int32            `json:"genre_ids"`
+	OriginalTitle    pgtype.Text        `json:"original_title"`
+	OriginalLanguage pgtype.Text        `json:"original_language"`
+}
+
+type MovieTranslation struct {
+	MovieID   int64              `json:"movie_id"`
+	Language  string             `json:"language"`
+	Title     string             `json:"title"`
+	Overview  string             `json:"overview"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type MovieRelease struct {
+	MovieID     int64              `json:"movie_id"`
+	Region      string             `json:"region"`
+	ReleaseDate pgtype.Date        `json:"release_date"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	Revision    int32              `json:"revision"`
+}
+
+type Region struct {
+	Code      string             `json:"code"`
+	Name      string             `json:"name"`
+	Enabled   bool               `json:"enabled"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type Snapshot struct {
@@ -30,6 +69,7 @@ type Snapshot struct {
 	MovieID  int64              `json:"movie_id"`
 	Tallies  json.RawMessage    `json:"tallies"`
 	ClosedAt pgtype.Timestamptz `json:"closed_at"`
+	Region   string             `json:"region"`
 }
 
 type User struct {
@@ -38,6 +78,7 @@ type User struct {
 	PasswordHash pgtype.Text        `json:"password_hash"`
 	PasswordSalt pgtype.Text        `json:"password_salt"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	Role         string             `json:"role"`
 }
 
 type Vote struct {
@@ -46,17 +87,53 @@ type Vote struct {
 	VoterID   pgtype.UUID        `json:"voter_id"`
 	Category  string             `json:"category"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	Region    string             `json:"region"`
+}
+
+type VoteMilestone struct {
+	MovieID   int64              `json:"movie_id"`
+	Region    string             `json:"region"`
+	Milestone int64              `json:"milestone"`
+	ReachedAt pgtype.Timestamptz `json:"reached_at"`
 }
 
 type VoteTally struct {
 	MovieID  int64       `json:"movie_id"`
 	Category string      `json:"category"`
 	Count    pgtype.Int8 `json:"count"`
+	Region   string      `json:"region"`
 }
 
 type Voter struct {
-	ID          pgtype.UUID        `json:"id"`
-	Fingerprint string             `json:"fingerprint"`
-	UserID      pgtype.UUID        `json:"user_id"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ID                pgtype.UUID        `json:"id"`
+	Fingerprint       pgtype.Text        `json:"fingerprint"`
+	UserID            pgtype.UUID        `json:"user_id"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	FingerprintHashed bool               `json:"fingerprint_hashed"`
+	AnonymizedAt      pgtype.Timestamptz `json:"anonymized_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64              `json:"id"`
+	SubscriptionID pgtype.UUID        `json:"subscription_id"`
+	Event          string             `json:"event"`
+	Payload        json.RawMessage    `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	LastAttemptAt  pgtype.Timestamptz `json:"last_attempt_at"`
+	ResponseStatus pgtype.Int4        `json:"response_status"`
+	LastError      pgtype.Text        `json:"last_error"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+}
+
+type WebhookSubscription struct {
+	ID        pgtype.UUID        `json:"id"`
+	Url       string             `json:"url"`
+	Events    []string           `json:"events"`
+	Secret    string             `json:"secret"`
+	Enabled   bool               `json:"enabled"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
@@ -11,52 +11,54 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const CountActiveMovies = `-- name: CountActiveMovies :one
-SELECT COUNT(*)
-FROM movies
-WHERE release_date >= date_trunc('month', $1::timestamptz)::date
-  AND release_date <= (date_trunc('month', $1::timestamptz)::date + interval '1 month - 1 second')
-  AND $1::timestamptz <= (release_date + interval '14 days')
+const GetMovieCard = `-- name: GetMovieCard :one
+SELECT m.id, m.title, m.overview, m.poster_path, r.release_date
+FROM movie_releases r
+JOIN movies m ON m.id = r.movie_id
+WHERE r.movie_id = $1 AND r.region = $2 AND NOT m.hidden
 `
 
-func (q *Queries) CountActiveMovies(ctx context.Context, dollar_1 pgtype.Timestamptz) (int64, error) {
-	row := q.db.QueryRow(ctx, CountActiveMovies, dollar_1)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type GetMovieCardParams struct {
+	MovieID int64  `json:"movie_id"`
+	Region  string `json:"region"`
 }
 
-const CountActiveMoviesFiltered = `-- name: CountActiveMoviesFiltered :one
-SELECT COUNT(*)
-FROM movies
-WHERE release_date >= date_trunc('month', $1::timestamptz)::date
-  AND release_date <= (date_trunc('month', $1::timestamptz)::date + interval '1 month - 1 second')
-  AND $1::timestamptz <= (release_date + interval '14 days')
-  AND ($2::float8 IS NULL OR popularity >= $2)
-  AND ($3::float8 IS NULL OR popularity <= $3)
-`
-
-type CountActiveMoviesFilteredParams struct {
-	Column1 pgtype.Timestamptz `json:"column_1"`
-	Column2 float64            `json:"column_2"`
-	Column3 float64            `json:"column_3"`
+type GetMovieCardRow struct {
+	ID          int64       `json:"id"`
+	Title       string      `json:"title"`
+	Overview    pgtype.Text `json:"overview"`
+	PosterPath  pgtype.Text `json:"poster_path"`
+	ReleaseDate pgtype.Date `json:"release_date"`
 }
 
-func (q *Queries) CountActiveMoviesFiltered(ctx context.Context, arg CountActiveMoviesFilteredParams) (int64, error) {
-	row := q.db.QueryRow(ctx, CountActiveMoviesFiltered, arg.Column1, arg.Column2, arg.Column3)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+// A visible movie released in region, as shown on its share card.
+func (q *Queries) GetMovieCard(ctx context.Context, arg GetMovieCardParams) (GetMovieCardRow, error) {
+	row := q.db.QueryRow(ctx, GetMovieCard, arg.MovieID, arg.Region)
+	var i GetMovieCardRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Overview,
+		&i.PosterPath,
+		&i.ReleaseDate,
+	)
+	return i, err
 }
 
 const GetMovieReleaseDate = `-- name: GetMovieReleaseDate :one
-SELECT release_date
-FROM movies
-WHERE id = $1
+SELECT r.release_date
+FROM movie_releases r
+JOIN movies m ON m.id = r.movie_id
+WHERE r.movie_id = $1 AND r.region = $2 AND NOT m.hidden
 `
 
-func (q *Queries) GetMovieReleaseDate(ctx context.Context, id int64) (pgtype.Date, error) {
-	row := q.db.QueryRow(ctx, GetMovieReleaseDate, id)
+type GetMovieReleaseDateParams struct {
+	MovieID int64  `json:"movie_id"`
+	Region  string `json:"region"`
+}
+
+func (q *Queries) GetMovieReleaseDate(ctx context.Context, arg GetMovieReleaseDateParams) (pgtype.Date, error) {
+	row := q.db.QueryRow(ctx, GetMovieReleaseDate, arg.MovieID, arg.Region)
 	var release_date pgtype.Date
 	err := row.Scan(&release_date)
 	return release_date, err
@@ -73,130 +75,114 @@ func (q *Queries) HasAnyMovies(ctx context.Context) (bool, error) {
 	return exists, err
 }
 
-const ListActiveMoviesFilteredPage = `-- name: ListActiveMoviesFilteredPage :many
-WITH base AS (
-  SELECT id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url
-  FROM movies
-  WHERE release_date >= date_trunc('month', $1::timestamptz)::date
-    AND release_date <= (date_trunc('month', $1::timestamptz)::date + interval '1 month - 1 second')
-    AND $1::timestamptz <= (release_date + interval '14 days')
-    AND ($2::float8 IS NULL OR popularity >= $2)
-    AND ($3::float8 IS NULL OR popularity <= $3)
-), t AS (
-  SELECT movie_id,
-         SUM(CASE WHEN category = 'solo_friends' THEN count ELSE 0 END)::bigint AS solo_friends,
-         SUM(CASE WHEN category = 'couple' THEN count ELSE 0 END)::bigint AS couple,
-         SUM(CASE WHEN category = 'streaming' THEN count ELSE 0 END)::bigint AS streaming,
-         SUM(CASE WHEN category = 'arr' THEN count ELSE 0 END)::bigint AS arr
-  FROM vote_tallies
-  GROUP BY movie_id
-), joined AS (
-  SELECT b.id, b.title, b.release_date, b.overview, b.poster_path, b.backdrop_path, b.popularity, b.imdb_url, b.cinemagia_url, COALESCE(t.solo_friends,0) AS solo_friends, COALESCE(t.couple,0) AS couple, COALESCE(t.streaming,0) AS streaming, COALESCE(t.arr,0) AS arr
-  FROM base b LEFT JOIN t ON t.movie_id = b.id
-), keyed AS (
-  SELECT id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url, solo_friends, couple, streaming, arr, CASE
-      WHEN $4::text = 'popularity' THEN popularity
-      WHEN $4::text = 'release_date' THEN extract(epoch from release_date)
-      WHEN $4::text = 'solo_friends' THEN solo_friends::double precision
-      WHEN $4::text = 'couple' THEN couple::double precision
-      WHEN $4::text = 'streaming' THEN streaming::double precision
-      WHEN $4::text = 'arr' THEN arr::double precision
-      ELSE popularity
-    END AS key_value
-  FROM joined
-), voted AS (
-  SELECT j.id, j.title, j.release_date, j.overview, j.poster_path, j.backdrop_path, j.popularity, j.imdb_url, j.cinemagia_url, j.solo_friends, j.couple, j.streaming, j.arr, j.key_value, COALESCE(v.category::text, '') AS voted_category
-  FROM keyed j
-  LEFT JOIN voters vr ON vr.fingerprint = $9
-  LEFT JOIN votes v ON v.movie_id = j.id AND v.voter_id = vr.id
-), paged AS (
-  SELECT id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url, solo_friends, couple, streaming, arr, key_value, voted_category FROM voted
-  WHERE (
-    $6::float8 IS NULL OR (
-      CASE WHEN $5::text = 'desc'
-           THEN (key_value < $6 OR (key_value = $6 AND id < $7::bigint))
-           ELSE (key_value > $6 OR (key_value = $6 AND id > $7::bigint))
-      END
-    )
-  )
-)
-SELECT id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url,
-       solo_friends, couple, streaming, arr, key_value, voted_category
-FROM paged p
-ORDER BY
-  CASE WHEN $5::text = 'desc' THEN key_value END DESC NULLS LAST,
-  CASE WHEN $5::text = 'asc'  THEN key_value END ASC  NULLS LAST,
-  CASE WHEN $4::text <> 'popularity' THEN popularity END DESC NULLS LAST,
-  CASE WHEN $5::text = 'desc' THEN p.id END DESC NULLS LAST,
-  CASE WHEN $5::text = 'asc'  THEN p.id END ASC  NULLS LAST
-LIMIT $8
+const ImportMovie = `-- name: ImportMovie :one
+INSERT INTO movies (id, title, release_date, overview, poster_path, backdrop_path, popularity, genre_ids, original_title, original_language)
+VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7::float8, 0), $8, $9, $10)
+ON CONFLICT (id) DO UPDATE SET
+  title = EXCLUDED.title,
+  release_date = EXCLUDED.release_date,
+  overview = COALESCE($4, movies.overview),
+  poster_path = COALESCE($5, movies.poster_path),
+  backdrop_path = COALESCE($6, movies.backdrop_path),
+  popularity = COALESCE($7, movies.popularity),
+  genre_ids = CASE WHEN cardinality(EXCLUDED.genre_ids) = 0 THEN movies.genre_ids ELSE EXCLUDED.genre_ids END,
+  original_title = COALESCE($9, movies.original_title),
+  original_language = COALESCE($10, movies.original_language),
+  updated_at = now()
+WHERE NOT movies.admin_locked
+  AND (movies.title, movies.release_date, movies.overview, movies.poster_path, movies.backdrop_path, movies.popularity,
+       movies.genre_ids, movies.original_title, movies.original_language)
+  IS DISTINCT FROM
+      (EXCLUDED.title, EXCLUDED.release_date, COALESCE($4, movies.overview), COALESCE($5, movies.poster_path),
+       COALESCE($6, movies.backdrop_path), COALESCE($7, movies.popularity),
+       CASE WHEN cardinality(EXCLUDED.genre_ids) = 0 THEN movies.genre_ids ELSE EXCLUDED.genre_ids END,
+       COALESCE($9, movies.original_title), COALESCE($10, movies.original_language))
+RETURNING (xmax = 0) AS inserted
 `
 
-type ListActiveMoviesFilteredPageParams struct {
-	Column1     pgtype.Timestamptz `json:"column_1"`
-	Column2     float64            `json:"column_2"`
-	Column3     float64            `json:"column_3"`
-	Column4     string             `json:"column_4"`
-	Column5     string             `json:"column_5"`
-	Column6     float64            `json:"column_6"`
-	Column7     int64              `json:"column_7"`
-	Limit       int32              `json:"limit"`
-	Fingerprint string             `json:"fingerprint"`
+type ImportMovieParams struct {
+	ID               int64         `json:"id"`
+	Title            string        `json:"title"`
+	ReleaseDate      pgtype.Date   `json:"release_date"`
+	Overview         pgtype.Text   `json:"overview"`
+	PosterPath       pgtype.Text   `json:"poster_path"`
+	BackdropPath     pgtype.Text   `json:"backdrop_path"`
+	Popularity       pgtype.Float8 `json:"popularity"`
+	GenreIds         []int32       `json:"genre_ids"`
+	OriginalTitle    pgtype.Text   `json:"original_title"`
+	OriginalLanguage pgtype.Text   `json:"original_language"`
 }
 
-type ListActiveMoviesFilteredPageRow struct {
-	ID            int64         `json:"id"`
-	Title         string        `json:"title"`
-	ReleaseDate   pgtype.Date   `json:"release_date"`
-	Overview      pgtype.Text   `json:"overview"`
-	PosterPath    pgtype.Text   `json:"poster_path"`
-	BackdropPath  pgtype.Text   `json:"backdrop_path"`
-	Popularity    pgtype.Float8 `json:"popularity"`
-	ImdbUrl       pgtype.Text   `json:"imdb_url"`
-	CinemagiaUrl  pgtype.Text   `json:"cinemagia_url"`
-	SoloFriends   int64         `json:"solo_friends"`
-	Couple        int64         `json:"couple"`
-	Streaming     int64         `json:"streaming"`
-	Arr           int64         `json:"arr"`
-	KeyValue      interface{}   `json:"key_value"`
-	VotedCategory interface{}   `json:"voted_category"`
+// ImportMovie upserts a movie from an archive. Fields the archive leaves empty
+// keep their stored values; admin-locked and unchanged movies are left alone
+// and return no row.
+func (q *Queries) ImportMovie(ctx context.Context, arg ImportMovieParams) (bool, error) {
+	row := q.db.QueryRow(ctx, ImportMovie,
+		arg.ID,
+		arg.Title,
+		arg.ReleaseDate,
+		arg.Overview,
+		arg.PosterPath,
+		arg.BackdropPath,
+		arg.Popularity,
+		arg.GenreIds,
+		arg.OriginalTitle,
+		arg.OriginalLanguage,
+	)
+	var inserted bool
+	err := row.Scan(&inserted)
+	return inserted, err
+}
+
+const ListCalendarReleases = `-- name: ListCalendarReleases :many
+SELECT m.id, m.title, r.release_date, r.revision, r.updated_at
+FROM movie_releases r
+JOIN movies m ON m.id = r.movie_id
+WHERE r.region = $1
+  AND NOT m.hidden
+  AND r.release_date >= $2::date
+  AND r.release_date < $3::date
+  AND (cardinality($4::int[]) = 0 OR m.genre_ids && $4::int[])
+ORDER BY r.release_date, m.id
+`
+
+type ListCalendarReleasesParams struct {
+	Region  string      `json:"region"`
+	Column2 pgtype.Date `json:"column_2"`
+	Column3 pgtype.Date `json:"column_3"`
+	Column4 []int32     `json:"column_4"`
 }
 
-func (q *Queries) ListActiveMoviesFilteredPage(ctx context.Context, arg ListActiveMoviesFilteredPageParams) ([]ListActiveMoviesFilteredPageRow, error) {
-	rows, err := q.db.Query(ctx, ListActiveMoviesFilteredPage,
-		arg.Column1,
+type ListCalendarReleasesRow struct {
+	ID          int64              `json:"id"`
+	Title       string             `json:"title"`
+	ReleaseDate pgtype.Date        `json:"release_date"`
+	Revision    int32              `json:"revision"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+// Releases in region from $2 up to (excluding) $3, optionally only movies with
+// any of the genres $4.
+func (q *Queries) ListCalendarReleases(ctx context.Context, arg ListCalendarReleasesParams) ([]ListCalendarReleasesRow, error) {
+	rows, err := q.db.Query(ctx, ListCalendarReleases,
+		arg.Region,
 		arg.Column2,
 		arg.Column3,
 		arg.Column4,
-		arg.Column5,
-		arg.Column6,
-		arg.Column7,
-		arg.Limit,
-		arg.Fingerprint,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []ListActiveMoviesFilteredPageRow{}
+	items := []ListCalendarReleasesRow{}
 	for rows.Next() {
-		var i ListActiveMoviesFilteredPageRow
+		var i ListCalendarReleasesRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Title,
 			&i.ReleaseDate,
-			&i.Overview,
-			&i.PosterPath,
-			&i.BackdropPath,
-			&i.Popularity,
-			&i.ImdbUrl,
-			&i.CinemagiaUrl,
-			&i.SoloFriends,
-			&i.Couple,
-			&i.Streaming,
-			&i.Arr,
-			&i.KeyValue,
-			&i.VotedCategory,
+			&i.Revision,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -208,62 +194,64 @@ func (q *Queries) ListActiveMoviesFilteredPage(ctx context.Context, arg ListActi
 	return items, nil
 }
 
-const ListActiveMoviesPage = `-- name: ListActiveMoviesPage :many
-SELECT id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url
-FROM movies
-WHERE release_date >= date_trunc('month', $1::timestamptz)::date
-  AND release_date <= (date_trunc('month', $1::timestamptz)::date + interval '1 month - 1 second')
-  AND $1::timestamptz <= (release_date + interval '14 days')
-  AND (
-    $3::bigint = 0 OR (popularity < $2) OR (popularity = $2 AND id < $3)
-  )
-ORDER BY popularity DESC, id DESC
-LIMIT $4
+const ListMovieIDsByMonth = `-- name: ListMovieIDsByMonth :many
+SELECT m.id
+FROM movies m
+JOIN movie_releases r ON r.movie_id = m.id AND r.region = $2
+WHERE r.release_date >= date_trunc('month', $1::date)
+  AND r.release_date <  (date_trunc('month', $1::date) + interval '1 month')
+  AND NOT m.hidden
+ORDER BY m.id
 `
 
-type ListActiveMoviesPageParams struct {
-	Column1    pgtype.Timestamptz `json:"column_1"`
-	Popularity pgtype.Float8      `json:"popularity"`
-	Column3    int64              `json:"column_3"`
-	Limit      int32              `json:"limit"`
+type ListMovieIDsByMonthParams struct {
+	Column1 pgtype.Date `json:"column_1"`
+	Region  string      `json:"region"`
 }
 
-type ListActiveMoviesPageRow struct {
-	ID           int64         `json:"id"`
-	Title        string        `json:"title"`
-	ReleaseDate  pgtype.Date   `json:"release_date"`
-	Overview     pgtype.Text   `json:"overview"`
-	PosterPath   pgtype.Text   `json:"poster_path"`
-	BackdropPath pgtype.Text   `json:"backdrop_path"`
-	Popularity   pgtype.Float8 `json:"popularity"`
-	ImdbUrl      pgtype.Text   `json:"imdb_url"`
-	CinemagiaUrl pgtype.Text   `json:"cinemagia_url"`
+func (q *Queries) ListMovieIDsByMonth(ctx context.Context, arg ListMovieIDsByMonthParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, ListMovieIDsByMonth, arg.Column1, arg.Region)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *Queries) ListActiveMoviesPage(ctx context.Context, arg ListActiveMoviesPageParams) ([]ListActiveMoviesPageRow, error) {
-	rows, err := q.db.Query(ctx, ListActiveMoviesPage,
-		arg.Column1,
-		arg.Popularity,
-		arg.Column3,
-		arg.Limit,
-	)
+const ListMovieReleases = `-- name: ListMovieReleases :many
+SELECT movie_id, region, release_date, updated_at, created_at, revision
+FROM movie_releases
+WHERE movie_id = $1
+ORDER BY region
+`
+
+func (q *Queries) ListMovieReleases(ctx context.Context, movieID int64) ([]MovieRelease, error) {
+	rows, err := q.db.Query(ctx, ListMovieReleases, movieID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []ListActiveMoviesPageRow{}
+	items := []MovieRelease{}
 	for rows.Next() {
-		var i ListActiveMoviesPageRow
+		var i MovieRelease
 		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
+			&i.MovieID,
+			&i.Region,
 			&i.ReleaseDate,
-			&i.Overview,
-			&i.PosterPath,
-			&i.BackdropPath,
-			&i.Popularity,
-			&i.ImdbUrl,
-			&i.CinemagiaUrl,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Revision,
 		); err != nil {
 			return nil, err
 		}
@@ -275,27 +263,55 @@ func (q *Queries) ListActiveMoviesPage(ctx context.Context, arg ListActiveMovies
 	return items, nil
 }
 
-const ListMovieIDsByMonth = `-- name: ListMovieIDsByMonth :many
-SELECT id
-FROM movies
-WHERE release_date >= date_trunc('month', $1::date)
-  AND release_date <  (date_trunc('month', $1::date) + interval '1 month')
-ORDER BY id
+const ListRecentlyVotableMovies = `-- name: ListRecentlyVotableMovies :many
+SELECT m.id, m.title, m.overview, m.poster_path, r.release_date,
+       GREATEST(date_trunc('month', r.release_date::timestamp) AT TIME ZONE 'UTC', r.created_at)::timestamptz AS votable_since
+FROM movie_releases r
+JOIN movies m ON m.id = r.movie_id
+WHERE r.region = $1
+  AND NOT m.hidden
+  AND r.release_date < (date_trunc('month', $2::timestamptz AT TIME ZONE 'UTC') + interval '1 month')::date
+ORDER BY votable_since DESC, m.id DESC
+LIMIT $3
 `
 
-func (q *Queries) ListMovieIDsByMonth(ctx context.Context, dollar_1 pgtype.Date) ([]int64, error) {
-	rows, err := q.db.Query(ctx, ListMovieIDsByMonth, dollar_1)
+type ListRecentlyVotableMoviesParams struct {
+	Region  string             `json:"region"`
+	Column2 pgtype.Timestamptz `json:"column_2"`
+	Limit   int32              `json:"limit"`
+}
+
+type ListRecentlyVotableMoviesRow struct {
+	ID           int64              `json:"id"`
+	Title        string             `json:"title"`
+	Overview     pgtype.Text        `json:"overview"`
+	PosterPath   pgtype.Text        `json:"poster_path"`
+	ReleaseDate  pgtype.Date        `json:"release_date"`
+	VotableSince pgtype.Timestamptz `json:"votable_since"`
+}
+
+// Movies become votable when their release month starts or, if added later,
+// when they were put on the region's board.
+func (q *Queries) ListRecentlyVotableMovies(ctx context.Context, arg ListRecentlyVotableMoviesParams) ([]ListRecentlyVotableMoviesRow, error) {
+	rows, err := q.db.Query(ctx, ListRecentlyVotableMovies, arg.Region, arg.Column2, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []int64{}
+	items := []ListRecentlyVotableMoviesRow{}
 	for rows.Next() {
-		var id int64
-		if err := rows.Scan(&id); err != nil {
+		var i ListRecentlyVotableMoviesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Overview,
+			&i.PosterPath,
+			&i.ReleaseDate,
+			&i.VotableSince,
+		); err != nil {
 			return nil, err
 		}
-		items = append(items, id)
+		items = append(items, i)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -303,9 +319,30 @@ func (q *Queries) ListMovieIDsByMonth(ctx context.Context, dollar_1 pgtype.Date)
 	return items, nil
 }
 
+const SetMovieRelease = `-- name: SetMovieRelease :exec
+INSERT INTO movie_releases (movie_id, region, release_date)
+VALUES ($1, $2, $3)
+ON CONFLICT (movie_id, region) DO UPDATE SET
+  release_date = EXCLUDED.release_date,
+  revision = movie_releases.revision + 1,
+  updated_at = now()
+WHERE movie_releases.release_date IS DISTINCT FROM EXCLUDED.release_date
+`
+
+type SetMovieReleaseParams struct {
+	MovieID     int64       `json:"movie_id"`
+	Region      string      `json:"region"`
+	ReleaseDate pgtype.Date `json:"release_date"`
+}
+
+func (q *Queries) SetMovieRelease(ctx context.Context, arg SetMovieReleaseParams) error {
+	_, err := q.db.Exec(ctx, SetMovieRelease, arg.MovieID, arg.Region, arg.ReleaseDate)
+	return err
+}
+
 const UpsertMovie = `-- name: UpsertMovie :exec
-INSERT INTO movies (id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+INSERT INTO movies (id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url, genre_ids, original_title, original_language)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 ON CONFLICT (id) DO UPDATE SET
   title = EXCLUDED.title,
   release_date = EXCLUDED.release_date,
@@ -315,19 +352,26 @@ ON CONFLICT (id) DO UPDATE SET
   popularity = EXCLUDED.popularity,
   imdb_url = EXCLUDED.imdb_url,
   cinemagia_url = EXCLUDED.cinemagia_url,
+  genre_ids = EXCLUDED.genre_ids,
+  original_title = EXCLUDED.original_title,
+  original_language = EXCLUDED.original_language,
   updated_at = now()
+WHERE NOT movies.admin_locked
 `
 
 type UpsertMovieParams struct {
-	ID           int64         `json:"id"`
-	Title        string        `json:"title"`
-	ReleaseDate  pgtype.Date   `json:"release_date"`
-	Overview     pgtype.Text   `json:"overview"`
-	PosterPath   pgtype.Text   `json:"poster_path"`
-	BackdropPath pgtype.Text   `json:"backdrop_path"`
-	Popularity   pgtype.Float8 `json:"popularity"`
-	ImdbUrl      pgtype.Text   `json:"imdb_url"`
-	CinemagiaUrl pgtype.Text   `json:"cinemagia_url"`
+	ID               int64         `json:"id"`
+	Title            string        `json:"title"`
+	ReleaseDate      pgtype.Date   `json:"release_date"`
+	Overview         pgtype.Text   `json:"overview"`
+	PosterPath       pgtype.Text   `json:"poster_path"`
+	BackdropPath     pgtype.Text   `json:"backdrop_path"`
+	Popularity       pgtype.Float8 `json:"popularity"`
+	ImdbUrl          pgtype.Text   `json:"imdb_url"`
+	CinemagiaUrl     pgtype.Text   `json:"cinemagia_url"`
+	GenreIds         []int32       `json:"genre_ids"`
+	OriginalTitle    pgtype.Text   `json:"original_title"`
+	OriginalLanguage pgtype.Text   `json:"original_language"`
 }
 
 func (q *Queries) UpsertMovie(ctx context.Context, arg UpsertMovieParams) error {
@@ -341,6 +385,60 @@ func (q *Queries) UpsertMovie(ctx context.Context, arg UpsertMovieParams) error
 		arg.Popularity,
 		arg.ImdbUrl,
 		arg.CinemagiaUrl,
+		arg.GenreIds,
+		arg.OriginalTitle,
+		arg.OriginalLanguage,
+	)
+	return err
+}
+
+const UpsertMovieRelease = `-- name: UpsertMovieRelease :one
+INSERT INTO movie_releases (movie_id, region, release_date)
+VALUES ($1, $2, $3)
+ON CONFLICT (movie_id, region) DO UPDATE SET
+  release_date = EXCLUDED.release_date,
+  revision = movie_releases.revision + 1,
+  updated_at = now()
+WHERE movie_releases.release_date IS DISTINCT FROM EXCLUDED.release_date
+  AND NOT EXISTS (SELECT 1 FROM movies WHERE id = EXCLUDED.movie_id AND admin_locked)
+RETURNING (xmax = 0) AS inserted
+`
+
+type UpsertMovieReleaseParams struct {
+	MovieID     int64       `json:"movie_id"`
+	Region      string      `json:"region"`
+	ReleaseDate pgtype.Date `json:"release_date"`
+}
+
+func (q *Queries) UpsertMovieRelease(ctx context.Context, arg UpsertMovieReleaseParams) (bool, error) {
+	row := q.db.QueryRow(ctx, UpsertMovieRelease, arg.MovieID, arg.Region, arg.ReleaseDate)
+	var inserted bool
+	err := row.Scan(&inserted)
+	return inserted, err
+}
+
+const UpsertMovieTranslation = `-- name: UpsertMovieTranslation :exec
+INSERT INTO movie_translations (movie_id, language, title, overview)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (movie_id, language) DO UPDATE SET
+  title = EXCLUDED.title,
+  overview = EXCLUDED.overview,
+  updated_at = now()
+`
+
+type UpsertMovieTranslationParams struct {
+	MovieID  int64  `json:"movie_id"`
+	Language string `json:"language"`
+	Title    string `json:"title"`
+	Overview string `json:"overview"`
+}
+
+func (q *Queries) UpsertMovieTranslation(ctx context.Context, arg UpsertMovieTranslationParams) error {
+	_, err := q.db.Exec(ctx, UpsertMovieTranslation,
+		arg.MovieID,
+		arg.Language,
+		arg.Title,
+		arg.Overview,
 	)
 	return err
 }
@@ -0,0 +1,104 @@
+-- name: GetMovieAdmin :one
+SELECT id, title, release_date, overview, poster_path, backdrop_path, popularity, created_at, updated_at, imdb_url, cinemagia_url, hidden, admin_locked, merged_into, genre_ids
+FROM movies
+WHERE id = $1;
+
+-- name: UpdateMovieAdmin :one
+UPDATE movies SET
+  title = COALESCE(sqlc.narg('title'), title),
+  release_date = COALESCE(sqlc.narg('release_date'), release_date),
+  overview = COALESCE(sqlc.narg('overview'), overview),
+  poster_path = COALESCE(sqlc.narg('poster_path'), poster_path),
+  backdrop_path = COALESCE(sqlc.narg('backdrop_path'), backdrop_path),
+  popularity = COALESCE(sqlc.narg('popularity'), popularity),
+  imdb_url = COALESCE(sqlc.narg('imdb_url'), imdb_url),
+  cinemagia_url = COALESCE(sqlc.narg('cinemagia_url'), cinemagia_url),
+  hidden = COALESCE(sqlc.narg('hidden'), hidden),
+  admin_locked = COALESCE(sqlc.narg('admin_locked'), true),
+  updated_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING id, title, release_date, overview, poster_path, backdrop_path, popularity, created_at, updated_at, imdb_url, cinemagia_url, hidden, admin_locked, merged_into;
+
+-- name: MoveVotesToMovie :execrows
+UPDATE votes v
+SET movie_id = sqlc.arg('target_id')
+WHERE v.movie_id = sqlc.arg('source_id')
+  AND NOT EXISTS (
+    SELECT 1 FROM votes t WHERE t.movie_id = sqlc.arg('target_id') AND t.region = v.region AND t.voter_id = v.voter_id
+  );
+
+-- name: DeleteVotesByMovie :execrows
+DELETE FROM votes WHERE movie_id = $1;
+
+-- name: MoveSnapshotsToMovie :execrows
+UPDATE snapshots s
+SET movie_id = sqlc.arg('target_id')
+WHERE s.movie_id = sqlc.arg('source_id')
+  AND NOT EXISTS (
+    SELECT 1 FROM snapshots t WHERE t.region = s.region AND t.month = s.month AND t.movie_id = sqlc.arg('target_id')
+  );
+
+-- name: MarkMovieMerged :exec
+UPDATE movies
+SET hidden = true, admin_locked = true, merged_into = sqlc.arg('target_id'), updated_at = now()
+WHERE id = sqlc.arg('source_id');
+
+-- name: DeleteTalliesForMovies :exec
+DELETE FROM vote_tallies WHERE movie_id = ANY($1::bigint[]);
+
+-- name: RebuildTalliesForMovies :exec
+INSERT INTO vote_tallies (movie_id, region, category, count)
+SELECT movie_id, region, category, COUNT(*)
+FROM votes
+WHERE movie_id = ANY($1::bigint[])
+GROUP BY movie_id, region, category;
+
+-- name: ListVotesAdmin :many
+SELECT v.id, v.movie_id, v.region, v.voter_id, vr.fingerprint, v.category::text AS category, v.created_at
+FROM votes v
+JOIN voters vr ON vr.id = v.voter_id
+WHERE (sqlc.narg('voter_id')::uuid IS NULL OR v.voter_id = sqlc.narg('voter_id'))
+  AND (sqlc.narg('fingerprint')::text IS NULL OR vr.fingerprint = sqlc.narg('fingerprint'))
+  AND (sqlc.narg('movie_id')::bigint IS NULL OR v.movie_id = sqlc.narg('movie_id'))
+  AND (sqlc.narg('region')::text IS NULL OR v.region = sqlc.narg('region'))
+  AND (sqlc.narg('since')::timestamptz IS NULL OR v.created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR v.created_at < sqlc.narg('until'))
+ORDER BY v.created_at DESC, v.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: VoidVotes :many
+DELETE FROM votes v
+USING voters vr
+WHERE vr.id = v.voter_id
+  AND (sqlc.narg('voter_id')::uuid IS NULL OR v.voter_id = sqlc.narg('voter_id'))
+  AND (sqlc.narg('fingerprint')::text IS NULL OR vr.fingerprint = sqlc.narg('fingerprint'))
+  AND (sqlc.narg('movie_id')::bigint IS NULL OR v.movie_id = sqlc.narg('movie_id'))
+  AND (sqlc.narg('region')::text IS NULL OR v.region = sqlc.narg('region'))
+  AND (sqlc.narg('since')::timestamptz IS NULL OR v.created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR v.created_at < sqlc.narg('until'))
+RETURNING v.movie_id;
+
+-- name: InsertAuditLog :exec
+INSERT INTO admin_audit_log (actor, action, target, details)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListAuditLog :many
+SELECT id, actor, action, target, details, created_at
+FROM admin_audit_log
+WHERE (sqlc.narg('before_id')::bigint IS NULL OR id < sqlc.narg('before_id'))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetAdminCredentials :one
+SELECT id, password_hash, password_salt
+FROM users
+WHERE email = $1 AND role = 'admin';
+
+-- name: UpsertUserCredentials :one
+INSERT INTO users (email, password_hash, password_salt, role)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (email) DO UPDATE SET
+  password_hash = EXCLUDED.password_hash,
+  password_salt = EXCLUDED.password_salt,
+  role = EXCLUDED.role
+RETURNING id;
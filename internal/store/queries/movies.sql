@@ -1,6 +1,6 @@
 -- name: UpsertMovie :exec
-INSERT INTO movies (id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+INSERT INTO movies (id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url, genre_ids, original_title, original_language)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 ON CONFLICT (id) DO UPDATE SET
   title = EXCLUDED.title,
   release_date = EXCLUDED.release_date,
@@ -10,105 +10,120 @@ ON CONFLICT (id) DO UPDATE SET
   popularity = EXCLUDED.popularity,
   imdb_url = EXCLUDED.imdb_url,
   cinemagia_url = EXCLUDED.cinemagia_url,
-  updated_at = now();
-
--- name: ListActiveMoviesPage :many
-SELECT id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url
-FROM movies
-WHERE release_date >= date_trunc('month', $1::timestamptz)::date
-  AND release_date <= (date_trunc('month', $1::timestamptz)::date + interval '1 month - 1 second')
-  AND $1::timestamptz <= (release_date + interval '14 days')
-  AND (
-    $3::bigint = 0 OR (popularity < $2) OR (popularity = $2 AND id < $3)
-  )
-ORDER BY popularity DESC, id DESC
-LIMIT $4;
-
--- name: CountActiveMovies :one
-SELECT COUNT(*)
-FROM movies
-WHERE release_date >= date_trunc('month', $1::timestamptz)::date
-  AND release_date <= (date_trunc('month', $1::timestamptz)::date + interval '1 month - 1 second')
-  AND $1::timestamptz <= (release_date + interval '14 days');
+  genre_ids = EXCLUDED.genre_ids,
+  original_title = EXCLUDED.original_title,
+  original_language = EXCLUDED.original_language,
+  updated_at = now()
+WHERE NOT movies.admin_locked;
 
 -- name: GetMovieReleaseDate :one
-SELECT release_date
-FROM movies
-WHERE id = $1;
+SELECT r.release_date
+FROM movie_releases r
+JOIN movies m ON m.id = r.movie_id
+WHERE r.movie_id = $1 AND r.region = $2 AND NOT m.hidden;
 
 -- name: HasAnyMovies :one
 SELECT EXISTS (SELECT 1 FROM movies LIMIT 1) AS exists;
 
+-- name: ListCalendarReleases :many
+-- Releases in region from $2 up to (excluding) $3, optionally only movies with
+-- any of the genres $4.
+SELECT m.id, m.title, r.release_date, r.revision, r.updated_at
+FROM movie_releases r
+JOIN movies m ON m.id = r.movie_id
+WHERE r.region = $1
+  AND NOT m.hidden
+  AND r.release_date >= $2::date
+  AND r.release_date < $3::date
+  AND (cardinality($4::int[]) = 0 OR m.genre_ids && $4::int[])
+ORDER BY r.release_date, m.id;
+
 -- name: ListMovieIDsByMonth :many
-SELECT id
-FROM movies
-WHERE release_date >= date_trunc('month', $1::date)
-  AND release_date <  (date_trunc('month', $1::date) + interval '1 month')
-ORDER BY id;
+SELECT m.id
+FROM movies m
+JOIN movie_releases r ON r.movie_id = m.id AND r.region = $2
+WHERE r.release_date >= date_trunc('month', $1::date)
+  AND r.release_date <  (date_trunc('month', $1::date) + interval '1 month')
+  AND NOT m.hidden
+ORDER BY m.id;
+
+-- name: ListRecentlyVotableMovies :many
+-- Movies become votable when their release month starts or, if added later,
+-- when they were put on the region's board.
+SELECT m.id, m.title, m.overview, m.poster_path, r.release_date,
+       GREATEST(date_trunc('month', r.release_date::timestamp) AT TIME ZONE 'UTC', r.created_at)::timestamptz AS votable_since
+FROM movie_releases r
+JOIN movies m ON m.id = r.movie_id
+WHERE r.region = $1
+  AND NOT m.hidden
+  AND r.release_date < (date_trunc('month', $2::timestamptz AT TIME ZONE 'UTC') + interval '1 month')::date
+ORDER BY votable_since DESC, m.id DESC
+LIMIT $3;
+
+-- name: UpsertMovieRelease :one
+INSERT INTO movie_releases (movie_id, region, release_date)
+VALUES ($1, $2, $3)
+ON CONFLICT (movie_id, region) DO UPDATE SET
+  release_date = EXCLUDED.release_date,
+  revision = movie_releases.revision + 1,
+  updated_at = now()
+WHERE movie_releases.release_date IS DISTINCT FROM EXCLUDED.release_date
+  AND NOT EXISTS (SELECT 1 FROM movies WHERE id = EXCLUDED.movie_id AND admin_locked)
+RETURNING (xmax = 0) AS inserted;
+
+-- name: SetMovieRelease :exec
+INSERT INTO movie_releases (movie_id, region, release_date)
+VALUES ($1, $2, $3)
+ON CONFLICT (movie_id, region) DO UPDATE SET
+  release_date = EXCLUDED.release_date,
+  revision = movie_releases.revision + 1,
+  updated_at = now()
+WHERE movie_releases.release_date IS DISTINCT FROM EXCLUDED.release_date;
 
--- name: ListActiveMoviesFilteredPage :many
-WITH base AS (
-  SELECT id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url
-  FROM movies
-  WHERE release_date >= date_trunc('month', $1::timestamptz)::date
-    AND release_date <= (date_trunc('month', $1::timestamptz)::date + interval '1 month - 1 second')
-    AND $1::timestamptz <= (release_date + interval '14 days')
-    AND ($2::float8 IS NULL OR popularity >= $2)
-    AND ($3::float8 IS NULL OR popularity <= $3)
-), t AS (
-  SELECT movie_id,
-         SUM(CASE WHEN category = 'solo_friends' THEN count ELSE 0 END)::bigint AS solo_friends,
-         SUM(CASE WHEN category = 'couple' THEN count ELSE 0 END)::bigint AS couple,
-         SUM(CASE WHEN category = 'streaming' THEN count ELSE 0 END)::bigint AS streaming,
-         SUM(CASE WHEN category = 'arr' THEN count ELSE 0 END)::bigint AS arr
-  FROM vote_tallies
-  GROUP BY movie_id
-), joined AS (
-  SELECT b.*, COALESCE(t.solo_friends,0) AS solo_friends, COALESCE(t.couple,0) AS couple, COALESCE(t.streaming,0) AS streaming, COALESCE(t.arr,0) AS arr
-  FROM base b LEFT JOIN t ON t.movie_id = b.id
-), keyed AS (
-  SELECT *, CASE
-      WHEN $4::text = 'popularity' THEN popularity
-      WHEN $4::text = 'release_date' THEN extract(epoch from release_date)
-      WHEN $4::text = 'solo_friends' THEN solo_friends::double precision
-      WHEN $4::text = 'couple' THEN couple::double precision
-      WHEN $4::text = 'streaming' THEN streaming::double precision
-      WHEN $4::text = 'arr' THEN arr::double precision
-      ELSE popularity
-    END AS key_value
-  FROM joined
-), voted AS (
-  SELECT j.*, COALESCE(v.category::text, '') AS voted_category
-  FROM keyed j
-  LEFT JOIN voters vr ON vr.fingerprint = $9
-  LEFT JOIN votes v ON v.movie_id = j.id AND v.voter_id = vr.id
-), paged AS (
-  SELECT * FROM voted
-  WHERE (
-    $6::float8 IS NULL OR (
-      CASE WHEN $5::text = 'desc'
-           THEN (key_value < $6 OR (key_value = $6 AND id < $7::bigint))
-           ELSE (key_value > $6 OR (key_value = $6 AND id > $7::bigint))
-      END
-    )
-  )
-)
-SELECT id, title, release_date, overview, poster_path, backdrop_path, popularity, imdb_url, cinemagia_url,
-       solo_friends, couple, streaming, arr, key_value, voted_category
-FROM paged p
-ORDER BY
-  CASE WHEN $5::text = 'desc' THEN key_value END DESC NULLS LAST,
-  CASE WHEN $5::text = 'asc'  THEN key_value END ASC  NULLS LAST,
-  CASE WHEN $4::text <> 'popularity' THEN popularity END DESC NULLS LAST,
-  CASE WHEN $5::text = 'desc' THEN p.id END DESC NULLS LAST,
-  CASE WHEN $5::text = 'asc'  THEN p.id END ASC  NULLS LAST
-LIMIT $8;
+-- name: ListMovieReleases :many
+SELECT movie_id, region, release_date, updated_at, created_at, revision
+FROM movie_releases
+WHERE movie_id = $1
+ORDER BY region;
+
+-- name: UpsertMovieTranslation :exec
+INSERT INTO movie_translations (movie_id, language, title, overview)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (movie_id, language) DO UPDATE SET
+  title = EXCLUDED.title,
+  overview = EXCLUDED.overview,
+  updated_at = now();
+
+-- name: ImportMovie :one
+-- ImportMovie upserts a movie from an archive. Fields the archive leaves empty
+-- keep their stored values; admin-locked and unchanged movies are left alone
+-- and return no row.
+INSERT INTO movies (id, title, release_date, overview, poster_path, backdrop_path, popularity, genre_ids, original_title, original_language)
+VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7::float8, 0), $8, $9, $10)
+ON CONFLICT (id) DO UPDATE SET
+  title = EXCLUDED.title,
+  release_date = EXCLUDED.release_date,
+  overview = COALESCE($4, movies.overview),
+  poster_path = COALESCE($5, movies.poster_path),
+  backdrop_path = COALESCE($6, movies.backdrop_path),
+  popularity = COALESCE($7, movies.popularity),
+  genre_ids = CASE WHEN cardinality(EXCLUDED.genre_ids) = 0 THEN movies.genre_ids ELSE EXCLUDED.genre_ids END,
+  original_title = COALESCE($9, movies.original_title),
+  original_language = COALESCE($10, movies.original_language),
+  updated_at = now()
+WHERE NOT movies.admin_locked
+  AND (movies.title, movies.release_date, movies.overview, movies.poster_path, movies.backdrop_path, movies.popularity,
+       movies.genre_ids, movies.original_title, movies.original_language)
+  IS DISTINCT FROM
+      (EXCLUDED.title, EXCLUDED.release_date, COALESCE($4, movies.overview), COALESCE($5, movies.poster_path),
+       COALESCE($6, movies.backdrop_path), COALESCE($7, movies.popularity),
+       CASE WHEN cardinality(EXCLUDED.genre_ids) = 0 THEN movies.genre_ids ELSE EXCLUDED.genre_ids END,
+       COALESCE($9, movies.original_title), COALESCE($10, movies.original_language))
+RETURNING (xmax = 0) AS inserted;
 
--- name: CountActiveMoviesFiltered :one
-SELECT COUNT(*)
-FROM movies
-WHERE release_date >= date_trunc('month', $1::timestamptz)::date
-  AND release_date <= (date_trunc('month', $1::timestamptz)::date + interval '1 month - 1 second')
-  AND $1::timestamptz <= (release_date + interval '14 days')
-  AND ($2::float8 IS NULL OR popularity >= $2)
-  AND ($3::float8 IS NULL OR popularity <= $3);
+-- name: GetMovieCard :one
+-- A visible movie released in region, as shown on its share card.
+SELECT m.id, m.title, m.overview, m.poster_path, r.release_date
+FROM movie_releases r
+JOIN movies m ON m.id = r.movie_id
+WHERE r.movie_id = $1 AND r.region = $2 AND NOT m.hidden;
@@ -0,0 +1,22 @@
+-- name: ListRegions :many
+SELECT code, name, enabled, created_at
+FROM regions
+ORDER BY code;
+
+-- name: ListEnabledRegionCodes :many
+SELECT code
+FROM regions
+WHERE enabled
+ORDER BY code;
+
+-- name: UpsertRegion :exec
+INSERT INTO regions (code, name, enabled)
+VALUES ($1, $2, $3)
+ON CONFLICT (code) DO UPDATE SET
+  name = EXCLUDED.name,
+  enabled = EXCLUDED.enabled;
+
+-- name: EnsureRegion :exec
+INSERT INTO regions (code, name)
+VALUES ($1, $2)
+ON CONFLICT (code) DO NOTHING;
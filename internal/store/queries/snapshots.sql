@@ -1,89 +1,79 @@
 -- name: UpsertSnapshot :exec
-INSERT INTO snapshots (month, movie_id, tallies)
-VALUES ($1, $2, $3)
-ON CONFLICT (month, movie_id) DO UPDATE SET
+INSERT INTO snapshots (region, month, movie_id, tallies)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (region, month, movie_id) DO UPDATE SET
   tallies = EXCLUDED.tallies,
   closed_at = now();
 
 -- name: GetSnapshotsByMonth :many
-SELECT id, month, movie_id, tallies, closed_at
+SELECT id, month, movie_id, tallies, closed_at, region
 FROM snapshots
-WHERE month = $1
+WHERE region = $1 AND month = $2
 ORDER BY movie_id ASC;
 
 -- name: GetSnapshot :one
-SELECT id, month, movie_id, tallies, closed_at
+SELECT id, month, movie_id, tallies, closed_at, region
 FROM snapshots
-WHERE month = $1 AND movie_id = $2;
-
--- name: ListSnapshotsByMonthPage :many
-SELECT id, month, movie_id, tallies, closed_at
-FROM snapshots
-WHERE month = $1
-  AND ($2::bigint = 0 OR movie_id > $2)
-ORDER BY movie_id ASC
-LIMIT $3;
-
--- name: CountSnapshotsByMonth :one
-SELECT COUNT(*) FROM snapshots WHERE month = $1;
-
--- name: ListSnapshotsByMonthFilteredPage :many
-WITH s AS (
-  SELECT st.month, st.movie_id, st.tallies, st.closed_at, m.popularity, m.title, m.release_date, m.overview, m.poster_path, m.backdrop_path, m.imdb_url, m.cinemagia_url
-  FROM snapshots st
-  JOIN movies m ON m.id = st.movie_id
-  WHERE st.month = $1
-    AND ($2::float8 IS NULL OR m.popularity >= $2)
-    AND ($3::float8 IS NULL OR m.popularity <= $3)
-), exploded AS (
-  SELECT month, movie_id, closed_at, popularity, title, release_date, overview, poster_path, backdrop_path, imdb_url, cinemagia_url,
-    COALESCE((tallies ->> 'solo_friends')::bigint, 0) AS solo_friends,
-    COALESCE((tallies ->> 'couple')::bigint, 0) AS couple,
-    COALESCE((tallies ->> 'streaming')::bigint, 0) AS streaming,
-    COALESCE((tallies ->> 'arr')::bigint, 0) AS arr
-  FROM s
-), keyed AS (
-  SELECT month, movie_id, closed_at, popularity, title, release_date, overview, poster_path, backdrop_path, imdb_url, cinemagia_url, solo_friends, couple, streaming, arr, CASE
-    WHEN $4::text = 'popularity' THEN popularity
-    WHEN $4::text = 'release_date' THEN extract(epoch from release_date)
-    WHEN $4::text = 'solo_friends' THEN solo_friends::double precision
-    WHEN $4::text = 'couple' THEN couple::double precision
-    WHEN $4::text = 'streaming' THEN streaming::double precision
-    WHEN $4::text = 'arr' THEN arr::double precision
-    ELSE popularity
-  END AS key_value
-  FROM exploded
-), paged AS (
-  SELECT * FROM keyed
-  WHERE (
-    $6::float8 IS NULL OR (
-      CASE WHEN $5::text = 'desc' THEN (key_value < $6 OR (key_value = $6 AND movie_id < $7))
-           ELSE (key_value > $6 OR (key_value = $6 AND movie_id > $7))
-      END
-    )
-  )
-)
-SELECT movie_id, month, closed_at, popularity, title, release_date, overview, poster_path, backdrop_path, imdb_url, cinemagia_url, solo_friends, couple, streaming, arr, key_value
-FROM paged
-ORDER BY
-  CASE WHEN $5::text = 'desc' THEN key_value END DESC NULLS LAST,
-  CASE WHEN $5::text = 'asc'  THEN key_value END ASC  NULLS LAST,
-  CASE WHEN $4::text <> 'popularity' THEN popularity END DESC NULLS LAST,
-  CASE WHEN $5::text = 'desc' THEN movie_id END DESC NULLS LAST,
-  CASE WHEN $5::text = 'asc'  THEN movie_id END ASC  NULLS LAST
-LIMIT $8;
-
--- name: CountSnapshotsByMonthFiltered :one
-SELECT COUNT(*)
-FROM snapshots st
-JOIN movies m ON m.id = st.movie_id
-WHERE st.month = $1
-  AND ($2::float8 IS NULL OR m.popularity >= $2)
-  AND ($3::float8 IS NULL OR m.popularity <= $3);
+WHERE region = $1 AND month = $2 AND movie_id = $3;
 
 -- name: ListAvailableSnapshotYearMonths :many
 SELECT (split_part(month, '-', 1))::int AS year,
        (split_part(month, '-', 2))::int AS month
 FROM snapshots
+WHERE region = $1
 GROUP BY 1, 2
 ORDER BY year DESC, month DESC;
+
+-- name: ListSnapshotMonths :many
+SELECT month, max(closed_at)::timestamptz AS closed_at, count(*) AS movies
+FROM snapshots
+WHERE region = $1
+GROUP BY month
+ORDER BY month DESC
+LIMIT $2;
+
+-- name: ListSnapshotWinners :many
+-- The movie with the most votes per month and category; ties go to the more
+-- popular movie.
+SELECT DISTINCT ON (s.month, t.key) s.month, t.key::text AS category, s.movie_id, m.title, t.value::bigint AS votes
+FROM snapshots s
+JOIN movies m ON m.id = s.movie_id
+CROSS JOIN LATERAL jsonb_each_text(s.tallies) AS t(key, value)
+WHERE s.region = $1
+  AND s.month = ANY($2::text[])
+  AND NOT m.hidden
+  AND t.value::bigint > 0
+ORDER BY s.month DESC, t.key, t.value::bigint DESC, COALESCE(m.popularity, 0) DESC, s.movie_id;
+
+-- name: ImportSnapshot :one
+-- ImportSnapshot upserts an archived snapshot, keeping the archive's closed_at
+-- when it has one. An identical snapshot is left alone and returns no row.
+INSERT INTO snapshots (region, month, movie_id, tallies, closed_at)
+VALUES ($1, $2, $3, $4, COALESCE(sqlc.narg('closed_at')::timestamptz, now()))
+ON CONFLICT (region, month, movie_id) DO UPDATE SET
+  tallies = EXCLUDED.tallies,
+  closed_at = COALESCE(sqlc.narg('closed_at')::timestamptz, snapshots.closed_at)
+WHERE snapshots.tallies IS DISTINCT FROM EXCLUDED.tallies
+   OR snapshots.closed_at IS DISTINCT FROM COALESCE(sqlc.narg('closed_at')::timestamptz, snapshots.closed_at)
+RETURNING (xmax = 0) AS inserted;
+
+-- name: GetSnapshotMonth :one
+-- A month of region's archive: when it closed, its visible movies and the votes
+-- they received per category. movies is 0 when the month was not archived.
+SELECT max(s.closed_at)::timestamptz AS closed_at, count(*) AS movies,
+       COALESCE(sum((s.tallies ->> 'solo_friends')::bigint), 0)::bigint AS solo_friends,
+       COALESCE(sum((s.tallies ->> 'couple')::bigint), 0)::bigint AS couple,
+       COALESCE(sum((s.tallies ->> 'streaming')::bigint), 0)::bigint AS streaming,
+       COALESCE(sum((s.tallies ->> 'arr')::bigint), 0)::bigint AS arr
+FROM snapshots s
+JOIN movies m ON m.id = s.movie_id
+WHERE s.region = $1 AND s.month = $2 AND NOT m.hidden;
+
+-- name: LockSnapshotMonth :exec
+-- Holds off every other close of region's month until the transaction ends.
+SELECT pg_advisory_xact_lock(hashtextextended('snapshot_month:' || sqlc.arg('region')::text || ':' || sqlc.arg('month')::text, 0));
+
+-- name: CountMonthSnapshots :one
+-- Every snapshot of region's month, hidden movies' included: 0 until the month
+-- is first archived.
+SELECT count(*) FROM snapshots WHERE region = $1 AND month = $2;
@@ -1,25 +1,12 @@
 -- name: GetTalliesByMovie :many
-SELECT movie_id, category, count
+SELECT movie_id, category, count, region
 FROM vote_tallies
-WHERE movie_id = $1;
+WHERE movie_id = $1 AND region = $2;
 
 -- name: IncrementTally :exec
-INSERT INTO vote_tallies (movie_id, category, count)
-VALUES ($1, $2, 1)
-ON CONFLICT (movie_id, category) DO UPDATE SET count = vote_tallies.count + 1;
-
--- name: ListTalliesByMoviePage :many
-SELECT movie_id, category, count
-FROM vote_tallies
-WHERE movie_id = $1
-  AND (
-    $3::text = '' OR (count < $2) OR (count = $2 AND category::text > $3)
-  )
-ORDER BY count DESC, category ASC
-LIMIT $4;
-
--- name: CountTalliesByMovie :one
-SELECT COUNT(*) FROM vote_tallies WHERE movie_id = $1;
+INSERT INTO vote_tallies (movie_id, region, category, count)
+VALUES ($1, $2, $3, 1)
+ON CONFLICT (movie_id, region, category) DO UPDATE SET count = vote_tallies.count + 1;
 
 -- name: GetTalliesForMovies :many
 WITH cats AS (
@@ -32,11 +19,27 @@ SELECT m.movie_id,
        COALESCE(t.count, 0) AS count
 FROM mids m
 CROSS JOIN cats c
-LEFT JOIN vote_tallies t ON t.movie_id = m.movie_id AND t.category = c.category
+LEFT JOIN vote_tallies t ON t.movie_id = m.movie_id AND t.region = $2 AND t.category = c.category
 ORDER BY m.movie_id, c.category;
 
 -- name: GetVoterCategoryByMovieAndFingerprint :one
 SELECT v.category::text AS category
 FROM votes v
 JOIN voters vr ON vr.id = v.voter_id
-WHERE v.movie_id = $1 AND vr.fingerprint = $2;
+WHERE v.movie_id = $1 AND v.region = $2 AND vr.fingerprint = $3;
+
+-- name: ListTallyDrift :many
+WITH actual AS (
+  SELECT movie_id, region, category, COUNT(*)::bigint AS count
+  FROM votes
+  GROUP BY movie_id, region, category
+)
+SELECT COALESCE(a.movie_id, t.movie_id)::bigint AS movie_id,
+       COALESCE(a.region, t.region)::text AS region,
+       COALESCE(a.category, t.category)::text AS category,
+       COALESCE(t.count, 0)::bigint AS stored,
+       COALESCE(a.count, 0)::bigint AS actual
+FROM actual a
+FULL OUTER JOIN vote_tallies t ON t.movie_id = a.movie_id AND t.region = a.region AND t.category = a.category
+WHERE COALESCE(t.count, 0) <> COALESCE(a.count, 0)
+ORDER BY 1, 2, 3;
@@ -2,7 +2,91 @@
 SELECT id FROM voters WHERE fingerprint = $1;
 
 -- name: InsertVoter :one
-INSERT INTO voters (fingerprint)
-VALUES ($1)
+INSERT INTO voters (fingerprint, fingerprint_hashed)
+VALUES ($1, true)
 RETURNING id;
 
+
+-- name: CountOrphanVoters :one
+SELECT COUNT(*)
+FROM voters vr
+WHERE vr.created_at < $1
+  AND vr.user_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM votes v WHERE v.voter_id = vr.id);
+
+-- name: DeleteOrphanVoters :execrows
+DELETE FROM voters vr
+WHERE vr.created_at < $1
+  AND vr.user_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM votes v WHERE v.voter_id = vr.id);
+
+-- name: ListUnhashedVoters :many
+SELECT id, fingerprint::text AS fingerprint, user_id
+FROM voters
+WHERE NOT fingerprint_hashed AND fingerprint IS NOT NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: SetVoterFingerprintDigest :execrows
+UPDATE voters
+SET fingerprint = sqlc.arg('digest'), fingerprint_hashed = true
+WHERE id = sqlc.arg('id')
+  AND NOT EXISTS (SELECT 1 FROM voters o WHERE o.fingerprint = sqlc.arg('digest'));
+
+-- name: DeleteDuplicateVoterVotes :many
+DELETE FROM votes v
+WHERE v.voter_id = sqlc.arg('source_id')
+  AND EXISTS (
+    SELECT 1 FROM votes t WHERE t.voter_id = sqlc.arg('target_id') AND t.movie_id = v.movie_id AND t.region = v.region
+  )
+RETURNING v.movie_id;
+
+-- name: MoveVoterVotes :execrows
+UPDATE votes SET voter_id = sqlc.arg('target_id') WHERE voter_id = sqlc.arg('source_id');
+
+-- name: AdoptVoterUser :exec
+-- Links voter id to an account unless it is linked to one already.
+UPDATE voters SET user_id = sqlc.arg('user_id')
+WHERE id = sqlc.arg('id') AND user_id IS NULL;
+
+-- name: DeleteVoter :exec
+DELETE FROM voters WHERE id = $1;
+
+-- name: GetUserCredentials :one
+SELECT id, password_hash, password_salt
+FROM users
+WHERE email = $1;
+
+-- name: ListVoterIDs :many
+SELECT id
+FROM voters
+WHERE (sqlc.narg('fingerprint')::text IS NOT NULL AND fingerprint = sqlc.narg('fingerprint'))
+   OR (sqlc.narg('user_id')::uuid IS NOT NULL AND user_id = sqlc.narg('user_id'))
+ORDER BY created_at, id;
+
+-- name: ListVotesByVoters :many
+SELECT v.movie_id, m.title, v.region, v.category::text AS category, v.created_at
+FROM votes v
+JOIN movies m ON m.id = v.movie_id
+WHERE v.voter_id = ANY($1::uuid[])
+ORDER BY v.created_at, v.id;
+
+-- name: AnonymizeVoters :execrows
+UPDATE voters
+SET fingerprint = NULL, fingerprint_hashed = true, user_id = NULL, anonymized_at = now()
+WHERE id = ANY($1::uuid[]);
+
+-- name: CountInactiveVoters :one
+SELECT COUNT(*)
+FROM voters vr
+WHERE vr.anonymized_at IS NULL
+  AND vr.created_at < $1
+  AND NOT EXISTS (SELECT 1 FROM votes v WHERE v.voter_id = vr.id AND v.created_at >= $1);
+
+-- name: AnonymizeInactiveVoters :execrows
+UPDATE voters vr
+SET fingerprint = NULL, fingerprint_hashed = true, user_id = NULL, anonymized_at = now()
+WHERE vr.anonymized_at IS NULL
+  AND vr.created_at < $1
+  AND NOT EXISTS (SELECT 1 FROM votes v WHERE v.voter_id = vr.id AND v.created_at >= $1);
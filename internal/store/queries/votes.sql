@@ -1,5 +1,5 @@
 -- name: InsertVote :one
-INSERT INTO votes (movie_id, voter_id, category)
-VALUES ($1, $2, $3)
-ON CONFLICT (movie_id, voter_id) DO NOTHING
+INSERT INTO votes (movie_id, region, voter_id, category)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (movie_id, region, voter_id) DO NOTHING
 RETURNING id;
@@ -0,0 +1,72 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (url, events, secret)
+VALUES ($1, $2, $3)
+RETURNING id, url, events, secret, enabled, created_at, updated_at;
+
+-- name: ListWebhookSubscriptions :many
+SELECT id, url, events, secret, enabled, created_at, updated_at
+FROM webhook_subscriptions
+ORDER BY created_at, id;
+
+-- name: GetWebhookSubscription :one
+SELECT id, url, events, secret, enabled, created_at, updated_at
+FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions WHERE id = $1;
+
+-- name: EnqueueWebhookEvent :execrows
+INSERT INTO webhook_deliveries (subscription_id, event, payload)
+SELECT s.id, sqlc.arg('event')::text, sqlc.arg('payload')::jsonb
+FROM webhook_subscriptions s
+WHERE s.enabled AND sqlc.arg('event')::text = ANY(s.events);
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = sqlc.arg('lease_until')
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at, id
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+  )
+RETURNING d.id, d.event, d.payload, d.attempts, d.created_at, s.url, s.secret;
+
+-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered', attempts = attempts + 1, last_attempt_at = now(), delivered_at = now(),
+    response_status = sqlc.narg('response_status'), last_error = NULL
+WHERE id = sqlc.arg('id');
+
+-- name: MarkWebhookFailed :exec
+UPDATE webhook_deliveries
+SET status = sqlc.arg('status'), attempts = attempts + 1, last_attempt_at = now(),
+    next_attempt_at = sqlc.arg('next_attempt_at'),
+    response_status = sqlc.narg('response_status'), last_error = sqlc.arg('last_error')
+WHERE id = sqlc.arg('id');
+
+-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event, payload, status, attempts, next_attempt_at, last_attempt_at, response_status, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg('subscription_id')
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('before_id')::bigint IS NULL OR id < sqlc.narg('before_id'))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: RetryWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = now()
+WHERE id = $1 AND status = 'dead';
+
+-- name: ReachVoteMilestones :many
+INSERT INTO vote_milestones (movie_id, region, milestone)
+SELECT sqlc.arg('movie_id'), sqlc.arg('region'), m
+FROM unnest(sqlc.arg('milestones')::bigint[]) AS m
+WHERE m <= (SELECT COALESCE(SUM(count), 0) FROM vote_tallies WHERE movie_id = sqlc.arg('movie_id') AND region = sqlc.arg('region'))
+ON CONFLICT DO NOTHING
+RETURNING milestone;
@@ -12,52 +12,38 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const CountSnapshotsByMonth = `-- name: CountSnapshotsByMonth :one
-SELECT COUNT(*) FROM snapshots WHERE month = $1
+const CountMonthSnapshots = `-- name: CountMonthSnapshots :one
+SELECT count(*) FROM snapshots WHERE region = $1 AND month = $2
 `
 
-func (q *Queries) CountSnapshotsByMonth(ctx context.Context, month string) (int64, error) {
-	row := q.db.QueryRow(ctx, CountSnapshotsByMonth, month)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
-}
-
-const CountSnapshotsByMonthFiltered = `-- name: CountSnapshotsByMonthFiltered :one
-SELECT COUNT(*)
-FROM snapshots st
-JOIN movies m ON m.id = st.movie_id
-WHERE st.month = $1
-  AND ($2::float8 IS NULL OR m.popularity >= $2)
-  AND ($3::float8 IS NULL OR m.popularity <= $3)
-`
-
-type CountSnapshotsByMonthFilteredParams struct {
-	Month   string  `json:"month"`
-	Column2 float64 `json:"column_2"`
-	Column3 float64 `json:"column_3"`
+type CountMonthSnapshotsParams struct {
+	Region string `json:"region"`
+	Month  string `json:"month"`
 }
 
-func (q *Queries) CountSnapshotsByMonthFiltered(ctx context.Context, arg CountSnapshotsByMonthFilteredParams) (int64, error) {
-	row := q.db.QueryRow(ctx, CountSnapshotsByMonthFiltered, arg.Month, arg.Column2, arg.Column3)
+// Every snapshot of region's month, hidden movies' included: 0 until the month
+// is first archived.
+func (q *Queries) CountMonthSnapshots(ctx context.Context, arg CountMonthSnapshotsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountMonthSnapshots, arg.Region, arg.Month)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
 const GetSnapshot = `-- name: GetSnapshot :one
-SELECT id, month, movie_id, tallies, closed_at
+SELECT id, month, movie_id, tallies, closed_at, region
 FROM snapshots
-WHERE month = $1 AND movie_id = $2
+WHERE region = $1 AND month = $2 AND movie_id = $3
 `
 
 type GetSnapshotParams struct {
+	Region  string `json:"region"`
 	Month   string `json:"month"`
 	MovieID int64  `json:"movie_id"`
 }
 
 func (q *Queries) GetSnapshot(ctx context.Context, arg GetSnapshotParams) (Snapshot, error) {
-	row := q.db.QueryRow(ctx, GetSnapshot, arg.Month, arg.MovieID)
+	row := q.db.QueryRow(ctx, GetSnapshot, arg.Region, arg.Month, arg.MovieID)
 	var i Snapshot
 	err := row.Scan(
 		&i.ID,
@@ -65,19 +51,66 @@ func (q *Queries) GetSnapshot(ctx context.Context, arg GetSnapshotParams) (Snaps
 		&i.MovieID,
 		&i.Tallies,
 		&i.ClosedAt,
+		&i.Region,
+	)
+	return i, err
+}
+
+const GetSnapshotMonth = `-- name: GetSnapshotMonth :one
+SELECT max(s.closed_at)::timestamptz AS closed_at, count(*) AS movies,
+       COALESCE(sum((s.tallies ->> 'solo_friends')::bigint), 0)::bigint AS solo_friends,
+       COALESCE(sum((s.tallies ->> 'couple')::bigint), 0)::bigint AS couple,
+       COALESCE(sum((s.tallies ->> 'streaming')::bigint), 0)::bigint AS streaming,
+       COALESCE(sum((s.tallies ->> 'arr')::bigint), 0)::bigint AS arr
+FROM snapshots s
+JOIN movies m ON m.id = s.movie_id
+WHERE s.region = $1 AND s.month = $2 AND NOT m.hidden
+`
+
+type GetSnapshotMonthParams struct {
+	Region string `json:"region"`
+	Month  string `json:"month"`
+}
+
+type GetSnapshotMonthRow struct {
+	ClosedAt    pgtype.Timestamptz `json:"closed_at"`
+	Movies      int64              `json:"movies"`
+	SoloFriends int64              `json:"solo_friends"`
+	Couple      int64              `json:"couple"`
+	Streaming   int64              `json:"streaming"`
+	Arr         int64              `json:"arr"`
+}
+
+// A month of region's archive: when it closed, its visible movies and the votes
+// they received per category. movies is 0 when the month was not archived.
+func (q *Queries) GetSnapshotMonth(ctx context.Context, arg GetSnapshotMonthParams) (GetSnapshotMonthRow, error) {
+	row := q.db.QueryRow(ctx, GetSnapshotMonth, arg.Region, arg.Month)
+	var i GetSnapshotMonthRow
+	err := row.Scan(
+		&i.ClosedAt,
+		&i.Movies,
+		&i.SoloFriends,
+		&i.Couple,
+		&i.Streaming,
+		&i.Arr,
 	)
 	return i, err
 }
 
 const GetSnapshotsByMonth = `-- name: GetSnapshotsByMonth :many
-SELECT id, month, movie_id, tallies, closed_at
+SELECT id, month, movie_id, tallies, closed_at, region
 FROM snapshots
-WHERE month = $1
+WHERE region = $1 AND month = $2
 ORDER BY movie_id ASC
 `
 
-func (q *Queries) GetSnapshotsByMonth(ctx context.Context, month string) ([]Snapshot, error) {
-	rows, err := q.db.Query(ctx, GetSnapshotsByMonth, month)
+type GetSnapshotsByMonthParams struct {
+	Region string `json:"region"`
+	Month  string `json:"month"`
+}
+
+func (q *Queries) GetSnapshotsByMonth(ctx context.Context, arg GetSnapshotsByMonthParams) ([]Snapshot, error) {
+	rows, err := q.db.Query(ctx, GetSnapshotsByMonth, arg.Region, arg.Month)
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +124,7 @@ func (q *Queries) GetSnapshotsByMonth(ctx context.Context, month string) ([]Snap
 			&i.MovieID,
 			&i.Tallies,
 			&i.ClosedAt,
+			&i.Region,
 		); err != nil {
 			return nil, err
 		}
@@ -102,10 +136,45 @@ func (q *Queries) GetSnapshotsByMonth(ctx context.Context, month string) ([]Snap
 	return items, nil
 }
 
+const ImportSnapshot = `-- name: ImportSnapshot :one
+INSERT INTO snapshots (region, month, movie_id, tallies, closed_at)
+VALUES ($1, $2, $3, $4, COALESCE($5::timestamptz, now()))
+ON CONFLICT (region, month, movie_id) DO UPDATE SET
+  tallies = EXCLUDED.tallies,
+  closed_at = COALESCE($5::timestamptz, snapshots.closed_at)
+WHERE snapshots.tallies IS DISTINCT FROM EXCLUDED.tallies
+   OR snapshots.closed_at IS DISTINCT FROM COALESCE($5::timestamptz, snapshots.closed_at)
+RETURNING (xmax = 0) AS inserted
+`
+
+type ImportSnapshotParams struct {
+	Region   string             `json:"region"`
+	Month    string             `json:"month"`
+	MovieID  int64              `json:"movie_id"`
+	Tallies  json.RawMessage    `json:"tallies"`
+	ClosedAt pgtype.Timestamptz `json:"closed_at"`
+}
+
+// ImportSnapshot upserts an archived snapshot, keeping the archive's closed_at
+// when it has one. An identical snapshot is left alone and returns no row.
+func (q *Queries) ImportSnapshot(ctx context.Context, arg ImportSnapshotParams) (bool, error) {
+	row := q.db.QueryRow(ctx, ImportSnapshot,
+		arg.Region,
+		arg.Month,
+		arg.MovieID,
+		arg.Tallies,
+		arg.ClosedAt,
+	)
+	var inserted bool
+	err := row.Scan(&inserted)
+	return inserted, err
+}
+
 const ListAvailableSnapshotYearMonths = `-- name: ListAvailableSnapshotYearMonths :many
 SELECT (split_part(month, '-', 1))::int AS year,
        (split_part(month, '-', 2))::int AS month
 FROM snapshots
+WHERE region = $1
 GROUP BY 1, 2
 ORDER BY year DESC, month DESC
 `
@@ -115,8 +184,8 @@ type ListAvailableSnapshotYearMonthsRow struct {
 	Month int32 `json:"month"`
 }
 
-func (q *Queries) ListAvailableSnapshotYearMonths(ctx context.Context) ([]ListAvailableSnapshotYearMonthsRow, error) {
-	rows, err := q.db.Query(ctx, ListAvailableSnapshotYearMonths)
+func (q *Queries) ListAvailableSnapshotYearMonths(ctx context.Context, region string) ([]ListAvailableSnapshotYearMonthsRow, error) {
+	rows, err := q.db.Query(ctx, ListAvailableSnapshotYearMonths, region)
 	if err != nil {
 		return nil, err
 	}
@@ -135,119 +204,36 @@ func (q *Queries) ListAvailableSnapshotYearMonths(ctx context.Context) ([]ListAv
 	return items, nil
 }
 
-const ListSnapshotsByMonthFilteredPage = `-- name: ListSnapshotsByMonthFilteredPage :many
-WITH s AS (
-  SELECT st.month, st.movie_id, st.tallies, st.closed_at, m.popularity, m.title, m.release_date, m.overview, m.poster_path, m.backdrop_path, m.imdb_url, m.cinemagia_url
-  FROM snapshots st
-  JOIN movies m ON m.id = st.movie_id
-  WHERE st.month = $1
-    AND ($2::float8 IS NULL OR m.popularity >= $2)
-    AND ($3::float8 IS NULL OR m.popularity <= $3)
-), exploded AS (
-  SELECT month, movie_id, closed_at, popularity, title, release_date, overview, poster_path, backdrop_path, imdb_url, cinemagia_url,
-    COALESCE((tallies ->> 'solo_friends')::bigint, 0) AS solo_friends,
-    COALESCE((tallies ->> 'couple')::bigint, 0) AS couple,
-    COALESCE((tallies ->> 'streaming')::bigint, 0) AS streaming,
-    COALESCE((tallies ->> 'arr')::bigint, 0) AS arr
-  FROM s
-), keyed AS (
-  SELECT month, movie_id, closed_at, popularity, title, release_date, overview, poster_path, backdrop_path, imdb_url, cinemagia_url, solo_friends, couple, streaming, arr, CASE
-    WHEN $4::text = 'popularity' THEN popularity
-    WHEN $4::text = 'release_date' THEN extract(epoch from release_date)
-    WHEN $4::text = 'solo_friends' THEN solo_friends::double precision
-    WHEN $4::text = 'couple' THEN couple::double precision
-    WHEN $4::text = 'streaming' THEN streaming::double precision
-    WHEN $4::text = 'arr' THEN arr::double precision
-    ELSE popularity
-  END AS key_value
-  FROM exploded
-), paged AS (
-  SELECT month, movie_id, closed_at, popularity, title, release_date, overview, poster_path, backdrop_path, imdb_url, cinemagia_url, solo_friends, couple, streaming, arr, key_value FROM keyed
-  WHERE (
-    $6::float8 IS NULL OR (
-      CASE WHEN $5::text = 'desc' THEN (key_value < $6 OR (key_value = $6 AND movie_id < $7))
-           ELSE (key_value > $6 OR (key_value = $6 AND movie_id > $7))
-      END
-    )
-  )
-)
-SELECT movie_id, month, closed_at, popularity, title, release_date, overview, poster_path, backdrop_path, imdb_url, cinemagia_url, solo_friends, couple, streaming, arr, key_value
-FROM paged
-ORDER BY
-  CASE WHEN $5::text = 'desc' THEN key_value END DESC NULLS LAST,
-  CASE WHEN $5::text = 'asc'  THEN key_value END ASC  NULLS LAST,
-  CASE WHEN $4::text <> 'popularity' THEN popularity END DESC NULLS LAST,
-  CASE WHEN $5::text = 'desc' THEN movie_id END DESC NULLS LAST,
-  CASE WHEN $5::text = 'asc'  THEN movie_id END ASC  NULLS LAST
-LIMIT $8
+const ListSnapshotMonths = `-- name: ListSnapshotMonths :many
+SELECT month, max(closed_at)::timestamptz AS closed_at, count(*) AS movies
+FROM snapshots
+WHERE region = $1
+GROUP BY month
+ORDER BY month DESC
+LIMIT $2
 `
 
-type ListSnapshotsByMonthFilteredPageParams struct {
-	Month   string  `json:"month"`
-	Column2 float64 `json:"column_2"`
-	Column3 float64 `json:"column_3"`
-	Column4 string  `json:"column_4"`
-	Column5 string  `json:"column_5"`
-	Column6 float64 `json:"column_6"`
-	MovieID int64   `json:"movie_id"`
-	Limit   int32   `json:"limit"`
+type ListSnapshotMonthsParams struct {
+	Region string `json:"region"`
+	Limit  int32  `json:"limit"`
 }
 
-type ListSnapshotsByMonthFilteredPageRow struct {
-	MovieID      int64              `json:"movie_id"`
-	Month        string             `json:"month"`
-	ClosedAt     pgtype.Timestamptz `json:"closed_at"`
-	Popularity   pgtype.Float8      `json:"popularity"`
-	Title        string             `json:"title"`
-	ReleaseDate  pgtype.Date        `json:"release_date"`
-	Overview     pgtype.Text        `json:"overview"`
-	PosterPath   pgtype.Text        `json:"poster_path"`
-	BackdropPath pgtype.Text        `json:"backdrop_path"`
-	ImdbUrl      pgtype.Text        `json:"imdb_url"`
-	CinemagiaUrl pgtype.Text        `json:"cinemagia_url"`
-	SoloFriends  interface{}        `json:"solo_friends"`
-	Couple       interface{}        `json:"couple"`
-	Streaming    interface{}        `json:"streaming"`
-	Arr          interface{}        `json:"arr"`
-	KeyValue     interface{}        `json:"key_value"`
+type ListSnapshotMonthsRow struct {
+	Month    string             `json:"month"`
+	ClosedAt pgtype.Timestamptz `json:"closed_at"`
+	Movies   int64              `json:"movies"`
 }
 
-func (q *Queries) ListSnapshotsByMonthFilteredPage(ctx context.Context, arg ListSnapshotsByMonthFilteredPageParams) ([]ListSnapshotsByMonthFilteredPageRow, error) {
-	rows, err := q.db.Query(ctx, ListSnapshotsByMonthFilteredPage,
-		arg.Month,
-		arg.Column2,
-		arg.Column3,
-		arg.Column4,
-		arg.Column5,
-		arg.Column6,
-		arg.MovieID,
-		arg.Limit,
-	)
+func (q *Queries) ListSnapshotMonths(ctx context.Context, arg ListSnapshotMonthsParams) ([]ListSnapshotMonthsRow, error) {
+	rows, err := q.db.Query(ctx, ListSnapshotMonths, arg.Region, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []ListSnapshotsByMonthFilteredPageRow{}
+	items := []ListSnapshotMonthsRow{}
 	for rows.Next() {
-		var i ListSnapshotsByMonthFilteredPageRow
-		if err := rows.Scan(
-			&i.MovieID,
-			&i.Month,
-			&i.ClosedAt,
-			&i.Popularity,
-			&i.Title,
-			&i.ReleaseDate,
-			&i.Overview,
-			&i.PosterPath,
-			&i.BackdropPath,
-			&i.ImdbUrl,
-			&i.CinemagiaUrl,
-			&i.SoloFriends,
-			&i.Couple,
-			&i.Streaming,
-			&i.Arr,
-			&i.KeyValue,
-		); err != nil {
+		var i ListSnapshotMonthsRow
+		if err := rows.Scan(&i.Month, &i.ClosedAt, &i.Movies); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -258,36 +244,48 @@ func (q *Queries) ListSnapshotsByMonthFilteredPage(ctx context.Context, arg List
 	return items, nil
 }
 
-const ListSnapshotsByMonthPage = `-- name: ListSnapshotsByMonthPage :many
-SELECT id, month, movie_id, tallies, closed_at
-FROM snapshots
-WHERE month = $1
-  AND ($2::bigint = 0 OR movie_id > $2)
-ORDER BY movie_id ASC
-LIMIT $3
+const ListSnapshotWinners = `-- name: ListSnapshotWinners :many
+SELECT DISTINCT ON (s.month, t.key) s.month, t.key::text AS category, s.movie_id, m.title, t.value::bigint AS votes
+FROM snapshots s
+JOIN movies m ON m.id = s.movie_id
+CROSS JOIN LATERAL jsonb_each_text(s.tallies) AS t(key, value)
+WHERE s.region = $1
+  AND s.month = ANY($2::text[])
+  AND NOT m.hidden
+  AND t.value::bigint > 0
+ORDER BY s.month DESC, t.key, t.value::bigint DESC, COALESCE(m.popularity, 0) DESC, s.movie_id
 `
 
-type ListSnapshotsByMonthPageParams struct {
-	Month   string `json:"month"`
-	Column2 int64  `json:"column_2"`
-	Limit   int32  `json:"limit"`
+type ListSnapshotWinnersParams struct {
+	Region  string   `json:"region"`
+	Column2 []string `json:"column_2"`
+}
+
+type ListSnapshotWinnersRow struct {
+	Month    string `json:"month"`
+	Category string `json:"category"`
+	MovieID  int64  `json:"movie_id"`
+	Title    string `json:"title"`
+	Votes    int64  `json:"votes"`
 }
 
-func (q *Queries) ListSnapshotsByMonthPage(ctx context.Context, arg ListSnapshotsByMonthPageParams) ([]Snapshot, error) {
-	rows, err := q.db.Query(ctx, ListSnapshotsByMonthPage, arg.Month, arg.Column2, arg.Limit)
+// The movie with the most votes per month and category; ties go to the more
+// popular movie.
+func (q *Queries) ListSnapshotWinners(ctx context.Context, arg ListSnapshotWinnersParams) ([]ListSnapshotWinnersRow, error) {
+	rows, err := q.db.Query(ctx, ListSnapshotWinners, arg.Region, arg.Column2)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Snapshot{}
+	items := []ListSnapshotWinnersRow{}
 	for rows.Next() {
-		var i Snapshot
+		var i ListSnapshotWinnersRow
 		if err := rows.Scan(
-			&i.ID,
 			&i.Month,
+			&i.Category,
 			&i.MovieID,
-			&i.Tallies,
-			&i.ClosedAt,
+			&i.Title,
+			&i.Votes,
 		); err != nil {
 			return nil, err
 		}
@@ -299,21 +297,42 @@ func (q *Queries) ListSnapshotsByMonthPage(ctx context.Context, arg ListSnapshot
 	return items, nil
 }
 
+const LockSnapshotMonth = `-- name: LockSnapshotMonth :exec
+SELECT pg_advisory_xact_lock(hashtextextended('snapshot_month:' || $1::text || ':' || $2::text, 0))
+`
+
+type LockSnapshotMonthParams struct {
+	Region string `json:"region"`
+	Month  string `json:"month"`
+}
+
+// Holds off every other close of region's month until the transaction ends.
+func (q *Queries) LockSnapshotMonth(ctx context.Context, arg LockSnapshotMonthParams) error {
+	_, err := q.db.Exec(ctx, LockSnapshotMonth, arg.Region, arg.Month)
+	return err
+}
+
 const UpsertSnapshot = `-- name: UpsertSnapshot :exec
-INSERT INTO snapshots (month, movie_id, tallies)
-VALUES ($1, $2, $3)
-ON CONFLICT (month, movie_id) DO UPDATE SET
+INSERT INTO snapshots (region, month, movie_id, tallies)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (region, month, movie_id) DO UPDATE SET
   tallies = EXCLUDED.tallies,
   closed_at = now()
 `
 
 type UpsertSnapshotParams struct {
+	Region  string          `json:"region"`
 	Month   string          `json:"month"`
 	MovieID int64           `json:"movie_id"`
 	Tallies json.RawMessage `json:"tallies"`
 }
 
 func (q *Queries) UpsertSnapshot(ctx context.Context, arg UpsertSnapshotParams) error {
-	_, err := q.db.Exec(ctx, UpsertSnapshot, arg.Month, arg.MovieID, arg.Tallies)
+	_, err := q.db.Exec(ctx, UpsertSnapshot,
+		arg.Region,
+		arg.Month,
+		arg.MovieID,
+		arg.Tallies,
+	)
 	return err
 }
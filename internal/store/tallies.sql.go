@@ -11,25 +11,19 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const CountTalliesByMovie = `-- name: CountTalliesByMovie :one
-SELECT COUNT(*) FROM vote_tallies WHERE movie_id = $1
-`
-
-func (q *Queries) CountTalliesByMovie(ctx context.Context, movieID int64) (int64, error) {
-	row := q.db.QueryRow(ctx, CountTalliesByMovie, movieID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
-}
-
 const GetTalliesByMovie = `-- name: GetTalliesByMovie :many
-SELECT movie_id, category, count
+SELECT movie_id, category, count, region
 FROM vote_tallies
-WHERE movie_id = $1
+WHERE movie_id = $1 AND region = $2
 `
 
-func (q *Queries) GetTalliesByMovie(ctx context.Context, movieID int64) ([]VoteTally, error) {
-	rows, err := q.db.Query(ctx, GetTalliesByMovie, movieID)
+type GetTalliesByMovieParams struct {
+	MovieID int64  `json:"movie_id"`
+	Region  string `json:"region"`
+}
+
+func (q *Queries) GetTalliesByMovie(ctx context.Context, arg GetTalliesByMovieParams) ([]VoteTally, error) {
+	rows, err := q.db.Query(ctx, GetTalliesByMovie, arg.MovieID, arg.Region)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +31,12 @@ func (q *Queries) GetTalliesByMovie(ctx context.Context, movieID int64) ([]VoteT
 	items := []VoteTally{}
 	for rows.Next() {
 		var i VoteTally
-		if err := rows.Scan(&i.MovieID, &i.Category, &i.Count); err != nil {
+		if err := rows.Scan(
+			&i.MovieID,
+			&i.Category,
+			&i.Count,
+			&i.Region,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -59,18 +58,23 @@ SELECT m.movie_id,
        COALESCE(t.count, 0) AS count
 FROM mids m
 CROSS JOIN cats c
-LEFT JOIN vote_tallies t ON t.movie_id = m.movie_id AND t.category = c.category
+LEFT JOIN vote_tallies t ON t.movie_id = m.movie_id AND t.region = $2 AND t.category = c.category
 ORDER BY m.movie_id, c.category
 `
 
+type GetTalliesForMoviesParams struct {
+	Column1 []int64 `json:"column_1"`
+	Region  string  `json:"region"`
+}
+
 type GetTalliesForMoviesRow struct {
 	MovieID  interface{} `json:"movie_id"`
 	Category string      `json:"category"`
 	Count    int64       `json:"count"`
 }
 
-func (q *Queries) GetTalliesForMovies(ctx context.Context, dollar_1 []int64) ([]GetTalliesForMoviesRow, error) {
-	rows, err := q.db.Query(ctx, GetTalliesForMovies, dollar_1)
+func (q *Queries) GetTalliesForMovies(ctx context.Context, arg GetTalliesForMoviesParams) ([]GetTalliesForMoviesRow, error) {
+	rows, err := q.db.Query(ctx, GetTalliesForMovies, arg.Column1, arg.Region)
 	if err != nil {
 		return nil, err
 	}
@@ -93,70 +97,80 @@ const GetVoterCategoryByMovieAndFingerprint = `-- name: GetVoterCategoryByMovieA
 SELECT v.category::text AS category
 FROM votes v
 JOIN voters vr ON vr.id = v.voter_id
-WHERE v.movie_id = $1 AND vr.fingerprint = $2
+WHERE v.movie_id = $1 AND v.region = $2 AND vr.fingerprint = $3
 `
 
 type GetVoterCategoryByMovieAndFingerprintParams struct {
-	MovieID     int64  `json:"movie_id"`
-	Fingerprint string `json:"fingerprint"`
+	MovieID     int64       `json:"movie_id"`
+	Region      string      `json:"region"`
+	Fingerprint pgtype.Text `json:"fingerprint"`
 }
 
 func (q *Queries) GetVoterCategoryByMovieAndFingerprint(ctx context.Context, arg GetVoterCategoryByMovieAndFingerprintParams) (string, error) {
-	row := q.db.QueryRow(ctx, GetVoterCategoryByMovieAndFingerprint, arg.MovieID, arg.Fingerprint)
+	row := q.db.QueryRow(ctx, GetVoterCategoryByMovieAndFingerprint, arg.MovieID, arg.Region, arg.Fingerprint)
 	var category string
 	err := row.Scan(&category)
 	return category, err
 }
 
 const IncrementTally = `-- name: IncrementTally :exec
-INSERT INTO vote_tallies (movie_id, category, count)
-VALUES ($1, $2, 1)
-ON CONFLICT (movie_id, category) DO UPDATE SET count = vote_tallies.count + 1
+INSERT INTO vote_tallies (movie_id, region, category, count)
+VALUES ($1, $2, $3, 1)
+ON CONFLICT (movie_id, region, category) DO UPDATE SET count = vote_tallies.count + 1
 `
 
 type IncrementTallyParams struct {
 	MovieID  int64  `json:"movie_id"`
+	Region   string `json:"region"`
 	Category string `json:"category"`
 }
 
 func (q *Queries) IncrementTally(ctx context.Context, arg IncrementTallyParams) error {
-	_, err := q.db.Exec(ctx, IncrementTally, arg.MovieID, arg.Category)
+	_, err := q.db.Exec(ctx, IncrementTally, arg.MovieID, arg.Region, arg.Category)
 	return err
 }
 
-const ListTalliesByMoviePage = `-- name: ListTalliesByMoviePage :many
-SELECT movie_id, category, count
-FROM vote_tallies
-WHERE movie_id = $1
-  AND (
-    $3::text = '' OR (count < $2) OR (count = $2 AND category::text > $3)
-  )
-ORDER BY count DESC, category ASC
-LIMIT $4
+const ListTallyDrift = `-- name: ListTallyDrift :many
+WITH actual AS (
+  SELECT movie_id, region, category, COUNT(*)::bigint AS count
+  FROM votes
+  GROUP BY movie_id, region, category
+)
+SELECT COALESCE(a.movie_id, t.movie_id)::bigint AS movie_id,
+       COALESCE(a.region, t.region)::text AS region,
+       COALESCE(a.category, t.category)::text AS category,
+       COALESCE(t.count, 0)::bigint AS stored,
+       COALESCE(a.count, 0)::bigint AS actual
+FROM actual a
+FULL OUTER JOIN vote_tallies t ON t.movie_id = a.movie_id AND t.region = a.region AND t.category = a.category
+WHERE COALESCE(t.count, 0) <> COALESCE(a.count, 0)
+ORDER BY 1, 2, 3
 `
 
-type ListTalliesByMoviePageParams struct {
-	MovieID int64       `json:"movie_id"`
-	Count   pgtype.Int8 `json:"count"`
-	Column3 string      `json:"column_3"`
-	Limit   int32       `json:"limit"`
+type ListTallyDriftRow struct {
+	MovieID  int64  `json:"movie_id"`
+	Region   string `json:"region"`
+	Category string `json:"category"`
+	Stored   int64  `json:"stored"`
+	Actual   int64  `json:"actual"`
 }
 
-func (q *Queries) ListTalliesByMoviePage(ctx context.Context, arg ListTalliesByMoviePageParams) ([]VoteTally, error) {
-	rows, err := q.db.Query(ctx, ListTalliesByMoviePage,
-		arg.MovieID,
-		arg.Count,
-		arg.Column3,
-		arg.Limit,
-	)
+func (q *Queries) ListTallyDrift(ctx context.Context) ([]ListTallyDriftRow, error) {
+	rows, err := q.db.Query(ctx, ListTallyDrift)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []VoteTally{}
+	items := []ListTallyDriftRow{}
 	for rows.Next() {
-		var i VoteTally
-		if err := rows.Scan(&i.MovieID, &i.Category, &i.Count); err != nil {
+		var i ListTallyDriftRow
+		if err := rows.Scan(
+			&i.MovieID,
+			&i.Region,
+			&i.Category,
+			&i.Stored,
+			&i.Actual,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
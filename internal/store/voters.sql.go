@@ -11,11 +11,164 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const AdoptVoterUser = `-- name: AdoptVoterUser :exec
+UPDATE voters SET user_id = $1
+WHERE id = $2 AND user_id IS NULL
+`
+
+type AdoptVoterUserParams struct {
+	UserID pgtype.UUID `json:"user_id"`
+	ID     pgtype.UUID `json:"id"`
+}
+
+// Links voter id to an account unless it is linked to one already.
+func (q *Queries) AdoptVoterUser(ctx context.Context, arg AdoptVoterUserParams) error {
+	_, err := q.db.Exec(ctx, AdoptVoterUser, arg.UserID, arg.ID)
+	return err
+}
+
+const AnonymizeInactiveVoters = `-- name: AnonymizeInactiveVoters :execrows
+UPDATE voters vr
+SET fingerprint = NULL, fingerprint_hashed = true, user_id = NULL, anonymized_at = now()
+WHERE vr.anonymized_at IS NULL
+  AND vr.created_at < $1
+  AND NOT EXISTS (SELECT 1 FROM votes v WHERE v.voter_id = vr.id AND v.created_at >= $1)
+`
+
+func (q *Queries) AnonymizeInactiveVoters(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, AnonymizeInactiveVoters, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const AnonymizeVoters = `-- name: AnonymizeVoters :execrows
+UPDATE voters
+SET fingerprint = NULL, fingerprint_hashed = true, user_id = NULL, anonymized_at = now()
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) AnonymizeVoters(ctx context.Context, dollar_1 []pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, AnonymizeVoters, dollar_1)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const CountInactiveVoters = `-- name: CountInactiveVoters :one
+SELECT COUNT(*)
+FROM voters vr
+WHERE vr.anonymized_at IS NULL
+  AND vr.created_at < $1
+  AND NOT EXISTS (SELECT 1 FROM votes v WHERE v.voter_id = vr.id AND v.created_at >= $1)
+`
+
+func (q *Queries) CountInactiveVoters(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	row := q.db.QueryRow(ctx, CountInactiveVoters, createdAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountOrphanVoters = `-- name: CountOrphanVoters :one
+SELECT COUNT(*)
+FROM voters vr
+WHERE vr.created_at < $1
+  AND vr.user_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM votes v WHERE v.voter_id = vr.id)
+`
+
+func (q *Queries) CountOrphanVoters(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	row := q.db.QueryRow(ctx, CountOrphanVoters, createdAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const DeleteDuplicateVoterVotes = `-- name: DeleteDuplicateVoterVotes :many
+DELETE FROM votes v
+WHERE v.voter_id = $1
+  AND EXISTS (
+    SELECT 1 FROM votes t WHERE t.voter_id = $2 AND t.movie_id = v.movie_id AND t.region = v.region
+  )
+RETURNING v.movie_id
+`
+
+type DeleteDuplicateVoterVotesParams struct {
+	SourceID pgtype.UUID `json:"source_id"`
+	TargetID pgtype.UUID `json:"target_id"`
+}
+
+func (q *Queries) DeleteDuplicateVoterVotes(ctx context.Context, arg DeleteDuplicateVoterVotesParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, DeleteDuplicateVoterVotes, arg.SourceID, arg.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var movie_id int64
+		if err := rows.Scan(&movie_id); err != nil {
+			return nil, err
+		}
+		items = append(items, movie_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const DeleteOrphanVoters = `-- name: DeleteOrphanVoters :execrows
+DELETE FROM voters vr
+WHERE vr.created_at < $1
+  AND vr.user_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM votes v WHERE v.voter_id = vr.id)
+`
+
+func (q *Queries) DeleteOrphanVoters(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, DeleteOrphanVoters, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const DeleteVoter = `-- name: DeleteVoter :exec
+DELETE FROM voters WHERE id = $1
+`
+
+func (q *Queries) DeleteVoter(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, DeleteVoter, id)
+	return err
+}
+
+const GetUserCredentials = `-- name: GetUserCredentials :one
+SELECT id, password_hash, password_salt
+FROM users
+WHERE email = $1
+`
+
+type GetUserCredentialsRow struct {
+	ID           pgtype.UUID `json:"id"`
+	PasswordHash pgtype.Text `json:"password_hash"`
+	PasswordSalt pgtype.Text `json:"password_salt"`
+}
+
+func (q *Queries) GetUserCredentials(ctx context.Context, email pgtype.Text) (GetUserCredentialsRow, error) {
+	row := q.db.QueryRow(ctx, GetUserCredentials, email)
+	var i GetUserCredentialsRow
+	err := row.Scan(&i.ID, &i.PasswordHash, &i.PasswordSalt)
+	return i, err
+}
+
 const GetVoterByFingerprint = `-- name: GetVoterByFingerprint :one
 SELECT id FROM voters WHERE fingerprint = $1
 `
 
-func (q *Queries) GetVoterByFingerprint(ctx context.Context, fingerprint string) (pgtype.UUID, error) {
+func (q *Queries) GetVoterByFingerprint(ctx context.Context, fingerprint pgtype.Text) (pgtype.UUID, error) {
 	row := q.db.QueryRow(ctx, GetVoterByFingerprint, fingerprint)
 	var id pgtype.UUID
 	err := row.Scan(&id)
@@ -23,14 +176,161 @@ func (q *Queries) GetVoterByFingerprint(ctx context.Context, fingerprint string)
 }
 
 const InsertVoter = `-- name: InsertVoter :one
-INSERT INTO voters (fingerprint)
-VALUES ($1)
+INSERT INTO voters (fingerprint, fingerprint_hashed)
+VALUES ($1, true)
 RETURNING id
 `
 
-func (q *Queries) InsertVoter(ctx context.Context, fingerprint string) (pgtype.UUID, error) {
+func (q *Queries) InsertVoter(ctx context.Context, fingerprint pgtype.Text) (pgtype.UUID, error) {
 	row := q.db.QueryRow(ctx, InsertVoter, fingerprint)
 	var id pgtype.UUID
 	err := row.Scan(&id)
 	return id, err
 }
+
+const ListUnhashedVoters = `-- name: ListUnhashedVoters :many
+SELECT id, fingerprint::text AS fingerprint, user_id
+FROM voters
+WHERE NOT fingerprint_hashed AND fingerprint IS NOT NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+type ListUnhashedVotersRow struct {
+	ID          pgtype.UUID `json:"id"`
+	Fingerprint string      `json:"fingerprint"`
+	UserID      pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) ListUnhashedVoters(ctx context.Context, limit int32) ([]ListUnhashedVotersRow, error) {
+	rows, err := q.db.Query(ctx, ListUnhashedVoters, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUnhashedVotersRow{}
+	for rows.Next() {
+		var i ListUnhashedVotersRow
+		if err := rows.Scan(&i.ID, &i.Fingerprint, &i.UserID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListVoterIDs = `-- name: ListVoterIDs :many
+SELECT id
+FROM voters
+WHERE ($1::text IS NOT NULL AND fingerprint = $1)
+   OR ($2::uuid IS NOT NULL AND user_id = $2)
+ORDER BY created_at, id
+`
+
+type ListVoterIDsParams struct {
+	Fingerprint pgtype.Text `json:"fingerprint"`
+	UserID      pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) ListVoterIDs(ctx context.Context, arg ListVoterIDsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, ListVoterIDs, arg.Fingerprint, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListVotesByVoters = `-- name: ListVotesByVoters :many
+SELECT v.movie_id, m.title, v.region, v.category::text AS category, v.created_at
+FROM votes v
+JOIN movies m ON m.id = v.movie_id
+WHERE v.voter_id = ANY($1::uuid[])
+ORDER BY v.created_at, v.id
+`
+
+type ListVotesByVotersRow struct {
+	MovieID   int64              `json:"movie_id"`
+	Title     string             `json:"title"`
+	Region    string             `json:"region"`
+	Category  string             `json:"category"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListVotesByVoters(ctx context.Context, dollar_1 []pgtype.UUID) ([]ListVotesByVotersRow, error) {
+	rows, err := q.db.Query(ctx, ListVotesByVoters, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListVotesByVotersRow{}
+	for rows.Next() {
+		var i ListVotesByVotersRow
+		if err := rows.Scan(
+			&i.MovieID,
+			&i.Title,
+			&i.Region,
+			&i.Category,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const MoveVoterVotes = `-- name: MoveVoterVotes :execrows
+UPDATE votes SET voter_id = $1 WHERE voter_id = $2
+`
+
+type MoveVoterVotesParams struct {
+	TargetID pgtype.UUID `json:"target_id"`
+	SourceID pgtype.UUID `json:"source_id"`
+}
+
+func (q *Queries) MoveVoterVotes(ctx context.Context, arg MoveVoterVotesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, MoveVoterVotes, arg.TargetID, arg.SourceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const SetVoterFingerprintDigest = `-- name: SetVoterFingerprintDigest :execrows
+UPDATE voters
+SET fingerprint = $1, fingerprint_hashed = true
+WHERE id = $2
+  AND NOT EXISTS (SELECT 1 FROM voters o WHERE o.fingerprint = $1)
+`
+
+type SetVoterFingerprintDigestParams struct {
+	Digest pgtype.Text `json:"digest"`
+	ID     pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) SetVoterFingerprintDigest(ctx context.Context, arg SetVoterFingerprintDigestParams) (int64, error) {
+	result, err := q.db.Exec(ctx, SetVoterFingerprintDigest, arg.Digest, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
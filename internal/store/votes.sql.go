@@ -12,20 +12,21 @@ import (
 )
 
 const InsertVote = `-- name: InsertVote :one
-INSERT INTO votes (movie_id, voter_id, category)
-VALUES ($1, $2, $3)
-ON CONFLICT (movie_id, voter_id) DO NOTHING
+INSERT INTO votes (movie_id, region, voter_id, category)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (movie_id, region, voter_id) DO NOTHING
 RETURNING id
 `
 
 type InsertVoteParams struct {
 	MovieID  int64       `json:"movie_id"`
+	Region   string      `json:"region"`
 	VoterID  pgtype.UUID `json:"voter_id"`
 	Category string      `json:"category"`
 }
 
 func (q *Queries) InsertVote(ctx context.Context, arg InsertVoteParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, InsertVote, arg.MovieID, arg.VoterID, arg.Category)
+	row := q.db.QueryRow(ctx, InsertVote, arg.MovieID, arg.Region, arg.VoterID, arg.Category)
 	var id pgtype.UUID
 	err := row.Scan(&id)
 	return id, err
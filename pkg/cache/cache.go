@@ -12,6 +12,8 @@ type Cache interface {
 	Set(ctx context.Context, key string, val string, ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
 	DeletePrefix(ctx context.Context, prefix string) error
+	Ping(ctx context.Context) error
+	Close() error
 }
 
 type InMemoryCache struct {
@@ -70,3 +72,7 @@ func (c *InMemoryCache) DeletePrefix(_ context.Context, prefix string) error {
 	c.mu.Unlock()
 	return nil
 }
+
+func (c *InMemoryCache) Ping(_ context.Context) error { return nil }
+
+func (c *InMemoryCache) Close() error { return nil }
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	pkgmetrics "cinekami-server/pkg/metrics"
+	pkgtracing "cinekami-server/pkg/tracing"
+)
+
+// Instrumented wraps a Cache with a span per operation and counts hits and
+// misses per key family. The family is the key prefix up to the first ':'
+// (e.g. "active_movies").
+type Instrumented struct {
+	Cache
+}
+
+func NewInstrumented(c Cache) *Instrumented { return &Instrumented{Cache: c} }
+
+func (c *Instrumented) Get(ctx context.Context, key string) (string, bool) {
+	ctx, span := startSpan(ctx, "GET", key)
+	defer span.End()
+	v, ok := c.Cache.Get(ctx, key)
+	result := "miss"
+	if ok {
+		result = "hit"
+	}
+	span.SetAttributes(attribute.String("cache.result", result))
+	pkgmetrics.CacheRequests.WithLabelValues(keyFamily(key), result).Inc()
+	return v, ok
+}
+
+func (c *Instrumented) Set(ctx context.Context, key string, val string, ttl time.Duration) error {
+	ctx, span := startSpan(ctx, "SET", key)
+	defer span.End()
+	return endSpan(span, c.Cache.Set(ctx, key, val, ttl))
+}
+
+func (c *Instrumented) Delete(ctx context.Context, key string) error {
+	ctx, span := startSpan(ctx, "DEL", key)
+	defer span.End()
+	return endSpan(span, c.Cache.Delete(ctx, key))
+}
+
+func (c *Instrumented) DeletePrefix(ctx context.Context, prefix string) error {
+	ctx, span := startSpan(ctx, "DEL_PREFIX", prefix)
+	defer span.End()
+	return endSpan(span, c.Cache.DeletePrefix(ctx, prefix))
+}
+
+func startSpan(ctx context.Context, op, key string) (context.Context, trace.Span) {
+	return pkgtracing.Tracer().Start(ctx, "cache "+op, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("cache.operation", op),
+			attribute.String("cache.key_family", keyFamily(key)),
+		))
+}
+
+func endSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func keyFamily(key string) string {
+	if i := strings.IndexByte(key, ':'); i > 0 {
+		return key[:i]
+	}
+	return key
+}
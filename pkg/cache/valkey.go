@@ -71,3 +71,12 @@ func (v *ValkeyClient) DeletePrefix(ctx context.Context, prefix string) error {
 	}
 	return lastErr
 }
+
+func (v *ValkeyClient) Ping(ctx context.Context) error {
+	return v.c.Do(ctx, v.c.B().Ping().Build()).Error()
+}
+
+func (v *ValkeyClient) Close() error {
+	v.c.Close()
+	return nil
+}
@@ -0,0 +1,308 @@
+// Package card renders share cards: 1200x630 PNG previews of results, sized
+// for Open Graph and Twitter, and the small HTML page that announces them.
+package card
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Size of the rendered image, the 1.91:1 ratio preview cards are cropped to.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// Content types of the rendered documents.
+const (
+	PNGContentType  = "image/png"
+	HTMLContentType = "text/html; charset=utf-8"
+)
+
+// Content-Security-Policies of the HTML documents, without frame-ancestors:
+// the page shows its card, the widget has inline styles and nothing else.
+const (
+	PageCSP   = "default-src 'none'; img-src 'self'; base-uri 'none'"
+	WidgetCSP = "default-src 'none'; style-src 'unsafe-inline'; base-uri 'none'; form-action 'none'"
+)
+
+// MaxBars is the number of bars a card has room for; further bars are dropped.
+const MaxBars = 4
+
+// Card is what an image shows. Bars are drawn in order, scaled to the largest
+// value; the Leading one is highlighted.
+type Card struct {
+	Title    string
+	Subtitle string
+	Bars     []Bar
+	Footer   string // bottom right, e.g. the leading category
+	Brand    string // bottom left
+}
+
+// Bar is one category: its label, an optional detail after it (such as the
+// movie that won it) and its count.
+type Bar struct {
+	Label   string
+	Detail  string
+	Value   int64
+	Leading bool
+}
+
+var (
+	background = color.RGBA{0x14, 0x16, 0x1c, 0xff}
+	foreground = color.RGBA{0xf2, 0xf2, 0xf5, 0xff}
+	muted      = color.RGBA{0x9a, 0xa0, 0xae, 0xff}
+	track      = color.RGBA{0x26, 0x2a, 0x34, 0xff}
+	bar        = color.RGBA{0x5b, 0x67, 0x82, 0xff}
+	accent     = color.RGBA{0xf5, 0xb7, 0x2e, 0xff}
+)
+
+const (
+	margin    = 72
+	barTop    = 226
+	barRow    = 80
+	barHeight = 26
+)
+
+// fonts are the embedded Go fonts, parsed once. Faces are made per render as
+// they are not safe for concurrent use.
+var fonts = sync.OnceValues(func() ([2]*opentype.Font, error) {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return [2]*opentype.Font{}, err
+	}
+	bold, err := opentype.Parse(gobold.TTF)
+	return [2]*opentype.Font{regular, bold}, err
+})
+
+// Render draws c as a PNG.
+func Render(c Card) ([]byte, error) {
+	f, err := fonts()
+	if err != nil {
+		return nil, fmt.Errorf("card: load fonts: %w", err)
+	}
+	faces := map[string]font.Face{}
+	for name, o := range map[string]struct {
+		font *opentype.Font
+		size float64
+	}{"title": {f[1], 60}, "subtitle": {f[0], 30}, "label": {f[1], 28}, "detail": {f[0], 28}, "brand": {f[1], 28}, "footer": {f[0], 26}} {
+		face, err := opentype.NewFace(o.font, &opentype.FaceOptions{Size: o.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, fmt.Errorf("card: %s face: %w", name, err)
+		}
+		defer face.Close()
+		faces[name] = face
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	fill(img, img.Bounds(), background)
+	fill(img, image.Rect(0, 0, 16, Height), accent)
+	inner := Width - 2*margin
+
+	text(img, faces["title"], foreground, margin, 128, fit(faces["title"], c.Title, inner))
+	text(img, faces["subtitle"], muted, margin, 180, fit(faces["subtitle"], c.Subtitle, inner))
+
+	var largest int64
+	bars := c.Bars
+	if len(bars) > MaxBars {
+		bars = bars[:MaxBars]
+	}
+	for _, b := range bars {
+		largest = max(largest, b.Value)
+	}
+	for i, b := range bars {
+		y := barTop + i*barRow
+		value := strconv.FormatInt(b.Value, 10)
+		valueWidth := measure(faces["label"], value)
+		text(img, faces["label"], foreground, Width-margin-valueWidth, y+24, value)
+		label := fit(faces["label"], b.Label, inner-valueWidth-24)
+		text(img, faces["label"], foreground, margin, y+24, label)
+		if b.Detail != "" {
+			x := margin + measure(faces["label"], label) + 16
+			text(img, faces["detail"], muted, x, y+24, fit(faces["detail"], b.Detail, Width-margin-valueWidth-24-x))
+		}
+		top := y + 38
+		fill(img, image.Rect(margin, top, Width-margin, top+barHeight), track)
+		if largest > 0 && b.Value > 0 {
+			col := bar
+			if b.Leading {
+				col = accent
+			}
+			w := int(int64(inner) * b.Value / largest)
+			fill(img, image.Rect(margin, top, margin+max(w, barHeight/2), top+barHeight), col)
+		}
+	}
+
+	text(img, faces["brand"], accent, margin, Height-44, c.Brand)
+	footer := fit(faces["footer"], c.Footer, inner-measure(faces["brand"], c.Brand)-32)
+	text(img, faces["footer"], muted, Width-margin-measure(faces["footer"], footer), Height-44, footer)
+
+	var buf bytes.Buffer
+	if err := (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("card: encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// text draws s with its baseline at y.
+func text(img draw.Image, face font.Face, c color.Color, x, y int, s string) {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+func measure(face font.Face, s string) int {
+	return font.MeasureString(face, s).Ceil()
+}
+
+// fit shortens s with an ellipsis until it is at most width pixels wide.
+func fit(face font.Face, s string, width int) string {
+	if measure(face, s) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 {
+		r = r[:len(r)-1]
+		if t := string(r) + "…"; measure(face, t) <= width {
+			return t
+		}
+	}
+	return ""
+}
+
+// Meta is the Open Graph description of a shared page. URL is the canonical
+// page, Image the absolute URL of its card and OEmbed, when set, the oEmbed
+// endpoint announced for it.
+type Meta struct {
+	SiteName    string
+	Title       string
+	Description string
+	URL         string
+	Image       string
+	ImageAlt    string
+	OEmbed      string
+}
+
+var page = template.Must(template.New("page").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+<link rel="canonical" href="{{.URL}}">
+{{if .OEmbed}}<link rel="alternate" type="application/json+oembed" href="{{.OEmbed}}" title="{{.Title}}">
+{{end}}<meta property="og:type" content="website">
+<meta property="og:site_name" content="{{.SiteName}}">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:image" content="{{.Image}}">
+<meta property="og:image:type" content="image/png">
+<meta property="og:image:width" content="{{.Width}}">
+<meta property="og:image:height" content="{{.Height}}">
+<meta property="og:image:alt" content="{{.ImageAlt}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<meta name="twitter:image" content="{{.Image}}">
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Description}}</p>
+<img src="{{.Image}}" width="{{.Width}}" height="{{.Height}}" alt="{{.ImageAlt}}">
+</body>
+</html>
+`))
+
+// Page renders the HTML page carrying m as Open Graph and Twitter card tags.
+func Page(m Meta) ([]byte, error) {
+	var buf bytes.Buffer
+	err := page.Execute(&buf, struct {
+		Meta
+		Width, Height int
+	}{m, Width, Height})
+	return buf.Bytes(), err
+}
+
+var widget = template.Must(template.New("widget").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+{{if .Refresh}}<meta http-equiv="refresh" content="{{.Refresh}}">
+{{end}}<title>{{.Title}}</title>
+<style>
+body{margin:0;font:14px/1.4 system-ui,sans-serif;color:#f2f2f5;background:#14161c}
+main{padding:14px 16px;border-left:4px solid #f5b72e}
+h1{margin:0;font-size:18px;white-space:nowrap;overflow:hidden;text-overflow:ellipsis}
+p{margin:2px 0 10px;color:#9aa0ae}
+ol{list-style:none;margin:0;padding:0}
+li{margin:0 0 8px}
+.row{display:flex;justify-content:space-between;font-weight:600}
+.row span:last-child{font-variant-numeric:tabular-nums}
+.track{height:8px;background:#262a34;border-radius:4px;overflow:hidden}
+.fill{height:100%;background:#5b6782}
+.lead .fill{background:#f5b72e}
+footer{display:flex;justify-content:space-between;margin-top:10px;color:#9aa0ae}
+a{color:#f5b72e;font-weight:600;text-decoration:none}
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<p>{{.Subtitle}}</p>
+<ol>
+{{range .Bars}}<li{{if .Leading}} class="lead"{{end}}><div class="row"><span>{{.Label}}</span><span>{{.Value}} · {{.Percent}}%</span></div><div class="track"><div class="fill" style="width:{{.Percent}}%"></div></div></li>
+{{end}}</ol>
+<footer><span>{{.Footer}}</span><a href="{{.Link}}" target="_blank" rel="noopener">{{.Brand}}</a></footer>
+</main>
+</body>
+</html>
+`))
+
+// Widget renders c as a small HTML document to embed in an iframe: a bar per
+// category with its share of the votes, linking to link. With refresh the
+// browser reloads it that often.
+func Widget(c Card, link string, refresh time.Duration) ([]byte, error) {
+	type row struct {
+		Bar
+		Percent int64
+	}
+	var total int64
+	for _, b := range c.Bars {
+		total += b.Value
+	}
+	rows := make([]row, 0, len(c.Bars))
+	for _, b := range c.Bars {
+		r := row{Bar: b}
+		if total > 0 {
+			r.Percent = (b.Value*100 + total/2) / total
+		}
+		rows = append(rows, r)
+	}
+	var buf bytes.Buffer
+	err := widget.Execute(&buf, struct {
+		Card
+		Bars    []row
+		Link    string
+		Refresh int
+	}{c, rows, link, int(refresh.Seconds())})
+	return buf.Bytes(), err
+}
@@ -0,0 +1,94 @@
+package card
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	b, err := Render(Card{
+		Title:    "A very long movie title that cannot possibly fit on a single line of the card",
+		Subtitle: "Released 14 March 2025 in RO · 6 votes",
+		Bars: []Bar{
+			{Label: "Solo / friends", Value: 1},
+			{Label: "Couple", Detail: "Winner", Value: 4, Leading: true},
+			{Label: "Streaming"},
+			{Label: "Arr", Value: 1},
+			{Label: "Dropped", Value: 9},
+		},
+		Brand:  "cinekami",
+		Footer: "Leading: Couple",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+	if got := img.Bounds().Size(); got.X != Width || got.Y != Height {
+		t.Fatalf("size %v, want %dx%d", got, Width, Height)
+	}
+	// The leading bar (second row) spans the width in the accent colour; the
+	// fifth bar is dropped, so it does not set the scale.
+	if r, g, bl, _ := img.At(Width-margin-1, barTop+barRow+38+barHeight/2).RGBA(); r>>8 != uint32(accent.R) || g>>8 != uint32(accent.G) || bl>>8 != uint32(accent.B) {
+		t.Fatalf("leading bar not drawn full width in the accent colour")
+	}
+	if r, _, _, _ := img.At(Width-margin-1, barTop+38+barHeight/2).RGBA(); r>>8 != uint32(track.R) {
+		t.Fatalf("smaller bar drawn full width")
+	}
+}
+
+func TestPage(t *testing.T) {
+	b, err := Page(Meta{
+		SiteName:    "cinekami",
+		Title:       `Fast & "Furious"`,
+		Description: "Leading: <Couple>",
+		URL:         "https://example.com/movies/1?region=RO",
+		Image:       "https://example.com/movies/1/card.png?region=RO",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(b)
+	for _, want := range []string{
+		`<meta property="og:title" content="Fast &amp; &#34;Furious&#34;">`,
+		`<meta property="og:description" content="Leading: &lt;Couple&gt;">`,
+		`<meta property="og:image" content="https://example.com/movies/1/card.png?region=RO">`,
+		`<meta property="og:image:width" content="1200">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("page lacks %s:\n%s", want, page)
+		}
+	}
+}
+
+func TestWidget(t *testing.T) {
+	b, err := Widget(Card{
+		Title: "<Dune>",
+		Bars: []Bar{
+			{Label: "Couple", Value: 2, Leading: true},
+			{Label: "Arr", Value: 1},
+		},
+		Brand: "cinekami",
+	}, "https://example.com/movies/1?region=RO", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(b)
+	for _, want := range []string{
+		`<meta http-equiv="refresh" content="60">`,
+		`<h1>&lt;Dune&gt;</h1>`,
+		`<li class="lead"><div class="row"><span>Couple</span><span>2 · 67%</span>`,
+		`style="width:33%"`,
+		`href="https://example.com/movies/1?region=RO" target="_blank"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("widget lacks %s:\n%s", want, page)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Fingerprinter turns client fingerprints into keyed digests, so the database
+// only ever holds values that cannot be linked back to a client without the key.
+// It is safe for concurrent use.
+type Fingerprinter struct {
+	key []byte
+}
+
+// NewFingerprinter returns a Fingerprinter keyed with key. Changing the key
+// detaches every stored voter from its client.
+func NewFingerprinter(key []byte) *Fingerprinter {
+	return &Fingerprinter{key: append([]byte(nil), key...)}
+}
+
+// Digest returns the hex HMAC-SHA256 of fingerprint, or "" for an empty one.
+func (f *Fingerprinter) Digest(fingerprint string) string {
+	if fingerprint == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, f.key)
+	mac.Write([]byte(fingerprint))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -1,126 +1,253 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"hash"
-	"math"
+	"time"
 )
 
 // Codec lists the crypto methods the handlers rely on.
 // Implementations must be safe for concurrent use.
+//
+// scope binds a cursor to the query that produced it (see Scope); decoding with
+// a different scope fails with ErrCursorScope.
 type Codec interface {
-	EncodeMoviesCursor(popularity float64, id int64) string
-	DecodeMoviesCursor(token string) (float64, int64, error)
+	EncodeMoviesCursor(scope string, c KeysetCursor) string
+	DecodeMoviesCursor(scope, token string) (KeysetCursor, error)
 
-	EncodeTalliesCursor(count int64, category string) string
-	DecodeTalliesCursor(token string) (int64, string, error)
+	EncodeTalliesCursor(scope string, count int64, category string) string
+	DecodeTalliesCursor(scope, token string) (int64, string, error)
 
-	// Snapshots cursor now encodes key (float64) + movieID (int64)
-	EncodeSnapshotsCursor(key float64, movieID int64) string
-	DecodeSnapshotsCursor(token string) (float64, int64, error)
+	// Snapshots cursors are sealed like the others (see the token layout
+	// below) around the same keyset payload as movies cursors: id(8) |
+	// backward(1) | key count(1) | per key, length(2) and text.
+	EncodeSnapshotsCursor(scope string, c KeysetCursor) string
+	DecodeSnapshotsCursor(scope, token string) (KeysetCursor, error)
 }
 
+// KeysetCursor points at the row a page starts after: its sort key values, in
+// sort order and rendered as text, and its id as the tie-breaker. Backward
+// cursors (prev_cursor) page towards the start.
+type KeysetCursor struct {
+	Keys     []string
+	ID       int64
+	Backward bool
+}
+
+// Cursor errors all wrap ErrInvalidCursor.
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrCursorMalformed  = fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	ErrCursorUnknownKey = fmt.Errorf("%w: unknown signing key", ErrInvalidCursor)
+	ErrCursorSignature  = fmt.Errorf("%w: bad signature", ErrInvalidCursor)
+	ErrCursorExpired    = fmt.Errorf("%w: expired", ErrInvalidCursor)
+	ErrCursorScope      = fmt.Errorf("%w: issued for different query parameters", ErrInvalidCursor)
+)
+
+// DefaultCursorTTL is how long a cursor stays valid unless HMAC.TTL says otherwise.
+const DefaultCursorTTL = 48 * time.Hour
+
+// Token layout (base64url, no padding):
+//
+//	version(1) | key id(4) | expires unix seconds(8) | scope hash(8) | payload | HMAC-SHA256(32)
+//
+// The key id is derived from the secret, so rotating only requires keeping the
+// old secret in the verification list until its cursors have expired.
+const (
+	cursorVersion = 1
+	headerLen     = 1 + 4 + 8 + 8
+	macLen        = sha256.Size
+)
+
 // HMAC implements Codec using HMAC-SHA256 for integrity.
 // It encodes payloads as base64 URL without padding.
 type HMAC struct {
-	key []byte
-	h   func() hash.Hash
+	// TTL is the lifetime of newly issued cursors; zero means DefaultCursorTTL.
+	TTL time.Duration
+
+	signKey []byte
+	signID  [4]byte
+	keys    map[[4]byte][]byte // every key accepted for verification
+	h       func() hash.Hash
+	now     func() time.Time
+}
+
+// NewHMAC creates an HMAC crypto that signs with key and also accepts cursors
+// signed with any of the previous keys.
+func NewHMAC(key []byte, previous ...[]byte) *HMAC {
+	c := &HMAC{h: sha256.New, now: time.Now, keys: map[[4]byte][]byte{}}
+	for _, k := range previous {
+		c.keys[keyID(k)] = append([]byte(nil), k...)
+	}
+	c.signKey = append([]byte(nil), key...)
+	c.signID = keyID(key)
+	c.keys[c.signID] = c.signKey
+	return c
 }
 
-// NewHMAC creates an HMAC crypto with the provided secret key.
-func NewHMAC(key []byte) *HMAC {
-	return &HMAC{key: append([]byte(nil), key...), h: sha256.New}
+func keyID(key []byte) [4]byte {
+	sum := sha256.Sum256(key)
+	return [4]byte(sum[:4])
 }
 
-// seal signs the payload and returns a base64url token payload||sig.
-func (c *HMAC) seal(payload []byte) string {
-	mac := hmac.New(c.h, c.key)
-	mac.Write(payload)
-	sig := mac.Sum(nil)
-	buf := append(payload, sig...)
+// Scope canonicalises the filter parameters a cursor is bound to. Callers pass
+// name/value pairs in a fixed order; empty values are kept so that "unset" and
+// "set" never collide.
+func Scope(kind string, kv ...string) string {
+	var b bytes.Buffer
+	b.WriteString(kind)
+	for _, s := range kv {
+		b.WriteByte(0)
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+func scopeHash(scope string) []byte {
+	sum := sha256.Sum256([]byte(scope))
+	return sum[:8]
+}
+
+func (c *HMAC) ttl() time.Duration {
+	if c.TTL > 0 {
+		return c.TTL
+	}
+	return DefaultCursorTTL
+}
+
+// seal prefixes the payload with the header, signs it and returns a base64url token.
+func (c *HMAC) seal(scope string, payload []byte) string {
+	buf := make([]byte, headerLen, headerLen+len(payload)+macLen)
+	buf[0] = cursorVersion
+	copy(buf[1:5], c.signID[:])
+	binary.BigEndian.PutUint64(buf[5:13], uint64(c.now().Add(c.ttl()).Unix()))
+	copy(buf[13:21], scopeHash(scope))
+	buf = append(buf, payload...)
+	mac := hmac.New(c.h, c.signKey)
+	mac.Write(buf)
+	buf = mac.Sum(buf)
 	return base64.RawURLEncoding.EncodeToString(buf)
 }
 
 // open verifies the token and returns the payload bytes.
-func (c *HMAC) open(token string, minPayloadLen int) ([]byte, error) {
+func (c *HMAC) open(scope, token string, minPayloadLen int) ([]byte, error) {
 	raw, err := base64.RawURLEncoding.DecodeString(token)
-	if err != nil {
-		return nil, err
+	if err != nil || len(raw) < headerLen+minPayloadLen+macLen || raw[0] != cursorVersion {
+		return nil, ErrCursorMalformed
+	}
+	key, ok := c.keys[[4]byte(raw[1:5])]
+	if !ok {
+		return nil, ErrCursorUnknownKey
 	}
-	if len(raw) < minPayloadLen+32 {
-		return nil, errors.New("invalid_cursor_length")
+	body, sig := raw[:len(raw)-macLen], raw[len(raw)-macLen:]
+	mac := hmac.New(c.h, key)
+	mac.Write(body)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, ErrCursorSignature
 	}
-	payload := raw[:len(raw)-32]
-	sig := raw[len(raw)-32:]
-	mac := hmac.New(c.h, c.key)
-	mac.Write(payload)
-	expected := mac.Sum(nil)
-	if !hmac.Equal(sig, expected) {
-		return nil, errors.New("invalid_cursor_signature")
+	if exp := int64(binary.BigEndian.Uint64(body[5:13])); c.now().Unix() >= exp {
+		return nil, ErrCursorExpired
 	}
-	return payload, nil
+	if !hmac.Equal(body[13:21], scopeHash(scope)) {
+		return nil, ErrCursorScope
+	}
+	return body[headerLen:], nil
 }
 
-// Active movies crypto: popularity(float64) + id(int64)
-func (c *HMAC) EncodeMoviesCursor(popularity float64, id int64) string {
-	payload := make([]byte, 16)
-	binary.BigEndian.PutUint64(payload[0:8], math.Float64bits(popularity))
-	binary.BigEndian.PutUint64(payload[8:16], uint64(id))
-	return c.seal(payload)
+// Active movies crypto: id(int64) + backward(1) + key count(1) + keys (uint16 length + bytes each)
+func (c *HMAC) EncodeMoviesCursor(scope string, k KeysetCursor) string {
+	return c.seal(scope, keysetPayload(k))
 }
 
-func (c *HMAC) DecodeMoviesCursor(token string) (float64, int64, error) {
-	payload, err := c.open(token, 16)
+func (c *HMAC) DecodeMoviesCursor(scope, token string) (KeysetCursor, error) {
+	payload, err := c.open(scope, token, keysetMinLen)
 	if err != nil {
-		return 0, 0, err
+		return KeysetCursor{}, err
 	}
-	pop := math.Float64frombits(binary.BigEndian.Uint64(payload[0:8]))
-	id := int64(binary.BigEndian.Uint64(payload[8:16]))
-	return pop, id, nil
+	return parseKeyset(payload)
+}
+
+// Snapshots crypto: same layout as movies
+func (c *HMAC) EncodeSnapshotsCursor(scope string, k KeysetCursor) string {
+	return c.seal(scope, keysetPayload(k))
+}
+
+func (c *HMAC) DecodeSnapshotsCursor(scope, token string) (KeysetCursor, error) {
+	payload, err := c.open(scope, token, keysetMinLen)
+	if err != nil {
+		return KeysetCursor{}, err
+	}
+	return parseKeyset(payload)
+}
+
+const keysetMinLen = 8 + 1 + 1
+
+func keysetPayload(k KeysetCursor) []byte {
+	payload := make([]byte, keysetMinLen, keysetMinLen+16*len(k.Keys))
+	binary.BigEndian.PutUint64(payload[0:8], uint64(k.ID))
+	if k.Backward {
+		payload[8] = 1
+	}
+	payload[9] = byte(len(k.Keys))
+	for _, key := range k.Keys {
+		payload = binary.BigEndian.AppendUint16(payload, uint16(len(key)))
+		payload = append(payload, key...)
+	}
+	return payload
+}
+
+func parseKeyset(payload []byte) (KeysetCursor, error) {
+	if len(payload) < keysetMinLen || payload[8] > 1 {
+		return KeysetCursor{}, ErrCursorMalformed
+	}
+	k := KeysetCursor{
+		ID:       int64(binary.BigEndian.Uint64(payload[0:8])),
+		Backward: payload[8] == 1,
+		Keys:     make([]string, payload[9]),
+	}
+	rest := payload[keysetMinLen:]
+	for i := range k.Keys {
+		if len(rest) < 2 {
+			return KeysetCursor{}, ErrCursorMalformed
+		}
+		n := int(binary.BigEndian.Uint16(rest))
+		if len(rest) < 2+n {
+			return KeysetCursor{}, ErrCursorMalformed
+		}
+		k.Keys[i], rest = string(rest[2:2+n]), rest[2+n:]
+	}
+	if len(rest) != 0 {
+		return KeysetCursor{}, ErrCursorMalformed
+	}
+	return k, nil
 }
 
 // Tallies crypto: count(int64) + categoryLen(uint16) + category bytes
-func (c *HMAC) EncodeTalliesCursor(count int64, category string) string {
+func (c *HMAC) EncodeTalliesCursor(scope string, count int64, category string) string {
 	catBytes := []byte(category)
 	payload := make([]byte, 8+2+len(catBytes))
 	binary.BigEndian.PutUint64(payload[0:8], uint64(count))
 	binary.BigEndian.PutUint16(payload[8:10], uint16(len(catBytes)))
 	copy(payload[10:], catBytes)
-	return c.seal(payload)
+	return c.seal(scope, payload)
 }
 
-func (c *HMAC) DecodeTalliesCursor(token string) (int64, string, error) {
-	payload, err := c.open(token, 10)
+func (c *HMAC) DecodeTalliesCursor(scope, token string) (int64, string, error) {
+	payload, err := c.open(scope, token, 10)
 	if err != nil {
 		return 0, "", err
 	}
 	cnt := int64(binary.BigEndian.Uint64(payload[0:8]))
 	catLen := int(binary.BigEndian.Uint16(payload[8:10]))
 	if 10+catLen != len(payload) {
-		return 0, "", errors.New("invalid_cursor_payload")
+		return 0, "", ErrCursorMalformed
 	}
 	category := string(payload[10:])
 	return cnt, category, nil
 }
-
-// Snapshots crypto: key(float64) + movie_id(int64)
-func (c *HMAC) EncodeSnapshotsCursor(key float64, movieID int64) string {
-	payload := make([]byte, 16)
-	binary.BigEndian.PutUint64(payload[0:8], math.Float64bits(key))
-	binary.BigEndian.PutUint64(payload[8:16], uint64(movieID))
-	return c.seal(payload)
-}
-
-func (c *HMAC) DecodeSnapshotsCursor(token string) (float64, int64, error) {
-	payload, err := c.open(token, 16)
-	if err != nil {
-		return 0, 0, err
-	}
-	key := math.Float64frombits(binary.BigEndian.Uint64(payload[0:8]))
-	id := int64(binary.BigEndian.Uint64(payload[8:16]))
-	return key, id, nil
-}
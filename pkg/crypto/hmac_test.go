@@ -0,0 +1,56 @@
+package crypto
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCursorRotationScopeAndExpiry(t *testing.T) {
+	oldKey := []byte("old-secret-old-secret-old-secret")
+	newKey := []byte("new-secret-new-secret-new-secret")
+	scope := Scope("active_movies", "2025-10", "popularity", "desc", "", "")
+
+	old := NewHMAC(oldKey)
+	want := KeysetCursor{Keys: []string{"12.5", "Dune: Part Two"}, ID: 42, Backward: true}
+	token := old.EncodeMoviesCursor(scope, want)
+
+	rotated := NewHMAC(newKey, oldKey)
+	if k, err := rotated.DecodeMoviesCursor(scope, token); err != nil || !reflect.DeepEqual(k, want) {
+		t.Fatalf("rotated codec should accept old cursor: %+v %v", k, err)
+	}
+	if _, err := NewHMAC(newKey).DecodeMoviesCursor(scope, token); !errors.Is(err, ErrCursorUnknownKey) {
+		t.Fatalf("expected unknown key once the old secret is dropped, got %v", err)
+	}
+
+	other := Scope("active_movies", "2025-10", "couple", "desc", "", "")
+	if _, err := rotated.DecodeMoviesCursor(other, token); !errors.Is(err, ErrCursorScope) {
+		t.Fatalf("expected scope mismatch, got %v", err)
+	}
+
+	tampered := []byte(token)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := rotated.DecodeMoviesCursor(scope, string(tampered)); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected tampered cursor to be rejected, got %v", err)
+	}
+
+	rotated.now = func() time.Time { return time.Now().Add(DefaultCursorTTL + time.Minute) }
+	if _, err := rotated.DecodeMoviesCursor(scope, token); !errors.Is(err, ErrCursorExpired) {
+		t.Fatalf("expected expired cursor, got %v", err)
+	}
+}
+
+func TestFingerprintDigest(t *testing.T) {
+	f := NewFingerprinter([]byte("fingerprint-secret-fingerprint-secret"))
+	d := f.Digest("client-1")
+	if len(d) != 64 || d == "client-1" || d != f.Digest("client-1") {
+		t.Fatalf("expected a stable 64-char digest, got %q", d)
+	}
+	if d == NewFingerprinter([]byte("another-key")).Digest("client-1") {
+		t.Fatal("digest does not depend on the key")
+	}
+	if f.Digest("") != "" {
+		t.Fatal("empty fingerprint should stay empty")
+	}
+}
@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Password hashes are stored as "pbkdf2-sha256$<iterations>$<base64 key>" with the
+// salt kept alongside in users.password_salt, so the cost can be raised later
+// without invalidating existing hashes.
+const (
+	passwordScheme     = "pbkdf2-sha256"
+	passwordIterations = 600_000
+	passwordKeyLen     = 32
+	passwordSaltLen    = 16
+)
+
+// HashPassword derives a hash for password with a fresh random salt.
+func HashPassword(password string) (hash, salt string, err error) {
+	rawSalt := make([]byte, passwordSaltLen)
+	if _, err := rand.Read(rawSalt); err != nil {
+		return "", "", err
+	}
+	salt = base64.RawStdEncoding.EncodeToString(rawSalt)
+	key, err := pbkdf2.Key(sha256.New, password, rawSalt, passwordIterations, passwordKeyLen)
+	if err != nil {
+		return "", "", err
+	}
+	hash = fmt.Sprintf("%s$%d$%s", passwordScheme, passwordIterations, base64.RawStdEncoding.EncodeToString(key))
+	return hash, salt, nil
+}
+
+// DummyPassword is the hash and salt of a random password, verified in place of
+// an unknown account's so the response time does not tell which accounts
+// exist. Should hashing fail the check fails fast, still as a mismatch.
+var DummyPassword = sync.OnceValues(func() (hash, salt string) {
+	hash, salt, _ = HashPassword(rand.Text())
+	return hash, salt
+})
+
+// VerifyPassword reports whether password matches a hash produced by HashPassword.
+func VerifyPassword(password, hash, salt string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 3 || parts[0] != passwordScheme {
+		return false, errors.New("unsupported password hash")
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false, errors.New("invalid password hash iterations")
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false, err
+	}
+	rawSalt, err := base64.RawStdEncoding.DecodeString(salt)
+	if err != nil {
+		return false, err
+	}
+	got, err := pbkdf2.Key(sha256.New, password, rawSalt, iter, len(want))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
@@ -7,14 +7,30 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// PoolOptions sizes the pool. The zero value selects the defaults used by Connect.
+type PoolOptions struct {
+	MaxConns          int32
+	MinConns          int32
+	HealthCheckPeriod time.Duration
+}
+
 // Connect creates a pgx pool with sensible defaults.
 func Connect(ctx context.Context, url string) (*pgxpool.Pool, error) {
+	return ConnectWith(ctx, url, PoolOptions{})
+}
+
+// ConnectWith creates a pgx pool sized by opts.
+func ConnectWith(ctx context.Context, url string, opts PoolOptions) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(url)
 	if err != nil {
 		return nil, err
 	}
-	cfg.MaxConns = 10
-	cfg.MinConns = 1
-	cfg.HealthCheckPeriod = 30 * time.Second
+	if opts == (PoolOptions{}) {
+		opts = PoolOptions{MaxConns: 10, MinConns: 1, HealthCheckPeriod: 30 * time.Second}
+	}
+	cfg.MaxConns = opts.MaxConns
+	cfg.MinConns = opts.MinConns
+	cfg.HealthCheckPeriod = opts.HealthCheckPeriod
+	cfg.ConnConfig.Tracer = queryTracer{}
 	return pgxpool.NewWithConfig(ctx, cfg)
 }
@@ -0,0 +1,212 @@
+// Package export writes streams of rows as CSV, NDJSON or Parquet, and picks
+// the format from a ?format= value or an Accept header.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format is an export file format.
+type Format string
+
+const (
+	CSV     Format = "csv"
+	NDJSON  Format = "ndjson"
+	Parquet Format = "parquet"
+)
+
+// Formats lists the supported formats; NDJSON is the default.
+var Formats = []Format{CSV, NDJSON, Parquet}
+
+var contentTypes = map[Format]string{
+	CSV:     "text/csv; charset=utf-8",
+	NDJSON:  "application/x-ndjson",
+	Parquet: "application/vnd.apache.parquet",
+}
+
+// Media types accepted in Accept for each format, besides ContentType's.
+var aliases = map[string]Format{
+	"text/csv":                       CSV,
+	"application/csv":                CSV,
+	"application/x-ndjson":           NDJSON,
+	"application/ndjson":             NDJSON,
+	"application/jsonl":              NDJSON,
+	"application/vnd.apache.parquet": Parquet,
+	"application/x-parquet":          Parquet,
+}
+
+// ContentType is the media type f is served as.
+func (f Format) ContentType() string { return contentTypes[f] }
+
+// ParseFormat validates a format name such as "csv".
+func ParseFormat(s string) (Format, bool) {
+	f := Format(strings.ToLower(s))
+	return f, slices.Contains(Formats, f)
+}
+
+// ForMediaType returns the format of a Content-Type such as
+// "text/csv; charset=utf-8".
+func ForMediaType(contentType string) (Format, bool) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	f, ok := aliases[mt]
+	return f, ok
+}
+
+// Negotiate picks the format from an Accept header: the supported media type
+// with the highest q, NDJSON for */* or no header. ok is false when the header
+// accepts none of the formats.
+func Negotiate(accept string) (f Format, ok bool) {
+	if strings.TrimSpace(accept) == "" {
+		return NDJSON, true
+	}
+	type choice struct {
+		format Format
+		q      float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		if mt == "*/*" {
+			choices = append(choices, choice{NDJSON, q})
+		} else if f, ok := aliases[mt]; ok {
+			choices = append(choices, choice{f, q})
+		}
+	}
+	if len(choices) == 0 {
+		return "", false
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	return choices[0].format, true
+}
+
+// Record is a row that knows its CSV form. Rows are written to NDJSON with
+// encoding/json and to Parquet with their parquet struct tags.
+type Record interface {
+	CSVHeader() []string
+	CSVRecord() []string
+}
+
+// Writer writes rows of one type. Close must be called to complete the output
+// (for Parquet, the footer); it does not close the underlying writer.
+type Writer[T Record] interface {
+	Write(row T) error
+	Close() error
+}
+
+// NewWriter returns a writer of rows in format f to w.
+func NewWriter[T Record](w io.Writer, f Format) Writer[T] {
+	switch f {
+	case CSV:
+		return &csvWriter[T]{w: csv.NewWriter(w)}
+	case Parquet:
+		return &parquetWriter[T]{w: parquet.NewGenericWriter[T](w, parquet.Compression(&parquet.Snappy))}
+	default:
+		bw := bufio.NewWriter(w)
+		return &ndjsonWriter[T]{bw: bw, enc: json.NewEncoder(bw)}
+	}
+}
+
+type csvWriter[T Record] struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvWriter[T]) Write(row T) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write(row.CSVHeader()); err != nil {
+			return err
+		}
+	}
+	return c.w.Write(row.CSVRecord())
+}
+
+// Close writes the header even when there were no rows.
+func (c *csvWriter[T]) Close() error {
+	if !c.header {
+		var zero T
+		c.header = true
+		if err := c.w.Write(zero.CSVHeader()); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonWriter[T Record] struct {
+	bw  *bufio.Writer
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter[T]) Write(row T) error { return n.enc.Encode(row) }
+
+func (n *ndjsonWriter[T]) Close() error { return n.bw.Flush() }
+
+// Rows are handed to Parquet in batches and a row group is cut every
+// parquetRowGroup rows, which bounds the memory an export holds.
+const (
+	parquetBatch    = 1024
+	parquetRowGroup = 64 * 1024
+)
+
+type parquetWriter[T Record] struct {
+	w       *parquet.GenericWriter[T]
+	batch   []T
+	inGroup int
+}
+
+func (p *parquetWriter[T]) Write(row T) error {
+	p.batch = append(p.batch, row)
+	if len(p.batch) < parquetBatch {
+		return nil
+	}
+	return p.flush()
+}
+
+func (p *parquetWriter[T]) flush() error {
+	if len(p.batch) > 0 {
+		if _, err := p.w.Write(p.batch); err != nil {
+			return err
+		}
+		p.inGroup += len(p.batch)
+		p.batch = p.batch[:0]
+	}
+	if p.inGroup >= parquetRowGroup {
+		p.inGroup = 0
+		return p.w.Flush()
+	}
+	return nil
+}
+
+func (p *parquetWriter[T]) Close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+	return p.w.Close()
+}
@@ -0,0 +1,106 @@
+package export
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+type row struct {
+	ID   int64  `json:"id" parquet:"id"`
+	Name string `json:"name" parquet:"name"`
+}
+
+func (row) CSVHeader() []string   { return []string{"id", "name"} }
+func (r row) CSVRecord() []string { return []string{strconv.FormatInt(r.ID, 10), r.Name} }
+
+func TestNegotiate(t *testing.T) {
+	for accept, want := range map[string]Format{
+		"":                                     NDJSON,
+		"*/*":                                  NDJSON,
+		"text/csv":                             CSV,
+		"application/vnd.apache.parquet":       Parquet,
+		"text/csv;q=0.5, application/x-ndjson": NDJSON,
+		"application/x-ndjson;q=0.2, text/csv;q=0.9, */*;q=0.1": CSV,
+		"text/html, text/csv;q=0.3":                             CSV,
+	} {
+		got, ok := Negotiate(accept)
+		if !ok || got != want {
+			t.Errorf("Negotiate(%q) = %q, %v; want %q", accept, got, ok, want)
+		}
+	}
+	for _, accept := range []string{"text/html", "application/json", "text/csv;q=0"} {
+		if f, ok := Negotiate(accept); ok {
+			t.Errorf("Negotiate(%q) = %q, want none acceptable", accept, f)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, ok := ParseFormat("CSV"); !ok || f != CSV {
+		t.Fatalf("ParseFormat(CSV) = %q, %v", f, ok)
+	}
+	if _, ok := ParseFormat("xlsx"); ok {
+		t.Fatal("ParseFormat accepted xlsx")
+	}
+}
+
+func write(t *testing.T, f Format, rows []row) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter[row](&buf, f)
+	for _, r := range rows {
+		if err := w.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestForMediaType(t *testing.T) {
+	if f, ok := ForMediaType("text/csv; charset=utf-8"); !ok || f != CSV {
+		t.Fatalf("ForMediaType(text/csv) = %q, %v", f, ok)
+	}
+	if _, ok := ForMediaType("application/xml"); ok {
+		t.Fatal("ForMediaType accepted application/xml")
+	}
+}
+
+func TestCSVAndNDJSON(t *testing.T) {
+	rows := []row{{1, "Dune"}, {2, "Anora, the film"}}
+	if got, want := string(write(t, CSV, rows)), "id,name\n1,Dune\n2,\"Anora, the film\"\n"; got != want {
+		t.Fatalf("csv = %q, want %q", got, want)
+	}
+	if got := string(write(t, CSV, nil)); got != "id,name\n" {
+		t.Fatalf("empty csv = %q, want the header", got)
+	}
+	if got, want := string(write(t, NDJSON, rows)), "{\"id\":1,\"name\":\"Dune\"}\n{\"id\":2,\"name\":\"Anora, the film\"}\n"; got != want {
+		t.Fatalf("ndjson = %q, want %q", got, want)
+	}
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	rows := make([]row, parquetBatch*3+7)
+	for i := range rows {
+		rows[i] = row{ID: int64(i), Name: strings.Repeat("x", i%5)}
+	}
+	b := write(t, Parquet, rows)
+	got, err := parquet.Read[row](bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(rows) {
+		t.Fatalf("read %d rows, want %d", len(got), len(rows))
+	}
+	for i := range rows {
+		if got[i] != rows[i] {
+			t.Fatalf("row %d = %+v, want %+v", i, got[i], rows[i])
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Package feed renders syndication feeds: Atom 1.0 (RFC 4287) and JSON Feed 1.1.
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Content types of the rendered documents.
+const (
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	JSONContentType = "application/feed+json; charset=utf-8"
+)
+
+const jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// Feed is a format-neutral feed. IDs must be stable URIs; entries are written
+// in the given order, which should be newest first.
+type Feed struct {
+	ID          string
+	Title       string
+	Description string
+	Link        string // page or resource the feed describes
+	Self        string // URL the feed is served from
+	Author      string
+	Updated     time.Time
+	Entries     []Entry
+	// ExtensionKey names the JSON Feed extension object carrying Entry.Extension,
+	// e.g. "_example"; it must start with an underscore. Atom has no equivalent.
+	ExtensionKey string
+}
+
+// Entry is a feed item. Summary is plain text.
+type Entry struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string
+	Image     string
+	Published time.Time
+	Updated   time.Time
+	Extension any
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomPerson  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published,omitempty"`
+	Links     []atomLink `xml:"link"`
+	Summary   *atomText  `xml:"summary,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// Atom renders f as an Atom 1.0 document.
+func (f Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  atomTime(f.Updated),
+		Author:   atomPerson{Name: f.Author},
+		Entries:  make([]atomEntry, 0, len(f.Entries)),
+	}
+	if f.Link != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "alternate", Href: f.Link})
+	}
+	if f.Self != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "self", Type: "application/atom+xml", Href: f.Self})
+	}
+	for _, e := range f.Entries {
+		ae := atomEntry{ID: e.ID, Title: e.Title, Updated: atomTime(e.Updated)}
+		if !e.Published.IsZero() {
+			ae.Published = atomTime(e.Published)
+		}
+		if e.Link != "" {
+			ae.Links = append(ae.Links, atomLink{Rel: "alternate", Href: e.Link})
+		}
+		if e.Image != "" {
+			ae.Links = append(ae.Links, atomLink{Rel: "enclosure", Type: "image/jpeg", Href: e.Image})
+		}
+		if e.Summary != "" {
+			ae.Summary = &atomText{Type: "text", Body: e.Summary}
+		}
+		doc.Entries = append(doc.Entries, ae)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+type jsonFeed struct {
+	Version     string       `json:"version"`
+	Title       string       `json:"title"`
+	HomePageURL string       `json:"home_page_url,omitempty"`
+	FeedURL     string       `json:"feed_url,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Authors     []jsonAuthor `json:"authors,omitempty"`
+	Items       []jsonItem   `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonItem struct {
+	ID            string     `json:"id"`
+	URL           string     `json:"url,omitempty"`
+	Title         string     `json:"title,omitempty"`
+	ContentText   string     `json:"content_text"`
+	Image         string     `json:"image,omitempty"`
+	DatePublished *time.Time `json:"date_published,omitempty"`
+	DateModified  *time.Time `json:"date_modified,omitempty"`
+
+	extKey string
+	ext    any
+}
+
+// MarshalJSON appends the extension object, which has a caller-chosen key.
+func (it jsonItem) MarshalJSON() ([]byte, error) {
+	type plain jsonItem
+	b, err := json.Marshal(plain(it))
+	if err != nil || it.ext == nil || it.extKey == "" {
+		return b, err
+	}
+	key, _ := json.Marshal(it.extKey)
+	ext, err := json.Marshal(it.ext)
+	if err != nil {
+		return nil, err
+	}
+	out := append(b[:len(b)-1], ',')
+	out = append(out, key...)
+	out = append(out, ':')
+	out = append(out, ext...)
+	return append(out, '}'), nil
+}
+
+// JSON renders f as a JSON Feed 1.1 document.
+func (f Feed) JSON() ([]byte, error) {
+	doc := jsonFeed{
+		Version:     jsonFeedVersion,
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.Self,
+		Description: f.Description,
+		Items:       make([]jsonItem, 0, len(f.Entries)),
+	}
+	if f.Author != "" {
+		doc.Authors = []jsonAuthor{{Name: f.Author}}
+	}
+	for _, e := range f.Entries {
+		doc.Items = append(doc.Items, jsonItem{
+			ID:            e.ID,
+			URL:           e.Link,
+			Title:         e.Title,
+			ContentText:   e.Summary,
+			Image:         e.Image,
+			DatePublished: jsonTime(e.Published),
+			DateModified:  jsonTime(e.Updated),
+			extKey:        f.ExtensionKey,
+			ext:           e.Extension,
+		})
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func jsonTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	u := t.UTC().Truncate(time.Second)
+	return &u
+}
@@ -0,0 +1,78 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	at := time.Date(2025, 11, 1, 0, 5, 0, 0, time.UTC)
+	return Feed{
+		ID:           "urn:test:feed",
+		Title:        "Results & more",
+		Link:         "https://example.com/results",
+		Self:         "https://example.com/feed",
+		Author:       "test",
+		Updated:      at,
+		ExtensionKey: "_test",
+		Entries: []Entry{{
+			ID:        "urn:test:2025-10",
+			Title:     "October <2025>",
+			Link:      "https://example.com/2025/10",
+			Summary:   "couple: A & B (3 votes)",
+			Published: at,
+			Updated:   at,
+			Extension: map[string]int{"votes": 3},
+		}},
+	}
+}
+
+func TestAtomRoundTrip(t *testing.T) {
+	b, err := testFeed().Atom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc atomFeed
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, b)
+	}
+	if doc.XMLName.Space != "http://www.w3.org/2005/Atom" || doc.Updated != "2025-11-01T00:05:00Z" || doc.Author.Name != "test" {
+		t.Fatalf("unexpected feed header: %+v", doc)
+	}
+	if len(doc.Entries) != 1 || doc.Entries[0].Title != "October <2025>" || doc.Entries[0].Summary.Body != "couple: A & B (3 votes)" {
+		t.Fatalf("unexpected entries: %+v", doc.Entries)
+	}
+	if !strings.Contains(string(b), `rel="self"`) {
+		t.Fatalf("missing self link:\n%s", b)
+	}
+}
+
+func TestJSONFeedCarriesExtension(t *testing.T) {
+	b, err := testFeed().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Version string `json:"version"`
+		FeedURL string `json:"feed_url"`
+		Items   []struct {
+			ID            string         `json:"id"`
+			ContentText   string         `json:"content_text"`
+			DatePublished string         `json:"date_published"`
+			Ext           map[string]int `json:"_test"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, b)
+	}
+	if doc.Version != "https://jsonfeed.org/version/1.1" || doc.FeedURL != "https://example.com/feed" || len(doc.Items) != 1 {
+		t.Fatalf("unexpected feed: %s", b)
+	}
+	it := doc.Items[0]
+	if it.ID != "urn:test:2025-10" || it.DatePublished != "2025-11-01T00:05:00Z" || it.Ext["votes"] != 3 {
+		t.Fatalf("unexpected item: %+v", it)
+	}
+}
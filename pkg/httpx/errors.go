@@ -28,6 +28,9 @@ func (e *HTTPError) Unwrap() error { return e.Err }
 func BadRequest(msg string, err error) *HTTPError {
 	return &HTTPError{StatusCode: http.StatusBadRequest, Message: msg, Code: "bad_request", Err: err}
 }
+func InvalidCursor(msg string, err error) *HTTPError {
+	return &HTTPError{StatusCode: http.StatusBadRequest, Message: msg, Code: "invalid_cursor", Err: err}
+}
 func Unauthorized(msg string, err error) *HTTPError {
 	return &HTTPError{StatusCode: http.StatusUnauthorized, Message: msg, Code: "unauthorized", Err: err}
 }
@@ -37,9 +40,27 @@ func Forbidden(msg string, err error) *HTTPError {
 func NotFound(msg string, err error) *HTTPError {
 	return &HTTPError{StatusCode: http.StatusNotFound, Message: msg, Code: "not_found", Err: err}
 }
+func NotAcceptable(msg string, err error) *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotAcceptable, Message: msg, Code: "not_acceptable", Err: err}
+}
 func Conflict(msg string, err error) *HTTPError {
 	return &HTTPError{StatusCode: http.StatusConflict, Message: msg, Code: "conflict", Err: err}
 }
+func PayloadTooLarge(msg string, err error) *HTTPError {
+	return &HTTPError{StatusCode: http.StatusRequestEntityTooLarge, Message: msg, Code: "payload_too_large", Err: err}
+}
+func UnsupportedMediaType(msg string, err error) *HTTPError {
+	return &HTTPError{StatusCode: http.StatusUnsupportedMediaType, Message: msg, Code: "unsupported_media_type", Err: err}
+}
+func TooManyRequests(msg string, err error) *HTTPError {
+	return &HTTPError{StatusCode: http.StatusTooManyRequests, Message: msg, Code: "too_many_requests", Err: err}
+}
+func NotImplemented(msg string, err error) *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotImplemented, Message: msg, Code: "not_implemented", Err: err}
+}
+func Unavailable(msg string, err error) *HTTPError {
+	return &HTTPError{StatusCode: http.StatusServiceUnavailable, Message: msg, Code: "unavailable", Err: err}
+}
 func Internal(msg string, err error) *HTTPError {
 	return &HTTPError{StatusCode: http.StatusInternalServerError, Message: msg, Code: "internal", Err: err}
 }
@@ -16,26 +16,35 @@ func WriteJSON(w http.ResponseWriter, status int, v any) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
+// ErrorBody is the error object carried by every non-2xx response.
+type ErrorBody struct {
+	Code          string         `json:"code"`
+	Message       string         `json:"message"`
+	CorrelationID string         `json:"correlation_id"`
+	Details       map[string]any `json:"details,omitempty"`
+}
+
+// ErrorResponse wraps ErrorBody under the "error" key.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
 // WriteError standardizes error responses and logs with correlation id.
 func WriteError(w http.ResponseWriter, r *http.Request, he *HTTPError) {
 	cid := pkgrequestctx.CorrelationID(r.Context())
 	if cid != "" {
 		w.Header().Set("X-Correlation-Id", cid)
 	}
-	payload := map[string]any{
-		"error": map[string]any{
-			"code":           he.Code,
-			"message":        he.Message,
-			"correlation_id": cid,
-		},
-	}
-	if he.Details != nil {
-		payload["error"].(map[string]any)["details"] = he.Details
-	}
+	payload := ErrorResponse{Error: ErrorBody{
+		Code:          he.Code,
+		Message:       he.Message,
+		CorrelationID: cid,
+		Details:       he.Details,
+	}}
 	status := he.StatusCode
 	if status == 0 {
 		status = http.StatusInternalServerError
 	}
-	log.Error().Str("correlation_id", cid).Str("code", he.Code).Err(he.Err).Msg(he.Message)
+	log.Error().Str("correlation_id", cid).Str("trace_id", pkgrequestctx.TraceID(r.Context())).Str("code", he.Code).Err(he.Err).Msg(he.Message)
 	WriteJSON(w, status, payload)
 }
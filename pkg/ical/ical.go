@@ -0,0 +1,162 @@
+// Package ical writes iCalendar (RFC 5545) documents for calendar
+// subscriptions: published events with stable UIDs, so clients update an event
+// whose SEQUENCE went up instead of adding a duplicate.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of a rendered calendar.
+const ContentType = "text/calendar; charset=utf-8"
+
+// Calendar is a VCALENDAR with its events.
+type Calendar struct {
+	ProdID      string // e.g. -//example//calendar//EN
+	Name        string
+	Description string
+	// Refresh is how often clients should poll (REFRESH-INTERVAL and the
+	// X-PUBLISHED-TTL most clients read); zero leaves it to them.
+	Refresh time.Duration
+	Events  []Event
+}
+
+// Event is a VEVENT. An AllDay event covers the dates from Start up to but
+// excluding End; otherwise Start and End are instants written in UTC.
+type Event struct {
+	UID         string
+	Sequence    int
+	Stamp       time.Time // when the event's information last changed
+	Summary     string
+	Description string
+	URL         string
+	Categories  []string
+	AllDay      bool
+	Start       time.Time
+	End         time.Time
+	// Alarm, when positive, adds a display reminder that long before Start.
+	Alarm time.Duration
+}
+
+// Encode renders c with CRLF line endings and lines folded at 75 octets.
+func (c Calendar) Encode() []byte {
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.prop("PRODID", c.ProdID)
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		w.text("NAME", c.Name)
+		w.text("X-WR-CALNAME", c.Name)
+	}
+	if c.Description != "" {
+		w.text("DESCRIPTION", c.Description)
+		w.text("X-WR-CALDESC", c.Description)
+	}
+	if c.Refresh > 0 {
+		w.prop("REFRESH-INTERVAL;VALUE=DURATION", duration(c.Refresh))
+		w.prop("X-PUBLISHED-TTL", duration(c.Refresh))
+	}
+	for _, e := range c.Events {
+		w.line("BEGIN:VEVENT")
+		w.prop("UID", e.UID)
+		w.prop("DTSTAMP", utc(e.Stamp))
+		w.prop("LAST-MODIFIED", utc(e.Stamp))
+		w.prop("SEQUENCE", fmt.Sprint(e.Sequence))
+		if e.AllDay {
+			w.prop("DTSTART;VALUE=DATE", e.Start.Format("20060102"))
+			w.prop("DTEND;VALUE=DATE", e.End.Format("20060102"))
+			w.line("TRANSP:TRANSPARENT")
+		} else {
+			w.prop("DTSTART", utc(e.Start))
+			w.prop("DTEND", utc(e.End))
+		}
+		w.text("SUMMARY", e.Summary)
+		if e.Description != "" {
+			w.text("DESCRIPTION", e.Description)
+		}
+		if e.URL != "" {
+			w.prop("URL", e.URL)
+		}
+		if len(e.Categories) > 0 {
+			parts := make([]string, len(e.Categories))
+			for i, cat := range e.Categories {
+				parts[i] = escape(cat)
+			}
+			w.prop("CATEGORIES", strings.Join(parts, ","))
+		}
+		if e.Alarm > 0 {
+			w.line("BEGIN:VALARM")
+			w.line("ACTION:DISPLAY")
+			w.text("DESCRIPTION", e.Summary)
+			w.prop("TRIGGER", "-"+duration(e.Alarm))
+			w.line("END:VALARM")
+		}
+		w.line("END:VEVENT")
+	}
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+func (w *writer) prop(name, value string) { w.line(name + ":" + value) }
+
+func (w *writer) text(name, value string) { w.line(name + ":" + escape(value)) }
+
+// line writes a content line folded after 75 octets, never inside a UTF-8
+// sequence; continuation lines start with a space.
+func (w *writer) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // the leading space counts
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escape escapes a TEXT value (RFC 5545 3.3.11).
+func escape(s string) string { return textEscaper.Replace(s) }
+
+func utc(t time.Time) string { return t.UTC().Format("20060102T150405Z") }
+
+// duration formats d as an RFC 5545 duration in whole days, hours, minutes
+// and seconds, e.g. P1D or PT12H.
+func duration(d time.Duration) string {
+	secs := int64(d / time.Second)
+	days := secs / 86400
+	secs %= 86400
+	out := "P"
+	if days > 0 {
+		out += fmt.Sprintf("%dD", days)
+	}
+	if secs > 0 || days == 0 {
+		out += "T"
+		if h := secs / 3600; h > 0 {
+			out += fmt.Sprintf("%dH", h)
+		}
+		if m := secs % 3600 / 60; m > 0 {
+			out += fmt.Sprintf("%dM", m)
+		}
+		if s := secs % 60; s > 0 || secs == 0 {
+			out += fmt.Sprintf("%dS", s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeEventsAndFolding(t *testing.T) {
+	release := time.Date(2025, 10, 3, 0, 0, 0, 0, time.UTC)
+	c := Calendar{
+		ProdID:  "-//test//calendar//EN",
+		Name:    "Releases, RO",
+		Refresh: 12 * time.Hour,
+		Events: []Event{{
+			UID:        "release-1-RO@test",
+			Sequence:   2,
+			Stamp:      time.Date(2025, 9, 20, 8, 30, 0, 0, time.UTC),
+			Summary:    "Dune; Part Two, in cinemas",
+			URL:        "https://example.com/movie/1",
+			Categories: []string{"Release", "RO"},
+			AllDay:     true,
+			Start:      release,
+			End:        release.AddDate(0, 0, 1),
+		}, {
+			UID:         "vote-close-1-RO@test",
+			Stamp:       time.Date(2025, 9, 20, 8, 30, 0, 0, time.UTC),
+			Summary:     "Voting closes",
+			Description: strings.Repeat("ă", 60) + "\nsecond line",
+			Start:       release.AddDate(0, 0, 14),
+			End:         release.AddDate(0, 0, 14),
+			Alarm:       24 * time.Hour,
+		}},
+	}
+	out := string(c.Encode())
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"X-WR-CALNAME:Releases\\, RO\r\n",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT12H\r\n",
+		"UID:release-1-RO@test\r\nDTSTAMP:20250920T083000Z\r\n",
+		"SEQUENCE:2\r\n",
+		"DTSTART;VALUE=DATE:20251003\r\nDTEND;VALUE=DATE:20251004\r\n",
+		"SUMMARY:Dune\\; Part Two\\, in cinemas\r\n",
+		"CATEGORIES:Release,RO\r\n",
+		"DTSTART:20251017T000000Z\r\n",
+		"TRIGGER:-P1D\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("line longer than 75 octets: %q", line)
+		}
+	}
+	if strings.Contains(out, "\n\n") || strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Fatalf("bare newline in output:\n%s", out)
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, "DESCRIPTION:"+strings.Repeat("ă", 60)+"\\nsecond line\r\n") {
+		t.Fatalf("folding broke the description:\n%s", unfolded)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                          "PT0S",
+		90 * time.Minute:           "PT1H30M",
+		24 * time.Hour:             "P1D",
+		36*time.Hour + time.Second: "P1DT12H1S",
+	} {
+		if got := duration(d); got != want {
+			t.Errorf("duration(%v) = %s, want %s", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package metrics holds the Prometheus collectors shared across the server.
+// Everything registers on Registry, which is exposed on the admin listener.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "cinekami"
+
+// Registry is the process-wide registry served at /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestDuration observes request latency by route pattern, method and status.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// CacheRequests counts cache lookups by key family and result (hit|miss).
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Cache lookups by key family and result.",
+	}, []string{"family", "result"})
+
+	// TMDbRequests counts outgoing TMDb calls by endpoint.
+	TMDbRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tmdb",
+		Name:      "requests_total",
+		Help:      "TMDb API requests by endpoint.",
+	}, []string{"endpoint"})
+
+	// TMDbErrors counts failed TMDb calls (transport errors and non-200 responses) by endpoint.
+	TMDbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tmdb",
+		Name:      "errors_total",
+		Help:      "TMDb API errors by endpoint.",
+	}, []string{"endpoint"})
+
+	// JobRunDuration observes background job run time by job and outcome (success|error).
+	JobRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "run_duration_seconds",
+		Help:      "Background job run duration by job and outcome.",
+		Buckets:   []float64{.1, .5, 1, 5, 15, 30, 60, 120, 300, 600},
+	}, []string{"job", "outcome"})
+
+	// JobLastSuccess records the unix time of the last successful run per job.
+	JobLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful run per job.",
+	}, []string{"job"})
+
+	// VotesRecorded counts accepted votes by category.
+	VotesRecorded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "votes",
+		Name:      "recorded_total",
+		Help:      "Votes recorded by category.",
+	}, []string{"category"})
+
+	// VotesDuplicate counts votes ignored because the voter already voted on the movie.
+	VotesDuplicate = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "votes",
+		Name:      "duplicates_ignored_total",
+		Help:      "Votes ignored because the voter had already voted for the movie.",
+	})
+
+	// WebhookDeliveries counts webhook delivery attempts by event and outcome (delivered|retry|dead).
+	WebhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhooks",
+		Name:      "deliveries_total",
+		Help:      "Webhook delivery attempts by event and outcome.",
+	}, []string{"event", "outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		CacheRequests,
+		TMDbRequests,
+		TMDbErrors,
+		JobRunDuration,
+		JobLastSuccess,
+		VotesRecorded,
+		VotesDuplicate,
+		WebhookDeliveries,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveHTTP records a finished HTTP request.
+func ObserveHTTP(route, method string, status int, d time.Duration) {
+	HTTPRequestDuration.WithLabelValues(route, method, strconv.Itoa(status)).Observe(d.Seconds())
+}
+
+// ObserveJob records a finished job run and bumps the last-success gauge on success.
+func ObserveJob(job string, started time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	JobRunDuration.WithLabelValues(job, outcome).Observe(time.Since(started).Seconds())
+	if err == nil {
+		JobLastSuccess.WithLabelValues(job).SetToCurrentTime()
+	}
+}
+
+// RegisterPool exposes pgxpool statistics for the given pool.
+func RegisterPool(pool *pgxpool.Pool) error {
+	return Registry.Register(&poolCollector{pool: pool})
+}
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+var (
+	poolAcquired     = poolDesc("acquired_conns", "Connections currently in use.")
+	poolIdle         = poolDesc("idle_conns", "Idle connections in the pool.")
+	poolTotal        = poolDesc("total_conns", "Total connections in the pool.")
+	poolMax          = poolDesc("max_conns", "Maximum pool size.")
+	poolAcquires     = poolDesc("acquire_total", "Successful connection acquires.")
+	poolEmptyAcquire = poolDesc("empty_acquire_total", "Acquires that had to wait for a connection.")
+	poolCanceled     = poolDesc("canceled_acquire_total", "Acquires canceled by their context.")
+	poolAcquireWait  = poolDesc("acquire_duration_seconds_total", "Total time spent waiting to acquire connections.")
+	poolNewConns     = poolDesc("new_conns_total", "Connections opened.")
+)
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{poolAcquired, poolIdle, poolTotal, poolMax, poolAcquires, poolEmptyAcquire, poolCanceled, poolAcquireWait, poolNewConns} {
+		ch <- d
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotal, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMax, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquire, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceled, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireWait, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(poolNewConns, prometheus.CounterValue, float64(s.NewConnsCount()))
+}
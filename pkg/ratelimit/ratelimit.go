@@ -0,0 +1,55 @@
+// Package ratelimit counts attempts per key in fixed windows, in memory.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows limit attempts per key and window. It is safe for concurrent
+// use; keys whose window ended are dropped as new attempts arrive.
+type Limiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	counts map[string]*count
+	swept  time.Time
+}
+
+type count struct {
+	n     int
+	start time.Time
+}
+
+// New returns a Limiter of limit attempts per key and window.
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{limit: limit, window: window, now: time.Now, counts: make(map[string]*count)}
+}
+
+// Allow counts an attempt for key. Over the limit it reports false and how
+// long until the key's window ends.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.swept) >= l.window {
+		for k, c := range l.counts {
+			if now.Sub(c.start) >= l.window {
+				delete(l.counts, k)
+			}
+		}
+		l.swept = now
+	}
+	c, ok := l.counts[key]
+	if !ok || now.Sub(c.start) >= l.window {
+		c = &count{start: now}
+		l.counts[key] = c
+	}
+	if c.n >= l.limit {
+		return false, c.start.Add(l.window).Sub(now)
+	}
+	c.n++
+	return true, 0
+}
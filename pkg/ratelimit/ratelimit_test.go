@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	l := New(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("attempt %d refused", i+1)
+		}
+	}
+	now = now.Add(20 * time.Second)
+	if ok, wait := l.Allow("a"); ok || wait != 40*time.Second {
+		t.Fatalf("third attempt: got %v, wait %s", ok, wait)
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Fatalf("other key refused")
+	}
+
+	now = now.Add(40 * time.Second)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Fatalf("attempt after the window refused")
+	}
+	now = now.Add(time.Minute)
+	l.Allow("a")
+	if _, ok := l.counts["b"]; ok {
+		t.Fatalf("expired key not dropped")
+	}
+}
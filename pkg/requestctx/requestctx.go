@@ -4,7 +4,11 @@ import "context"
 
 type ctxKey string
 
-const correlationIDKey ctxKey = "correlation_id"
+const (
+	correlationIDKey ctxKey = "correlation_id"
+	traceIDKey       ctxKey = "trace_id"
+	actorKey         ctxKey = "actor"
+)
 
 // WithCorrelationID returns a new context with the provided correlation ID.
 func WithCorrelationID(ctx context.Context, id string) context.Context {
@@ -19,3 +23,33 @@ func CorrelationID(ctx context.Context) string {
 	}
 	return ""
 }
+
+// WithTraceID returns a new context carrying the OpenTelemetry trace ID of the request,
+// so logs can be joined with traces next to the correlation ID.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey, id)
+}
+
+// TraceID fetches the trace ID from the context, if any.
+func TraceID(ctx context.Context) string {
+	v := ctx.Value(traceIDKey)
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
+
+// WithActor returns a new context carrying the authenticated admin actor,
+// e.g. "api_key:deploy" or "user:ops@example.com".
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor fetches the authenticated admin actor from the context, if any.
+func Actor(ctx context.Context) string {
+	v := ctx.Value(actorKey)
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+// Package stats holds the small amount of statistics the API reports about votes.
+package stats
+
+import (
+	"math"
+	"strconv"
+)
+
+// Z95 is the standard normal quantile for a two-sided 95% interval.
+const Z95 = 1.959963984540054
+
+// Wilson returns the Wilson score interval for k successes out of n trials at
+// quantile z. It behaves well for small n and shares near 0 or 1, unlike the
+// normal approximation; n == 0 yields [0, 0].
+func Wilson(k, n int64, z float64) (low, high float64) {
+	if n <= 0 {
+		return 0, 0
+	}
+	nf := float64(n)
+	p := float64(k) / nf
+	z2 := z * z
+	center := p + z2/(2*nf)
+	margin := z * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf))
+	denom := 1 + z2/nf
+	low = math.Max(0, (center-margin)/denom)
+	high = math.Min(1, (center+margin)/denom)
+	return low, high
+}
+
+// WilsonLowerSQL is the lower bound of Wilson(k, n, Z95) as a float8 SQL
+// expression over the given count and total columns; 0 when total is 0. It must
+// stay in step with Wilson so that sorted listings agree with reported intervals.
+func WilsonLowerSQL(k, n string) string {
+	p := "(" + k + "::float8 / " + n + ")"
+	z := strconv.FormatFloat(Z95, 'g', -1, 64) + "::float8"
+	z2 := "(" + z + " * " + z + ")"
+	return "(CASE WHEN " + n + " = 0 THEN 0::float8 ELSE greatest(0, (" +
+		p + " + " + z2 + " / (2 * " + n + ") - " + z + " * sqrt(" + p + " * (1 - " + p + ") / " + n + " + " + z2 + " / (4 * " + n + "::float8 * " + n + ")))" +
+		" / (1 + " + z2 + " / " + n + ")) END)"
+}
@@ -0,0 +1,24 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWilson(t *testing.T) {
+	cases := []struct {
+		k, n      int64
+		low, high float64
+	}{
+		{0, 0, 0, 0},
+		{3, 3, 0.4385, 1},
+		{0, 10, 0, 0.2775},
+		{1500, 3000, 0.4821, 0.5179},
+	}
+	for _, c := range cases {
+		low, high := Wilson(c.k, c.n, Z95)
+		if math.Abs(low-c.low) > 1e-4 || math.Abs(high-c.high) > 1e-4 {
+			t.Errorf("Wilson(%d, %d) = [%.4f, %.4f], want [%.4f, %.4f]", c.k, c.n, low, high, c.low, c.high)
+		}
+	}
+}
@@ -1,12 +1,24 @@
 package tmdb
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	pkgmetrics "cinekami-server/pkg/metrics"
+	pkgtracing "cinekami-server/pkg/tracing"
 )
 
 type Client struct {
@@ -16,13 +28,16 @@ type Client struct {
 }
 
 type Movie struct {
-	TMDBID       int32
-	Title        string
-	ReleaseDate  time.Time
-	Overview     string
-	PosterPath   string
-	BackdropPath string
-	Popularity   float64
+	TMDBID           int32
+	Title            string
+	OriginalTitle    string
+	OriginalLanguage string // ISO 639-1
+	ReleaseDate      time.Time
+	Overview         string
+	PosterPath       string
+	BackdropPath     string
+	Popularity       float64
+	GenreIDs         []int32
 }
 
 type discoverResp struct {
@@ -34,6 +49,7 @@ type discoverResp struct {
 type discoverItem struct {
 	ID               int32   `json:"id"`
 	Title            string  `json:"title"`
+	OriginalTitle    string  `json:"original_title"`
 	ReleaseDate      string  `json:"release_date"`
 	Overview         string  `json:"overview"`
 	PosterPath       string  `json:"poster_path"`
@@ -41,20 +57,90 @@ type discoverItem struct {
 	Popularity       float64 `json:"popularity"`
 	OriginalLanguage string  `json:"original_language"`
 	Adult            bool    `json:"adult"`
+	GenreIDs         []int32 `json:"genre_ids"`
+}
+
+// Release types of the release_dates endpoint; discovery and regional dates only
+// consider the theatrical ones.
+const (
+	ReleasePremiere          = 1
+	ReleaseTheatricalLimited = 2
+	ReleaseTheatrical        = 3
+)
+
+type releaseDatesResp struct {
+	Results []struct {
+		Region       string `json:"iso_3166_1"`
+		ReleaseDates []struct {
+			ReleaseDate string `json:"release_date"` // RFC 3339
+			Type        int    `json:"type"`
+		} `json:"release_dates"`
+	} `json:"results"`
+}
+
+// Translation is a movie's title and overview in one language. Either may be
+// empty when TMDb has no translated text, typically when it equals the original.
+type Translation struct {
+	Language string // ISO 639-1, e.g. ro
+	Country  string // ISO 3166-1, e.g. RO
+	Title    string
+	Overview string
+}
+
+type translationsResp struct {
+	Translations []struct {
+		Language string `json:"iso_639_1"`
+		Country  string `json:"iso_3166_1"`
+		Data     struct {
+			Title    string `json:"title"`
+			Overview string `json:"overview"`
+		} `json:"data"`
+	} `json:"translations"`
 }
 
+type movieResp struct {
+	ID               int32   `json:"id"`
+	Title            string  `json:"title"`
+	OriginalTitle    string  `json:"original_title"`
+	ReleaseDate      string  `json:"release_date"`
+	Overview         string  `json:"overview"`
+	PosterPath       string  `json:"poster_path"`
+	BackdropPath     string  `json:"backdrop_path"`
+	Popularity       float64 `json:"popularity"`
+	OriginalLanguage string  `json:"original_language"`
+	Genres           []struct {
+		ID int32 `json:"id"`
+	} `json:"genres"`
+}
+
+// ErrNotFound is returned by GetMovie for ids TMDb does not know.
+var ErrNotFound = errors.New("tmdb: movie not found")
+
 type ExternalIDs struct {
 	ImdbID string `json:"imdb_id"`
 	// other fields omitted
 }
 
+// ImageURL returns the public URL of an image path such as a movie's
+// poster_path in the given size (e.g. w500 or original).
+func ImageURL(path, size string) string {
+	return "https://image.tmdb.org/t/p/" + size + path
+}
+
+// MovieURL returns the TMDb page of a movie.
+func MovieURL(id int64) string {
+	return "https://www.themoviedb.org/movie/" + strconv.FormatInt(id, 10)
+}
+
 func New(apiKey string) *Client {
 	return &Client{APIKey: apiKey, BaseURL: "https://api.themoviedb.org/3", Client: &http.Client{Timeout: 15 * time.Second}}
 }
 
-// DiscoverByReleaseWindow fetches movies with a primary_release_date between start and end (inclusive).
+// DiscoverByReleaseWindow fetches movies released between start and end (inclusive):
+// in region when one is given, by primary release date otherwise. ReleaseDate
+// of the results is still the primary release date; see GetRegionalReleaseDates.
 // If maxPages <= 0, fetch all pages; otherwise stop at maxPages.
-func (c *Client) DiscoverByReleaseWindow(start, end time.Time, region, language string, maxPages int) ([]Movie, error) {
+func (c *Client) DiscoverByReleaseWindow(ctx context.Context, start, end time.Time, region, language string, maxPages int) ([]Movie, error) {
 	if c.APIKey == "" {
 		return nil, fmt.Errorf("missing TMDB API key")
 	}
@@ -84,12 +170,16 @@ func (c *Client) DiscoverByReleaseWindow(start, end time.Time, region, language
 		u, _ := url.Parse(c.BaseURL + "/discover/movie")
 		q := u.Query()
 		q.Set("api_key", c.APIKey)
+		q.Set("with_release_type", "3|2|1") // Premiere, Theatrical Limited, Theatrical
 		if region != "" {
+			// release_date.* match the region's release dates of the types above
 			q.Set("region", region)
+			q.Set("release_date.gte", start.Format("2006-01-02"))
+			q.Set("release_date.lte", end.Format("2006-01-02"))
+		} else {
+			q.Set("primary_release_date.gte", start.Format("2006-01-02"))
+			q.Set("primary_release_date.lte", end.Format("2006-01-02"))
 		}
-		q.Set("with_release_type", "3|2|1") // Premiere, Theatrical Limited, Theatrical
-		q.Set("primary_release_date.gte", start.Format("2006-01-02"))
-		q.Set("primary_release_date.lte", end.Format("2006-01-02"))
 		if language != "" {
 			q.Set("language", language)
 		}
@@ -97,8 +187,7 @@ func (c *Client) DiscoverByReleaseWindow(start, end time.Time, region, language
 		q.Set("page", strconv.Itoa(page))
 		u.RawQuery = q.Encode()
 
-		req, _ := http.NewRequest(http.MethodGet, u.String(), nil)
-		resp, err := c.Client.Do(req)
+		resp, err := c.get(ctx, "discover_movie", u.String())
 		if err != nil {
 			return nil, err
 		}
@@ -137,7 +226,7 @@ func (c *Client) DiscoverByReleaseWindow(start, end time.Time, region, language
 				if e != nil {
 					continue
 				}
-				out = append(out, Movie{TMDBID: it.ID, Title: it.Title, ReleaseDate: d, Overview: it.Overview, PosterPath: it.PosterPath, BackdropPath: it.BackdropPath, Popularity: it.Popularity})
+				out = append(out, Movie{TMDBID: it.ID, Title: it.Title, OriginalTitle: it.OriginalTitle, OriginalLanguage: it.OriginalLanguage, ReleaseDate: d, Overview: it.Overview, PosterPath: it.PosterPath, BackdropPath: it.BackdropPath, Popularity: it.Popularity, GenreIDs: it.GenreIDs})
 			}
 			// Determine if we're done fetching pages
 			if (maxPages > 0 && page >= maxPages) || dr.Page >= dr.TotalPages {
@@ -156,8 +245,44 @@ func (c *Client) DiscoverByReleaseWindow(start, end time.Time, region, language
 	return out, nil
 }
 
+// GetMovie fetches a single movie's details. ReleaseDate is the primary
+// release date (zero when TMDb has none); see GetRegionalReleaseDates.
+func (c *Client) GetMovie(ctx context.Context, movieID int32) (Movie, error) {
+	if c.APIKey == "" {
+		return Movie{}, fmt.Errorf("missing TMDB API key")
+	}
+	u, _ := url.Parse(fmt.Sprintf(c.BaseURL+"/movie/%d", movieID))
+	q := u.Query()
+	q.Set("api_key", c.APIKey)
+	u.RawQuery = q.Encode()
+	resp, err := c.get(ctx, "movie_details", u.String())
+	if err != nil {
+		return Movie{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Movie{}, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Movie{}, fmt.Errorf("tmdb movie status %d", resp.StatusCode)
+	}
+	var mr movieResp
+	if err := json.NewDecoder(resp.Body).Decode(&mr); err != nil {
+		return Movie{}, err
+	}
+	m := Movie{TMDBID: mr.ID, Title: mr.Title, OriginalTitle: mr.OriginalTitle, OriginalLanguage: mr.OriginalLanguage, Overview: mr.Overview,
+		PosterPath: mr.PosterPath, BackdropPath: mr.BackdropPath, Popularity: mr.Popularity, GenreIDs: make([]int32, 0, len(mr.Genres))}
+	if d, err := time.Parse("2006-01-02", mr.ReleaseDate); err == nil {
+		m.ReleaseDate = d
+	}
+	for _, g := range mr.Genres {
+		m.GenreIDs = append(m.GenreIDs, g.ID)
+	}
+	return m, nil
+}
+
 // GetExternalIDs fetches external IDs for a movie (imdb_id, etc.).
-func (c *Client) GetExternalIDs(movieID int32) (ExternalIDs, error) {
+func (c *Client) GetExternalIDs(ctx context.Context, movieID int32) (ExternalIDs, error) {
 	var out ExternalIDs
 	if c.APIKey == "" {
 		return out, fmt.Errorf("missing TMDB API key")
@@ -166,8 +291,7 @@ func (c *Client) GetExternalIDs(movieID int32) (ExternalIDs, error) {
 	q := u.Query()
 	q.Set("api_key", c.APIKey)
 	u.RawQuery = q.Encode()
-	req, _ := http.NewRequest(http.MethodGet, u.String(), nil)
-	resp, err := c.Client.Do(req)
+	resp, err := c.get(ctx, "movie_external_ids", u.String())
 	if err != nil {
 		return out, err
 	}
@@ -180,3 +304,143 @@ func (c *Client) GetExternalIDs(movieID int32) (ExternalIDs, error) {
 	}
 	return out, nil
 }
+
+// GetRegionalReleaseDates fetches a movie's release dates and returns the
+// earliest premiere or theatrical date per region (ISO 3166-1 code).
+func (c *Client) GetRegionalReleaseDates(ctx context.Context, movieID int32) (map[string]time.Time, error) {
+	if c.APIKey == "" {
+		return nil, fmt.Errorf("missing TMDB API key")
+	}
+	u, _ := url.Parse(fmt.Sprintf(c.BaseURL+"/movie/%d/release_dates", movieID))
+	q := u.Query()
+	q.Set("api_key", c.APIKey)
+	u.RawQuery = q.Encode()
+	resp, err := c.get(ctx, "movie_release_dates", u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tmdb release_dates status %d", resp.StatusCode)
+	}
+	var rr releaseDatesResp
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, err
+	}
+	out := make(map[string]time.Time, len(rr.Results))
+	for _, res := range rr.Results {
+		for _, rd := range res.ReleaseDates {
+			if rd.Type != ReleasePremiere && rd.Type != ReleaseTheatricalLimited && rd.Type != ReleaseTheatrical {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, rd.ReleaseDate)
+			if err != nil {
+				continue
+			}
+			d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+			if cur, ok := out[res.Region]; !ok || d.Before(cur) {
+				out[res.Region] = d
+			}
+		}
+	}
+	return out, nil
+}
+
+// GetTranslations fetches the titles and overviews TMDb has for a movie in every language.
+func (c *Client) GetTranslations(ctx context.Context, movieID int32) ([]Translation, error) {
+	if c.APIKey == "" {
+		return nil, fmt.Errorf("missing TMDB API key")
+	}
+	u, _ := url.Parse(fmt.Sprintf(c.BaseURL+"/movie/%d/translations", movieID))
+	q := u.Query()
+	q.Set("api_key", c.APIKey)
+	u.RawQuery = q.Encode()
+	resp, err := c.get(ctx, "movie_translations", u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tmdb translations status %d", resp.StatusCode)
+	}
+	var tr translationsResp
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, err
+	}
+	out := make([]Translation, 0, len(tr.Translations))
+	for _, t := range tr.Translations {
+		out = append(out, Translation{Language: t.Language, Country: t.Country, Title: t.Data.Title, Overview: t.Data.Overview})
+	}
+	return out, nil
+}
+
+// PickTranslation returns the translation for a language tag such as ro-RO:
+// the exact language and country if TMDb has it, otherwise any translation in
+// the same language.
+func PickTranslation(ts []Translation, tag string) (Translation, bool) {
+	lang, country, _ := strings.Cut(tag, "-")
+	var fallback *Translation
+	for i, t := range ts {
+		if !strings.EqualFold(t.Language, lang) {
+			continue
+		}
+		if strings.EqualFold(t.Country, country) {
+			return t, true
+		}
+		if fallback == nil {
+			fallback = &ts[i]
+		}
+	}
+	if fallback == nil {
+		return Translation{}, false
+	}
+	return *fallback, true
+}
+
+// get performs a GET request inside a client span, propagating the trace context,
+// and records request and error counts for endpoint.
+// Callers own the response body and still check the status code.
+func (c *Client) get(ctx context.Context, endpoint, rawURL string) (*http.Response, error) {
+	ctx, span := pkgtracing.Tracer().Start(ctx, "tmdb "+endpoint, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", http.MethodGet),
+			attribute.String("tmdb.endpoint", endpoint),
+		))
+	defer span.End()
+	pkgmetrics.TMDbRequests.WithLabelValues(endpoint).Inc()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		pkgmetrics.TMDbErrors.WithLabelValues(endpoint).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode != http.StatusOK {
+		pkgmetrics.TMDbErrors.WithLabelValues(endpoint).Inc()
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
+
+// ValidateKey checks the API key against TMDb's authentication endpoint.
+func (c *Client) ValidateKey(ctx context.Context) error {
+	if c.APIKey == "" {
+		return fmt.Errorf("missing TMDB API key")
+	}
+	u, _ := url.Parse(c.BaseURL + "/authentication")
+	q := u.Query()
+	q.Set("api_key", c.APIKey)
+	u.RawQuery = q.Encode()
+	resp, err := c.get(ctx, "authentication", u.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tmdb authentication status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Package webhook signs and sends webhook deliveries. Receivers verify the
+// X-Cinekami-Signature header with Verify (or its equivalent): an HMAC-SHA256
+// of "<unix seconds>.<body>" keyed with the subscription secret.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on every delivery.
+const (
+	SignatureHeader = "X-Cinekami-Signature"
+	EventHeader     = "X-Cinekami-Event"
+	DeliveryHeader  = "X-Cinekami-Delivery"
+)
+
+var (
+	ErrBadSignature = errors.New("webhook: bad signature")
+	ErrExpired      = errors.New("webhook: signature timestamp outside tolerance")
+)
+
+// Envelope is the JSON body of a delivery. ID is stable across retries, so
+// receivers can drop duplicates.
+type Envelope struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Sign returns the signature header value for body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + mac(secret, ts, body)
+}
+
+// Verify checks a signature header against body and rejects timestamps more
+// than tolerance away from now, which bounds replays.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return ErrBadSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(mac(secret, ts, body))) {
+		return ErrBadSignature
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return ErrExpired
+	}
+	return nil
+}
+
+func mac(secret, ts string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(ts))
+	m.Write([]byte("."))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Backoff is the wait before retry number attempt (1-based): 30s doubling per
+// attempt, capped at 6h.
+func Backoff(attempt int) time.Duration {
+	const base, ceiling = 30 * time.Second, 6 * time.Hour
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 20 {
+		return ceiling
+	}
+	return min(base<<(attempt-1), ceiling)
+}
+
+// Sender posts signed envelopes.
+type Sender struct {
+	Client    *http.Client
+	UserAgent string
+	Now       func() time.Time // for tests; defaults to time.Now
+}
+
+// StatusError is returned for a non-2xx response.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook: receiver answered %d", e.StatusCode)
+}
+
+// Send posts env to url signed with secret and returns the response status
+// (0 when no response arrived). Any non-2xx status is an error.
+func (s *Sender) Send(ctx context.Context, url, secret string, env Envelope) (int, error) {
+	body, err := json.Marshal(env)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, env.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(env.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, now(), body))
+	if s.UserAgent != "" {
+		req.Header.Set("User-Agent", s.UserAgent)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, &StatusError{StatusCode: resp.StatusCode}
+	}
+	return resp.StatusCode, nil
+}
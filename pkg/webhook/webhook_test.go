@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendSignsEnvelope(t *testing.T) {
+	const secret = "whsec-test"
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	var got Envelope
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify(secret, r.Header.Get(SignatureHeader), body, now, 5*time.Minute); err != nil {
+			t.Errorf("verify: %v", err)
+		}
+		if r.Header.Get(EventHeader) != "snapshot.closed" || r.Header.Get(DeliveryHeader) != "42" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		_ = json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	s := &Sender{Client: srv.Client(), Now: func() time.Time { return now }}
+	env := Envelope{ID: 42, Event: "snapshot.closed", CreatedAt: now, Data: json.RawMessage(`{"region":"RO","month":"2025-09"}`)}
+	status, err := s.Send(context.Background(), srv.URL, secret, env)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("send: %d %v", status, err)
+	}
+	if got.ID != 42 || string(got.Data) != `{"region":"RO","month":"2025-09"}` {
+		t.Fatalf("receiver got %+v", got)
+	}
+}
+
+func TestSendReportsFailureStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	status, err := (&Sender{Client: srv.Client()}).Send(context.Background(), srv.URL, "s", Envelope{ID: 1, Event: "e"})
+	var se *StatusError
+	if status != http.StatusBadGateway || !errors.As(err, &se) {
+		t.Fatalf("expected a 502 StatusError, got %d %v", status, err)
+	}
+}
+
+func TestVerifyRejectsTamperingAndReplays(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":1}`)
+	sig := Sign("secret", now, body)
+	if err := Verify("secret", sig, []byte(`{"id":2}`), now, time.Minute); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("tampered body: %v", err)
+	}
+	if err := Verify("other", sig, body, now, time.Minute); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("wrong secret: %v", err)
+	}
+	if err := Verify("secret", sig, body, now.Add(time.Hour), time.Minute); !errors.Is(err, ErrExpired) {
+		t.Fatalf("replay: %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 5: 8 * time.Minute, 12: 6 * time.Hour, 100: 6 * time.Hour}
+	for attempt, want := range cases {
+		if got := Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}